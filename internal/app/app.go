@@ -13,7 +13,9 @@ func Init() {
 	docs.SwaggerInfo.BasePath = viper.GetString("SWAGGER_BASEPATH")
 
 	initPostgres()
+	initSQLConnections()
 	initRepositories()
+	initSQLFacts()
 	initElasticsearch()
 	initServices()
 
@@ -57,6 +57,28 @@ var AllowedConfigKey = [][]helpers.ConfigKey{
 		{Type: helpers.StringFlag, Name: "AUTHENTICATION_CREATE_SUPERUSER", DefaultValue: "false", Description: "Create superuser if not exists"},
 		{Type: helpers.StringFlag, Name: "JWT_SIGNING_KEY", DefaultValue: "", Description: "JWT signing key for token generation. If not set, a random key will be generated on startup (in production mode only)."},
 		{Type: helpers.StringFlag, Name: "JOB_BOOST_LIFETIME", DefaultValue: "5m", Description: "Time-to-live for boost and revert actions in the BoostManager. Actions older than this duration will be automatically cleaned up."},
+		{Type: helpers.StringFlag, Name: "FACT_CACHE_ENABLED", DefaultValue: "false", Description: "Enable the in-memory fact result cache"},
+		{Type: helpers.StringFlag, Name: "FACT_CACHE_DEFAULT_TTL", DefaultValue: "0s", Description: "Default TTL of the fact results in cache. 0s only caches the facts with a dedicated TTL."},
+		{Type: helpers.StringFlag, Name: "FACT_CACHE_MAX_ENTRIES", DefaultValue: "10000", Description: "Maximum number of fact results kept in cache"},
+		{Type: helpers.StringFlag, Name: "FACT_COST_MODE", DefaultValue: "off", Description: "Enforcement of the fact cost limits (off, warn, block)"},
+		{Type: helpers.StringFlag, Name: "FACT_COST_MAX_INDICES", DefaultValue: "0", Description: "Maximum number of indices touched by a fact (0 for unlimited)"},
+		{Type: helpers.StringFlag, Name: "FACT_COST_MAX_BUCKETS", DefaultValue: "0", Description: "Maximum estimated number of buckets of a fact (0 for unlimited)"},
+		{Type: helpers.StringFlag, Name: "FACT_COST_MAX_NHIT", DefaultValue: "0", Description: "Maximum number of hits requested on a fact execution (0 for unlimited)"},
+		{Type: helpers.StringFlag, Name: "HISTORY_ARCHIVE_PATH", DefaultValue: "archives/history/", Description: "Directory of the daily history archives written by the purges"},
+		{Type: helpers.StringFlag, Name: "RECOMMENDATION_HISTORY_LIMIT", DefaultValue: "200", Description: "Number of last resolved issues of a situation used to rank the recommendations"},
+		{Type: helpers.StringFlag, Name: "RECOMMENDATION_RECENCY_HALF_LIFE", DefaultValue: "720h", Description: "Age after which a past resolution weights half as much in the recommendations ranking"},
+		{Type: helpers.StringFlag, Name: "RECOMMENDATION_NEIGHBOURS", DefaultValue: "5", Description: "Number of similar past issues (on the fact values) used to rank the recommendations"},
+		{Type: helpers.StringFlag, Name: "ISSUE_EVENTS_BUFFER_SIZE", DefaultValue: "1000", Description: "Number of issue events queued for the notifier before being dropped, when it is too slow (the webhook deliveries are persisted on publication)"},
+		{Type: helpers.StringFlag, Name: "ISSUE_WEBHOOK_TIMEOUT", DefaultValue: "10s", Description: "Timeout of an issue webhook delivery"},
+		{Type: helpers.StringFlag, Name: "ISSUE_WEBHOOK_RETRIES", DefaultValue: "3", Description: "Number of retries of a failed issue webhook delivery, with an exponential backoff"},
+		{Type: helpers.StringFlag, Name: "ISSUE_WEBHOOK_ALLOW_PRIVATE_HOSTS", DefaultValue: "false", Description: "Allow the issue webhooks targeting a loopback, private or link-local address"},
+		{Type: helpers.StringFlag, Name: "ISSUE_TIMEOUT_CHECK_INTERVAL", DefaultValue: "0s", Description: "Interval between the closures of the expired open issues as timed out (0 disables the timeouts)"},
+		{Type: helpers.StringFlag, Name: "ISSUE_FLAPPING_WINDOW", DefaultValue: "0s", Description: "Maximum delay between the closure of an issue and a new detection with the same key to handle it as a flap (0 disables the flapping detection)"},
+		{Type: helpers.StringFlag, Name: "ISSUE_FLAPPING_MODE", DefaultValue: "reopen", Description: "Handling of a flapping issue: reopen the closed issue (if closed without feedback) or link a new issue to it (reopen, link)"},
+		{Type: helpers.StringFlag, Name: "ISSUE_FLAPPING_THRESHOLD", DefaultValue: "3", Description: "Number of flaps from which an issue is flapping (0 never flags an issue as flapping)"},
+		{Type: helpers.StringFlag, Name: "ISSUE_FLAPPING_SUPPRESS_NOTIFICATIONS", DefaultValue: "false", Description: "Suppress the created, reopened and level changed events of the flapping issues"},
+		{Type: helpers.StringFlag, Name: "ISSUE_ATTACHMENTS_BASE_PATH", DefaultValue: "attachments/", Description: "Directory where the files attached to the issue comments are stored"},
+		{Type: helpers.StringFlag, Name: "ISSUE_ATTACHMENTS_MAX_SIZE", DefaultValue: "10485760", Description: "Maximum size in bytes of a file attached to an issue comment (0 for unlimited)"},
 	},
 }
 
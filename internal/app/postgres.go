@@ -2,6 +2,7 @@ package app
 
 import (
 	"github.com/myrteametrics/myrtea-engine-api/v5/migrations"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/fact"
 	"github.com/myrteametrics/myrtea-sdk/v5/postgres"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -41,3 +42,52 @@ func initPostgres() {
 		zap.L().Info("Skipping database migration")
 	}
 }
+
+// initSQLConnections opens the SQL connections usable by SQL facts
+// Only the explicitly configured connections are usable, and a connection with the engine credentials on the engine database is refused
+func initSQLConnections() {
+	if !viper.IsSet("sqlconnection") { // if no key set no connections given, but no parse error
+		return
+	}
+
+	var connections []fact.SQLConnectionConfig
+	if err := viper.UnmarshalKey("sqlconnection", &connections); err != nil {
+		zap.L().Error("Couldn't parse the sql connections configuration", zap.Error(err))
+		return
+	}
+
+	for _, c := range connections {
+		if c.Name == "" {
+			zap.L().Warn("Invalid sql connection name", zap.String("name", c.Name))
+			continue
+		}
+		if c.URL == viper.GetString("POSTGRESQL_HOSTNAME") && c.DbName == viper.GetString("POSTGRESQL_DBNAME") && c.User == viper.GetString("POSTGRESQL_USERNAME") {
+			zap.L().Error("SQL connection refused, it uses the engine credentials on the engine database (a dedicated read-only role is required)", zap.String("name", c.Name))
+			continue
+		}
+		dbClient, err := postgres.DbConnection(postgres.Credentials{
+			URL:      c.URL,
+			Port:     c.Port,
+			DbName:   c.DbName,
+			User:     c.User,
+			Password: c.Password,
+		})
+		if err != nil {
+			zap.L().Error("Couldn't open sql connection", zap.String("name", c.Name), zap.Error(err))
+			continue
+		}
+		fact.RegisterSQLConnection(c.Name, dbClient)
+		zap.L().Info("SQL connection initialized", zap.String("name", c.Name), zap.String("host", c.URL), zap.String("dbname", c.DbName))
+	}
+}
+
+// initSQLFacts loads the SQL definitions of the facts, executed on the SQL connections
+// It must be called once the repositories are initialized
+func initSQLFacts() {
+	sqlDefinitions, err := fact.R().GetSQLDefinitions()
+	if err != nil {
+		zap.L().Error("Couldn't load fact SQL definitions", zap.Error(err))
+		return
+	}
+	fact.LoadSQLDefinitions(sqlDefinitions)
+}
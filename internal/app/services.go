@@ -22,12 +22,21 @@ import (
 	"github.com/myrteametrics/myrtea-sdk/v5/repositories/externalconfig"
 	"github.com/myrteametrics/myrtea-sdk/v5/repositories/variablesconfig"
 
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/baseline"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/connector"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/coordinator"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/action"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/catalog"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/comment"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/draft"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/events"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/incident"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/rootcause"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/sla"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/webhook"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/maintenance"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/modeler"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/notifier"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/notifier/notification"
@@ -37,6 +46,7 @@ import (
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/tasker"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/history"
+	pluginbaseline "github.com/myrteametrics/myrtea-engine-api/v5/pkg/plugins/baseline"
 	"github.com/myrteametrics/myrtea-sdk/v5/postgres"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -54,11 +64,17 @@ func initRepositories() {
 	scheduler.ReplaceGlobalJobBoostManager(scheduler.NewJobBoostManager())
 	notification.ReplaceGlobals(notification.NewPostgresRepository(dbClient))
 	issues.ReplaceGlobals(issues.NewPostgresRepository(dbClient))
+	sla.ReplaceGlobals(sla.NewPostgresRepository(dbClient))
+	incident.ReplaceGlobals(incident.NewPostgresRepository(dbClient))
 	rootcause.ReplaceGlobals(rootcause.NewPostgresRepository(dbClient))
 	action.ReplaceGlobals(action.NewPostgresRepository(dbClient))
+	catalog.ReplaceGlobals(catalog.NewPostgresRepository(dbClient))
+	webhook.ReplaceGlobals(webhook.NewPostgresRepository(dbClient))
+	comment.ReplaceGlobals(comment.NewPostgresRepository(dbClient))
 	draft.ReplaceGlobals(draft.NewPostgresRepository(dbClient))
 	search.ReplaceGlobals(search.NewPostgresRepository(dbClient))
 	calendar.ReplaceGlobals(calendar.NewPostgresRepository(dbClient))
+	maintenance.ReplaceGlobals(maintenance.NewPostgresRepository(dbClient))
 	connector.ReplaceGlobals(connector.NewPostgresRepository(dbClient))
 	rule.ReplaceGlobals(rule.NewPostgresRepository(dbClient))
 	modeler.ReplaceGlobals(modeler.NewPostgresRepository(dbClient))
@@ -70,6 +86,8 @@ func initRepositories() {
 	connectorconfig.ReplaceGlobals(connectorconfig.NewPostgresRepository(dbClient))
 	esconfig.ReplaceGlobals(esconfig.NewPostgresRepository(dbClient))
 	history.ReplaceGlobals(history.New(dbClient))
+	baseline.ReplaceGlobals(baseline.NewPostgresRepository(dbClient))
+	pluginbaseline.RegisterBuiltin(baseline.NewService())
 	variablesconfig.ReplaceGlobals(variablesconfig.NewPostgresRepository(dbClient))
 	apikey.ReplaceGlobals(apikey.NewPostgresRepository(dbClient))
 	confighistory.ReplaceGlobals(confighistory.NewPostgresRepository(dbClient))
@@ -78,7 +96,10 @@ func initRepositories() {
 
 func initServices() {
 	initCoordinator()
+	initFactCache()
 	initNotifier()
+	initIssueEvents()
+	initIssueAttachments()
 	initScheduler()
 	initTasker()
 	initCalendars()
@@ -90,6 +111,15 @@ func stopServices() {
 	tasker.T().StopBatchProcessor()
 	scheduler.S().C.Stop()
 	scheduler.JBM().Stop()
+	if explainer.IT() != nil {
+		explainer.IT().Stop()
+	}
+	if events.B() != nil {
+		events.B().Stop()
+	}
+	if webhook.D() != nil {
+		webhook.D().Stop()
+	}
 }
 
 func initNotifier() {
@@ -97,10 +127,34 @@ func initNotifier() {
 	handler := notification.NewHandler(notificationLifetime)
 	handler.RegisterNotificationType(notification.MockNotification{})
 	handler.RegisterNotificationType(export.ExportNotification{})
+	handler.RegisterNotificationType(events.IssueEventNotification{})
+	handler.RegisterNotificationType(events.IssueMentionNotification{})
 	notification.ReplaceHandlerGlobals(handler)
 	notifier.ReplaceGlobals(notifier.NewNotifier())
 }
 
+func initIssueEvents() {
+	bus := events.NewBus(viper.GetInt("ISSUE_EVENTS_BUFFER_SIZE"))
+	bus.Subscribe("notifier", events.Notify)
+	dispatcher := webhook.NewDispatcher(viper.GetDuration("ISSUE_WEBHOOK_TIMEOUT"), viper.GetInt("ISSUE_WEBHOOK_RETRIES"),
+		viper.GetBool("ISSUE_WEBHOOK_ALLOW_PRIVATE_HOSTS"))
+	bus.SubscribeSync("webhooks", dispatcher.Enqueue)
+	dispatcher.Start()
+	webhook.ReplaceGlobalDispatcher(dispatcher)
+	bus.Start()
+	events.ReplaceGlobals(bus)
+
+	timeouts := explainer.NewIssueTimeouts(viper.GetDuration("ISSUE_TIMEOUT_CHECK_INTERVAL"))
+	timeouts.Start()
+	explainer.ReplaceGlobalIssueTimeouts(timeouts)
+}
+
+func initIssueAttachments() {
+	store := comment.NewAttachmentStore(viper.GetString("ISSUE_ATTACHMENTS_BASE_PATH"), viper.GetInt64("ISSUE_ATTACHMENTS_MAX_SIZE"))
+	store.Init()
+	comment.ReplaceGlobalStore(store)
+}
+
 func initScheduler() {
 	scheduler.ReplaceGlobals(scheduler.NewScheduler())
 	err := scheduler.S().Init()
@@ -112,6 +166,22 @@ func initScheduler() {
 		}
 	}
 }
+
+func initFactCache() {
+	cache := fact.NewResultCache(
+		viper.GetBool("FACT_CACHE_ENABLED"),
+		viper.GetDuration("FACT_CACHE_DEFAULT_TTL"),
+		viper.GetInt("FACT_CACHE_MAX_ENTRIES"),
+	)
+	ttls, err := fact.R().GetCacheTTLs()
+	if err != nil {
+		zap.L().Error("Couldn't load fact cache TTLs", zap.Error(err))
+	} else {
+		cache.LoadTTLs(ttls)
+	}
+	fact.ReplaceGlobalCache(cache)
+}
+
 func initTasker() {
 	tasker.ReplaceGlobals(tasker.NewTasker())
 	tasker.T().StartBatchProcessor()
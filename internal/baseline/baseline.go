@@ -0,0 +1,70 @@
+package baseline
+
+import (
+	"errors"
+	"time"
+)
+
+// Baseline is the definition of a built-in seasonal baseline of a fact
+// The history of the fact is grouped by day of week and hour of day, for each situation instance
+type Baseline struct {
+	ID           int64      `json:"id"`
+	Name         string     `json:"name"`
+	FactID       int64      `json:"factId"`
+	SituationID  int64      `json:"situationId,omitempty"` // Optional, restricts the baseline to a situation
+	LookbackDays int        `json:"lookbackDays"`          // Number of days of history used to compute the baseline
+	BandFactor   float64    `json:"bandFactor"`            // The bands are avg ± bandFactor × std
+	Timezone     string     `json:"timezone,omitempty"`    // Timezone of the days and hours (UTC by default)
+	LastBuiltAt  *time.Time `json:"lastBuiltAt,omitempty"`
+}
+
+// Validate checks if the baseline definition is valid
+func (b Baseline) Validate() error {
+	if b.Name == "" {
+		return errors.New("baseline name is required")
+	}
+	if b.FactID <= 0 {
+		return errors.New("baseline factId is required")
+	}
+	if b.LookbackDays <= 0 {
+		return errors.New("baseline lookbackDays must be positive")
+	}
+	if b.BandFactor < 0 {
+		return errors.New("baseline bandFactor must not be negative")
+	}
+	if _, err := b.Location(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Location returns the timezone of the baseline seasons
+func (b Baseline) Location() (*time.Location, error) {
+	if b.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(b.Timezone)
+}
+
+// SeasonalValue is the value of a baseline for a situation instance, on an hour of a day of week
+type SeasonalValue struct {
+	BaselineID          int64   `json:"baselineId"`
+	SituationID         int64   `json:"situationId"`
+	SituationInstanceID int64   `json:"situationInstanceId"`
+	DayOfWeek           int     `json:"dayOfWeek"` // 0 is sunday
+	Hour                int     `json:"hour"`
+	Count               int64   `json:"count"`
+	Avg                 float64 `json:"avg"`
+	Std                 float64 `json:"std"`
+	Median              float64 `json:"median"`
+	Lower               float64 `json:"lower"`
+	Upper               float64 `json:"upper"`
+}
+
+// FactValue is a value of the fact history
+type FactValue struct {
+	SituationID         int64
+	SituationInstanceID int64
+	Ts                  time.Time
+	Value               float64
+}
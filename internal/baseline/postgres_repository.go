@@ -0,0 +1,257 @@
+package baseline
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/reader"
+)
+
+const (
+	table       = "baseline_v1"
+	valuesTable = "baseline_values_v1"
+
+	valuesInsertBatchSize = 1000
+)
+
+var baselineColumns = []string{"id", "name", "fact_id", "coalesce(situation_id, 0)", "lookback_days", "band_factor", "timezone", "last_built_at"}
+
+// PostgresRepository is a repository containing the built-in baselines based on a PSQL database
+// and implementing the repository interface
+type PostgresRepository struct {
+	conn *sqlx.DB
+}
+
+// NewPostgresRepository returns a new instance of PostgresRepository
+func NewPostgresRepository(dbClient *sqlx.DB) Repository {
+	r := PostgresRepository{
+		conn: dbClient,
+	}
+	var ifm Repository = &r
+	return ifm
+}
+
+// Create creates a new baseline in the repository
+func (r *PostgresRepository) Create(baseline Baseline) (int64, error) {
+	if err := baseline.Validate(); err != nil {
+		return -1, err
+	}
+
+	var id int64
+	err := newStatement().
+		Insert(table).
+		Columns("name", "fact_id", "situation_id", "lookback_days", "band_factor", "timezone").
+		Values(baseline.Name, baseline.FactID, nullableID(baseline.SituationID), baseline.LookbackDays, baseline.BandFactor, baseline.Timezone).
+		Suffix("RETURNING \"id\"").
+		RunWith(r.conn.DB).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// Get returns a baseline by its ID
+func (r *PostgresRepository) Get(id int64) (Baseline, bool, error) {
+	baselines, err := r.query(newStatement().Select(baselineColumns...).From(table).Where(sq.Eq{"id": id}))
+	if err != nil {
+		return Baseline{}, false, err
+	}
+	if len(baselines) == 0 {
+		return Baseline{}, false, nil
+	}
+	return baselines[0], true, nil
+}
+
+// GetAll returns all the baselines
+func (r *PostgresRepository) GetAll() ([]Baseline, error) {
+	return r.query(newStatement().Select(baselineColumns...).From(table).OrderBy("id"))
+}
+
+// GetAllByFactID returns all the baselines of a fact
+func (r *PostgresRepository) GetAllByFactID(factID int64) ([]Baseline, error) {
+	return r.query(newStatement().Select(baselineColumns...).From(table).Where(sq.Eq{"fact_id": factID}).OrderBy("id"))
+}
+
+func (r *PostgresRepository) query(statement sq.SelectBuilder) ([]Baseline, error) {
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	baselines := make([]Baseline, 0)
+	for rows.Next() {
+		var b Baseline
+		var lastBuiltAt sql.NullTime
+		err = rows.Scan(&b.ID, &b.Name, &b.FactID, &b.SituationID, &b.LookbackDays, &b.BandFactor, &b.Timezone, &lastBuiltAt)
+		if err != nil {
+			return nil, err
+		}
+		if lastBuiltAt.Valid {
+			b.LastBuiltAt = &lastBuiltAt.Time
+		}
+		baselines = append(baselines, b)
+	}
+	return baselines, rows.Err()
+}
+
+// Update updates a baseline definition, its seasonal values are kept until the next build
+func (r *PostgresRepository) Update(baseline Baseline) error {
+	if err := baseline.Validate(); err != nil {
+		return err
+	}
+
+	res, err := newStatement().
+		Update(table).
+		Set("name", baseline.Name).
+		Set("fact_id", baseline.FactID).
+		Set("situation_id", nullableID(baseline.SituationID)).
+		Set("lookback_days", baseline.LookbackDays).
+		Set("band_factor", baseline.BandFactor).
+		Set("timezone", baseline.Timezone).
+		Where(sq.Eq{"id": baseline.ID}).
+		RunWith(r.conn.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// Delete deletes a baseline and its seasonal values
+func (r *PostgresRepository) Delete(id int64) error {
+	res, err := newStatement().Delete(table).Where(sq.Eq{"id": id}).RunWith(r.conn.DB).Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// GetSeasonalValue returns the value of a baseline for a situation instance on an hour of a day of week
+func (r *PostgresRepository) GetSeasonalValue(baselineID int64, situationID int64, situationInstanceID int64, dayOfWeek int, hour int) (SeasonalValue, bool, error) {
+	v := SeasonalValue{BaselineID: baselineID, SituationID: situationID, SituationInstanceID: situationInstanceID, DayOfWeek: dayOfWeek, Hour: hour}
+	err := newStatement().
+		Select("count", "avg", "std", "median", "lower", "upper").
+		From(valuesTable).
+		Where(sq.Eq{
+			"baseline_id":           baselineID,
+			"situation_id":          situationID,
+			"situation_instance_id": situationInstanceID,
+			"day_of_week":           dayOfWeek,
+			"hour":                  hour,
+		}).
+		RunWith(r.conn.DB).
+		QueryRow().
+		Scan(&v.Count, &v.Avg, &v.Std, &v.Median, &v.Lower, &v.Upper)
+	if err == sql.ErrNoRows {
+		return SeasonalValue{}, false, nil
+	}
+	if err != nil {
+		return SeasonalValue{}, false, err
+	}
+	return v, true, nil
+}
+
+// ReplaceSeasonalValues replaces all the seasonal values of a baseline, in a single transaction
+func (r *PostgresRepository) ReplaceSeasonalValues(baselineID int64, builtAt time.Time, values []SeasonalValue) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = newStatement().Delete(valuesTable).Where(sq.Eq{"baseline_id": baselineID}).RunWith(tx).Exec()
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(values); start += valuesInsertBatchSize {
+		end := start + valuesInsertBatchSize
+		if end > len(values) {
+			end = len(values)
+		}
+		statement := newStatement().
+			Insert(valuesTable).
+			Columns("baseline_id", "situation_id", "situation_instance_id", "day_of_week", "hour", "count", "avg", "std", "median", "lower", "upper")
+		for _, v := range values[start:end] {
+			statement = statement.Values(baselineID, v.SituationID, v.SituationInstanceID, v.DayOfWeek, v.Hour, v.Count, v.Avg, v.Std, v.Median, v.Lower, v.Upper)
+		}
+		if _, err = statement.RunWith(tx).Exec(); err != nil {
+			return err
+		}
+	}
+
+	_, err = newStatement().Update(table).Set("last_built_at", builtAt).Where(sq.Eq{"id": baselineID}).RunWith(tx).Exec()
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetFactValues returns the values of a fact history over [from, to), optionally restricted to a situation (if situationID > 0)
+func (r *PostgresRepository) GetFactValues(factID int64, situationID int64, from time.Time, to time.Time) ([]FactValue, error) {
+	statement := newStatement().
+		Select("coalesce(situation_id, 0)", "coalesce(situation_instance_id, 0)", "ts", "result").
+		From("fact_history_v5").
+		Where(sq.Eq{"fact_id": factID}).
+		Where(sq.GtOrEq{"ts": from}).
+		Where(sq.Lt{"ts": to})
+	if situationID > 0 {
+		statement = statement.Where(sq.Eq{"situation_id": situationID})
+	}
+
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]FactValue, 0)
+	for rows.Next() {
+		var v FactValue
+		var result []byte
+		if err = rows.Scan(&v.SituationID, &v.SituationInstanceID, &v.Ts, &result); err != nil {
+			return nil, err
+		}
+		if len(result) == 0 {
+			continue
+		}
+
+		var item reader.Item
+		if err = json.Unmarshal(result, &item); err != nil {
+			return nil, err
+		}
+		value, ok := itemValue(item)
+		if !ok {
+			continue
+		}
+		v.Value = value
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+func nullableID(id int64) interface{} {
+	if id <= 0 {
+		return nil
+	}
+	return id
+}
+
+func checkAffected(res sql.Result) error {
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i != 1 {
+		return errors.New("no row updated (or multiple row updated) instead of 1 row")
+	}
+	return nil
+}
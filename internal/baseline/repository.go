@@ -0,0 +1,53 @@
+package baseline
+
+import (
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// Repository is a storage interface which can be implemented by multiple backends
+// (in-memory map, sql database, in-memory cache, file system, ...)
+// It allows standard CRUD operations on the built-in baselines, and the storage of their seasonal values
+type Repository interface {
+	Create(baseline Baseline) (int64, error)
+	Get(id int64) (Baseline, bool, error)
+	GetAll() ([]Baseline, error)
+	GetAllByFactID(factID int64) ([]Baseline, error)
+	Update(baseline Baseline) error
+	Delete(id int64) error
+
+	GetSeasonalValue(baselineID int64, situationID int64, situationInstanceID int64, dayOfWeek int, hour int) (SeasonalValue, bool, error)
+	ReplaceSeasonalValues(baselineID int64, builtAt time.Time, values []SeasonalValue) error
+	GetFactValues(factID int64, situationID int64, from time.Time, to time.Time) ([]FactValue, error)
+}
+
+var (
+	_globalRepositoryMu sync.RWMutex
+	_globalRepository   Repository
+)
+
+// R is used to access the global repository singleton
+func R() Repository {
+	_globalRepositoryMu.RLock()
+	defer _globalRepositoryMu.RUnlock()
+
+	repository := _globalRepository
+	return repository
+}
+
+// ReplaceGlobals affects a new repository to the global repository singleton
+func ReplaceGlobals(repository Repository) func() {
+	_globalRepositoryMu.Lock()
+	defer _globalRepositoryMu.Unlock()
+
+	prev := _globalRepository
+	_globalRepository = repository
+	return func() { ReplaceGlobals(prev) }
+}
+
+// newStatement creates a new SQL statement builder with Dollar placeholder format
+func newStatement() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
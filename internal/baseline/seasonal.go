@@ -0,0 +1,126 @@
+package baseline
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/reader"
+)
+
+type seasonKey struct {
+	situationID         int64
+	situationInstanceID int64
+	dayOfWeek           int
+	hour                int
+}
+
+// season returns the day of week and hour of day of a time in a timezone
+func season(t time.Time, loc *time.Location) (int, int) {
+	t = t.In(loc)
+	return int(t.Weekday()), t.Hour()
+}
+
+// ComputeSeasonalValues computes the seasonal values of a baseline from the fact history values
+// Values are sorted by situation instance, day of week and hour
+func ComputeSeasonalValues(b Baseline, loc *time.Location, values []FactValue) []SeasonalValue {
+	seasons := make(map[seasonKey][]float64)
+	for _, v := range values {
+		dayOfWeek, hour := season(v.Ts, loc)
+		key := seasonKey{situationID: v.SituationID, situationInstanceID: v.SituationInstanceID, dayOfWeek: dayOfWeek, hour: hour}
+		seasons[key] = append(seasons[key], v.Value)
+	}
+
+	result := make([]SeasonalValue, 0, len(seasons))
+	for key, values := range seasons {
+		avg, std := meanStd(values)
+		result = append(result, SeasonalValue{
+			BaselineID:          b.ID,
+			SituationID:         key.situationID,
+			SituationInstanceID: key.situationInstanceID,
+			DayOfWeek:           key.dayOfWeek,
+			Hour:                key.hour,
+			Count:               int64(len(values)),
+			Avg:                 avg,
+			Std:                 std,
+			Median:              median(values),
+			Lower:               avg - b.BandFactor*std,
+			Upper:               avg + b.BandFactor*std,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.SituationID != b.SituationID {
+			return a.SituationID < b.SituationID
+		}
+		if a.SituationInstanceID != b.SituationInstanceID {
+			return a.SituationInstanceID < b.SituationInstanceID
+		}
+		if a.DayOfWeek != b.DayOfWeek {
+			return a.DayOfWeek < b.DayOfWeek
+		}
+		return a.Hour < b.Hour
+	})
+	return result
+}
+
+// meanStd returns the mean and the population standard deviation of values
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// itemValue returns the value of a fact result, like the history search: its aggregation value, or its doc_count
+func itemValue(item reader.Item) (float64, bool) {
+	var value, docCount interface{}
+	for k, v := range item.Aggs {
+		if v == nil {
+			continue
+		}
+		if k == "doc_count" {
+			docCount = v.Value
+		} else {
+			value = v.Value
+		}
+	}
+	if value == nil {
+		value = docCount
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,104 @@
+package baseline
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/reader"
+)
+
+func TestComputeSeasonalValues(t *testing.T) {
+	// 2026-01-05 is a monday
+	monday := time.Date(2026, 1, 5, 10, 15, 0, 0, time.UTC)
+	values := []FactValue{
+		{SituationID: 1, SituationInstanceID: 2, Ts: monday, Value: 2},
+		{SituationID: 1, SituationInstanceID: 2, Ts: monday.AddDate(0, 0, 7), Value: 4},
+		{SituationID: 1, SituationInstanceID: 2, Ts: monday.AddDate(0, 0, 14).Add(30 * time.Minute), Value: 9},
+		{SituationID: 1, SituationInstanceID: 2, Ts: monday.Add(time.Hour), Value: 100},
+		{SituationID: 1, SituationInstanceID: 3, Ts: monday, Value: 7},
+	}
+
+	result := ComputeSeasonalValues(Baseline{ID: 8, BandFactor: 2}, time.UTC, values)
+	if len(result) != 3 {
+		t.Fatalf("expected 3 seasonal values, got %+v", result)
+	}
+
+	v := result[0]
+	if v.BaselineID != 8 || v.SituationInstanceID != 2 || v.DayOfWeek != 1 || v.Hour != 10 || v.Count != 3 {
+		t.Fatalf("unexpected seasonal value %+v", v)
+	}
+	if v.Avg != 5 || v.Median != 4 {
+		t.Errorf("unexpected avg or median %+v", v)
+	}
+	std := math.Sqrt((9.0 + 1.0 + 16.0) / 3)
+	if math.Abs(v.Std-std) > 1e-9 || math.Abs(v.Upper-(5+2*std)) > 1e-9 || math.Abs(v.Lower-(5-2*std)) > 1e-9 {
+		t.Errorf("unexpected std or bands %+v", v)
+	}
+
+	if v := result[1]; v.Hour != 11 || v.Avg != 100 || v.Std != 0 {
+		t.Errorf("unexpected seasonal value %+v", v)
+	}
+	if v := result[2]; v.SituationInstanceID != 3 || v.Avg != 7 {
+		t.Errorf("unexpected seasonal value %+v", v)
+	}
+}
+
+func TestComputeSeasonalValuesTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("timezone database unavailable")
+	}
+	// Sunday 23:30 UTC is monday 00:30 in Paris
+	ts := time.Date(2026, 1, 4, 23, 30, 0, 0, time.UTC)
+	result := ComputeSeasonalValues(Baseline{}, loc, []FactValue{{Ts: ts, Value: 1}})
+	if len(result) != 1 || result[0].DayOfWeek != 1 || result[0].Hour != 0 {
+		t.Errorf("unexpected seasonal values %+v", result)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	if m := median([]float64{5, 1, 3}); m != 3 {
+		t.Errorf("expected 3, got %v", m)
+	}
+	if m := median([]float64{4, 1, 3, 2}); m != 2.5 {
+		t.Errorf("expected 2.5, got %v", m)
+	}
+	if m := median(nil); m != 0 {
+		t.Errorf("expected 0, got %v", m)
+	}
+}
+
+func TestItemValue(t *testing.T) {
+	item := reader.Item{Aggs: map[string]*reader.ItemAgg{"doc_count": {Value: 10.0}, "sum": {Value: 42.0}}}
+	if v, ok := itemValue(item); !ok || v != 42 {
+		t.Errorf("expected the aggregation value, got %v (%v)", v, ok)
+	}
+	item = reader.Item{Aggs: map[string]*reader.ItemAgg{"doc_count": {Value: 10.0}}}
+	if v, ok := itemValue(item); !ok || v != 10 {
+		t.Errorf("expected the doc_count, got %v (%v)", v, ok)
+	}
+	if _, ok := itemValue(reader.Item{}); ok {
+		t.Error("expected no value")
+	}
+}
+
+func TestBaselineValidate(t *testing.T) {
+	b := Baseline{Name: "weekly", FactID: 1, LookbackDays: 56, BandFactor: 2}
+	if err := b.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	invalid := []Baseline{
+		{FactID: 1, LookbackDays: 56},
+		{Name: "weekly", LookbackDays: 56},
+		{Name: "weekly", FactID: 1},
+		{Name: "weekly", FactID: 1, LookbackDays: 56, BandFactor: -1},
+		{Name: "weekly", FactID: 1, LookbackDays: 56, Timezone: "Not/AZone"},
+	}
+	for _, b := range invalid {
+		if err := b.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", b)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package baseline
+
+import (
+	"fmt"
+	"time"
+
+	pluginbaseline "github.com/myrteametrics/myrtea-engine-api/v5/pkg/plugins/baseline"
+	"go.uber.org/zap"
+)
+
+// Service is the built-in baseline engine, used when the baseline plugin is not available
+// It implements the same BaselineService interface as the plugin
+type Service struct{}
+
+var _ pluginbaseline.BaselineService = Service{}
+
+// NewService returns a new built-in baseline service
+func NewService() Service {
+	return Service{}
+}
+
+// GetBaselineValues returns the values of the baselines of a fact for a situation instance at a time, by baseline name
+// An id of -1 returns the values of every baseline of the fact
+func (s Service) GetBaselineValues(id int64, factID int64, situationID int64, situationInstanceID int64, ti time.Time) (map[string]pluginbaseline.BaselineValue, error) {
+	var baselines []Baseline
+	if id == -1 {
+		var err error
+		baselines, err = R().GetAllByFactID(factID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		b, found, err := R().Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("baseline %d not found", id)
+		}
+		baselines = []Baseline{b}
+	}
+
+	values := make(map[string]pluginbaseline.BaselineValue)
+	for _, b := range baselines {
+		if b.SituationID > 0 && b.SituationID != situationID {
+			continue
+		}
+		loc, err := b.Location()
+		if err != nil {
+			return nil, err
+		}
+		dayOfWeek, hour := season(ti, loc)
+		v, found, err := R().GetSeasonalValue(b.ID, situationID, situationInstanceID, dayOfWeek, hour)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		values[b.Name] = pluginbaseline.BaselineValue{
+			Time:       ti,
+			Value:      v.Avg,
+			ValueLower: v.Lower,
+			ValueUpper: v.Upper,
+			Avg:        v.Avg,
+			Std:        v.Std,
+			Median:     v.Median,
+		}
+	}
+	return values, nil
+}
+
+// BuildBaselineValues computes the seasonal values of a baseline from the fact history of its lookback period
+func (s Service) BuildBaselineValues(baselineID int64) error {
+	b, found, err := R().Get(baselineID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("baseline %d not found", baselineID)
+	}
+	loc, err := b.Location()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	factValues, err := R().GetFactValues(b.FactID, b.SituationID, now.AddDate(0, 0, -b.LookbackDays), now)
+	if err != nil {
+		return err
+	}
+
+	values := ComputeSeasonalValues(b, loc, factValues)
+	if err = R().ReplaceSeasonalValues(b.ID, now, values); err != nil {
+		return err
+	}
+
+	zap.L().Info("Baseline built", zap.Int64("baselineID", b.ID), zap.Int("factValues", len(factValues)), zap.Int("seasonalValues", len(values)))
+	return nil
+}
@@ -26,7 +26,7 @@ func NewPostgresRepository(dbClient *sqlx.DB) Repository {
 
 // Get use to retrieve an action by id
 func (r *PostgresRepository) Get(id int64) (model.Action, bool, error) {
-	query := `SELECT name, description, rootcause_id FROM ref_action_v1 WHERE id = :id`
+	query := `SELECT name, description, rootcause_id, catalog_id FROM ref_action_v1 WHERE id = :id`
 	params := map[string]interface{}{
 		"id": id,
 	}
@@ -39,9 +39,10 @@ func (r *PostgresRepository) Get(id int64) (model.Action, bool, error) {
 
 	var name, description string
 	var rootCauseID int64
+	var catalogID sql.NullInt64
 
 	if rows.Next() {
-		err := rows.Scan(&name, &description, &rootCauseID)
+		err := rows.Scan(&name, &description, &rootCauseID, &catalogID)
 		if err != nil {
 			return model.Action{}, false, fmt.Errorf("Couldn't scan the action with id %d: %s", id, err.Error())
 		}
@@ -54,6 +55,7 @@ func (r *PostgresRepository) Get(id int64) (model.Action, bool, error) {
 		Name:        name,
 		Description: description,
 		RootCauseID: rootCauseID,
+		CatalogID:   nullableID(catalogID),
 	}, true, nil
 }
 
@@ -63,12 +65,13 @@ func (r *PostgresRepository) Create(tx *sqlx.Tx, action model.Action) (int64, er
 		return -1, errors.New("missing action data")
 	}
 
-	query := `INSERT into ref_action_v1 (id, name, description, rootcause_id) 
-			 values (DEFAULT, :name, :description, :rootcause_id) RETURNING id;`
+	query := `INSERT into ref_action_v1 (id, name, description, rootcause_id, catalog_id) 
+			 values (DEFAULT, :name, :description, :rootcause_id, :catalog_id) RETURNING id;`
 	params := map[string]interface{}{
 		"name":         action.Name,
 		"description":  action.Description,
 		"rootcause_id": action.RootCauseID,
+		"catalog_id":   action.CatalogID,
 	}
 
 	var err error
@@ -159,7 +162,7 @@ func (r *PostgresRepository) Delete(tx *sqlx.Tx, id int64) error {
 func (r *PostgresRepository) GetAll() (map[int64]model.Action, error) {
 	actions := make(map[int64]model.Action, 0)
 
-	query := `SELECT id, name, description, rootcause_id FROM ref_action_v1`
+	query := `SELECT id, name, description, rootcause_id, catalog_id FROM ref_action_v1`
 	rows, err := r.conn.Query(query)
 
 	if err != nil {
@@ -170,8 +173,9 @@ func (r *PostgresRepository) GetAll() (map[int64]model.Action, error) {
 	for rows.Next() {
 		var id, rootCauseID int64
 		var name, description string
+		var catalogID sql.NullInt64
 
-		err := rows.Scan(&id, &name, &description, &rootCauseID)
+		err := rows.Scan(&id, &name, &description, &rootCauseID, &catalogID)
 		if err != nil {
 			return nil, err
 		}
@@ -181,6 +185,7 @@ func (r *PostgresRepository) GetAll() (map[int64]model.Action, error) {
 			Name:        name,
 			Description: description,
 			RootCauseID: rootCauseID,
+			CatalogID:   nullableID(catalogID),
 		}
 
 		actions[action.ID] = action
@@ -192,7 +197,7 @@ func (r *PostgresRepository) GetAll() (map[int64]model.Action, error) {
 func (r *PostgresRepository) GetAllBySituationID(situationID int64) (map[int64]model.Action, error) {
 	actions := make(map[int64]model.Action, 0)
 
-	query := `SELECT a.id, a.name, a.description, a.rootcause_id 
+	query := `SELECT a.id, a.name, a.description, a.rootcause_id, a.catalog_id 
 		FROM ref_action_v1 a INNER JOIN ref_rootcause_v1 rc ON a.rootcause_id = rc.id
 		where rc.situation_id = :situation_id`
 	params := map[string]interface{}{
@@ -208,8 +213,9 @@ func (r *PostgresRepository) GetAllBySituationID(situationID int64) (map[int64]m
 	for rows.Next() {
 		var id, rootCauseID int64
 		var name, description string
+		var catalogID sql.NullInt64
 
-		err := rows.Scan(&id, &name, &description, &rootCauseID)
+		err := rows.Scan(&id, &name, &description, &rootCauseID, &catalogID)
 		if err != nil {
 			return nil, err
 		}
@@ -219,6 +225,7 @@ func (r *PostgresRepository) GetAllBySituationID(situationID int64) (map[int64]m
 			Name:        name,
 			Description: description,
 			RootCauseID: rootCauseID,
+			CatalogID:   nullableID(catalogID),
 		}
 
 		actions[action.ID] = action
@@ -230,7 +237,7 @@ func (r *PostgresRepository) GetAllBySituationID(situationID int64) (map[int64]m
 func (r *PostgresRepository) GetAllByRootCauseID(rootCauseID int64) (map[int64]model.Action, error) {
 	actions := make(map[int64]model.Action, 0)
 
-	query := `SELECT id, name, description, rootcause_id FROM ref_action_v1 where rootcause_id = :rootcause_id`
+	query := `SELECT id, name, description, rootcause_id, catalog_id FROM ref_action_v1 where rootcause_id = :rootcause_id`
 	params := map[string]interface{}{
 		"rootcause_id": rootCauseID,
 	}
@@ -244,8 +251,9 @@ func (r *PostgresRepository) GetAllByRootCauseID(rootCauseID int64) (map[int64]m
 	for rows.Next() {
 		var id, rootCauseID int64
 		var name, description string
+		var catalogID sql.NullInt64
 
-		err := rows.Scan(&id, &name, &description, &rootCauseID)
+		err := rows.Scan(&id, &name, &description, &rootCauseID, &catalogID)
 		if err != nil {
 			return nil, err
 		}
@@ -255,6 +263,7 @@ func (r *PostgresRepository) GetAllByRootCauseID(rootCauseID int64) (map[int64]m
 			Name:        name,
 			Description: description,
 			RootCauseID: rootCauseID,
+			CatalogID:   nullableID(catalogID),
 		}
 
 		actions[action.ID] = action
@@ -271,3 +280,10 @@ func checkValidity(action model.Action) bool {
 	}
 	return true
 }
+
+func nullableID(id sql.NullInt64) *int64 {
+	if !id.Valid {
+		return nil
+	}
+	return &id.Int64
+}
@@ -0,0 +1,458 @@
+package catalog
+
+import (
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+const (
+	rootCausesTable = "ref_catalog_rootcause_v1"
+	actionsTable    = "ref_catalog_action_v1"
+)
+
+// unusedRootCause and unusedAction filter the rootcauses and actions of the situations which are not used in an issue resolution
+const (
+	unusedRootCause = "NOT EXISTS (SELECT 1 FROM issue_resolution_v1 ir WHERE ir.rootcause_id = ref_rootcause_v1.id) " +
+		"AND NOT EXISTS (SELECT 1 FROM ref_action_v1 a WHERE a.rootcause_id = ref_rootcause_v1.id)"
+	unusedAction = "NOT EXISTS (SELECT 1 FROM issue_resolution_v1 ir WHERE ir.action_id = ref_action_v1.id)"
+)
+
+// PostgresRepository is a repository containing the rootcauses and actions catalog based on a PSQL database and
+// implementing the repository interface
+type PostgresRepository struct {
+	conn *sqlx.DB
+}
+
+// NewPostgresRepository returns a new instance of PostgresRepository
+func NewPostgresRepository(dbClient *sqlx.DB) Repository {
+	r := PostgresRepository{
+		conn: dbClient,
+	}
+	var repo Repository = &r
+	return repo
+}
+
+// GetRootCause returns a catalog rootcause with its actions
+func (r *PostgresRepository) GetRootCause(id int64) (model.CatalogRootCause, bool, error) {
+	rootCauses, err := r.queryRootCauses(sq.Eq{"id": id})
+	if err != nil {
+		return model.CatalogRootCause{}, false, err
+	}
+	if len(rootCauses) == 0 {
+		return model.CatalogRootCause{}, false, nil
+	}
+	return rootCauses[0], true, nil
+}
+
+// GetAllRootCauses returns all the catalog rootcauses with their actions, by name
+func (r *PostgresRepository) GetAllRootCauses() ([]model.CatalogRootCause, error) {
+	return r.queryRootCauses(nil)
+}
+
+func (r *PostgresRepository) queryRootCauses(where sq.Sqlizer) ([]model.CatalogRootCause, error) {
+	statement := newStatement().Select("id", "name", "description").From(rootCausesTable).OrderBy("name")
+	if where != nil {
+		statement = statement.Where(where)
+	}
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rootCauses := make([]model.CatalogRootCause, 0)
+	ids := make([]int64, 0)
+	for rows.Next() {
+		rootCause := model.CatalogRootCause{Actions: make([]model.CatalogAction, 0)}
+		if err = rows.Scan(&rootCause.ID, &rootCause.Name, &rootCause.Description); err != nil {
+			return nil, err
+		}
+		rootCauses = append(rootCauses, rootCause)
+		ids = append(ids, rootCause.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return rootCauses, nil
+	}
+
+	actions, err := r.queryActions(sq.Eq{"catalog_rootcause_id": ids})
+	if err != nil {
+		return nil, err
+	}
+	for i := range rootCauses {
+		for _, action := range actions {
+			if action.CatalogRootCauseID == rootCauses[i].ID {
+				rootCauses[i].Actions = append(rootCauses[i].Actions, action)
+			}
+		}
+	}
+	return rootCauses, nil
+}
+
+// CreateRootCause creates a catalog rootcause with its actions
+func (r *PostgresRepository) CreateRootCause(rootCause model.CatalogRootCause) (int64, error) {
+	if _, err := rootCause.IsValid(); err != nil {
+		return -1, err
+	}
+
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = newStatement().
+		Insert(rootCausesTable).
+		Columns("name", "description").
+		Values(rootCause.Name, rootCause.Description).
+		Suffix("RETURNING \"id\"").
+		RunWith(tx).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	for _, action := range rootCause.Actions {
+		action.CatalogRootCauseID = id
+		if _, err = insertAction(tx, action); err != nil {
+			return -1, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+// UpdateRootCause updates the name and description of a catalog rootcause, and of the rootcauses of its linked situations
+func (r *PostgresRepository) UpdateRootCause(rootCause model.CatalogRootCause) error {
+	if rootCause.Name == "" || rootCause.Description == "" {
+		return errors.New("missing rootcause data")
+	}
+
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := newStatement().
+		Update(rootCausesTable).
+		Set("name", rootCause.Name).
+		Set("description", rootCause.Description).
+		Where(sq.Eq{"id": rootCause.ID}).
+		RunWith(tx).
+		Exec()
+	if err != nil {
+		return err
+	}
+	if err = checkAffected(res); err != nil {
+		return err
+	}
+	_, err = newStatement().
+		Update("ref_rootcause_v1").
+		Set("name", rootCause.Name).
+		Set("description", rootCause.Description).
+		Where(sq.Eq{"catalog_id": rootCause.ID}).
+		RunWith(tx).
+		Exec()
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteRootCause deletes a catalog rootcause and its actions
+// The rootcauses and actions of the linked situations are deleted too, unless they are used in an issue resolution:
+// they are then only detached from the catalog, to keep the resolutions history
+func (r *PostgresRepository) DeleteRootCause(id int64) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = detachRootCauses(tx, sq.Eq{"catalog_id": id}); err != nil {
+		return err
+	}
+	res, err := newStatement().Delete(rootCausesTable).Where(sq.Eq{"id": id}).RunWith(tx).Exec()
+	if err != nil {
+		return err
+	}
+	if err = checkAffected(res); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAction returns a catalog action
+func (r *PostgresRepository) GetAction(id int64) (model.CatalogAction, bool, error) {
+	actions, err := r.queryActions(sq.Eq{"id": id})
+	if err != nil {
+		return model.CatalogAction{}, false, err
+	}
+	if len(actions) == 0 {
+		return model.CatalogAction{}, false, nil
+	}
+	return actions[0], true, nil
+}
+
+func (r *PostgresRepository) queryActions(where sq.Sqlizer) ([]model.CatalogAction, error) {
+	rows, err := newStatement().
+		Select("id", "name", "description", "catalog_rootcause_id").
+		From(actionsTable).
+		Where(where).
+		OrderBy("name").
+		RunWith(r.conn.DB).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := make([]model.CatalogAction, 0)
+	for rows.Next() {
+		var action model.CatalogAction
+		if err = rows.Scan(&action.ID, &action.Name, &action.Description, &action.CatalogRootCauseID); err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, rows.Err()
+}
+
+// CreateAction creates a catalog action, which is added to the rootcauses of all the linked situations
+func (r *PostgresRepository) CreateAction(action model.CatalogAction) (int64, error) {
+	if _, err := action.IsValid(); err != nil {
+		return -1, err
+	}
+
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	id, err := insertAction(tx, action)
+	if err != nil {
+		return -1, err
+	}
+	_, err = tx.Exec(`INSERT INTO ref_action_v1 (name, description, rootcause_id, catalog_id)
+		SELECT $1, $2, rc.id, $3 FROM ref_rootcause_v1 rc WHERE rc.catalog_id = $4
+		ON CONFLICT (name, rootcause_id) DO UPDATE SET description = EXCLUDED.description, catalog_id = EXCLUDED.catalog_id`,
+		action.Name, action.Description, id, action.CatalogRootCauseID)
+	if err != nil {
+		return -1, err
+	}
+	return id, tx.Commit()
+}
+
+func insertAction(tx *sqlx.Tx, action model.CatalogAction) (int64, error) {
+	var id int64
+	err := newStatement().
+		Insert(actionsTable).
+		Columns("name", "description", "catalog_rootcause_id").
+		Values(action.Name, action.Description, action.CatalogRootCauseID).
+		Suffix("RETURNING \"id\"").
+		RunWith(tx).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// UpdateAction updates the name and description of a catalog action, and of the actions of its linked situations
+func (r *PostgresRepository) UpdateAction(action model.CatalogAction) error {
+	if action.Name == "" || action.Description == "" {
+		return errors.New("missing action data")
+	}
+
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := newStatement().
+		Update(actionsTable).
+		Set("name", action.Name).
+		Set("description", action.Description).
+		Where(sq.Eq{"id": action.ID}).
+		RunWith(tx).
+		Exec()
+	if err != nil {
+		return err
+	}
+	if err = checkAffected(res); err != nil {
+		return err
+	}
+	_, err = newStatement().
+		Update("ref_action_v1").
+		Set("name", action.Name).
+		Set("description", action.Description).
+		Where(sq.Eq{"catalog_id": action.ID}).
+		RunWith(tx).
+		Exec()
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteAction deletes a catalog action
+// The actions of the linked situations are deleted too, unless they are used in an issue resolution
+func (r *PostgresRepository) DeleteAction(id int64) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = detachActions(tx, sq.Eq{"catalog_id": id}); err != nil {
+		return err
+	}
+	res, err := newStatement().Delete(actionsTable).Where(sq.Eq{"id": id}).RunWith(tx).Exec()
+	if err != nil {
+		return err
+	}
+	if err = checkAffected(res); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetLinks returns the situations and rules linked to a catalog rootcause
+func (r *PostgresRepository) GetLinks(id int64) ([]model.CatalogLink, error) {
+	rows, err := newStatement().
+		Select("situation_id", "rule_id", "id").
+		From("ref_rootcause_v1").
+		Where(sq.Eq{"catalog_id": id}).
+		OrderBy("situation_id", "rule_id").
+		RunWith(r.conn.DB).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]model.CatalogLink, 0)
+	for rows.Next() {
+		var link model.CatalogLink
+		if err = rows.Scan(&link.SituationID, &link.RuleID, &link.RootCauseID); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// Link adds a catalog rootcause and its actions to a situation and rule, and returns the rootcause of the situation
+// An existing rootcause (or action) of the situation and rule with the same name is attached to the catalog
+func (r *PostgresRepository) Link(id int64, situationID int64, ruleID int64) (int64, error) {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	var rootCauseID int64
+	err = tx.QueryRow(`INSERT INTO ref_rootcause_v1 (name, description, situation_id, rule_id, catalog_id)
+		SELECT name, description, $1, $2, id FROM `+rootCausesTable+` WHERE id = $3
+		ON CONFLICT (name, situation_id, rule_id) DO UPDATE SET description = EXCLUDED.description, catalog_id = EXCLUDED.catalog_id
+		RETURNING id`, situationID, ruleID, id).Scan(&rootCauseID)
+	if err != nil {
+		return -1, err
+	}
+	_, err = tx.Exec(`INSERT INTO ref_action_v1 (name, description, rootcause_id, catalog_id)
+		SELECT name, description, $1, id FROM `+actionsTable+` WHERE catalog_rootcause_id = $2
+		ON CONFLICT (name, rootcause_id) DO UPDATE SET description = EXCLUDED.description, catalog_id = EXCLUDED.catalog_id`,
+		rootCauseID, id)
+	if err != nil {
+		return -1, err
+	}
+	return rootCauseID, tx.Commit()
+}
+
+// Unlink removes a catalog rootcause and its actions from a situation and rule
+// The rootcause and actions of the situation used in an issue resolution are only detached from the catalog
+func (r *PostgresRepository) Unlink(id int64, situationID int64, ruleID int64) (bool, error) {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	where := sq.Eq{"catalog_id": id, "situation_id": situationID, "rule_id": ruleID}
+	var count int64
+	err = newStatement().Select("count(*)").From("ref_rootcause_v1").Where(where).RunWith(tx).QueryRow().Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+	if err = detachRootCauses(tx, where); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// detachRootCauses deletes the rootcauses of the situations matching a condition, and their actions,
+// the ones used in an issue resolution are detached from the catalog instead
+func detachRootCauses(tx *sqlx.Tx, where sq.Eq) error {
+	rows, err := newStatement().Select("id").From("ref_rootcause_v1").Where(where).RunWith(tx).Query()
+	if err != nil {
+		return err
+	}
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err = detachActions(tx, sq.Eq{"rootcause_id": ids}); err != nil {
+		return err
+	}
+	_, err = newStatement().Delete("ref_rootcause_v1").Where(sq.Eq{"id": ids}).Where(unusedRootCause).RunWith(tx).Exec()
+	if err != nil {
+		return err
+	}
+	_, err = newStatement().Update("ref_rootcause_v1").Set("catalog_id", nil).Where(sq.Eq{"id": ids}).RunWith(tx).Exec()
+	return err
+}
+
+// detachActions deletes the actions of the situations matching a condition,
+// the ones used in an issue resolution are detached from the catalog instead
+func detachActions(tx *sqlx.Tx, where sq.Eq) error {
+	_, err := newStatement().Delete("ref_action_v1").Where(where).Where(unusedAction).RunWith(tx).Exec()
+	if err != nil {
+		return err
+	}
+	_, err = newStatement().Update("ref_action_v1").Set("catalog_id", nil).Where(where).RunWith(tx).Exec()
+	return err
+}
+
+func checkAffected(res sql.Result) error {
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i != 1 {
+		return errors.New("no row updated (or multiple row updated) instead of 1 row")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package catalog
+
+import (
+	"sync"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+// Repository is a storage interface which can be implemented by multiple backend
+// (in-memory map, sql database, in-memory cache, file system, ...)
+// It allows standard CRUD operation on the rootcauses and actions catalog, and its links to the situations and rules
+type Repository interface {
+	GetRootCause(id int64) (model.CatalogRootCause, bool, error)
+	GetAllRootCauses() ([]model.CatalogRootCause, error)
+	CreateRootCause(rootCause model.CatalogRootCause) (int64, error)
+	UpdateRootCause(rootCause model.CatalogRootCause) error
+	DeleteRootCause(id int64) error
+
+	GetAction(id int64) (model.CatalogAction, bool, error)
+	CreateAction(action model.CatalogAction) (int64, error)
+	UpdateAction(action model.CatalogAction) error
+	DeleteAction(id int64) error
+
+	// Situations and rules links
+	GetLinks(id int64) ([]model.CatalogLink, error)
+	Link(id int64, situationID int64, ruleID int64) (int64, error)
+	Unlink(id int64, situationID int64, ruleID int64) (bool, error)
+}
+
+var (
+	_globalRepositoryMu sync.RWMutex
+	_globalRepository   Repository
+)
+
+// R is used to access the global repository singleton
+func R() Repository {
+	_globalRepositoryMu.RLock()
+	defer _globalRepositoryMu.RUnlock()
+
+	repository := _globalRepository
+	return repository
+}
+
+// ReplaceGlobals affect a new repository to the global repository singleton
+func ReplaceGlobals(repository Repository) func() {
+	_globalRepositoryMu.Lock()
+	defer _globalRepositoryMu.Unlock()
+
+	prev := _globalRepository
+	_globalRepository = repository
+	return func() { ReplaceGlobals(prev) }
+}
+
+// newStatement creates a new SQL statement builder with Dollar placeholder format
+func newStatement() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
@@ -0,0 +1,272 @@
+package comment
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+const (
+	table            = "issue_comment_v1"
+	attachmentsTable = "issue_comment_attachment_v1"
+)
+
+var (
+	columns           = []string{"id", "issue_id", "parent_id", "author", "content", "mentions", "created_at", "edited_at"}
+	attachmentColumns = []string{"id", "comment_id", "file_name", "content_type", "size", "storage_name", "author", "created_at"}
+)
+
+// PostgresRepository is a repository containing the issue comments based on a PSQL database and
+// implementing the repository interface
+type PostgresRepository struct {
+	conn *sqlx.DB
+}
+
+// NewPostgresRepository returns a new instance of PostgresRepository
+func NewPostgresRepository(dbClient *sqlx.DB) Repository {
+	r := PostgresRepository{
+		conn: dbClient,
+	}
+	var repo Repository = &r
+	return repo
+}
+
+// Get returns an issue comment (without its replies) by its ID
+func (r *PostgresRepository) Get(id int64) (model.IssueComment, bool, error) {
+	comments, err := r.query(newStatement().Select(columns...).From(table).Where(sq.Eq{"id": id}))
+	if err != nil {
+		return model.IssueComment{}, false, err
+	}
+	if len(comments) == 0 {
+		return model.IssueComment{}, false, nil
+	}
+	return comments[0], true, nil
+}
+
+// GetByIssue returns all the comments of an issue, in chronological order
+func (r *PostgresRepository) GetByIssue(issueID int64) ([]model.IssueComment, error) {
+	return r.query(newStatement().Select(columns...).From(table).Where(sq.Eq{"issue_id": issueID}).OrderBy("created_at", "id"))
+}
+
+func (r *PostgresRepository) query(statement sq.SelectBuilder) ([]model.IssueComment, error) {
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := make([]model.IssueComment, 0)
+	indexes := make(map[int64]int)
+	ids := make([]int64, 0)
+	for rows.Next() {
+		comment := model.IssueComment{Attachments: make([]model.IssueCommentAttachment, 0)}
+		var parentID sql.NullInt64
+		var editedAt sql.NullTime
+		var mentions []byte
+		if err = rows.Scan(&comment.ID, &comment.IssueID, &parentID, &comment.Author, &comment.Content, &mentions, &comment.CreatedAt, &editedAt); err != nil {
+			return nil, err
+		}
+		if parentID.Valid {
+			comment.ParentID = &parentID.Int64
+		}
+		if editedAt.Valid {
+			comment.EditedAt = &editedAt.Time
+		}
+		if err = json.Unmarshal(mentions, &comment.Mentions); err != nil {
+			return nil, err
+		}
+		indexes[comment.ID] = len(comments)
+		ids = append(ids, comment.ID)
+		comments = append(comments, comment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return comments, nil
+	}
+
+	attachments, err := r.queryAttachments(newStatement().
+		Select(attachmentColumns...).
+		From(attachmentsTable).
+		Where("comment_id = any(?)", pq.Array(ids)).
+		OrderBy("id"))
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		i := indexes[attachment.CommentID]
+		comments[i].Attachments = append(comments[i].Attachments, attachment)
+	}
+	return comments, nil
+}
+
+// Create creates a new issue comment
+func (r *PostgresRepository) Create(comment model.IssueComment) (int64, error) {
+	if _, err := comment.IsValid(); err != nil {
+		return -1, err
+	}
+	mentions, err := marshalMentions(comment.Mentions)
+	if err != nil {
+		return -1, err
+	}
+
+	var id int64
+	err = newStatement().
+		Insert(table).
+		Columns("issue_id", "parent_id", "author", "content", "mentions", "created_at").
+		Values(comment.IssueID, comment.ParentID, comment.Author, comment.Content, mentions, comment.CreatedAt).
+		Suffix("RETURNING \"id\"").
+		RunWith(r.conn.DB).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// Update updates the content (and the mentions) of an issue comment
+func (r *PostgresRepository) Update(id int64, content string, mentions []string, editedAt time.Time) error {
+	jsonMentions, err := marshalMentions(mentions)
+	if err != nil {
+		return err
+	}
+	res, err := newStatement().
+		Update(table).
+		Set("content", content).
+		Set("mentions", jsonMentions).
+		Set("edited_at", editedAt).
+		Where(sq.Eq{"id": id}).
+		RunWith(r.conn.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// Delete deletes an issue comment with all its replies, and returns their attachments to remove from the storage
+func (r *PostgresRepository) Delete(id int64) ([]model.IssueCommentAttachment, error) {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	thread := `with recursive thread as (
+			select id from ` + table + ` where id = $1
+			union
+			select c.id from ` + table + ` c inner join thread t on c.parent_id = t.id
+		)`
+	rows, err := tx.Query(thread+` select a.id, a.comment_id, a.file_name, a.content_type, a.size, a.storage_name, a.author, a.created_at
+		from `+attachmentsTable+` a
+		where a.comment_id in (select id from thread)`, id)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := scanAttachments(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tx.Exec(thread+` delete from `+table+` where id in (select id from thread)`, id)
+	if err != nil {
+		return nil, err
+	}
+	if i, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if i == 0 {
+		return nil, ErrCommentNotFound
+	}
+	return attachments, tx.Commit()
+}
+
+// GetAttachment returns an issue comment attachment by its ID
+func (r *PostgresRepository) GetAttachment(id int64) (model.IssueCommentAttachment, bool, error) {
+	attachments, err := r.queryAttachments(newStatement().Select(attachmentColumns...).From(attachmentsTable).Where(sq.Eq{"id": id}))
+	if err != nil {
+		return model.IssueCommentAttachment{}, false, err
+	}
+	if len(attachments) == 0 {
+		return model.IssueCommentAttachment{}, false, nil
+	}
+	return attachments[0], true, nil
+}
+
+func (r *PostgresRepository) queryAttachments(statement sq.SelectBuilder) ([]model.IssueCommentAttachment, error) {
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	return scanAttachments(rows)
+}
+
+func scanAttachments(rows *sql.Rows) ([]model.IssueCommentAttachment, error) {
+	defer rows.Close()
+
+	attachments := make([]model.IssueCommentAttachment, 0)
+	for rows.Next() {
+		var attachment model.IssueCommentAttachment
+		if err := rows.Scan(&attachment.ID, &attachment.CommentID, &attachment.FileName, &attachment.ContentType,
+			&attachment.Size, &attachment.StorageName, &attachment.Author, &attachment.CreatedAt); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, rows.Err()
+}
+
+// CreateAttachment creates a new issue comment attachment, its file must already be stored
+func (r *PostgresRepository) CreateAttachment(attachment model.IssueCommentAttachment) (int64, error) {
+	var id int64
+	err := newStatement().
+		Insert(attachmentsTable).
+		Columns("comment_id", "file_name", "content_type", "size", "storage_name", "author", "created_at").
+		Values(attachment.CommentID, attachment.FileName, attachment.ContentType, attachment.Size, attachment.StorageName, attachment.Author, attachment.CreatedAt).
+		Suffix("RETURNING \"id\"").
+		RunWith(r.conn.DB).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// DeleteAttachment deletes an issue comment attachment
+func (r *PostgresRepository) DeleteAttachment(id int64) error {
+	res, err := newStatement().Delete(attachmentsTable).Where(sq.Eq{"id": id}).RunWith(r.conn.DB).Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func marshalMentions(mentions []string) (string, error) {
+	if mentions == nil {
+		mentions = make([]string, 0)
+	}
+	b, err := json.Marshal(mentions)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func checkAffected(res sql.Result) error {
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i != 1 {
+		return errors.New("no row updated (or multiple row updated) instead of 1 row")
+	}
+	return nil
+}
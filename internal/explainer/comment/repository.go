@@ -0,0 +1,64 @@
+package comment
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+var (
+	// ErrCommentNotFound is returned when an issue comment does not exist
+	ErrCommentNotFound = errors.New("issue comment not found")
+	// ErrAttachmentNotFound is returned when an issue comment attachment does not exist
+	ErrAttachmentNotFound = errors.New("issue comment attachment not found")
+	// ErrNotAuthor is returned when a user edits or deletes a comment (or its attachments) of another user
+	ErrNotAuthor = errors.New("only the author of an issue comment can modify it")
+	// ErrInvalidParent is returned when a comment answers a comment of another issue
+	ErrInvalidParent = errors.New("the parent comment does not belong to the issue")
+)
+
+// Repository is a storage interface which can be implemented by multiple backend
+// (in-memory map, sql database, in-memory cache, file system, ...)
+// It allows standard CRUD operation on the issue comments and their attachments
+type Repository interface {
+	Get(id int64) (model.IssueComment, bool, error)
+	GetByIssue(issueID int64) ([]model.IssueComment, error)
+	Create(comment model.IssueComment) (int64, error)
+	Update(id int64, content string, mentions []string, editedAt time.Time) error
+	Delete(id int64) ([]model.IssueCommentAttachment, error)
+	GetAttachment(id int64) (model.IssueCommentAttachment, bool, error)
+	CreateAttachment(attachment model.IssueCommentAttachment) (int64, error)
+	DeleteAttachment(id int64) error
+}
+
+var (
+	_globalRepositoryMu sync.RWMutex
+	_globalRepository   Repository
+)
+
+// R is used to access the global repository singleton
+func R() Repository {
+	_globalRepositoryMu.RLock()
+	defer _globalRepositoryMu.RUnlock()
+
+	repository := _globalRepository
+	return repository
+}
+
+// ReplaceGlobals affect a new repository to the global repository singleton
+func ReplaceGlobals(repository Repository) func() {
+	_globalRepositoryMu.Lock()
+	defer _globalRepositoryMu.Unlock()
+
+	prev := _globalRepository
+	_globalRepository = repository
+	return func() { ReplaceGlobals(prev) }
+}
+
+// newStatement creates a new SQL statement builder with Dollar placeholder format
+func newStatement() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
@@ -0,0 +1,132 @@
+package comment
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ErrAttachmentTooLarge is returned when an attachment exceeds the maximum size of the store
+var ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+// AttachmentStore stores the issue comment attachments as files of a local directory
+// The files are named with a random name, the original file name is only kept in the database
+type AttachmentStore struct {
+	BasePath string
+	MaxSize  int64 // maximum size of an attachment in bytes (0 for unlimited)
+}
+
+// NewAttachmentStore returns a new attachment store
+func NewAttachmentStore(basePath string, maxSize int64) *AttachmentStore {
+	return &AttachmentStore{
+		BasePath: basePath,
+		MaxSize:  maxSize,
+	}
+}
+
+// Init creates the attachments directory if it does not exist
+func (store *AttachmentStore) Init() {
+	_, err := os.Stat(store.BasePath)
+	if err != nil {
+
+		if os.IsNotExist(err) {
+			zap.L().Info("The attachments directory not exists, trying to create...", zap.String("ISSUE_ATTACHMENTS_BASE_PATH", store.BasePath))
+
+			if err := os.MkdirAll(store.BasePath, os.ModePerm); err != nil {
+				zap.L().Error("Couldn't create attachments directory", zap.String("ISSUE_ATTACHMENTS_BASE_PATH", store.BasePath), zap.Error(err))
+			} else {
+				zap.L().Info("The attachments directory has been successfully created.")
+			}
+
+		} else {
+			zap.L().Error("Couldn't access to attachments directory", zap.String("ISSUE_ATTACHMENTS_BASE_PATH", store.BasePath), zap.Error(err))
+		}
+
+	}
+}
+
+// Save writes the content of an attachment to a new file, and returns its storage name and its size
+// The file is removed if the content exceeds the maximum size
+func (store *AttachmentStore) Save(content io.Reader) (string, int64, error) {
+	storageName, err := newStorageName()
+	if err != nil {
+		return "", 0, err
+	}
+
+	path := store.Path(storageName)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	reader := content
+	if store.MaxSize > 0 {
+		reader = io.LimitReader(content, store.MaxSize+1)
+	}
+	size, err := io.Copy(file, reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && store.MaxSize > 0 && size > store.MaxSize {
+		err = ErrAttachmentTooLarge
+	}
+	if err != nil {
+		if removeErr := os.Remove(path); removeErr != nil {
+			zap.L().Warn("Couldn't remove the attachment file", zap.String("path", path), zap.Error(removeErr))
+		}
+		return "", 0, err
+	}
+	return storageName, size, nil
+}
+
+// Path returns the path of the file of an attachment
+func (store *AttachmentStore) Path(storageName string) string {
+	return filepath.Join(store.BasePath, filepath.Base(storageName))
+}
+
+// Remove removes the file of an attachment, a missing file is not an error
+func (store *AttachmentStore) Remove(storageName string) error {
+	err := os.Remove(store.Path(storageName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func newStorageName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+var (
+	_globalStoreMu sync.RWMutex
+	_globalStore   *AttachmentStore
+)
+
+// S is used to access the global attachment store singleton
+func S() *AttachmentStore {
+	_globalStoreMu.RLock()
+	defer _globalStoreMu.RUnlock()
+
+	store := _globalStore
+	return store
+}
+
+// ReplaceGlobalStore affect a new attachment store to the global attachment store singleton
+func ReplaceGlobalStore(store *AttachmentStore) func() {
+	_globalStoreMu.Lock()
+	defer _globalStoreMu.Unlock()
+
+	prev := _globalStore
+	_globalStore = store
+	return func() { ReplaceGlobalStore(prev) }
+}
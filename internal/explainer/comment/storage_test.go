@@ -0,0 +1,60 @@
+package comment
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAttachmentStoreSave(t *testing.T) {
+	store := NewAttachmentStore(t.TempDir(), 10)
+
+	storageName, size, err := store.Save(strings.NewReader("a;b\n1;2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 8 {
+		t.Errorf("unexpected size %d", size)
+	}
+	b, err := os.ReadFile(store.Path(storageName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "a;b\n1;2\n" {
+		t.Errorf("unexpected content %s", string(b))
+	}
+
+	if err = store.Remove(storageName); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(store.Path(storageName)); !os.IsNotExist(err) {
+		t.Error("expected the attachment file to be removed")
+	}
+	if err = store.Remove(storageName); err != nil {
+		t.Errorf("expected no error on removing a missing file, got %v", err)
+	}
+}
+
+func TestAttachmentStoreSaveTooLarge(t *testing.T) {
+	store := NewAttachmentStore(t.TempDir(), 4)
+
+	_, _, err := store.Save(strings.NewReader("too large"))
+	if !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Fatalf("expected ErrAttachmentTooLarge, got %v", err)
+	}
+	entries, err := os.ReadDir(store.BasePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected the too large attachment to be removed, found %d files", len(entries))
+	}
+}
+
+func TestAttachmentStorePath(t *testing.T) {
+	store := NewAttachmentStore("/data/attachments", 0)
+	if path := store.Path("../../etc/passwd"); path != "/data/attachments/passwd" {
+		t.Errorf("unexpected path %s", path)
+	}
+}
@@ -0,0 +1,189 @@
+package explainer
+
+import (
+	"io"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/comment"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/events"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/users"
+	"go.uber.org/zap"
+)
+
+// GetIssueComments returns the comment threads of an issue, with their attachments
+func GetIssueComments(issueID int64) ([]model.IssueComment, error) {
+	comments, err := comment.R().GetByIssue(issueID)
+	if err != nil {
+		return nil, err
+	}
+	return model.BuildIssueCommentThreads(comments), nil
+}
+
+// CreateIssueComment adds a comment (or an answer to a comment) to an issue, and notifies the mentioned users
+func CreateIssueComment(issue model.Issue, parentID *int64, content string, user users.User) (model.IssueComment, error) {
+	if parentID != nil {
+		parent, found, err := comment.R().Get(*parentID)
+		if err != nil {
+			return model.IssueComment{}, err
+		}
+		if !found || parent.IssueID != issue.ID {
+			return model.IssueComment{}, comment.ErrInvalidParent
+		}
+	}
+
+	newComment := model.IssueComment{
+		IssueID:     issue.ID,
+		ParentID:    parentID,
+		Author:      user.Login,
+		Content:     content,
+		Mentions:    model.ParseIssueCommentMentions(content),
+		CreatedAt:   time.Now().Truncate(1 * time.Millisecond).UTC(),
+		Attachments: make([]model.IssueCommentAttachment, 0),
+	}
+	id, err := comment.R().Create(newComment)
+	if err != nil {
+		return model.IssueComment{}, err
+	}
+	newComment.ID = id
+
+	publishIssueCommented(issue, newComment, user)
+	events.NotifyMentions(issue, newComment, newComment.Mentions)
+	return newComment, nil
+}
+
+// EditIssueComment updates the content of a comment of an issue, only the users newly mentioned are notified
+func EditIssueComment(issue model.Issue, commentID int64, content string, user users.User) (model.IssueComment, error) {
+	existing, err := getIssueCommentOfAuthor(issue, commentID, user)
+	if err != nil {
+		return model.IssueComment{}, err
+	}
+
+	newMentions := model.NewIssueCommentMentions(existing.Mentions, content)
+	existing.Content = content
+	existing.Mentions = model.ParseIssueCommentMentions(content)
+	if _, err = existing.IsValid(); err != nil {
+		return model.IssueComment{}, err
+	}
+	editedAt := time.Now().Truncate(1 * time.Millisecond).UTC()
+	existing.EditedAt = &editedAt
+	if err = comment.R().Update(existing.ID, existing.Content, existing.Mentions, editedAt); err != nil {
+		return model.IssueComment{}, err
+	}
+
+	publishIssueCommented(issue, existing, user)
+	events.NotifyMentions(issue, existing, newMentions)
+	return existing, nil
+}
+
+// DeleteIssueComment deletes a comment of an issue with all its answers, and removes their attachments from the storage
+func DeleteIssueComment(issue model.Issue, commentID int64, user users.User) error {
+	if _, err := getIssueCommentOfAuthor(issue, commentID, user); err != nil {
+		return err
+	}
+	attachments, err := comment.R().Delete(commentID)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		removeAttachmentFile(attachment)
+	}
+	return nil
+}
+
+// AddIssueCommentAttachment stores a file on the local disk and attaches it to a comment of an issue
+func AddIssueCommentAttachment(issue model.Issue, commentID int64, fileName string, contentType string, content io.Reader, user users.User) (model.IssueCommentAttachment, error) {
+	if _, err := getIssueCommentOfAuthor(issue, commentID, user); err != nil {
+		return model.IssueCommentAttachment{}, err
+	}
+
+	storageName, size, err := comment.S().Save(content)
+	if err != nil {
+		return model.IssueCommentAttachment{}, err
+	}
+	attachment := model.IssueCommentAttachment{
+		CommentID:   commentID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		StorageName: storageName,
+		Author:      user.Login,
+		CreatedAt:   time.Now().Truncate(1 * time.Millisecond).UTC(),
+	}
+	id, err := comment.R().CreateAttachment(attachment)
+	if err != nil {
+		removeAttachmentFile(attachment)
+		return model.IssueCommentAttachment{}, err
+	}
+	attachment.ID = id
+	return attachment, nil
+}
+
+// GetIssueCommentAttachment returns an attachment of a comment of an issue, and the path of its file
+func GetIssueCommentAttachment(issue model.Issue, commentID int64, attachmentID int64) (model.IssueCommentAttachment, string, error) {
+	attachment, found, err := comment.R().GetAttachment(attachmentID)
+	if err != nil {
+		return model.IssueCommentAttachment{}, "", err
+	}
+	if !found || attachment.CommentID != commentID {
+		return model.IssueCommentAttachment{}, "", comment.ErrAttachmentNotFound
+	}
+	if _, err = getIssueComment(issue, commentID); err != nil {
+		return model.IssueCommentAttachment{}, "", err
+	}
+	return attachment, comment.S().Path(attachment.StorageName), nil
+}
+
+// DeleteIssueCommentAttachment deletes an attachment of a comment of an issue, and removes its file from the storage
+func DeleteIssueCommentAttachment(issue model.Issue, commentID int64, attachmentID int64, user users.User) error {
+	if _, err := getIssueCommentOfAuthor(issue, commentID, user); err != nil {
+		return err
+	}
+	attachment, found, err := comment.R().GetAttachment(attachmentID)
+	if err != nil {
+		return err
+	}
+	if !found || attachment.CommentID != commentID {
+		return comment.ErrAttachmentNotFound
+	}
+	if err = comment.R().DeleteAttachment(attachmentID); err != nil {
+		return err
+	}
+	removeAttachmentFile(attachment)
+	return nil
+}
+
+func getIssueComment(issue model.Issue, commentID int64) (model.IssueComment, error) {
+	existing, found, err := comment.R().Get(commentID)
+	if err != nil {
+		return model.IssueComment{}, err
+	}
+	if !found || existing.IssueID != issue.ID {
+		return model.IssueComment{}, comment.ErrCommentNotFound
+	}
+	return existing, nil
+}
+
+func getIssueCommentOfAuthor(issue model.Issue, commentID int64, user users.User) (model.IssueComment, error) {
+	existing, err := getIssueComment(issue, commentID)
+	if err != nil {
+		return model.IssueComment{}, err
+	}
+	if existing.Author != user.Login {
+		return model.IssueComment{}, comment.ErrNotAuthor
+	}
+	return existing, nil
+}
+
+func removeAttachmentFile(attachment model.IssueCommentAttachment) {
+	if err := comment.S().Remove(attachment.StorageName); err != nil {
+		zap.L().Warn("Cannot remove the issue comment attachment file", zap.Int64("attachmentID", attachment.ID), zap.String("storageName", attachment.StorageName), zap.Error(err))
+	}
+}
+
+func publishIssueCommented(issue model.Issue, issueComment model.IssueComment, user users.User) {
+	event := model.NewIssueEvent(model.IssueEventCommented, issue, user.Login)
+	event.Comment = issueComment.Content
+	event.CommentID = issueComment.ID
+	events.Publish(event)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/draft"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/events"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
 )
 
@@ -52,5 +53,11 @@ func SaveIssueDraft(tx *sqlx.Tx, issue model.Issue, issueDraft model.FrontDraft,
 		return err
 	}
 
+	// Drafts saved within a closure transaction are followed by the closed event
+	if tx == nil {
+		issue.State = model.Draft
+		events.Publish(model.NewIssueEvent(model.IssueEventDrafted, issue, user.Login))
+	}
+
 	return nil
 }
@@ -0,0 +1,151 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"go.uber.org/zap"
+)
+
+var (
+	_globalBusMu sync.RWMutex
+	_globalBus   *Bus
+)
+
+// B is used to access the global issue event bus singleton
+func B() *Bus {
+	_globalBusMu.RLock()
+	defer _globalBusMu.RUnlock()
+
+	bus := _globalBus
+	return bus
+}
+
+// ReplaceGlobals affect a new bus to the global issue event bus singleton
+func ReplaceGlobals(bus *Bus) func() {
+	_globalBusMu.Lock()
+	defer _globalBusMu.Unlock()
+
+	prev := _globalBus
+	_globalBus = bus
+	return func() { ReplaceGlobals(prev) }
+}
+
+// Publish publishes an issue event on the global bus, if any
+func Publish(event model.IssueEvent) {
+	if bus := B(); bus != nil {
+		bus.Publish(event)
+	}
+}
+
+// Handler processes the issue events of a subscriber, it is called sequentially by the bus and must not block for long
+type Handler func(event model.IssueEvent)
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+// Bus dispatches the issue events to its subscribers, in the order of publication
+// The events are queued so the publishers (issue creation, closure, ...) are never blocked by a subscriber,
+// the events published while the queue is full are dropped. The synchronous subscribers receive every event
+// on its publication, before it is queued.
+type Bus struct {
+	mu              sync.RWMutex
+	subscribers     []subscriber
+	syncSubscribers []subscriber
+	queue           chan model.IssueEvent
+	stopped         bool
+	wg              sync.WaitGroup
+}
+
+// NewBus returns a new issue event bus with a queue of bufferSize events
+func NewBus(bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	return &Bus{
+		subscribers:     make([]subscriber, 0),
+		syncSubscribers: make([]subscriber, 0),
+		queue:           make(chan model.IssueEvent, bufferSize),
+	}
+}
+
+// Subscribe adds a subscriber to the bus
+func (bus *Bus) Subscribe(name string, handler Handler) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.subscribers = append(bus.subscribers, subscriber{name: name, handler: handler})
+}
+
+// SubscribeSync adds a subscriber called by the publisher itself, it must only do quick durable writes
+// (ie. the webhooks outbox) so that it never misses an event, even if the queue is full
+func (bus *Bus) SubscribeSync(name string, handler Handler) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.syncSubscribers = append(bus.syncSubscribers, subscriber{name: name, handler: handler})
+}
+
+// Start starts dispatching the queued events
+func (bus *Bus) Start() {
+	bus.wg.Add(1)
+	go func() {
+		defer bus.wg.Done()
+		for event := range bus.queue {
+			bus.dispatch(event)
+		}
+	}()
+}
+
+// Stop stops accepting new events, and waits for the queued events to be dispatched
+func (bus *Bus) Stop() {
+	bus.mu.Lock()
+	if bus.stopped {
+		bus.mu.Unlock()
+		return
+	}
+	bus.stopped = true
+	close(bus.queue)
+	bus.mu.Unlock()
+
+	bus.wg.Wait()
+}
+
+// Publish passes an event to the synchronous subscribers and queues it, it returns false if the event has been dropped
+func (bus *Bus) Publish(event model.IssueEvent) bool {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	if bus.stopped {
+		return false
+	}
+	for _, s := range bus.syncSubscribers {
+		s.call(event)
+	}
+	select {
+	case bus.queue <- event:
+		return true
+	default:
+		zap.L().Warn("Issue event queue is full, event dropped", zap.String("type", string(event.Type)), zap.Int64("issueID", event.IssueID))
+		return false
+	}
+}
+
+func (bus *Bus) dispatch(event model.IssueEvent) {
+	bus.mu.RLock()
+	subscribers := bus.subscribers
+	bus.mu.RUnlock()
+
+	for _, s := range subscribers {
+		s.call(event)
+	}
+}
+
+func (s subscriber) call(event model.IssueEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			zap.L().Error("Issue event subscriber panic", zap.String("subscriber", s.name), zap.Any("recover", r))
+		}
+	}()
+	s.handler(event)
+}
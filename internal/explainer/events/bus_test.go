@@ -0,0 +1,41 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+func TestBusDispatch(t *testing.T) {
+	bus := NewBus(10)
+	received := make([]model.IssueEventType, 0)
+	bus.Subscribe("panic", func(event model.IssueEvent) { panic("subscriber failure") })
+	bus.Subscribe("test", func(event model.IssueEvent) { received = append(received, event.Type) })
+	bus.Start()
+
+	bus.Publish(model.NewIssueEvent(model.IssueEventCreated, model.Issue{ID: 1}, ""))
+	bus.Publish(model.NewIssueEvent(model.IssueEventClosed, model.Issue{ID: 1}, "admin"))
+	bus.Stop()
+
+	if len(received) != 2 || received[0] != model.IssueEventCreated || received[1] != model.IssueEventClosed {
+		t.Errorf("unexpected events %v", received)
+	}
+	if bus.Publish(model.NewIssueEvent(model.IssueEventCreated, model.Issue{ID: 2}, "")) {
+		t.Error("expected no event to be accepted once the bus is stopped")
+	}
+}
+
+func TestBusDropWhenFull(t *testing.T) {
+	bus := NewBus(1)
+	persisted := 0
+	bus.SubscribeSync("outbox", func(event model.IssueEvent) { persisted++ })
+	if !bus.Publish(model.NewIssueEvent(model.IssueEventCreated, model.Issue{ID: 1}, "")) {
+		t.Error("expected the first event to be queued")
+	}
+	if bus.Publish(model.NewIssueEvent(model.IssueEventCreated, model.Issue{ID: 2}, "")) {
+		t.Error("expected the event to be dropped")
+	}
+	if persisted != 2 {
+		t.Errorf("expected the synchronous subscriber to receive the dropped event, got %d events", persisted)
+	}
+}
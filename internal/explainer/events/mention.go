@@ -0,0 +1,104 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/notifier"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/notifier/notification"
+	"go.uber.org/zap"
+)
+
+// IssueMentionNotification is the notification of a user mentioned in an issue comment
+type IssueMentionNotification struct {
+	notification.BaseNotification
+	IssueID     int64  `json:"issueId"`
+	SituationID int64  `json:"situationId"`
+	IssueName   string `json:"issueName"`
+	CommentID   int64  `json:"commentId"`
+	Author      string `json:"author"`
+	Content     string `json:"content"`
+}
+
+// NewIssueMentionNotification returns a new (persistent) notification of a user mentioned in an issue comment
+func NewIssueMentionNotification(issue model.Issue, comment model.IssueComment) *IssueMentionNotification {
+	return &IssueMentionNotification{
+		BaseNotification: notification.BaseNotification{
+			Type:       "IssueMentionNotification",
+			Persistent: true,
+		},
+		IssueID:     issue.ID,
+		SituationID: issue.SituationID,
+		IssueName:   issue.Name,
+		CommentID:   comment.ID,
+		Author:      comment.Author,
+		Content:     comment.Content,
+	}
+}
+
+// ToBytes convert a notification in a json byte slice to be sent through any required channel
+func (n IssueMentionNotification) ToBytes() ([]byte, error) {
+	b, err := json.Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NewInstance returns a new instance of a IssueMentionNotification
+func (n IssueMentionNotification) NewInstance(id int64, data []byte, isRead bool) (notification.Notification, error) {
+	var notif IssueMentionNotification
+	err := json.Unmarshal(data, &notif)
+	if err != nil {
+		return nil, err
+	}
+	notif.Id = id
+	notif.IsRead = isRead
+	notif.Notification = notif
+	return notif, nil
+}
+
+// Equals returns true if the two notifications are equals
+func (n IssueMentionNotification) Equals(notification notification.Notification) bool {
+	notif, ok := notification.(IssueMentionNotification)
+	if !ok {
+		return ok
+	}
+	if !notif.BaseNotification.Equals(n.BaseNotification) {
+		return false
+	}
+	return reflect.DeepEqual(notif, n)
+}
+
+// SetId set the notification ID
+func (n IssueMentionNotification) SetId(id int64) notification.Notification {
+	n.Id = id
+	return n
+}
+
+// SetPersistent sets whether the notification is persistent (saved to a database)
+func (n IssueMentionNotification) SetPersistent(persistent bool) notification.Notification {
+	n.Persistent = persistent
+	return n
+}
+
+// IsPersistent returns whether the notification is persistent (saved to a database)
+func (n IssueMentionNotification) IsPersistent() bool {
+	return n.Persistent
+}
+
+// NotifyMentions sends a notification to each user mentioned in an issue comment (except its author)
+func NotifyMentions(issue model.Issue, comment model.IssueComment, mentions []string) {
+	if notifier.C() == nil {
+		return
+	}
+	for _, login := range mentions {
+		if login == comment.Author {
+			continue
+		}
+		if err := notifier.C().SendToUserLogin(*NewIssueMentionNotification(issue, comment), login); err != nil {
+			zap.L().Error("Cannot notify the user mentioned in an issue comment", zap.String("login", login), zap.Int64("commentID", comment.ID), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/notifier"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/notifier/notification"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+)
+
+// IssueEventNotification is the notification of an issue event, sent to the WS/SSE clients
+type IssueEventNotification struct {
+	notification.BaseNotification
+	Event model.IssueEvent `json:"event"`
+}
+
+// NewIssueEventNotification returns a new (non-persistent) notification of an issue event
+func NewIssueEventNotification(event model.IssueEvent) *IssueEventNotification {
+	return &IssueEventNotification{
+		BaseNotification: notification.BaseNotification{
+			Type:       "IssueEventNotification",
+			Persistent: false,
+		},
+		Event: event,
+	}
+}
+
+// ToBytes convert a notification in a json byte slice to be sent through any required channel
+func (n IssueEventNotification) ToBytes() ([]byte, error) {
+	b, err := json.Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NewInstance returns a new instance of a IssueEventNotification
+func (n IssueEventNotification) NewInstance(id int64, data []byte, isRead bool) (notification.Notification, error) {
+	var notif IssueEventNotification
+	err := json.Unmarshal(data, &notif)
+	if err != nil {
+		return nil, err
+	}
+	notif.Id = id
+	notif.IsRead = isRead
+	notif.Notification = notif
+	return notif, nil
+}
+
+// Equals returns true if the two notifications are equals
+func (n IssueEventNotification) Equals(notification notification.Notification) bool {
+	notif, ok := notification.(IssueEventNotification)
+	if !ok {
+		return ok
+	}
+	if !notif.BaseNotification.Equals(n.BaseNotification) {
+		return false
+	}
+	return reflect.DeepEqual(notif.Event, n.Event)
+}
+
+// SetId set the notification ID
+func (n IssueEventNotification) SetId(id int64) notification.Notification {
+	n.Id = id
+	return n
+}
+
+// SetPersistent sets whether the notification is persistent (saved to a database)
+func (n IssueEventNotification) SetPersistent(persistent bool) notification.Notification {
+	n.Persistent = persistent
+	return n
+}
+
+// IsPersistent returns whether the notification is persistent (saved to a database)
+func (n IssueEventNotification) IsPersistent() bool {
+	return n.Persistent
+}
+
+// Notify is the issue event bus handler of the notifier
+// The event is only sent to the connected users allowed to read the issues of its situation
+func Notify(event model.IssueEvent) {
+	if notifier.C() == nil {
+		return
+	}
+	permission := permissions.New(permissions.TypeSituationIssues, strconv.FormatInt(event.SituationID, 10), permissions.ActionGet)
+	notifier.C().SendToPermitted(*NewIssueEventNotification(event), permission)
+}
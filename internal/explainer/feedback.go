@@ -10,6 +10,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/action"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/events"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/rootcause"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
@@ -38,6 +39,8 @@ func CloseIssueWithoutFeedback(dbClient *sqlx.DB, issue model.Issue, user users.
 		tx.Rollback()
 		return err
 	}
+
+	publishIssueClosed(issue, targetState, user)
 	return nil
 }
 
@@ -97,9 +100,18 @@ func CloseIssueWithFeedback(dbClient *sqlx.DB, issue model.Issue, recommendation
 		return err
 	}
 
+	publishIssueClosed(issue, targetState, user)
 	return nil
 }
 
+// publishIssueClosed publishes the closed event of an issue, once its closure is committed
+func publishIssueClosed(issue model.Issue, targetState model.IssueState, user users.User) {
+	issue.State = targetState
+	event := model.NewIssueEvent(model.IssueEventClosed, issue, user.Login)
+	event.State = targetState.String()
+	events.Publish(event)
+}
+
 func checkExistsIssueResolution(dbClient *sqlx.DB, issueID int64) (bool, error) {
 	var exists bool
 	checkNameQuery := `select exists(select 1 from issue_resolution_v1 where issue_id = $1) AS "exists"`
@@ -111,11 +123,20 @@ func checkExistsIssueResolution(dbClient *sqlx.DB, issueID int64) (bool, error)
 }
 
 func updateIssueState(tx *sqlx.Tx, issue model.Issue, targetState model.IssueState, user users.User) error {
+	previous := issue
 	issue.State = targetState
 	err := issues.R().Update(tx, issue.ID, issue, user)
 	if err != nil {
 		return err
 	}
+
+	now := time.Now()
+	if targetState == model.Draft && previous.AssignedAt == nil {
+		observeIssueAcknowledged(previous, now)
+	}
+	if targetState.IsClosed() {
+		observeIssueResolved(previous, now)
+	}
 	return nil
 }
 
@@ -0,0 +1,79 @@
+package explainer
+
+import (
+	"errors"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/draft"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/events"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// getIssueFlappingOptions returns the flapping detection options of the configuration
+// The detection is disabled if the options are not valid
+func getIssueFlappingOptions() model.IssueFlappingOptions {
+	options := model.IssueFlappingOptions{
+		Window:                viper.GetDuration("ISSUE_FLAPPING_WINDOW"),
+		Mode:                  model.FlappingMode(viper.GetString("ISSUE_FLAPPING_MODE")),
+		Threshold:             viper.GetInt("ISSUE_FLAPPING_THRESHOLD"),
+		SuppressNotifications: viper.GetBool("ISSUE_FLAPPING_SUPPRESS_NOTIFICATIONS"),
+	}
+	if ok, err := options.IsValid(); !ok {
+		zap.L().Warn("Invalid issue flapping options, flapping detection disabled", zap.Error(err))
+		return model.IssueFlappingOptions{}
+	}
+	return options
+}
+
+// findFlappingIssue returns the issue with the same key closed within the flapping window of a detection, if any
+func findFlappingIssue(detection model.Issue, options model.IssueFlappingOptions) (*model.Issue, error) {
+	if !options.Enabled() {
+		return nil, nil
+	}
+	closed, found, err := issues.R().GetLastClosedByKey(detection.Key, options.WindowStart(detection.SituationTS))
+	if err != nil {
+		return nil, err
+	}
+	if !found || !options.IsWithinWindow(closed, detection.SituationTS) {
+		return nil, nil
+	}
+	return &closed, nil
+}
+
+// reopenFlappingIssue reopens a closed issue on a new detection
+// The issue is reopened in draft state if a resolution draft was saved before its closure
+func reopenFlappingIssue(closed model.Issue, detection model.Issue, options model.IssueFlappingOptions) (int64, error) {
+	state := model.Open
+	hasDraft, err := draft.R().CheckExists(nil, closed.ID)
+	if err != nil {
+		return -1, err
+	}
+	if hasDraft {
+		state = model.Draft
+	}
+
+	reopened, found, err := issues.R().Reopen(closed.ID, detection, state)
+	if err != nil {
+		return -1, err
+	}
+	if !found {
+		return -1, errors.New("the flapping issue is not closed anymore")
+	}
+	zap.L().Info("Flapping issue reopened", zap.Int64("issueID", reopened.ID), zap.String("key", reopened.Key),
+		zap.Int("flapCount", reopened.FlapCount))
+
+	if options.SuppressNotifications && options.IsFlapping(reopened) {
+		return reopened.ID, nil
+	}
+	event := model.NewIssueEvent(model.IssueEventReopened, reopened, "")
+	event.State = closed.State.String()
+	events.Publish(event)
+	if closed.Level != reopened.Level {
+		event = model.NewIssueEvent(model.IssueEventLevelChanged, reopened, "")
+		event.PreviousLevel = closed.Level.String()
+		events.Publish(event)
+	}
+	return reopened.ID, nil
+}
@@ -0,0 +1,412 @@
+package incident
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/users"
+)
+
+const (
+	table      = "incident_v1"
+	rulesTable = "incident_correlation_rule_v1"
+)
+
+var incidentColumns = []string{"id", "name", "state", "correlation_rule_id", "correlation_key", "created_at", "last_modified", "last_issue_at",
+	"closed_at", "closed_by", "(SELECT COALESCE(array_agg(i.id ORDER BY i.id), '{}') FROM issues_v1 i WHERE i.incident_id = incident_v1.id)"}
+
+var ruleColumns = []string{"id", "name", "enabled", "keys", "time_window"}
+
+// PostgresRepository is a repository containing the incidents based on a PSQL database and
+// implementing the repository interface
+type PostgresRepository struct {
+	conn *sqlx.DB
+}
+
+// NewPostgresRepository returns a new instance of PostgresRepository
+func NewPostgresRepository(dbClient *sqlx.DB) Repository {
+	r := PostgresRepository{
+		conn: dbClient,
+	}
+	var repo Repository = &r
+	return repo
+}
+
+// Get returns an incident by its ID
+func (r *PostgresRepository) Get(id int64) (model.Incident, bool, error) {
+	incidents, err := r.query(newStatement().Select(incidentColumns...).From(table).Where(sq.Eq{"id": id}))
+	if err != nil {
+		return model.Incident{}, false, err
+	}
+	if len(incidents) == 0 {
+		return model.Incident{}, false, nil
+	}
+	return incidents[0], true, nil
+}
+
+// GetByStates returns the incidents in some states (all the incidents if no state is given), the most recent first
+func (r *PostgresRepository) GetByStates(states []model.IncidentState) ([]model.Incident, error) {
+	statement := newStatement().Select(incidentColumns...).From(table).OrderBy("last_issue_at desc", "id desc")
+	if len(states) > 0 {
+		s := make([]string, 0, len(states))
+		for _, state := range states {
+			s = append(s, string(state))
+		}
+		statement = statement.Where(sq.Eq{"state": s})
+	}
+	return r.query(statement)
+}
+
+// Correlate adds the issues of an incident to the open incident of its correlation rule and key which last issue situation date
+// is after since, or creates the incident if there is none. It returns the ID of the incident, and whether it was created.
+// Concurrent correlations of the same rule and key are serialized by a transaction lock, so that they never create two incidents.
+func (r *PostgresRepository) Correlate(incident model.Incident, since time.Time) (int64, bool, error) {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return -1, false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended($1, $2))", incident.CorrelationKey, incident.CorrelationRuleID)
+	if err != nil {
+		return -1, false, err
+	}
+
+	var id int64
+	err = newStatement().Select("id").From(table).
+		Where(sq.Eq{"correlation_rule_id": incident.CorrelationRuleID, "correlation_key": incident.CorrelationKey, "state": string(model.IncidentOpen)}).
+		Where(sq.GtOrEq{"last_issue_at": since}).
+		OrderBy("last_issue_at desc").
+		Limit(1).
+		Suffix("FOR UPDATE").
+		RunWith(tx).
+		QueryRow().
+		Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		if id, err = insertIncident(tx, incident); err != nil {
+			return -1, false, err
+		}
+		if err = attachIssues(tx, id, incident.IssueIDs); err != nil {
+			return -1, false, err
+		}
+		return id, true, tx.Commit()
+	case err != nil:
+		return -1, false, err
+	}
+
+	_, err = newStatement().
+		Update(table).
+		Set("last_issue_at", sq.Expr("GREATEST(last_issue_at, ?)", incident.LastIssueTS)).
+		Set("last_modified", time.Now().Truncate(1*time.Millisecond).UTC()).
+		Where(sq.Eq{"id": id}).
+		RunWith(tx).
+		Exec()
+	if err != nil {
+		return -1, false, err
+	}
+	if err = attachIssues(tx, id, incident.IssueIDs); err != nil {
+		return -1, false, err
+	}
+	return id, false, tx.Commit()
+}
+
+func (r *PostgresRepository) query(statement sq.SelectBuilder) ([]model.Incident, error) {
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	incidents := make([]model.Incident, 0)
+	for rows.Next() {
+		var incident model.Incident
+		var state string
+		var ruleID sql.NullInt64
+		var closedAt sql.NullTime
+		var closedBy sql.NullString
+		err = rows.Scan(&incident.ID, &incident.Name, &state, &ruleID, &incident.CorrelationKey, &incident.CreationTS, &incident.LastModificationTS,
+			&incident.LastIssueTS, &closedAt, &closedBy, pq.Array(&incident.IssueIDs))
+		if err != nil {
+			return nil, err
+		}
+		incident.State = model.IncidentState(state)
+		if ruleID.Valid {
+			incident.CorrelationRuleID = &ruleID.Int64
+		}
+		if closedAt.Valid {
+			incident.ClosedAt = &closedAt.Time
+		}
+		if closedBy.Valid {
+			incident.CloseBy = &closedBy.String
+		}
+		incidents = append(incidents, incident)
+	}
+	return incidents, rows.Err()
+}
+
+// Create creates a new open incident with its issues
+func (r *PostgresRepository) Create(incident model.Incident) (int64, error) {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	id, err := insertIncident(tx, incident)
+	if err != nil {
+		return -1, err
+	}
+	if err = attachIssues(tx, id, incident.IssueIDs); err != nil {
+		return -1, err
+	}
+	return id, tx.Commit()
+}
+
+func insertIncident(tx *sqlx.Tx, incident model.Incident) (int64, error) {
+	ts := time.Now().Truncate(1 * time.Millisecond).UTC()
+	lastIssueTS := incident.LastIssueTS
+	if lastIssueTS.IsZero() {
+		lastIssueTS = ts
+	}
+
+	var id int64
+	err := newStatement().
+		Insert(table).
+		Columns("name", "state", "correlation_rule_id", "correlation_key", "created_at", "last_modified", "last_issue_at").
+		Values(incident.Name, string(model.IncidentOpen), incident.CorrelationRuleID, incident.CorrelationKey, ts, ts, lastIssueTS).
+		Suffix("RETURNING \"id\"").
+		RunWith(tx).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+func attachIssues(tx *sqlx.Tx, id int64, issueIDs []int64) error {
+	if len(issueIDs) == 0 {
+		return nil
+	}
+	_, err := newStatement().Update("issues_v1").Set("incident_id", id).Where(sq.Eq{"id": issueIDs}).RunWith(tx).Exec()
+	return err
+}
+
+// AddIssues moves some issues into an incident, and updates the situation date of its last issue
+func (r *PostgresRepository) AddIssues(id int64, issueIDs []int64, lastIssueTS time.Time) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := newStatement().
+		Update(table).
+		Set("last_issue_at", sq.Expr("GREATEST(last_issue_at, ?)", lastIssueTS)).
+		Set("last_modified", time.Now().Truncate(1*time.Millisecond).UTC()).
+		Where(sq.Eq{"id": id}).
+		RunWith(tx).
+		Exec()
+	if err != nil {
+		return err
+	}
+	if err = checkAffected(res); err != nil {
+		return err
+	}
+	if err = attachIssues(tx, id, issueIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Merge moves the issues of some incidents into an incident, and deletes the merged incidents
+func (r *PostgresRepository) Merge(id int64, incidentIDs []int64) error {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = newStatement().Update("issues_v1").Set("incident_id", id).Where(sq.Eq{"incident_id": incidentIDs}).RunWith(tx).Exec()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE `+table+` SET last_modified = $1,
+		last_issue_at = GREATEST(last_issue_at, (SELECT max(last_issue_at) FROM `+table+` WHERE id = ANY($2)))
+		WHERE id = $3`, time.Now().Truncate(1*time.Millisecond).UTC(), pq.Array(incidentIDs), id)
+	if err != nil {
+		return err
+	}
+	_, err = newStatement().Delete(table).Where(sq.Eq{"id": incidentIDs}).RunWith(tx).Exec()
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Split moves some issues of an incident into a new manual incident
+func (r *PostgresRepository) Split(id int64, issueIDs []int64, name string) (int64, error) {
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	var lastIssueTS time.Time
+	err = tx.QueryRow(`SELECT max(situation_date) FROM issues_v1 WHERE incident_id = $1 AND id = ANY($2)`, id, pq.Array(issueIDs)).Scan(&lastIssueTS)
+	if err != nil {
+		return -1, err
+	}
+
+	newID, err := insertIncident(tx, model.Incident{Name: name, LastIssueTS: lastIssueTS})
+	if err != nil {
+		return -1, err
+	}
+	if err = attachIssues(tx, newID, issueIDs); err != nil {
+		return -1, err
+	}
+	return newID, tx.Commit()
+}
+
+// Close closes an open incident
+func (r *PostgresRepository) Close(id int64, user users.User) error {
+	ts := time.Now().Truncate(1 * time.Millisecond).UTC()
+	res, err := newStatement().
+		Update(table).
+		Set("state", string(model.IncidentClosed)).
+		Set("closed_at", ts).
+		Set("closed_by", user.Login).
+		Set("last_modified", ts).
+		Where(sq.Eq{"id": id, "state": string(model.IncidentOpen)}).
+		RunWith(r.conn.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// Delete deletes an incident, its issues are kept
+func (r *PostgresRepository) Delete(id int64) error {
+	res, err := newStatement().Delete(table).Where(sq.Eq{"id": id}).RunWith(r.conn.DB).Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// GetRule returns an incident correlation rule by its ID
+func (r *PostgresRepository) GetRule(id int64) (model.IncidentCorrelationRule, bool, error) {
+	rules, err := r.queryRules(newStatement().Select(ruleColumns...).From(rulesTable).Where(sq.Eq{"id": id}))
+	if err != nil {
+		return model.IncidentCorrelationRule{}, false, err
+	}
+	if len(rules) == 0 {
+		return model.IncidentCorrelationRule{}, false, nil
+	}
+	return rules[0], true, nil
+}
+
+// GetAllRules returns all the incident correlation rules, by order of priority
+func (r *PostgresRepository) GetAllRules() ([]model.IncidentCorrelationRule, error) {
+	return r.queryRules(newStatement().Select(ruleColumns...).From(rulesTable).OrderBy("id"))
+}
+
+func (r *PostgresRepository) queryRules(statement sq.SelectBuilder) ([]model.IncidentCorrelationRule, error) {
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]model.IncidentCorrelationRule, 0)
+	for rows.Next() {
+		var rule model.IncidentCorrelationRule
+		var keys []byte
+		if err = rows.Scan(&rule.ID, &rule.Name, &rule.Enabled, &keys, &rule.Window); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(keys, &rule.Keys); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// CreateRule creates a new incident correlation rule
+func (r *PostgresRepository) CreateRule(rule model.IncidentCorrelationRule) (int64, error) {
+	if _, err := rule.IsValid(); err != nil {
+		return -1, err
+	}
+	keys, err := json.Marshal(rule.Keys)
+	if err != nil {
+		return -1, err
+	}
+
+	var id int64
+	err = newStatement().
+		Insert(rulesTable).
+		Columns("name", "enabled", "keys", "time_window").
+		Values(rule.Name, rule.Enabled, string(keys), rule.Window).
+		Suffix("RETURNING \"id\"").
+		RunWith(r.conn.DB).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// UpdateRule updates an incident correlation rule, the existing incidents are kept
+func (r *PostgresRepository) UpdateRule(rule model.IncidentCorrelationRule) error {
+	if _, err := rule.IsValid(); err != nil {
+		return err
+	}
+	keys, err := json.Marshal(rule.Keys)
+	if err != nil {
+		return err
+	}
+
+	res, err := newStatement().
+		Update(rulesTable).
+		Set("name", rule.Name).
+		Set("enabled", rule.Enabled).
+		Set("keys", string(keys)).
+		Set("time_window", rule.Window).
+		Where(sq.Eq{"id": rule.ID}).
+		RunWith(r.conn.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// DeleteRule deletes an incident correlation rule, its incidents become manual incidents
+func (r *PostgresRepository) DeleteRule(id int64) error {
+	res, err := newStatement().Delete(rulesTable).Where(sq.Eq{"id": id}).RunWith(r.conn.DB).Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func checkAffected(res sql.Result) error {
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i != 1 {
+		return errors.New("no row updated (or multiple row updated) instead of 1 row")
+	}
+	return nil
+}
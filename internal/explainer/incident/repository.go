@@ -0,0 +1,63 @@
+package incident
+
+import (
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/users"
+)
+
+// Repository is a storage interface which can be implemented by multiple backend
+// (in-memory map, sql database, in-memory cache, file system, ...)
+// It allows standard CRUD operation on the incidents and their correlation rules
+type Repository interface {
+	Get(id int64) (model.Incident, bool, error)
+	GetByStates(states []model.IncidentState) ([]model.Incident, error)
+	Create(incident model.Incident) (int64, error)
+	Delete(id int64) error
+	Close(id int64, user users.User) error
+
+	// Issue memberships
+	Correlate(incident model.Incident, since time.Time) (int64, bool, error)
+	AddIssues(id int64, issueIDs []int64, lastIssueTS time.Time) error
+	Merge(id int64, incidentIDs []int64) error
+	Split(id int64, issueIDs []int64, name string) (int64, error)
+
+	// Correlation rules
+	GetRule(id int64) (model.IncidentCorrelationRule, bool, error)
+	GetAllRules() ([]model.IncidentCorrelationRule, error)
+	CreateRule(rule model.IncidentCorrelationRule) (int64, error)
+	UpdateRule(rule model.IncidentCorrelationRule) error
+	DeleteRule(id int64) error
+}
+
+var (
+	_globalRepositoryMu sync.RWMutex
+	_globalRepository   Repository
+)
+
+// R is used to access the global repository singleton
+func R() Repository {
+	_globalRepositoryMu.RLock()
+	defer _globalRepositoryMu.RUnlock()
+
+	repository := _globalRepository
+	return repository
+}
+
+// ReplaceGlobals affect a new repository to the global repository singleton
+func ReplaceGlobals(repository Repository) func() {
+	_globalRepositoryMu.Lock()
+	defer _globalRepositoryMu.Unlock()
+
+	prev := _globalRepository
+	_globalRepository = repository
+	return func() { ReplaceGlobals(prev) }
+}
+
+// newStatement creates a new SQL statement builder with Dollar placeholder format
+func newStatement() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
@@ -0,0 +1,236 @@
+package explainer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/draft"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/incident"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/functionalsituation"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/users"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/situation"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidIncidentOperation is returned when an incident operation is not allowed (ie. on a closed incident)
+var ErrInvalidIncidentOperation = errors.New("invalid incident operation")
+
+// CorrelateIssue groups a new issue into an incident, with the first enabled correlation rule matching it
+// The issue joins the open incident of the rule with the same correlation key if its last issue is within the rule time window,
+// a new incident is created otherwise
+func CorrelateIssue(issue model.Issue) error {
+	if incident.R() == nil {
+		return nil
+	}
+	rules, err := incident.R().GetAllRules()
+	if err != nil {
+		return err
+	}
+
+	// The attributes are only loaded once, by the first rule needing them
+	attributes := model.IssueCorrelationAttributes{}
+	attributesLoaded := false
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		window, err := rule.WindowDuration()
+		if err != nil {
+			zap.L().Warn("Invalid incident correlation rule", zap.Int64("ruleID", rule.ID), zap.Error(err))
+			continue
+		}
+		if rule.NeedsAttributes() && !attributesLoaded {
+			loaded, err := getIssueCorrelationAttributes(issue)
+			if err != nil {
+				return err
+			}
+			attributes = *loaded
+			attributesLoaded = true
+		}
+
+		key, ok := rule.CorrelationKey(issue, attributes)
+		if !ok {
+			continue
+		}
+
+		ruleID := rule.ID
+		_, _, err = incident.R().Correlate(model.Incident{
+			Name:              issue.Name,
+			CorrelationRuleID: &ruleID,
+			CorrelationKey:    key,
+			LastIssueTS:       issue.SituationTS,
+			IssueIDs:          []int64{issue.ID},
+		}, issue.SituationTS.Add(-window))
+		return err
+	}
+	return nil
+}
+
+// getIssueCorrelationAttributes returns the functional situations and the template instance parameters of an issue
+func getIssueCorrelationAttributes(issue model.Issue) (*model.IssueCorrelationAttributes, error) {
+	attributes := &model.IssueCorrelationAttributes{Parameters: make(map[string]interface{})}
+
+	if functionalsituation.R() != nil {
+		ids, err := functionalsituation.R().GetIDsBySituation(issue.SituationID, issue.TemplateInstanceID)
+		if err != nil {
+			return nil, err
+		}
+		attributes.FunctionalSituationIDs = ids
+	}
+
+	if issue.TemplateInstanceID != 0 {
+		instance, found, err := situation.R().GetTemplateInstance(issue.TemplateInstanceID)
+		if err != nil {
+			return nil, err
+		}
+		if found && instance.Parameters != nil {
+			attributes.Parameters = instance.Parameters
+		}
+	}
+	return attributes, nil
+}
+
+// CreateIncident creates a manual incident with some issues, which are removed from their previous incident
+func CreateIncident(name string, issueIDs []int64) (int64, error) {
+	if name == "" {
+		return -1, fmt.Errorf("%w: missing name", ErrInvalidIncidentOperation)
+	}
+	if len(issueIDs) == 0 {
+		return -1, fmt.Errorf("%w: missing issues", ErrInvalidIncidentOperation)
+	}
+	for _, issueID := range issueIDs {
+		issue, found, err := issues.R().Get(issueID)
+		if err != nil {
+			return -1, err
+		}
+		if !found {
+			return -1, fmt.Errorf("%w: issue %d not found", ErrInvalidIncidentOperation, issueID)
+		}
+		if issue.State.IsClosed() {
+			return -1, fmt.Errorf("%w: issue %d is closed", ErrInvalidIncidentOperation, issueID)
+		}
+	}
+	return incident.R().Create(model.Incident{Name: name, IssueIDs: issueIDs})
+}
+
+// MergeIncidents moves the issues of some open incidents into an open incident, the merged incidents are deleted
+func MergeIncidents(target model.Incident, incidentIDs []int64) error {
+	if target.State != model.IncidentOpen {
+		return fmt.Errorf("%w: incident %d is closed", ErrInvalidIncidentOperation, target.ID)
+	}
+	if len(incidentIDs) == 0 {
+		return fmt.Errorf("%w: missing incidents to merge", ErrInvalidIncidentOperation)
+	}
+	for _, id := range incidentIDs {
+		if id == target.ID {
+			return fmt.Errorf("%w: an incident cannot be merged into itself", ErrInvalidIncidentOperation)
+		}
+		source, found, err := incident.R().Get(id)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w: incident %d not found", ErrInvalidIncidentOperation, id)
+		}
+		if source.State != model.IncidentOpen {
+			return fmt.Errorf("%w: incident %d is closed", ErrInvalidIncidentOperation, id)
+		}
+	}
+	return incident.R().Merge(target.ID, incidentIDs)
+}
+
+// SplitIncident moves some issues of an open incident into a new manual incident
+func SplitIncident(source model.Incident, issueIDs []int64, name string) (int64, error) {
+	if source.State != model.IncidentOpen {
+		return -1, fmt.Errorf("%w: incident %d is closed", ErrInvalidIncidentOperation, source.ID)
+	}
+	if len(issueIDs) == 0 {
+		return -1, fmt.Errorf("%w: missing issues to split", ErrInvalidIncidentOperation)
+	}
+	if len(issueIDs) >= len(source.IssueIDs) {
+		return -1, fmt.Errorf("%w: at least one issue must be kept in the incident", ErrInvalidIncidentOperation)
+	}
+	members := make(map[int64]bool, len(source.IssueIDs))
+	for _, id := range source.IssueIDs {
+		members[id] = true
+	}
+	for _, id := range issueIDs {
+		if !members[id] {
+			return -1, fmt.Errorf("%w: issue %d is not in incident %d", ErrInvalidIncidentOperation, id, source.ID)
+		}
+	}
+	if name == "" {
+		name = source.Name
+	}
+	return incident.R().Split(source.ID, issueIDs, name)
+}
+
+// CloseIncidentWithFeedback closes all the open and draft issues of an incident with the same rootcause/action feedback
+// The feedback is applied by name on the issues of the other situations and rules (with custom rootcause and actions if needed),
+// the incident is closed once all its issues are closed
+func CloseIncidentWithFeedback(dbClient *sqlx.DB, inc model.Incident, recommendation model.FrontRecommendation, user users.User, isFakeAlert bool) (model.CloseIssuesStatus, error) {
+	status := model.CloseIssuesStatus{AllOk: true}
+	if inc.State != model.IncidentOpen {
+		return status, fmt.Errorf("%w: incident %d is closed", ErrInvalidIncidentOperation, inc.ID)
+	}
+
+	selectedRootCause, selectedActions, err := ExtractSelectedFromTree(recommendation)
+	if err != nil {
+		return status, fmt.Errorf("%w: %s", ErrInvalidIncidentOperation, err)
+	}
+
+	for _, issueID := range inc.IssueIDs {
+		issue, found, err := issues.R().Get(issueID)
+		if err != nil {
+			CloseHandleError(&status, issueID, err, httputil.ErrAPIDBSelectFailed)
+			continue
+		}
+		if !found || issue.State.IsClosed() {
+			continue
+		}
+
+		issueRecommendation, err := incidentIssueRecommendation(issue, *selectedRootCause, selectedActions)
+		if err != nil {
+			zap.L().Error("Generating rootcauses / actions tree", zap.Int64("id", issue.ID), zap.Error(err))
+			CloseHandleError(&status, issueID, err, httputil.ErrAPIDBSelectFailed)
+			continue
+		}
+
+		err = CloseIssueWithFeedback(dbClient, issue, issueRecommendation, user, isFakeAlert)
+		if err != nil {
+			zap.L().Error("CloseIssueWithFeedback", zap.Int64("id", issue.ID), zap.Error(err))
+			CloseHandleError(&status, issueID, err, httputil.ErrAPIDBUpdateFailed)
+			continue
+		}
+		status.SuccessCount++
+	}
+
+	if !status.AllOk {
+		return status, nil
+	}
+	return status, incident.R().Close(inc.ID, user)
+}
+
+// incidentIssueRecommendation returns the recommendation tree of an issue with the incident feedback selected
+func incidentIssueRecommendation(issue model.Issue, rootCause model.FrontRootCause, actions []*model.FrontAction) (model.FrontRecommendation, error) {
+	tree, err := buildRecommendationTree(issue.SituationID, issue.Rule.RuleID)
+	if err != nil {
+		return model.FrontRecommendation{}, err
+	}
+	recommendation := tree.SelectByName(rootCause, actions)
+
+	if issue.State == model.Draft {
+		existing, found, err := draft.R().Get(issue.ID)
+		if err != nil {
+			return model.FrontRecommendation{}, err
+		}
+		if found {
+			recommendation.ConcurrencyUUID = existing.ConcurrencyUUID
+		}
+	}
+	return recommendation, nil
+}
@@ -5,11 +5,14 @@ import (
 
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/fact"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/reader"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/users"
 
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/events"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/history"
 	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+	"github.com/myrteametrics/myrtea-sdk/v5/postgres"
 	"go.uber.org/zap"
 )
 
@@ -37,6 +40,7 @@ func CreateIssue(situationHistoryID int64, situationID int64, templateInstanceID
 		return -1, err
 	}
 
+	var previousIssue *model.Issue
 	if isOpen {
 		//zap.L().Debug("Issue creation skipped (timeout not reached)")
 		for _, existingIssue := range existingIssues {
@@ -44,17 +48,76 @@ func CreateIssue(situationHistoryID int64, situationID int64, templateInstanceID
 				zap.L().Debug("Issue creation skipped - same level already exists")
 				return 0, nil
 			}
+			if previousIssue == nil || existingIssue.ID > previousIssue.ID {
+				previous := existingIssue
+				previousIssue = &previous
+			}
 		}
 		zap.L().Debug("Existing issues found but with different level - creating new issue")
 	}
 
+	// A detection shortly after the closure of an issue with the same key is a flap, the closed issue is reopened or linked
+	flapping := getIssueFlappingOptions()
+	if !isOpen {
+		closedIssue, err := findFlappingIssue(issue, flapping)
+		if err != nil {
+			zap.L().Error("Cannot search flapping issue", zap.String("key", key), zap.Error(err))
+			return -1, err
+		}
+		if closedIssue != nil && flapping.CanReopen(*closedIssue) {
+			return reopenFlappingIssue(*closedIssue, issue, flapping)
+		}
+		if closedIssue != nil {
+			flapTS := time.Now().Truncate(1 * time.Millisecond).UTC()
+			issue.PreviousIssueID = &closedIssue.ID
+			issue.FlapCount = closedIssue.FlapCount + 1
+			issue.LastFlapAt = &flapTS
+		}
+	}
+
 	id, err := issues.R().Create(issue)
 	if err != nil {
 		return -1, err
 	}
+
+	issue.ID = id
+	if err = CorrelateIssue(issue); err != nil {
+		zap.L().Error("Cannot correlate issue into an incident", zap.Int64("issueID", id), zap.Error(err))
+	}
+
+	if flapping.SuppressNotifications && flapping.IsFlapping(issue) {
+		return id, nil
+	}
+	events.Publish(model.NewIssueEvent(model.IssueEventCreated, issue, ""))
+	if previousIssue != nil {
+		event := model.NewIssueEvent(model.IssueEventLevelChanged, issue, "")
+		event.PreviousLevel = previousIssue.Level.String()
+		events.Publish(event)
+	}
 	return id, nil
 }
 
+// UpdateIssueComment updates the comment of an issue and publishes its commented event
+func UpdateIssueComment(issueID int64, comment string, user users.User) error {
+	issue, found, err := issues.R().Get(issueID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return issues.ErrIssueNotFound
+	}
+
+	if err = issues.R().UpdateComment(postgres.DB(), issueID, comment); err != nil {
+		return err
+	}
+
+	issue.Comment = &comment
+	event := model.NewIssueEvent(model.IssueEventCommented, issue, user.Login)
+	event.Comment = comment
+	events.Publish(event)
+	return nil
+}
+
 //func isNewIssue(issue model.Issue) (bool, error) {
 //	issues, err := issues.R().GetCloseToTimeoutByKey(issue.Key, issue.SituationTS)
 //	if err != nil {
@@ -17,6 +17,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrIssueNotFound is returned when an issue does not exist
+var ErrIssueNotFound = errors.New("issue not found")
+
 // PostgresRepository is a repository containing the Issue definition based on a PSQL database and
 // implementing the repository interface
 type PostgresRepository struct {
@@ -36,7 +39,8 @@ func NewPostgresRepository(dbClient *sqlx.DB) Repository {
 func (r *PostgresRepository) Get(id int64) (model.Issue, bool, error) {
 	query := `SELECT i.id, i.key, i.name, i.level, i.situation_history_id, i.situation_id, situation_instance_id, i.situation_date,
 			  i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified, i.detection_rating_avg,
-			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment
+			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+			  i.flap_count, i.last_flap_at, i.previous_issue_id
 			  FROM issues_v1 as i
 			  WHERE  i.id = :id`
 	rows, err := r.conn.NamedQuery(query, map[string]interface{}{
@@ -71,8 +75,8 @@ func (r *PostgresRepository) Create(issue model.Issue) (int64, error) {
 		return -1, err
 	}
 
-	query := `INSERT into issues_v1 (id, key, name, level, situation_history_id, situation_id, situation_instance_id, situation_date, expiration_date, rule_data, state, created_at, last_modified, detection_rating_avg, comment)
-			  values (DEFAULT, :key, :name, :level, :situation_history_id, :situation_id, :situation_instance_id, :situation_date, :expiration_date, :rule_data, :state, :created_at, :last_modified, :detection_rating_avg, :comment) RETURNING id`
+	query := `INSERT into issues_v1 (id, key, name, level, situation_history_id, situation_id, situation_instance_id, situation_date, expiration_date, rule_data, state, created_at, last_modified, detection_rating_avg, comment, flap_count, last_flap_at, previous_issue_id)
+			  values (DEFAULT, :key, :name, :level, :situation_history_id, :situation_id, :situation_instance_id, :situation_date, :expiration_date, :rule_data, :state, :created_at, :last_modified, :detection_rating_avg, :comment, :flap_count, :last_flap_at, :previous_issue_id) RETURNING id`
 	params := map[string]interface{}{
 		"key":                   issue.Key,
 		"name":                  issue.Name,
@@ -88,6 +92,9 @@ func (r *PostgresRepository) Create(issue model.Issue) (int64, error) {
 		"last_modified":         lastModificationTS,
 		"detection_rating_avg":  -1,
 		"comment":               issue.Comment,
+		"flap_count":            issue.FlapCount,
+		"last_flap_at":          issue.LastFlapAt,
+		"previous_issue_id":     issue.PreviousIssueID,
 	}
 
 	rows, err := r.conn.NamedQuery(query, params)
@@ -147,7 +154,7 @@ func (r *PostgresRepository) Update(tx *sqlx.Tx, id int64, issue model.Issue, us
 		query = query + `, closed_at = :ts, closed_by = :user`
 	}
 	if issue.State == model.Draft && issue.AssignedAt == nil {
-		query = query + `, assigned_at = :ts, assigned_to = :user`
+		query = query + `, assigned_at = :ts, assigned_to = :user, acknowledged_at = COALESCE(acknowledged_at, :ts)`
 	}
 	query = query + ` WHERE id = :id`
 
@@ -182,6 +189,19 @@ func (r *PostgresRepository) Update(tx *sqlx.Tx, id int64, issue model.Issue, us
 	if i != 1 {
 		return errors.New("no row inserted (or multiple row inserted) instead of 1 row")
 	}
+
+	if issue.State == model.Draft && issue.AssignedAt == nil {
+		// Drafting an unassigned issue assigns it to its author
+		entry := model.IssueAuditEntry{IssueID: id, TS: lastModificationTS, User: user.Login, Action: model.IssueAuditAssign, NewValue: &user.Login}
+		if tx != nil {
+			_, err = insertAuditEntry(tx, entry)
+		} else {
+			_, err = insertAuditEntry(r.conn, entry)
+		}
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -192,7 +212,8 @@ func (r *PostgresRepository) GetOpenAndDraftIssuesByKey(key string) (map[int64]m
 
 	query := `SELECT i.id, i.key, i.name, i.level,  i.situation_history_id, i.situation_id, situation_instance_id, i.situation_date,
 			  i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified, i.detection_rating_avg,
-			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment
+			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+			  i.flap_count, i.last_flap_at, i.previous_issue_id
 			  FROM issues_v1 as i
 			  WHERE key = :key and (i.state = ANY ( :states ))`
 
@@ -215,6 +236,87 @@ func (r *PostgresRepository) GetOpenAndDraftIssuesByKey(key string) (map[int64]m
 	return issues, nil
 }
 
+// GetLastClosedByKey returns the last issue with a key closed since a date (or last modified, for the issues closed by the tasks)
+func (r *PostgresRepository) GetLastClosedByKey(key string, closedSince time.Time) (model.Issue, bool, error) {
+	query := `SELECT i.id, i.key, i.name, i.level,  i.situation_history_id, i.situation_id, situation_instance_id, i.situation_date,
+			  i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified, i.detection_rating_avg,
+			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+			  i.flap_count, i.last_flap_at, i.previous_issue_id
+			  FROM issues_v1 as i
+			  WHERE key = :key and (i.state = ANY ( :closed_states ))
+			  and COALESCE(i.closed_at, i.last_modified) >= :closed_since
+			  ORDER BY COALESCE(i.closed_at, i.last_modified) DESC, i.id DESC
+			  LIMIT 1`
+
+	rows, err := r.conn.NamedQuery(query, map[string]interface{}{
+		"key":           key,
+		"closed_since":  closedSince,
+		"closed_states": pq.Array(closedStates()),
+	})
+	if err != nil {
+		return model.Issue{}, false, errors.New("couldn't retrieve the last closed issue with key: " + err.Error())
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return model.Issue{}, false, rows.Err()
+	}
+	issue, err := scanIssue(rows)
+	if err != nil {
+		return model.Issue{}, false, err
+	}
+	return issue, true, nil
+}
+
+// Reopen reopens a closed issue on a new detection, and counts the flap
+// The issue takes the level, situation history and expiration of the detection
+// It returns false if the issue does not exist or is not closed anymore
+func (r *PostgresRepository) Reopen(id int64, detection model.Issue, toState model.IssueState) (model.Issue, bool, error) {
+	ts := time.Now().Truncate(1 * time.Millisecond).UTC()
+
+	ruleData, err := json.Marshal(detection.Rule)
+	if err != nil {
+		return model.Issue{}, false, err
+	}
+
+	query := `UPDATE issues_v1 SET state = :to_state, level = :level, situation_history_id = :situation_history_id,
+			  situation_date = :situation_date, expiration_date = :expiration_date, rule_data = :rule_data,
+			  last_modified = :ts, closed_at = NULL, closed_by = NULL, flap_count = flap_count + 1, last_flap_at = :ts
+			  WHERE id = :id AND state = ANY ( :closed_states )
+			  RETURNING ` + changedIssueColumns
+
+	reopened, err := r.queryChangedIssues(query, map[string]interface{}{
+		"id":                   id,
+		"to_state":             toState.String(),
+		"level":                detection.Level.String(),
+		"situation_history_id": detection.SituationHistoryID,
+		"situation_date":       detection.SituationTS,
+		"expiration_date":      detection.ExpirationTS,
+		"rule_data":            string(ruleData),
+		"ts":                   ts,
+		"closed_states":        pq.Array(closedStates()),
+	})
+	if err != nil {
+		return model.Issue{}, false, err
+	}
+	if len(reopened) == 0 {
+		return model.Issue{}, false, nil
+	}
+	return reopened[0], true, nil
+}
+
+func closedStates() []string {
+	return []string{
+		model.ClosedFeedbackConfirmed.String(),
+		model.ClosedFeedbackRejected.String(),
+		model.ClosedNoFeedback.String(),
+		model.ClosedTimeout.String(),
+		model.ClosedDiscard.String(),
+		model.ClosedConfirmed.String(),
+		model.ClosedRejected.String(),
+	}
+}
+
 // GetCloseToTimeoutByKey get all issues that belong to the same situation and their
 // creation time are within the timeout duration
 func (r *PostgresRepository) GetCloseToTimeoutByKey(key string, firstSituationTS time.Time) (map[int64]model.Issue, error) {
@@ -222,7 +324,8 @@ func (r *PostgresRepository) GetCloseToTimeoutByKey(key string, firstSituationTS
 
 	query := `SELECT i.id, i.key, i.name, i.level,  i.situation_history_id, i.situation_id, situation_instance_id, i.situation_date,
 			  i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified, i.detection_rating_avg,
-			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment
+			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+			  i.flap_count, i.last_flap_at, i.previous_issue_id
 			  FROM issues_v1 as i
 			  WHERE key = :key and :first_situation_date < expiration_date
 			  and NOT ( i.state = ANY ( :closed_states ))`
@@ -262,7 +365,8 @@ func (r *PostgresRepository) GetByKeyByPage(key string, options model.SearchOpti
 	query := `SELECT i.id, i.key, i.name, i.level, i.situation_history_id,
         i.situation_id, situation_instance_id, i.situation_date,
         i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified,
-        i.detection_rating_avg, i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment
+        i.detection_rating_avg, i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+        i.flap_count, i.last_flap_at, i.previous_issue_id
     	FROM issues_v1 as i
 		inner join situation_definition_v1 on situation_definition_v1.id = i.situation_id
 		WHERE i.key = :key`
@@ -301,7 +405,7 @@ func (r *PostgresRepository) GetByKeyByPage(key string, options model.SearchOpti
 }
 
 // ChangeState method used to change the issues state with key and created_date between from and to
-func (r *PostgresRepository) ChangeState(key string, fromStates []model.IssueState, toState model.IssueState) error {
+func (r *PostgresRepository) ChangeState(key string, fromStates []model.IssueState, toState model.IssueState) ([]model.Issue, error) {
 	LastModificationTS := time.Now().Truncate(1 * time.Millisecond).UTC()
 
 	// Review 2023-02-10 : We are about to close all issues, there's no need to have "key" here
@@ -310,7 +414,8 @@ func (r *PostgresRepository) ChangeState(key string, fromStates []model.IssueSta
 	//		  WHERE key = :key AND state = ANY ( :from_states )`
 
 	query := `UPDATE issues_v1 SET state = :to_state, last_modified = :last_modified
-			  WHERE state = ANY ( :from_states )`
+			  WHERE state = ANY ( :from_states )
+			  RETURNING ` + changedIssueColumns
 
 	var states []string
 	for _, state := range fromStates {
@@ -324,28 +429,17 @@ func (r *PostgresRepository) ChangeState(key string, fromStates []model.IssueSta
 		"last_modified": LastModificationTS,
 	}
 
-	var res sql.Result
-	var err error
-	res, err = r.conn.NamedExec(query, params)
-
-	if err != nil {
-		return errors.New("couldn't query the database:" + err.Error())
-	}
-
-	_, err = res.RowsAffected()
-	if err != nil {
-		return errors.New("error with the affected res:" + err.Error())
-	}
-	return nil
+	return r.queryChangedIssues(query, params)
 }
 
 // ChangeStateBetweenDates method used to change the issues state with key and created_date between from and to
-func (r *PostgresRepository) ChangeStateBetweenDates(key string, fromStates []model.IssueState, toState model.IssueState, from time.Time, to time.Time) error {
+func (r *PostgresRepository) ChangeStateBetweenDates(key string, fromStates []model.IssueState, toState model.IssueState, from time.Time, to time.Time) ([]model.Issue, error) {
 	LastModificationTS := time.Now().Truncate(1 * time.Millisecond).UTC()
 
 	// Here we exclude some fields that are not to be updated
 	query := `UPDATE issues_v1 SET state = :to_state, last_modified = :last_modified
-			  WHERE key = :key AND state = ANY ( :from_states ) AND created_at >= :from AND created_at < :to`
+			  WHERE key = :key AND state = ANY ( :from_states ) AND created_at >= :from AND created_at < :to
+			  RETURNING ` + changedIssueColumns
 
 	var states []string
 	for _, state := range fromStates {
@@ -361,19 +455,49 @@ func (r *PostgresRepository) ChangeStateBetweenDates(key string, fromStates []mo
 		"last_modified": LastModificationTS,
 	}
 
-	var res sql.Result
-	var err error
-	res, err = r.conn.NamedExec(query, params)
+	return r.queryChangedIssues(query, params)
+}
 
-	if err != nil {
-		return errors.New("couldn't query the database:" + err.Error())
+// CloseExpired closes as timed out the open issues which expiration date is before a date, and returns them
+// The draft issues are kept, as their resolution is being written
+func (r *PostgresRepository) CloseExpired(before time.Time) ([]model.Issue, error) {
+	query := `UPDATE issues_v1 SET state = :to_state, last_modified = :last_modified
+			  WHERE state = ANY ( :from_states ) AND expiration_date <= :before
+			  RETURNING ` + changedIssueColumns
+
+	params := map[string]interface{}{
+		"from_states":   pq.Array([]string{model.Open.String()}),
+		"to_state":      model.ClosedTimeout.String(),
+		"before":        before,
+		"last_modified": time.Now().Truncate(1 * time.Millisecond).UTC(),
 	}
 
-	_, err = res.RowsAffected()
+	return r.queryChangedIssues(query, params)
+}
+
+// changedIssueColumns are the issue columns returned by the state changes, in the scanIssue order
+const changedIssueColumns = `id, key, name, level, situation_history_id, situation_id, situation_instance_id, situation_date,
+			  expiration_date, rule_data, state, created_at, last_modified, detection_rating_avg,
+			  assigned_at, assigned_to, closed_at, closed_by, comment, acknowledged_at, incident_id,
+			  flap_count, last_flap_at, previous_issue_id`
+
+// queryChangedIssues executes a state change query and returns the changed issues
+func (r *PostgresRepository) queryChangedIssues(query string, params map[string]interface{}) ([]model.Issue, error) {
+	rows, err := r.conn.NamedQuery(query, params)
 	if err != nil {
-		return errors.New("error with the affected res:" + err.Error())
+		return nil, errors.New("couldn't query the database:" + err.Error())
 	}
-	return nil
+	defer rows.Close()
+
+	changed := make([]model.Issue, 0)
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, issue)
+	}
+	return changed, rows.Err()
 }
 
 // GetAll method used to get all issues
@@ -382,7 +506,8 @@ func (r *PostgresRepository) GetAll() (map[int64]model.Issue, error) {
 
 	query := `SELECT i.id, i.key, i.name, i.level, i.situation_history_id, i.situation_id, situation_instance_id, i.situation_date,
 			  i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified, i.detection_rating_avg,
-			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment
+			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+			  i.flap_count, i.last_flap_at, i.previous_issue_id
 			  FROM issues_v1 as i`
 	rows, err := r.conn.NamedQuery(query, map[string]interface{}{})
 
@@ -406,7 +531,8 @@ func (r *PostgresRepository) GetAllBySituationIDs(situationIDs []int64) (map[int
 
 	query := `SELECT i.id, i.key, i.name, i.level, i.situation_history_id, i.situation_id, situation_instance_id, i.situation_date,
 		  i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified, i.detection_rating_avg,
-		  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment
+		  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+		  i.flap_count, i.last_flap_at, i.previous_issue_id
 		  FROM issues_v1 as i
 		  inner join situation_definition_v1 on situation_definition_v1.id = i.situation_id
 		  WHERE situation_definition_v1.id = ANY(:situation_ids)`
@@ -435,7 +561,8 @@ func (r *PostgresRepository) GetByStates(issueStates []string) (map[int64]model.
 
 	query := `SELECT i.id, i.key, i.name, i.level, i.situation_history_id, i.situation_id, situation_instance_id, i.situation_date,
 			  i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified, i.detection_rating_avg,
-			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment
+			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+			  i.flap_count, i.last_flap_at, i.previous_issue_id
 			  FROM issues_v1 as i`
 	params := map[string]interface{}{}
 
@@ -467,7 +594,8 @@ func (r *PostgresRepository) GetByStatesBySituationIDs(issueStates []string, sit
 
 	query := `SELECT i.id, i.key, i.name, i.level, i.situation_history_id, i.situation_id, situation_instance_id, i.situation_date,
 			  i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified, i.detection_rating_avg,
-			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment
+			  i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+			  i.flap_count, i.last_flap_at, i.previous_issue_id
 			  FROM issues_v1 as i
 			  inner join situation_definition_v1 on situation_definition_v1.id = i.situation_id
 			  WHERE situation_definition_v1.id = ANY(:situation_ids)`
@@ -497,19 +625,25 @@ func (r *PostgresRepository) GetByStatesBySituationIDs(issueStates []string, sit
 	return issues, nil
 }
 
-// GetByStateByPage method used to get all issues
-func (r *PostgresRepository) GetByStateByPage(issueStates []string, options model.SearchOptions) ([]model.Issue, int, error) {
+// GetByStateByPage method used to get all issues (optionally assigned to a user)
+func (r *PostgresRepository) GetByStateByPage(issueStates []string, options model.SearchOptions, assignedTo string) ([]model.Issue, int, error) {
 	issues := make([]model.Issue, 0)
 	query := `SELECT i.id, i.key, i.name, i.level, i.situation_history_id,
 		i.situation_id, situation_instance_id, i.situation_date,
 		i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified,
-		i.detection_rating_avg, i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment
+		i.detection_rating_avg, i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+		i.flap_count, i.last_flap_at, i.previous_issue_id
 	FROM issues_v1 as i`
 	params := map[string]interface{}{}
+	query += ` WHERE true`
 	if len(issueStates) > 0 {
-		query += ` WHERE i.state = ANY (:states)`
+		query += ` and i.state = ANY (:states)`
 		params["states"] = pq.Array(issueStates)
 	}
+	if assignedTo != "" {
+		query += ` and i.assigned_to = :assigned_to`
+		params["assigned_to"] = assignedTo
+	}
 	if len(options.SortBy) == 0 {
 		options.SortBy = []model.SortOption{{Field: "id", Order: model.Asc}}
 	}
@@ -534,7 +668,7 @@ func (r *PostgresRepository) GetByStateByPage(issueStates []string, options mode
 		issues = append(issues, issue)
 	}
 
-	total, err := r.CountByStateByPage(issueStates)
+	total, err := r.CountByStateByPage(issueStates, assignedTo)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -542,14 +676,15 @@ func (r *PostgresRepository) GetByStateByPage(issueStates []string, options mode
 	return issues, total, nil
 }
 
-// GetByStateByPage method used to get all issues
-func (r *PostgresRepository) GetByStateByPageBySituationIDs(issueStates []string, options model.SearchOptions, situationIDs []int64) ([]model.Issue, int, error) {
+// GetByStateByPageBySituationIDs method used to get all issues of some situations (optionally assigned to a user)
+func (r *PostgresRepository) GetByStateByPageBySituationIDs(issueStates []string, options model.SearchOptions, situationIDs []int64, assignedTo string) ([]model.Issue, int, error) {
 	issues := make([]model.Issue, 0)
 
 	query := `SELECT i.id, i.key, i.name, i.level, i.situation_history_id,
 		i.situation_id, situation_instance_id, i.situation_date,
 		i.expiration_date, i.rule_data, i.state, i.created_at, i.last_modified,
-		i.detection_rating_avg, i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment
+		i.detection_rating_avg, i.assigned_at, i.assigned_to, i.closed_at, i.closed_by, i.comment, i.acknowledged_at, i.incident_id,
+		i.flap_count, i.last_flap_at, i.previous_issue_id
 	FROM issues_v1 as i
 	inner join situation_definition_v1 on situation_definition_v1.id = i.situation_id
 	WHERE situation_definition_v1.id = ANY(:situation_ids)`
@@ -560,6 +695,10 @@ func (r *PostgresRepository) GetByStateByPageBySituationIDs(issueStates []string
 		query += ` and i.state = ANY (:states)`
 		params["states"] = pq.Array(issueStates)
 	}
+	if assignedTo != "" {
+		query += ` and i.assigned_to = :assigned_to`
+		params["assigned_to"] = assignedTo
+	}
 	if len(options.SortBy) == 0 {
 		options.SortBy = []model.SortOption{{Field: "id", Order: model.Asc}}
 	}
@@ -584,7 +723,7 @@ func (r *PostgresRepository) GetByStateByPageBySituationIDs(issueStates []string
 		issues = append(issues, issue)
 	}
 
-	total, err := r.CountByStateByPageBySituationIDs(issueStates, situationIDs)
+	total, err := r.CountByStateByPageBySituationIDs(issueStates, situationIDs, assignedTo)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -593,15 +732,19 @@ func (r *PostgresRepository) GetByStateByPageBySituationIDs(issueStates []string
 }
 
 // CountByStateByPage method used to count all issues
-func (r *PostgresRepository) CountByStateByPage(issueStates []string) (int, error) {
+func (r *PostgresRepository) CountByStateByPage(issueStates []string, assignedTo string) (int, error) {
 
 	query := `select count(*)
-		FROM issues_v1`
+		FROM issues_v1 WHERE true`
 	params := map[string]interface{}{}
 	if len(issueStates) > 0 {
-		query += ` WHERE issues_v1.state = ANY (:states)`
+		query += ` and issues_v1.state = ANY (:states)`
 		params["states"] = pq.Array(issueStates)
 	}
+	if assignedTo != "" {
+		query += ` and issues_v1.assigned_to = :assigned_to`
+		params["assigned_to"] = assignedTo
+	}
 	rows, err := r.conn.NamedQuery(query, params)
 	if err != nil {
 		return 0, err
@@ -619,7 +762,7 @@ func (r *PostgresRepository) CountByStateByPage(issueStates []string) (int, erro
 }
 
 // CountByStateByPage method used to count all issues
-func (r *PostgresRepository) CountByStateByPageBySituationIDs(issueStates []string, situationIDs []int64) (int, error) {
+func (r *PostgresRepository) CountByStateByPageBySituationIDs(issueStates []string, situationIDs []int64, assignedTo string) (int, error) {
 
 	query := `select count(*)
 		FROM issues_v1
@@ -632,6 +775,10 @@ func (r *PostgresRepository) CountByStateByPageBySituationIDs(issueStates []stri
 		query += ` and issues_v1.state = ANY (:states)`
 		params["states"] = pq.Array(issueStates)
 	}
+	if assignedTo != "" {
+		query += ` and issues_v1.assigned_to = :assigned_to`
+		params["assigned_to"] = assignedTo
+	}
 	rows, err := r.conn.NamedQuery(query, params)
 	if err != nil {
 		return 0, err
@@ -699,7 +846,12 @@ func scanIssue(rows *sqlx.Rows) (model.Issue, error) {
 		&issue.AssignedTo,
 		&issue.ClosedAt,
 		&issue.CloseBy,
-		&issue.Comment)
+		&issue.Comment,
+		&issue.AcknowledgedAt,
+		&issue.IncidentID,
+		&issue.FlapCount,
+		&issue.LastFlapAt,
+		&issue.PreviousIssueID)
 	if err != nil {
 		return model.Issue{}, err
 	}
@@ -778,7 +930,8 @@ func (r *PostgresRepository) SearchByName(name string, issueStates []string, opt
 		"i.id", "i.key", "i.name", "i.level", "i.situation_history_id",
 		"i.situation_id", "situation_instance_id", "i.situation_date",
 		"i.expiration_date", "i.rule_data", "i.state", "i.created_at", "i.last_modified",
-		"i.detection_rating_avg", "i.assigned_at", "i.assigned_to", "i.closed_at", "i.closed_by", "i.comment",
+		"i.detection_rating_avg", "i.assigned_at", "i.assigned_to", "i.closed_at", "i.closed_by", "i.comment", "i.acknowledged_at", "i.incident_id",
+		"i.flap_count", "i.last_flap_at", "i.previous_issue_id",
 		"COUNT(*) OVER() AS total_count",
 	).From("issues_v1 as i").
 		Where(sq.ILike{"i.name": "%" + name + "%"}).
@@ -845,6 +998,11 @@ func scanIssueWithTotal(rows *sqlx.Rows) (model.Issue, int, error) {
 		&issue.ClosedAt,
 		&issue.CloseBy,
 		&issue.Comment,
+		&issue.AcknowledgedAt,
+		&issue.IncidentID,
+		&issue.FlapCount,
+		&issue.LastFlapAt,
+		&issue.PreviousIssueID,
 		&total,
 	)
 	if err != nil {
@@ -865,3 +1023,79 @@ func scanIssueWithTotal(rows *sqlx.Rows) (model.Issue, int, error) {
 
 	return issue, total, nil
 }
+
+// Assign assigns an issue to a user, or unassigns it if assignee is nil, and records the change in the issue audit trail
+// The first assignment acknowledges the issue
+func (r *PostgresRepository) Assign(id int64, assignee *string, user users.User) (model.IssueAuditEntry, error) {
+	ts := time.Now().Truncate(1 * time.Millisecond).UTC()
+
+	tx, err := r.conn.Beginx()
+	if err != nil {
+		return model.IssueAuditEntry{}, err
+	}
+	defer tx.Rollback()
+
+	var previous *string
+	err = tx.QueryRowx(`SELECT assigned_to FROM issues_v1 WHERE id = $1 FOR UPDATE`, id).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return model.IssueAuditEntry{}, ErrIssueNotFound
+	}
+	if err != nil {
+		return model.IssueAuditEntry{}, err
+	}
+
+	action, err := model.IssueAssignmentAction(previous, assignee)
+	if err != nil {
+		return model.IssueAuditEntry{}, err
+	}
+
+	query := `UPDATE issues_v1 SET assigned_to = :assigned_to, assigned_at = :ts, acknowledged_at = COALESCE(acknowledged_at, :ts), last_modified = :ts WHERE id = :id`
+	if action == model.IssueAuditUnassign {
+		query = `UPDATE issues_v1 SET assigned_to = NULL, assigned_at = NULL, last_modified = :ts WHERE id = :id`
+	}
+	_, err = tx.NamedExec(query, map[string]interface{}{
+		"id":          id,
+		"assigned_to": assignee,
+		"ts":          ts,
+	})
+	if err != nil {
+		return model.IssueAuditEntry{}, errors.New("couldn't query the database:" + err.Error())
+	}
+
+	entry := model.IssueAuditEntry{IssueID: id, TS: ts, User: user.Login, Action: action, PreviousValue: previous, NewValue: assignee}
+	entry.ID, err = insertAuditEntry(tx, entry)
+	if err != nil {
+		return model.IssueAuditEntry{}, err
+	}
+
+	return entry, tx.Commit()
+}
+
+// GetAudit returns the audit trail of an issue, in chronological order
+func (r *PostgresRepository) GetAudit(id int64) ([]model.IssueAuditEntry, error) {
+	rows, err := r.conn.Queryx(`SELECT id, issue_id, ts, user_login, action, previous_value, new_value
+		FROM issue_audit_v1 WHERE issue_id = $1 ORDER BY ts, id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]model.IssueAuditEntry, 0)
+	for rows.Next() {
+		var entry model.IssueAuditEntry
+		err = rows.Scan(&entry.ID, &entry.IssueID, &entry.TS, &entry.User, &entry.Action, &entry.PreviousValue, &entry.NewValue)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func insertAuditEntry(q sqlx.Queryer, entry model.IssueAuditEntry) (int64, error) {
+	var id int64
+	err := q.QueryRowx(`INSERT INTO issue_audit_v1 (issue_id, ts, user_login, action, previous_value, new_value)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		entry.IssueID, entry.TS, entry.User, entry.Action, entry.PreviousValue, entry.NewValue).Scan(&id)
+	return id, err
+}
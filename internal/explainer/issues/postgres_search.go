@@ -0,0 +1,286 @@
+package issues
+
+import (
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/utils/queryutils"
+)
+
+// textSearchVector is the full-text document of an issue, it must match the expression of the idx_issues_v1_text_search index
+const textSearchVector = `to_tsvector('simple', i.name || ' ' || coalesce(i.comment, ''))`
+
+// issueFacetSelections are the key, name and joins of each issues search facet
+var issueFacetSelections = map[model.IssueSearchFacet]struct {
+	key  string
+	name string
+	join string
+}{
+	model.IssueFacetSituation: {
+		key:  "cast(i.situation_id as text)",
+		name: "coalesce(s.name, '')",
+		join: "LEFT JOIN situation_definition_v1 s ON s.id = i.situation_id",
+	},
+	model.IssueFacetTemplateInstance: {
+		key:  "cast(i.situation_instance_id as text)",
+		name: "coalesce(sti.name, '')",
+		join: "LEFT JOIN situation_template_instances_v1 sti ON sti.id = i.situation_instance_id",
+	},
+	model.IssueFacetTag: {
+		key:  "cast(t.id as text)",
+		name: "t.name",
+		join: `JOIN tags_v1 t ON (
+			exists (select 1 from tags_situations_v1 ts where ts.tag_id = t.id and ts.situation_id = i.situation_id)
+			or exists (select 1 from tags_situation_template_instances_v1 tsi where tsi.tag_id = t.id and tsi.situation_template_instance_id = i.situation_instance_id))`,
+	},
+	model.IssueFacetFunctionalSituation: {
+		key:  "cast(fs.id as text)",
+		name: "fs.name",
+		join: `JOIN functional_situation_v1 fs ON (
+			exists (select 1 from functional_situation_situations_v1 fss where fss.functional_situation_id = fs.id and fss.situation_id = i.situation_id)
+			or exists (select 1 from functional_situation_instances_v1 fsi where fsi.functional_situation_id = fs.id and fsi.template_instance_id = i.situation_instance_id))`,
+	},
+	model.IssueFacetLevel: {
+		key:  "i.level",
+		name: "i.level",
+	},
+	model.IssueFacetState: {
+		key:  "i.state",
+		name: "i.state",
+	},
+	model.IssueFacetAssignee: {
+		key:  "coalesce(i.assigned_to, '')",
+		name: "coalesce(i.assigned_to, '')",
+	},
+	model.IssueFacetDetectionRating: {
+		key:  "case when i.detection_rating_avg is null or i.detection_rating_avg < 0 then 'unrated' else cast(round(cast(i.detection_rating_avg as numeric)) as text) end",
+		name: "case when i.detection_rating_avg is null or i.detection_rating_avg < 0 then 'unrated' else cast(round(cast(i.detection_rating_avg as numeric)) as text) end",
+	},
+}
+
+// issueSearchFilters are the filters of an issues search
+type issueSearchFilters struct {
+	textMatch sq.Sqlizer                            // join on the issues matching the text, nil without text
+	common    []sq.Sqlizer                          // filters applied to all the facets
+	facets    map[model.IssueSearchFacet]sq.Sqlizer // filter of each facet dimension
+}
+
+// Search returns a page of the issues matching a full-text and faceted search, with the counts of the requested facets
+// The issues are restricted to a list of situations if situationIDs is not nil
+func (r *PostgresRepository) Search(query model.IssueSearchQuery, situationIDs []int64) (model.IssueSearchResult, error) {
+	filters := newIssueSearchFilters(query, situationIDs)
+
+	builder, err := issueSearchQuery(query, filters)
+	if err != nil {
+		return model.IssueSearchResult{}, err
+	}
+	statement, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return model.IssueSearchResult{}, err
+	}
+	rows, err := r.conn.Queryx(statement, args...)
+	if err != nil {
+		return model.IssueSearchResult{}, err
+	}
+	defer rows.Close()
+
+	result := model.IssueSearchResult{
+		Items:  make([]model.Issue, 0),
+		Facets: make(map[model.IssueSearchFacet][]model.IssueFacetValue),
+	}
+	for rows.Next() {
+		issue, total, err := scanIssueWithTotal(rows)
+		if err != nil {
+			return model.IssueSearchResult{}, err
+		}
+		result.Items = append(result.Items, issue)
+		result.Total = total
+	}
+	if err = rows.Err(); err != nil {
+		return model.IssueSearchResult{}, err
+	}
+
+	for _, facet := range query.Facets {
+		if _, exists := result.Facets[facet]; exists {
+			continue
+		}
+		values, err := r.searchFacet(facet, filters, query.FacetLimit())
+		if err != nil {
+			return model.IssueSearchResult{}, err
+		}
+		result.Facets[facet] = values
+	}
+	return result, nil
+}
+
+// issueSearchQuery returns the query of a page of the issues matching a search
+func issueSearchQuery(query model.IssueSearchQuery, filters issueSearchFilters) (sq.SelectBuilder, error) {
+	builder := sq.Select(
+		"i.id", "i.key", "i.name", "i.level", "i.situation_history_id",
+		"i.situation_id", "situation_instance_id", "i.situation_date",
+		"i.expiration_date", "i.rule_data", "i.state", "i.created_at", "i.last_modified",
+		"i.detection_rating_avg", "i.assigned_at", "i.assigned_to", "i.closed_at", "i.closed_by", "i.comment", "i.acknowledged_at", "i.incident_id",
+		"i.flap_count", "i.last_flap_at", "i.previous_issue_id",
+		"COUNT(*) OVER() AS total_count",
+	).From("issues_v1 as i")
+	if filters.textMatch != nil {
+		builder = builder.JoinClause(filters.textMatch)
+	}
+	for _, filter := range filters.common {
+		builder = builder.Where(filter)
+	}
+	for _, facet := range model.IssueSearchFacets {
+		if filter, ok := filters.facets[facet]; ok {
+			builder = builder.Where(filter)
+		}
+	}
+
+	options := query.SearchOptions()
+	if len(options.SortBy) == 0 {
+		if query.Text != "" {
+			builder = builder.OrderByClause("ts_rank("+textSearchVector+", websearch_to_tsquery('simple', ?)) DESC", query.Text)
+		}
+		options.SortBy = []model.SortOption{{Field: "created_at", Order: model.Desc}}
+	}
+	return queryutils.AppendSearchOptionsToBuilder(builder, options, "i")
+}
+
+// searchFacet counts the issues matching a search by value of a facet, ignoring the filter of the facet itself
+func (r *PostgresRepository) searchFacet(facet model.IssueSearchFacet, filters issueSearchFilters, size int) ([]model.IssueFacetValue, error) {
+	builder, err := issueFacetQuery(facet, filters, size)
+	if err != nil {
+		return nil, err
+	}
+	statement, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.conn.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]model.IssueFacetValue, 0)
+	for rows.Next() {
+		var value model.IssueFacetValue
+		if err = rows.Scan(&value.Key, &value.Name, &value.Count); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// issueFacetQuery returns the query counting the issues matching a search by value of a facet,
+// with all the filters of the search but the one of the facet dimension
+func issueFacetQuery(facet model.IssueSearchFacet, filters issueSearchFilters, size int) (sq.SelectBuilder, error) {
+	selection, ok := issueFacetSelections[facet]
+	if !ok {
+		return sq.SelectBuilder{}, fmt.Errorf("invalid facet '%s'", facet)
+	}
+
+	builder := sq.Select(selection.key+" AS facet_key", selection.name+" AS facet_name", "COUNT(DISTINCT i.id) AS facet_count").
+		From("issues_v1 as i")
+	if filters.textMatch != nil {
+		builder = builder.JoinClause(filters.textMatch)
+	}
+	if selection.join != "" {
+		builder = builder.JoinClause(selection.join)
+	}
+	for _, filter := range filters.common {
+		builder = builder.Where(filter)
+	}
+	for _, f := range model.IssueSearchFacets {
+		if filter, ok := filters.facets[f]; ok && f != facet {
+			builder = builder.Where(filter)
+		}
+	}
+	return builder.GroupBy("facet_key", "facet_name").
+		OrderBy("facet_count DESC", "facet_key").
+		Limit(uint64(size)), nil
+}
+
+// issueTextMatch joins the issues to the union of the ids of the issues matching a text on their name and comment,
+// on their comment threads and on the rootcause or action labels of their resolution
+// Each source is matched with its own full-text index (idx_issues_v1_text_search, idx_issue_comment_v1_text_search,
+// idx_ref_rootcause_v1_text_search and idx_ref_action_v1_text_search)
+func issueTextMatch(text string) sq.Sqlizer {
+	return sq.Expr(`JOIN (
+			SELECT i.id AS issue_id FROM issues_v1 i
+			WHERE `+textSearchVector+` @@ websearch_to_tsquery('simple', ?)
+			UNION
+			SELECT c.issue_id FROM issue_comment_v1 c
+			WHERE to_tsvector('simple', c.content) @@ websearch_to_tsquery('simple', ?)
+			UNION
+			SELECT ir.issue_id FROM issue_resolution_v1 ir
+			WHERE ir.rootcause_id IN (SELECT rc.id FROM ref_rootcause_v1 rc WHERE to_tsvector('simple', rc.name) @@ websearch_to_tsquery('simple', ?))
+			OR ir.action_id IN (SELECT a.id FROM ref_action_v1 a WHERE to_tsvector('simple', a.name) @@ websearch_to_tsquery('simple', ?))
+		) text_match ON text_match.issue_id = i.id`,
+		text, text, text, text)
+}
+
+// newIssueSearchFilters returns the filters of an issues search
+func newIssueSearchFilters(query model.IssueSearchQuery, situationIDs []int64) issueSearchFilters {
+	filters := issueSearchFilters{
+		common: make([]sq.Sqlizer, 0),
+		facets: make(map[model.IssueSearchFacet]sq.Sqlizer),
+	}
+	if query.Text != "" {
+		filters.textMatch = issueTextMatch(query.Text)
+	}
+	if situationIDs != nil {
+		filters.common = append(filters.common, sq.Expr("i.situation_id = ANY(?)", pq.Array(situationIDs)))
+	}
+	if query.CreatedFrom != nil {
+		filters.common = append(filters.common, sq.GtOrEq{"i.created_at": *query.CreatedFrom})
+	}
+	if query.CreatedTo != nil {
+		filters.common = append(filters.common, sq.Lt{"i.created_at": *query.CreatedTo})
+	}
+	if query.ClosedFrom != nil {
+		filters.common = append(filters.common, sq.GtOrEq{"i.closed_at": *query.ClosedFrom})
+	}
+	if query.ClosedTo != nil {
+		filters.common = append(filters.common, sq.Lt{"i.closed_at": *query.ClosedTo})
+	}
+
+	if len(query.SituationIDs) > 0 {
+		filters.facets[model.IssueFacetSituation] = sq.Expr("i.situation_id = ANY(?)", pq.Array(query.SituationIDs))
+	}
+	if len(query.TemplateInstanceIDs) > 0 {
+		filters.facets[model.IssueFacetTemplateInstance] = sq.Expr("i.situation_instance_id = ANY(?)", pq.Array(query.TemplateInstanceIDs))
+	}
+	if len(query.TagIDs) > 0 {
+		filters.facets[model.IssueFacetTag] = sq.Expr(`(exists (select 1 from tags_situations_v1 ts where ts.situation_id = i.situation_id and ts.tag_id = ANY(?))
+			or exists (select 1 from tags_situation_template_instances_v1 tsi where tsi.situation_template_instance_id = i.situation_instance_id and tsi.tag_id = ANY(?)))`,
+			pq.Array(query.TagIDs), pq.Array(query.TagIDs))
+	}
+	if len(query.FunctionalSituationIDs) > 0 {
+		filters.facets[model.IssueFacetFunctionalSituation] = sq.Expr(`(exists (select 1 from functional_situation_situations_v1 fss where fss.situation_id = i.situation_id and fss.functional_situation_id = ANY(?))
+			or exists (select 1 from functional_situation_instances_v1 fsi where fsi.template_instance_id = i.situation_instance_id and fsi.functional_situation_id = ANY(?)))`,
+			pq.Array(query.FunctionalSituationIDs), pq.Array(query.FunctionalSituationIDs))
+	}
+	if len(query.Levels) > 0 {
+		filters.facets[model.IssueFacetLevel] = sq.Expr("i.level = ANY(?)", pq.Array(query.Levels))
+	}
+	if len(query.States) > 0 {
+		filters.facets[model.IssueFacetState] = sq.Expr("i.state = ANY(?)", pq.Array(query.States))
+	}
+	if len(query.AssignedTo) > 0 {
+		filters.facets[model.IssueFacetAssignee] = sq.Expr("coalesce(i.assigned_to, '') = ANY(?)", pq.Array(query.AssignedTo))
+	}
+	if query.MinDetectionRating != nil || query.MaxDetectionRating != nil {
+		rating := sq.And{}
+		if query.MinDetectionRating != nil {
+			rating = append(rating, sq.GtOrEq{"i.detection_rating_avg": *query.MinDetectionRating})
+		}
+		if query.MaxDetectionRating != nil {
+			rating = append(rating, sq.LtOrEq{"i.detection_rating_avg": *query.MaxDetectionRating})
+		}
+		filters.facets[model.IssueFacetDetectionRating] = rating
+	}
+	return filters
+}
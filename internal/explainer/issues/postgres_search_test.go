@@ -0,0 +1,90 @@
+package issues
+
+import (
+	"strings"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+func TestIssueFacetQueryExcludesItsOwnFilter(t *testing.T) {
+	minRating, maxRating := 1.0, 4.0
+	query := model.IssueSearchQuery{
+		Text:                   "disk full",
+		SituationIDs:           []int64{1},
+		TemplateInstanceIDs:    []int64{2},
+		TagIDs:                 []int64{3},
+		FunctionalSituationIDs: []int64{4},
+		Levels:                 []string{"critical"},
+		States:                 []string{"open"},
+		AssignedTo:             []string{"john"},
+		MinDetectionRating:     &minRating,
+		MaxDetectionRating:     &maxRating,
+	}
+	filters := newIssueSearchFilters(query, nil)
+
+	// markers identify the filter of each facet dimension in a query
+	markers := map[model.IssueSearchFacet]string{
+		model.IssueFacetSituation:           "i.situation_id = ANY(",
+		model.IssueFacetTemplateInstance:    "i.situation_instance_id = ANY(",
+		model.IssueFacetTag:                 "ts.tag_id = ANY(",
+		model.IssueFacetFunctionalSituation: "fss.functional_situation_id = ANY(",
+		model.IssueFacetLevel:               "i.level = ANY(",
+		model.IssueFacetState:               "i.state = ANY(",
+		model.IssueFacetAssignee:            "coalesce(i.assigned_to, '') = ANY(",
+		model.IssueFacetDetectionRating:     "i.detection_rating_avg >=",
+	}
+	if len(markers) != len(model.IssueSearchFacets) {
+		t.Fatalf("expected a marker for each facet")
+	}
+
+	for _, facet := range model.IssueSearchFacets {
+		statement, _, err := mustFacetQuery(t, facet, filters).PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Count(statement, "text_match ON text_match.issue_id = i.id") != 1 {
+			t.Errorf("facet %s: expected the text match to be joined once, got %s", facet, statement)
+		}
+		for f, marker := range markers {
+			contains := strings.Contains(statement, marker)
+			if f == facet && contains {
+				t.Errorf("facet %s: expected its own filter to be excluded, got %s", facet, statement)
+			}
+			if f != facet && !contains {
+				t.Errorf("facet %s: expected the filter of %s, got %s", facet, f, statement)
+			}
+		}
+	}
+}
+
+func TestIssueSearchQueryTextMatch(t *testing.T) {
+	query := model.IssueSearchQuery{Text: "disk full", Limit: 5000}
+	builder, err := issueSearchQuery(query, newIssueSearchFilters(query, []int64{1, 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	statement, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(statement, "UNION") != 2 || strings.Contains(statement, "exists (select 1 from issue_comment_v1") {
+		t.Errorf("expected the text to be matched on the union of the sources, got %s", statement)
+	}
+	if !strings.Contains(statement, "LIMIT 1000") {
+		t.Errorf("expected a capped limit, got %s", statement)
+	}
+	// 4 text sources, the situations restriction and the relevance
+	if len(args) != 6 {
+		t.Errorf("unexpected arguments %v", args)
+	}
+}
+
+func mustFacetQuery(t *testing.T, facet model.IssueSearchFacet, filters issueSearchFilters) sq.SelectBuilder {
+	builder, err := issueFacetQuery(facet, filters, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return builder
+}
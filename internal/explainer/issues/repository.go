@@ -23,20 +23,27 @@ type Repository interface {
 	GetAllBySituationIDs(situationIDs []int64) (map[int64]model.Issue, error)
 	GetByStates(issueStates []string) (map[int64]model.Issue, error)
 	GetByStatesBySituationIDs(issueStates []string, situationIDs []int64) (map[int64]model.Issue, error)
-	GetByStateByPage(issuesStates []string, options model.SearchOptions) ([]model.Issue, int, error)
-	GetByStateByPageBySituationIDs(issuesStates []string, options model.SearchOptions, situationIDs []int64) ([]model.Issue, int, error)
+	GetByStateByPage(issuesStates []string, options model.SearchOptions, assignedTo string) ([]model.Issue, int, error)
+	GetByStateByPageBySituationIDs(issuesStates []string, options model.SearchOptions, situationIDs []int64, assignedTo string) ([]model.Issue, int, error)
 	GetByKeyByPage(key string, options model.SearchOptions) ([]model.Issue, int, error)
 
 	GetCloseToTimeoutByKey(key string, firstSituationTS time.Time) (map[int64]model.Issue, error)
 	GetOpenAndDraftIssuesByKey(key string) (map[int64]model.Issue, error)
+	GetLastClosedByKey(key string, closedSince time.Time) (model.Issue, bool, error)
+	Reopen(id int64, detection model.Issue, toState model.IssueState) (model.Issue, bool, error)
 
-	ChangeState(key string, fromStates []model.IssueState, toState model.IssueState) error
-	ChangeStateBetweenDates(key string, fromStates []model.IssueState, toState model.IssueState, from time.Time, to time.Time) error
+	ChangeState(key string, fromStates []model.IssueState, toState model.IssueState) ([]model.Issue, error)
+	ChangeStateBetweenDates(key string, fromStates []model.IssueState, toState model.IssueState, from time.Time, to time.Time) ([]model.Issue, error)
+	CloseExpired(before time.Time) ([]model.Issue, error)
 
 	DeleteOldIssues(ts time.Time) error
 	DeleteOldIssueDetections(ts time.Time) error
 	DeleteOldIssueResolutions(ts time.Time) error
 	SearchByName(name string, issueStates []string, options model.SearchOptions) ([]model.Issue, int, error)
+	Search(query model.IssueSearchQuery, situationIDs []int64) (model.IssueSearchResult, error)
+
+	Assign(id int64, assignee *string, user users.User) (model.IssueAuditEntry, error)
+	GetAudit(id int64) ([]model.IssueAuditEntry, error)
 }
 
 var (
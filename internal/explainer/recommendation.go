@@ -4,11 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/action"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/draft"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/rootcause"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/fact"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/history"
+	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
 // GetRecommendationTree build a recommendation tree based on issue resolution stats table
@@ -17,7 +23,7 @@ func GetRecommendationTree(issue model.Issue) (*model.FrontRecommendation, error
 	var err error
 	switch {
 	case issue.State == model.Open:
-		recommendation, err = buildRecommendationTree(issue.SituationID, issue.Rule.RuleID)
+		recommendation, err = buildIssueRecommendationTree(issue)
 		if err != nil {
 			return nil, err
 		}
@@ -107,6 +113,120 @@ func buildRecommendationTree(situationID int64, ruleID int64) (*model.FrontRecom
 	return &model.FrontRecommendation{Tree: tree}, nil
 }
 
+// buildIssueRecommendationTree builds the recommendation tree of an issue, ranked with the past resolutions of its situation
+// The fact values of the issue are compared with the ones of the past issues to find the most similar ones
+func buildIssueRecommendationTree(issue model.Issue) (*model.FrontRecommendation, error) {
+	recommendation, err := buildRecommendationTree(issue.SituationID, issue.Rule.RuleID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolutions, historyIDs, err := GetPastResolutions(issue.SituationID, issue.ID, viper.GetInt("RECOMMENDATION_HISTORY_LIMIT"))
+	if err != nil {
+		return nil, fmt.Errorf("GetPastResolutions(): %s", err.Error())
+	}
+	if len(resolutions) == 0 {
+		return recommendation, nil
+	}
+
+	historySituationIDs := make([]int64, 0, len(historyIDs)+1)
+	if issue.SituationHistoryID != 0 {
+		historySituationIDs = append(historySituationIDs, issue.SituationHistoryID)
+	}
+	for _, historyID := range historyIDs {
+		historySituationIDs = append(historySituationIDs, historyID)
+	}
+
+	// The fact values are only used to find the similar issues, the ranking falls back on the past resolutions without them
+	factValues, err := getHistoryFactValues(historySituationIDs)
+	if err != nil {
+		zap.L().Warn("Get history fact values", zap.Int64("issueID", issue.ID), zap.Error(err))
+		factValues = make(map[int64]map[int64]float64)
+	}
+	for i, resolution := range resolutions {
+		if historyID, ok := historyIDs[resolution.IssueID]; ok {
+			resolutions[i].Facts = factValues[historyID]
+		}
+	}
+
+	ranking := model.RankResolutions(factValues[issue.SituationHistoryID], resolutions, time.Now(), model.RankingOptions{
+		HalfLife:   viper.GetDuration("RECOMMENDATION_RECENCY_HALF_LIFE"),
+		Neighbours: viper.GetInt("RECOMMENDATION_NEIGHBOURS"),
+	})
+	rankRecommendationTree(recommendation, ranking)
+
+	return recommendation, nil
+}
+
+// getHistoryFactValues returns the numeric fact values of some situation histories, by situation history ID and fact ID
+func getHistoryFactValues(historySituationIDs []int64) (map[int64]map[int64]float64, error) {
+	values := make(map[int64]map[int64]float64)
+	if len(historySituationIDs) == 0 {
+		return values, nil
+	}
+
+	historyFacts, historySituationFacts, err := history.S().GetHistoryFactsFromSituationIds(historySituationIDs)
+	if err != nil {
+		return nil, err
+	}
+	historyFactsByID := make(map[int64]history.HistoryFactsV4, len(historyFacts))
+	for _, historyFact := range historyFacts {
+		historyFactsByID[historyFact.ID] = historyFact
+	}
+
+	definitions := make(map[int64]*engine.Fact)
+	for _, historySituationFact := range historySituationFacts {
+		historyFact, ok := historyFactsByID[historySituationFact.HistoryFactID]
+		if !ok {
+			continue
+		}
+
+		definition, ok := definitions[historyFact.FactID]
+		if !ok {
+			f, found, err := fact.R().Get(historyFact.FactID)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				definition = &f
+			}
+			definitions[historyFact.FactID] = definition
+		}
+		if definition == nil {
+			continue
+		}
+
+		singleValue, ok := extractFactValue(historyFact.Result, *definition).(*model.SingleValue)
+		if !ok {
+			continue
+		}
+		value, ok := factValueToFloat(singleValue.Value)
+		if !ok {
+			continue
+		}
+		if _, exists := values[historySituationFact.HistorySituationID]; !exists {
+			values[historySituationFact.HistorySituationID] = make(map[int64]float64)
+		}
+		values[historySituationFact.HistorySituationID][historyFact.FactID] = value
+	}
+	return values, nil
+}
+
+func factValueToFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
 func buildRootCauseTree(situationID int64, ruleID int64) ([]*model.FrontRootCause, error) {
 	rootCauseStats, err := GetRootCauseStats(situationID)
 	if err != nil {
@@ -189,3 +309,34 @@ func sortRecommendationTree(rootCauses []*model.FrontRootCause) {
 		})
 	}
 }
+
+// rankRecommendationTree sets the ranking scores and the similar issues on a recommendation tree, and sorts it by score
+func rankRecommendationTree(recommendation *model.FrontRecommendation, ranking model.RecommendationRanking) {
+	withNeighbours := len(ranking.SimilarIssues) > 0
+	for _, rootCause := range recommendation.Tree {
+		score, ok := ranking.RootCauses[rootCause.ID]
+		if !ok {
+			score = model.NoRecommendationScore(withNeighbours)
+		}
+		rootCause.Score = &score
+		rootCause.ClusteringScore = score.Similarity
+
+		for _, action := range rootCause.Actions {
+			score, ok := ranking.Actions[action.ID]
+			if !ok {
+				score = model.NoRecommendationScore(withNeighbours)
+			}
+			action.Score = &score
+		}
+
+		actions := rootCause.Actions
+		sort.SliceStable(actions, func(i, j int) bool {
+			return actions[i].Score.Score > actions[j].Score.Score
+		})
+	}
+	recommendation.SimilarIssues = ranking.SimilarIssues
+
+	sort.SliceStable(recommendation.Tree, func(i, j int) bool {
+		return recommendation.Tree[i].Score.Score > recommendation.Tree[j].Score.Score
+	})
+}
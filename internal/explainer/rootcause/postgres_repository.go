@@ -26,7 +26,7 @@ func NewPostgresRepository(dbClient *sqlx.DB) Repository {
 
 // Get use to retrieve an rootCause by id
 func (r *PostgresRepository) Get(id int64) (model.RootCause, bool, error) {
-	query := `SELECT name, description, situation_id, rule_id FROM ref_rootcause_v1 WHERE id = :id`
+	query := `SELECT name, description, situation_id, rule_id, catalog_id FROM ref_rootcause_v1 WHERE id = :id`
 	params := map[string]interface{}{
 		"id": id,
 	}
@@ -39,9 +39,10 @@ func (r *PostgresRepository) Get(id int64) (model.RootCause, bool, error) {
 
 	var name, description string
 	var situationID, ruleID int64
+	var catalogID sql.NullInt64
 
 	if rows.Next() {
-		err := rows.Scan(&name, &description, &situationID, &ruleID)
+		err := rows.Scan(&name, &description, &situationID, &ruleID, &catalogID)
 		if err != nil {
 			return model.RootCause{}, false, fmt.Errorf("Couldn't scan the action with id %d: %s", id, err.Error())
 		}
@@ -55,6 +56,7 @@ func (r *PostgresRepository) Get(id int64) (model.RootCause, bool, error) {
 		Description: description,
 		SituationID: situationID,
 		RuleID:      ruleID,
+		CatalogID:   nullableID(catalogID),
 	}, true, nil
 }
 
@@ -64,13 +66,14 @@ func (r *PostgresRepository) Create(tx *sqlx.Tx, rootCause model.RootCause) (int
 		return -1, errors.New("missing rootcause data")
 	}
 
-	query := `INSERT into ref_rootcause_v1 (id, name, description, situation_id, rule_id) 
-			 values (DEFAULT, :name, :description, :situation_id, :rule_id) RETURNING id`
+	query := `INSERT into ref_rootcause_v1 (id, name, description, situation_id, rule_id, catalog_id) 
+			 values (DEFAULT, :name, :description, :situation_id, :rule_id, :catalog_id) RETURNING id`
 	params := map[string]interface{}{
 		"name":         rootCause.Name,
 		"description":  rootCause.Description,
 		"situation_id": rootCause.SituationID,
 		"rule_id":      rootCause.RuleID,
+		"catalog_id":   rootCause.CatalogID,
 	}
 
 	var err error
@@ -161,7 +164,7 @@ func (r *PostgresRepository) Delete(tx *sqlx.Tx, id int64) error {
 func (r *PostgresRepository) GetAll() (map[int64]model.RootCause, error) {
 	rootCauses := make(map[int64]model.RootCause, 0)
 
-	query := `SELECT id, name, description, situation_id, rule_id FROM ref_rootcause_v1`
+	query := `SELECT id, name, description, situation_id, rule_id, catalog_id FROM ref_rootcause_v1`
 	rows, err := r.conn.Query(query)
 
 	if err != nil {
@@ -172,8 +175,9 @@ func (r *PostgresRepository) GetAll() (map[int64]model.RootCause, error) {
 	for rows.Next() {
 		var id, situationID, ruleID int64
 		var name, description string
+		var catalogID sql.NullInt64
 
-		err := rows.Scan(&id, &name, &description, &situationID, &ruleID)
+		err := rows.Scan(&id, &name, &description, &situationID, &ruleID, &catalogID)
 		if err != nil {
 			return nil, err
 		}
@@ -184,6 +188,7 @@ func (r *PostgresRepository) GetAll() (map[int64]model.RootCause, error) {
 			Description: description,
 			SituationID: situationID,
 			RuleID:      ruleID,
+			CatalogID:   nullableID(catalogID),
 		}
 
 		rootCauses[rootCause.ID] = rootCause
@@ -195,7 +200,7 @@ func (r *PostgresRepository) GetAll() (map[int64]model.RootCause, error) {
 func (r *PostgresRepository) GetAllBySituationID(situationID int64) (map[int64]model.RootCause, error) {
 	rootCauses := make(map[int64]model.RootCause, 0)
 
-	query := `SELECT id, name, description, rule_id FROM ref_rootcause_v1 where situation_id = :situation_id`
+	query := `SELECT id, name, description, rule_id, catalog_id FROM ref_rootcause_v1 where situation_id = :situation_id`
 	params := map[string]interface{}{
 		"situation_id": situationID,
 	}
@@ -209,8 +214,9 @@ func (r *PostgresRepository) GetAllBySituationID(situationID int64) (map[int64]m
 	for rows.Next() {
 		var id, ruleID int64
 		var name, description string
+		var catalogID sql.NullInt64
 
-		err := rows.Scan(&id, &name, &description, &ruleID)
+		err := rows.Scan(&id, &name, &description, &ruleID, &catalogID)
 		if err != nil {
 			return nil, err
 		}
@@ -221,6 +227,7 @@ func (r *PostgresRepository) GetAllBySituationID(situationID int64) (map[int64]m
 			Description: description,
 			SituationID: situationID,
 			RuleID:      ruleID,
+			CatalogID:   nullableID(catalogID),
 		}
 
 		rootCauses[rootCause.ID] = rootCause
@@ -232,7 +239,7 @@ func (r *PostgresRepository) GetAllBySituationID(situationID int64) (map[int64]m
 func (r *PostgresRepository) GetAllBySituationIDRuleID(situationID int64, ruleID int64) (map[int64]model.RootCause, error) {
 	rootCauses := make(map[int64]model.RootCause, 0)
 
-	query := `SELECT id, name, description FROM ref_rootcause_v1 where situation_id = :situation_id and rule_id = :rule_id`
+	query := `SELECT id, name, description, catalog_id FROM ref_rootcause_v1 where situation_id = :situation_id and rule_id = :rule_id`
 	params := map[string]interface{}{
 		"situation_id": situationID,
 		"rule_id":      ruleID,
@@ -247,8 +254,9 @@ func (r *PostgresRepository) GetAllBySituationIDRuleID(situationID int64, ruleID
 	for rows.Next() {
 		var id int64
 		var name, description string
+		var catalogID sql.NullInt64
 
-		err := rows.Scan(&id, &name, &description)
+		err := rows.Scan(&id, &name, &description, &catalogID)
 		if err != nil {
 			return nil, err
 		}
@@ -259,6 +267,7 @@ func (r *PostgresRepository) GetAllBySituationIDRuleID(situationID int64, ruleID
 			Description: description,
 			SituationID: situationID,
 			RuleID:      ruleID,
+			CatalogID:   nullableID(catalogID),
 		}
 
 		rootCauses[rootCause.ID] = rootCause
@@ -275,3 +284,10 @@ func checkValidity(rootCause model.RootCause) bool {
 	}
 	return true
 }
+
+func nullableID(id sql.NullInt64) *int64 {
+	if !id.Valid {
+		return nil
+	}
+	return &id.Int64
+}
@@ -0,0 +1,200 @@
+package explainer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/events"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/sla"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/metrics"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/users"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	_timeToAcknowledgeHistogram = _newRegisteredSLAHistogram("issue_time_to_acknowledge_seconds", "time between the creation and the first assignment of the issues")
+	_timeToResolveHistogram     = _newRegisteredSLAHistogram("issue_time_to_resolve_seconds", "time between the creation and the closing of the issues")
+	_                           = _newRegisteredSLACollector()
+)
+
+// slaBuckets ranges from 1 minute to 2 days
+var slaBuckets = []float64{60, 300, 900, 1800, 3600, 2 * 3600, 4 * 3600, 8 * 3600, 24 * 3600, 48 * 3600}
+
+func _newRegisteredSLAHistogram(name string, help string) *stdprometheus.HistogramVec {
+	histogram := stdprometheus.NewHistogramVec(stdprometheus.HistogramOpts{
+		Namespace:   metrics.MetricNamespace,
+		ConstLabels: metrics.MetricPrometheusLabels,
+		Name:        name,
+		Help:        help,
+		Buckets:     slaBuckets,
+	}, []string{"situation", "level"})
+
+	// Register metrics
+	stdprometheus.MustRegister(histogram)
+
+	return histogram
+}
+
+// EnrichIssuesSLA sets the SLA status of the issues which have an SLA target
+func EnrichIssuesSLA(issuesSlice []model.Issue) []model.Issue {
+	if sla.R() == nil || len(issuesSlice) == 0 {
+		return issuesSlice
+	}
+	slas, err := sla.R().GetAll()
+	if err != nil {
+		zap.L().Warn("Cannot get issue SLAs", zap.Error(err))
+		return issuesSlice
+	}
+
+	now := time.Now()
+	for i, issue := range issuesSlice {
+		if target, ok := model.MatchIssueSLA(slas, issue); ok {
+			status := target.Status(issue, now)
+			issuesSlice[i].SLA = &status
+		}
+	}
+	return issuesSlice
+}
+
+// observeIssueAcknowledged records the time to acknowledge of an issue on its first assignment
+func observeIssueAcknowledged(issue model.Issue, ts time.Time) {
+	if issue.AcknowledgedAt != nil {
+		return
+	}
+	_timeToAcknowledgeHistogram.WithLabelValues(strconv.FormatInt(issue.SituationID, 10), issue.Level.String()).
+		Observe(ts.Sub(issue.CreationTS).Seconds())
+}
+
+// observeIssueResolved records the time to resolve of an issue on its closing
+func observeIssueResolved(issue model.Issue, ts time.Time) {
+	_timeToResolveHistogram.WithLabelValues(strconv.FormatInt(issue.SituationID, 10), issue.Level.String()).
+		Observe(ts.Sub(issue.CreationTS).Seconds())
+}
+
+// ErrIssueClosed is returned when an issue cannot be assigned because it is already closed
+var ErrIssueClosed = errors.New("issue is already in a closed state")
+
+// AssignIssue assigns an issue to a user, or unassigns it if assignee is nil
+func AssignIssue(issue model.Issue, assignee *string, user users.User) (model.IssueAuditEntry, error) {
+	if issue.State.IsClosed() {
+		return model.IssueAuditEntry{}, fmt.Errorf("Issue with id %d: %w", issue.ID, ErrIssueClosed)
+	}
+	entry, err := issues.R().Assign(issue.ID, assignee, user)
+	if err != nil {
+		return model.IssueAuditEntry{}, err
+	}
+	if assignee != nil {
+		observeIssueAcknowledged(issue, entry.TS)
+	}
+
+	issue.AssignedTo = assignee
+	issue.AssignedAt = &entry.TS
+	event := model.NewIssueEvent(model.IssueEventAssigned, issue, user.Login)
+	event.AssignedTo = assignee
+	events.Publish(event)
+	return entry, nil
+}
+
+// slaBreachesTTL is the time during which the SLA breaches are reused by the scrapes
+const slaBreachesTTL = 1 * time.Minute
+
+// slaCollector exposes the number of open and draft issues breaching their SLA targets
+// The breaches are computed by the first scrape, and are reused by the following scrapes during slaBreachesTTL
+type slaCollector struct {
+	breaches *stdprometheus.Desc
+
+	mu         sync.Mutex
+	computedAt time.Time
+	metrics    []stdprometheus.Metric
+}
+
+func _newRegisteredSLACollector() *slaCollector {
+	collector := &slaCollector{
+		breaches: stdprometheus.NewDesc(
+			stdprometheus.BuildFQName(metrics.MetricNamespace, "", "issue_sla_breaches"),
+			"number of open and draft issues breaching their SLA target",
+			[]string{"situation", "level", "target"},
+			metrics.MetricPrometheusLabels,
+		),
+	}
+
+	// Register metrics
+	stdprometheus.MustRegister(collector)
+
+	return collector
+}
+
+// Describe implements prometheus.Collector
+func (c *slaCollector) Describe(ch chan<- *stdprometheus.Desc) {
+	ch <- c.breaches
+}
+
+// Collect implements prometheus.Collector
+func (c *slaCollector) Collect(ch chan<- stdprometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.computedAt.IsZero() || now.Sub(c.computedAt) >= slaBreachesTTL {
+		metrics, err := c.compute(now)
+		if err != nil {
+			zap.L().Warn("Cannot compute the SLA metrics", zap.Error(err))
+			return
+		}
+		c.metrics, c.computedAt = metrics, now
+	}
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+// compute returns the number of open and draft issues breaching their SLA targets at now
+func (c *slaCollector) compute(now time.Time) ([]stdprometheus.Metric, error) {
+	metrics := make([]stdprometheus.Metric, 0)
+	if issues.R() == nil || sla.R() == nil {
+		return metrics, nil
+	}
+	slas, err := sla.R().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(slas) == 0 {
+		return metrics, nil
+	}
+	openIssues, err := issues.R().GetByStates([]string{model.Open.String(), model.Draft.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	type breachKey struct {
+		situation string
+		level     string
+		target    string
+	}
+	breaches := make(map[breachKey]float64)
+	for _, issue := range openIssues {
+		target, ok := model.MatchIssueSLA(slas, issue)
+		if !ok {
+			continue
+		}
+		status := target.Status(issue, now)
+		situation := strconv.FormatInt(issue.SituationID, 10)
+		if status.AcknowledgeBreached {
+			breaches[breachKey{situation, issue.Level.String(), "acknowledge"}]++
+		}
+		if status.ResolveBreached {
+			breaches[breachKey{situation, issue.Level.String(), "resolve"}]++
+		}
+	}
+
+	for key, count := range breaches {
+		metrics = append(metrics, stdprometheus.MustNewConstMetric(c.breaches, stdprometheus.GaugeValue, count, key.situation, key.level, key.target))
+	}
+	return metrics, nil
+}
@@ -0,0 +1,135 @@
+package sla
+
+import (
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+const table = "issue_sla_v1"
+
+var slaColumns = []string{"id", "coalesce(situation_id, 0)", "level", "acknowledge_within", "resolve_within"}
+
+// PostgresRepository is a repository containing the issue SLA targets based on a PSQL database and
+// implementing the repository interface
+type PostgresRepository struct {
+	conn *sqlx.DB
+}
+
+// NewPostgresRepository returns a new instance of PostgresRepository
+func NewPostgresRepository(dbClient *sqlx.DB) Repository {
+	r := PostgresRepository{
+		conn: dbClient,
+	}
+	var repo Repository = &r
+	return repo
+}
+
+// Get returns an issue SLA by its ID
+func (r *PostgresRepository) Get(id int64) (model.IssueSLA, bool, error) {
+	slas, err := r.query(newStatement().Select(slaColumns...).From(table).Where(sq.Eq{"id": id}))
+	if err != nil {
+		return model.IssueSLA{}, false, err
+	}
+	if len(slas) == 0 {
+		return model.IssueSLA{}, false, nil
+	}
+	return slas[0], true, nil
+}
+
+// GetAll returns all the issue SLAs
+func (r *PostgresRepository) GetAll() ([]model.IssueSLA, error) {
+	return r.query(newStatement().Select(slaColumns...).From(table).OrderBy("id"))
+}
+
+func (r *PostgresRepository) query(statement sq.SelectBuilder) ([]model.IssueSLA, error) {
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slas := make([]model.IssueSLA, 0)
+	for rows.Next() {
+		var sla model.IssueSLA
+		var level string
+		if err = rows.Scan(&sla.ID, &sla.SituationID, &level, &sla.AcknowledgeWithin, &sla.ResolveWithin); err != nil {
+			return nil, err
+		}
+		sla.Level = model.ToIssueLevel(level)
+		slas = append(slas, sla)
+	}
+	return slas, rows.Err()
+}
+
+// Create creates a new issue SLA
+func (r *PostgresRepository) Create(sla model.IssueSLA) (int64, error) {
+	if _, err := sla.IsValid(); err != nil {
+		return -1, err
+	}
+
+	var id int64
+	err := newStatement().
+		Insert(table).
+		Columns("situation_id", "level", "acknowledge_within", "resolve_within").
+		Values(nullableID(sla.SituationID), sla.Level.String(), sla.AcknowledgeWithin, sla.ResolveWithin).
+		Suffix("RETURNING \"id\"").
+		RunWith(r.conn.DB).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// Update updates an issue SLA
+func (r *PostgresRepository) Update(sla model.IssueSLA) error {
+	if _, err := sla.IsValid(); err != nil {
+		return err
+	}
+
+	res, err := newStatement().
+		Update(table).
+		Set("situation_id", nullableID(sla.SituationID)).
+		Set("level", sla.Level.String()).
+		Set("acknowledge_within", sla.AcknowledgeWithin).
+		Set("resolve_within", sla.ResolveWithin).
+		Where(sq.Eq{"id": sla.ID}).
+		RunWith(r.conn.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// Delete deletes an issue SLA
+func (r *PostgresRepository) Delete(id int64) error {
+	res, err := newStatement().Delete(table).Where(sq.Eq{"id": id}).RunWith(r.conn.DB).Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func nullableID(id int64) interface{} {
+	if id <= 0 {
+		return nil
+	}
+	return id
+}
+
+func checkAffected(res sql.Result) error {
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i != 1 {
+		return errors.New("no row updated (or multiple row updated) instead of 1 row")
+	}
+	return nil
+}
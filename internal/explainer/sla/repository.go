@@ -0,0 +1,48 @@
+package sla
+
+import (
+	"sync"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+// Repository is a storage interface which can be implemented by multiple backend
+// (in-memory map, sql database, in-memory cache, file system, ...)
+// It allows standard CRUD operation on the issue SLA targets
+type Repository interface {
+	Get(id int64) (model.IssueSLA, bool, error)
+	GetAll() ([]model.IssueSLA, error)
+	Create(sla model.IssueSLA) (int64, error)
+	Update(sla model.IssueSLA) error
+	Delete(id int64) error
+}
+
+var (
+	_globalRepositoryMu sync.RWMutex
+	_globalRepository   Repository
+)
+
+// R is used to access the global repository singleton
+func R() Repository {
+	_globalRepositoryMu.RLock()
+	defer _globalRepositoryMu.RUnlock()
+
+	repository := _globalRepository
+	return repository
+}
+
+// ReplaceGlobals affect a new repository to the global repository singleton
+func ReplaceGlobals(repository Repository) func() {
+	_globalRepositoryMu.Lock()
+	defer _globalRepositoryMu.Unlock()
+
+	prev := _globalRepository
+	_globalRepository = repository
+	return func() { ReplaceGlobals(prev) }
+}
+
+// newStatement creates a new SQL statement builder with Dollar placeholder format
+func newStatement() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
@@ -0,0 +1,103 @@
+package explainer
+
+import (
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-sdk/v5/postgres"
+)
+
+// GetCatalogStats returns the usage statistics of the catalog rootcauses and actions, aggregated on all their linked situations
+// The statistics are restricted to the issues of a single situation if situationID is not 0
+func GetCatalogStats(situationID int64) ([]model.CatalogRootCauseStats, error) {
+	params := map[string]interface{}{
+		"situation_id": situationID,
+	}
+
+	issueCount, err := getResolvedIssueCount(params)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `select c.id, c.name, count(distinct i.id) as occurrences
+		from ref_catalog_rootcause_v1 c
+		left join ref_rootcause_v1 rc on rc.catalog_id = c.id
+		left join issue_resolution_v1 ir on ir.rootcause_id = rc.id
+		left join issues_v1 i on i.id = ir.issue_id and (:situation_id = 0 or i.situation_id = :situation_id)
+		group by c.id, c.name
+		order by occurrences desc, c.name`
+	rows, err := postgres.DB().NamedQuery(query, params)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]model.CatalogRootCauseStats, 0)
+	for rows.Next() {
+		stat := model.CatalogRootCauseStats{Actions: make([]model.CatalogActionStats, 0)}
+		if err = rows.Scan(&stat.ID, &stat.Name, &stat.Occurrences); err != nil {
+			return nil, err
+		}
+		stat.UsageRate = usageRate(stat.Occurrences, issueCount)
+		stats = append(stats, stat)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	actionsQuery := `select ca.id, ca.name, ca.catalog_rootcause_id, count(distinct i.id) as occurrences
+		from ref_catalog_action_v1 ca
+		left join ref_action_v1 a on a.catalog_id = ca.id
+		left join issue_resolution_v1 ir on ir.action_id = a.id
+		left join issues_v1 i on i.id = ir.issue_id and (:situation_id = 0 or i.situation_id = :situation_id)
+		group by ca.id, ca.name, ca.catalog_rootcause_id
+		order by occurrences desc, ca.name`
+	actionRows, err := postgres.DB().NamedQuery(actionsQuery, params)
+	if err != nil {
+		return nil, err
+	}
+	defer actionRows.Close()
+
+	indexes := make(map[int64]int, len(stats))
+	for i, stat := range stats {
+		indexes[stat.ID] = i
+	}
+	for actionRows.Next() {
+		var stat model.CatalogActionStats
+		var rootCauseID int64
+		if err = actionRows.Scan(&stat.ID, &stat.Name, &rootCauseID, &stat.Occurrences); err != nil {
+			return nil, err
+		}
+		stat.UsageRate = usageRate(stat.Occurrences, issueCount)
+		if i, ok := indexes[rootCauseID]; ok {
+			stats[i].Actions = append(stats[i].Actions, stat)
+		}
+	}
+	return stats, actionRows.Err()
+}
+
+// getResolvedIssueCount returns the number of issues with a resolution, on all the situations if situation_id is 0
+func getResolvedIssueCount(params map[string]interface{}) (int64, error) {
+	query := `select count(distinct ir.issue_id)
+		from issue_resolution_v1 ir
+		inner join issues_v1 i on i.id = ir.issue_id
+		where :situation_id = 0 or i.situation_id = :situation_id`
+	rows, err := postgres.DB().NamedQuery(query, params)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count int64
+	if rows.Next() {
+		if err = rows.Scan(&count); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+func usageRate(occurrences int64, issueCount int64) float64 {
+	if issueCount == 0 {
+		return 0
+	}
+	return float64(occurrences) / float64(issueCount)
+}
@@ -0,0 +1,129 @@
+package explainer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-sdk/v5/postgres"
+)
+
+// issueAnalyticsGroupQueries are the (issue, group key, group name) selections of each issue analytics grouping
+// The functional situations and tags are linked to the situations, or to the situation template instances
+var issueAnalyticsGroupQueries = map[model.IssueAnalyticsGroupBy]string{
+	model.IssueAnalyticsBySituation: `select i.id as issue_id, cast(i.situation_id as text) as group_key, coalesce(s.name, '') as group_name
+		from filtered_issues i
+		left join situation_definition_v1 s on s.id = i.situation_id`,
+	model.IssueAnalyticsByRule: `select i.id as issue_id, coalesce(i.rule_data->>'ruleId', '') as group_key, coalesce(r.name, '') as group_name
+		from filtered_issues i
+		left join rules_v1 r on cast(r.id as text) = i.rule_data->>'ruleId'`,
+	model.IssueAnalyticsByLevel: `select i.id as issue_id, i.level as group_key, i.level as group_name
+		from filtered_issues i`,
+	model.IssueAnalyticsByFunctionalSituation: `select distinct l.issue_id, cast(l.fs_id as text) as group_key, fs.name as group_name
+		from (
+			select i.id as issue_id, fss.functional_situation_id as fs_id
+			from filtered_issues i
+			inner join functional_situation_situations_v1 fss on fss.situation_id = i.situation_id
+			union
+			select i.id as issue_id, fsi.functional_situation_id as fs_id
+			from filtered_issues i
+			inner join functional_situation_instances_v1 fsi on fsi.template_instance_id = i.situation_instance_id
+		) l
+		inner join functional_situation_v1 fs on fs.id = l.fs_id`,
+	model.IssueAnalyticsByTag: `select distinct l.issue_id, cast(l.tag_id as text) as group_key, t.name as group_name
+		from (
+			select i.id as issue_id, ts.tag_id
+			from filtered_issues i
+			inner join tags_situations_v1 ts on ts.situation_id = i.situation_id
+			union
+			select i.id as issue_id, tsi.tag_id
+			from filtered_issues i
+			inner join tags_situation_template_instances_v1 tsi on tsi.situation_template_instance_id = i.situation_instance_id
+		) l
+		inner join tags_v1 t on t.id = l.tag_id`,
+}
+
+// GetIssueAnalytics returns the analytics of the issues created between from (included) and to (excluded), grouped by groupBy
+// The issues are restricted to a list of situations if situationIDs is not nil
+// Only the top rootcauses of each group are returned
+func GetIssueAnalytics(groupBy model.IssueAnalyticsGroupBy, from time.Time, to time.Time, situationIDs []int64, top int) (model.IssueAnalytics, error) {
+	groupQuery, ok := issueAnalyticsGroupQueries[groupBy]
+	if !ok {
+		return model.IssueAnalytics{}, fmt.Errorf("invalid group by '%s'", groupBy)
+	}
+
+	params := map[string]interface{}{
+		"from":          from,
+		"to":            to,
+		"restricted":    situationIDs != nil,
+		"situation_ids": pq.Array(situationIDs),
+	}
+	filteredIssues := `with filtered_issues as (
+			select * from issues_v1
+			where created_at >= :from and created_at < :to
+			and (not :restricted or situation_id = any(:situation_ids))
+		)
+		`
+
+	query := filteredIssues + `select i.id, g.group_key, g.group_name, i.state, i.created_at, i.acknowledged_at, i.closed_at, i.last_modified
+		from filtered_issues i
+		inner join (` + groupQuery + `) g on g.issue_id = i.id
+		order by i.id`
+	rows, err := postgres.DB().NamedQuery(query, params)
+	if err != nil {
+		return model.IssueAnalytics{}, err
+	}
+	defer rows.Close()
+
+	records := make([]model.IssueAnalyticsRecord, 0)
+	for rows.Next() {
+		var record model.IssueAnalyticsRecord
+		var state string
+		if err = rows.Scan(&record.IssueID, &record.GroupKey, &record.GroupName, &state, &record.CreationTS, &record.AcknowledgedAt, &record.ClosedAt,
+			&record.LastModificationTS); err != nil {
+			return model.IssueAnalytics{}, err
+		}
+		record.State = model.ToIssueState(state)
+		records = append(records, record)
+	}
+	if err = rows.Err(); err != nil {
+		return model.IssueAnalytics{}, err
+	}
+
+	rootCauses, err := getIssueAnalyticsRootCauses(filteredIssues, params)
+	if err != nil {
+		return model.IssueAnalytics{}, err
+	}
+
+	return model.IssueAnalytics{
+		GroupBy: groupBy,
+		From:    from,
+		To:      to,
+		Groups:  model.BuildIssueAnalyticsGroups(records, rootCauses, top),
+	}, nil
+}
+
+// getIssueAnalyticsRootCauses returns the distinct rootcause names of the resolution of each filtered issue
+func getIssueAnalyticsRootCauses(filteredIssues string, params map[string]interface{}) (map[int64][]string, error) {
+	query := filteredIssues + `select distinct ir.issue_id, rc.name
+		from issue_resolution_v1 ir
+		inner join filtered_issues i on i.id = ir.issue_id
+		inner join ref_rootcause_v1 rc on rc.id = ir.rootcause_id`
+	rows, err := postgres.DB().NamedQuery(query, params)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rootCauses := make(map[int64][]string)
+	for rows.Next() {
+		var issueID int64
+		var name string
+		if err = rows.Scan(&issueID, &name); err != nil {
+			return nil, err
+		}
+		rootCauses[issueID] = append(rootCauses[issueID], name)
+	}
+	return rootCauses, rows.Err()
+}
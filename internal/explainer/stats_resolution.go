@@ -0,0 +1,79 @@
+package explainer
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-sdk/v5/postgres"
+)
+
+// GetPastResolutions returns the feedbacks of the last resolved issues of a situation, the most recent first
+// The returned map links each resolution to the situation history of its issue, when it still exists
+func GetPastResolutions(situationID int64, excludedIssueID int64, limit int) ([]model.PastResolution, map[int64]int64, error) {
+	query := `with past as (
+			select i.id, i.name, i.situation_history_id, i.detection_rating_avg, max(ir.feedback_date) as resolved_at
+			from issues_v1 i
+			inner join issue_resolution_v1 ir on ir.issue_id = i.id
+			where i.situation_id = :situation_id and i.id <> :issue_id
+			group by i.id
+			order by resolved_at desc
+			limit :limit
+		)
+		select p.id, p.name, p.situation_history_id, p.detection_rating_avg, p.resolved_at,
+			ir.rootcause_id, rc.name, ir.action_id, a.name
+		from past p
+		inner join issue_resolution_v1 ir on ir.issue_id = p.id
+		inner join ref_rootcause_v1 rc on rc.id = ir.rootcause_id
+		inner join ref_action_v1 a on a.id = ir.action_id
+		order by p.resolved_at desc, p.id, ir.action_id`
+	params := map[string]interface{}{
+		"situation_id": situationID,
+		"issue_id":     excludedIssueID,
+		"limit":        limit,
+	}
+
+	rows, err := postgres.DB().NamedQuery(query, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	resolutions := make([]model.PastResolution, 0)
+	historyIDs := make(map[int64]int64)
+	for rows.Next() {
+		var issueID, rootCauseID, actionID int64
+		var issueName, rootCauseName, actionName string
+		var historyID sql.NullInt64
+		var rating sql.NullFloat64
+		var resolvedAt time.Time
+		err := rows.Scan(&issueID, &issueName, &historyID, &rating, &resolvedAt, &rootCauseID, &rootCauseName, &actionID, &actionName)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		action := model.SimilarIssueAction{ID: actionID, Name: actionName}
+		if n := len(resolutions); n > 0 && resolutions[n-1].IssueID == issueID {
+			resolutions[n-1].Actions = append(resolutions[n-1].Actions, action)
+			continue
+		}
+
+		resolution := model.PastResolution{
+			IssueID:         issueID,
+			IssueName:       issueName,
+			ResolvedAt:      resolvedAt,
+			DetectionRating: -1,
+			RootCauseID:     rootCauseID,
+			RootCauseName:   rootCauseName,
+			Actions:         []model.SimilarIssueAction{action},
+		}
+		if rating.Valid {
+			resolution.DetectionRating = rating.Float64
+		}
+		if historyID.Valid {
+			historyIDs[issueID] = historyID.Int64
+		}
+		resolutions = append(resolutions, resolution)
+	}
+	return resolutions, historyIDs, nil
+}
@@ -0,0 +1,101 @@
+package explainer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/events"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"go.uber.org/zap"
+)
+
+// CloseExpiredIssues closes as timed out the open issues which have expired, and publishes their closed events
+func CloseExpiredIssues(now time.Time) (int, error) {
+	closed, err := issues.R().CloseExpired(now)
+	if err != nil {
+		return 0, err
+	}
+	for _, issue := range closed {
+		event := model.NewIssueEvent(model.IssueEventClosed, issue, "")
+		event.State = model.ClosedTimeout.String()
+		events.Publish(event)
+	}
+	return len(closed), nil
+}
+
+// IssueTimeouts periodically closes the expired issues
+type IssueTimeouts struct {
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewIssueTimeouts returns a new issue timeouts checker, running every interval
+func NewIssueTimeouts(interval time.Duration) *IssueTimeouts {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &IssueTimeouts{
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start starts the periodic check in a goroutine, it is disabled if the interval is not positive
+func (t *IssueTimeouts) Start() {
+	if t.interval <= 0 {
+		zap.L().Info("Issue timeouts check disabled")
+		return
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				closed, err := CloseExpiredIssues(time.Now().Truncate(1 * time.Millisecond).UTC())
+				if err != nil {
+					zap.L().Error("Cannot close the expired issues", zap.Error(err))
+				} else if closed > 0 {
+					zap.L().Info("Expired issues closed", zap.Int("count", closed))
+				}
+			case <-t.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic check and waits for the running one to finish
+func (t *IssueTimeouts) Stop() {
+	t.cancel()
+	t.wg.Wait()
+}
+
+var (
+	_globalIssueTimeoutsMu sync.RWMutex
+	_globalIssueTimeouts   *IssueTimeouts
+)
+
+// IT is used to access the global issue timeouts checker singleton
+func IT() *IssueTimeouts {
+	_globalIssueTimeoutsMu.RLock()
+	defer _globalIssueTimeoutsMu.RUnlock()
+
+	timeouts := _globalIssueTimeouts
+	return timeouts
+}
+
+// ReplaceGlobalIssueTimeouts affect a new issue timeouts checker to the global singleton
+func ReplaceGlobalIssueTimeouts(timeouts *IssueTimeouts) func() {
+	_globalIssueTimeoutsMu.Lock()
+	defer _globalIssueTimeoutsMu.Unlock()
+
+	prev := _globalIssueTimeouts
+	_globalIssueTimeouts = timeouts
+	return func() { ReplaceGlobalIssueTimeouts(prev) }
+}
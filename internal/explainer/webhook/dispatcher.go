@@ -0,0 +1,364 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"go.uber.org/zap"
+)
+
+// Headers of a webhook delivery
+const (
+	HeaderEvent     = "X-Myrtea-Event"
+	HeaderDelivery  = "X-Myrtea-Delivery"
+	HeaderSignature = "X-Myrtea-Signature"
+)
+
+// ErrPrivateHost is returned when a webhook URL targets a loopback, private or link-local address
+var ErrPrivateHost = errors.New("the webhook host must be a public address")
+
+// Dispatcher delivers the issue events to the enabled webhooks subscribed to them
+// The events are persisted in the deliveries outbox on their publication, then each webhook has its own worker
+// sending its deliveries in order: a delivery is only sent once the previous one has been delivered or has failed
+type Dispatcher struct {
+	client            *http.Client
+	retries           int
+	backoff           time.Duration
+	pollInterval      time.Duration
+	allowPrivateHosts bool
+
+	mu       sync.Mutex
+	webhooks []model.IssueWebhook // cached list of the webhooks, nil until loaded
+	workers  map[int64]chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher returns a new webhook dispatcher, a failed delivery is retried with an exponential backoff
+// The webhooks targeting a loopback, private or link-local address are rejected, unless allowPrivateHosts is set
+func NewDispatcher(timeout time.Duration, retries int, allowPrivateHosts bool) *Dispatcher {
+	if retries < 0 {
+		retries = 0
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		retries:           retries,
+		backoff:           1 * time.Second,
+		pollInterval:      1 * time.Minute,
+		allowPrivateHosts: allowPrivateHosts,
+		workers:           make(map[int64]chan struct{}),
+		ctx:               ctx,
+		cancel:            cancel,
+	}
+
+	// The resolved address is checked on each connection, so that a public host name can't be rebound to a private address
+	dialer := &net.Dialer{Timeout: timeout, Control: d.controlAddress}
+	d.client = &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+	return d
+}
+
+// Start starts the workers of the webhooks, resuming the deliveries pending in the outbox
+func (d *Dispatcher) Start() {
+	webhooks, err := d.Webhooks()
+	if err != nil {
+		zap.L().Error("Cannot get issue webhooks", zap.Error(err))
+		return
+	}
+	for _, webhook := range webhooks {
+		d.wake(webhook.ID)
+	}
+}
+
+// Stop stops the workers, and waits for their current delivery attempts
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Webhooks returns the cached list of the webhooks, loading it if needed
+func (d *Dispatcher) Webhooks() ([]model.IssueWebhook, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.webhooks == nil {
+		webhooks, err := R().GetAll()
+		if err != nil {
+			return nil, err
+		}
+		d.webhooks = webhooks
+	}
+	return d.webhooks, nil
+}
+
+// Invalidate clears the cached list of the webhooks, it must be called once a webhook has been changed
+func (d *Dispatcher) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.webhooks = nil
+}
+
+// Enqueue is the synchronous issue event bus handler of the webhooks
+// It persists a delivery of the event in the outbox for each webhook subscribed to it, and wakes their workers up
+func (d *Dispatcher) Enqueue(event model.IssueEvent) {
+	if R() == nil {
+		return
+	}
+	webhooks, err := d.Webhooks()
+	if err != nil {
+		zap.L().Error("Cannot get issue webhooks", zap.Error(err))
+		return
+	}
+
+	deliveries := make([]Delivery, 0)
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(event) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				zap.L().Error("Cannot marshal issue event", zap.Error(err))
+				return
+			}
+		}
+		deliveries = append(deliveries, Delivery{WebhookID: webhook.ID, EventID: event.ID, EventType: event.Type, Payload: payload})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if err = R().CreateDeliveries(deliveries); err != nil {
+		zap.L().Error("Cannot persist the issue webhook deliveries", zap.String("eventID", event.ID), zap.Error(err))
+		return
+	}
+	for _, delivery := range deliveries {
+		d.wake(delivery.WebhookID)
+	}
+}
+
+// wake wakes the worker of a webhook up, starting it if needed
+func (d *Dispatcher) wake(webhookID int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.ctx.Err() != nil {
+		return
+	}
+	wakeup, ok := d.workers[webhookID]
+	if !ok {
+		wakeup = make(chan struct{}, 1)
+		d.workers[webhookID] = wakeup
+		d.wg.Add(1)
+		go d.work(webhookID, wakeup)
+	}
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) work(webhookID int64, wakeup chan struct{}) {
+	defer d.wg.Done()
+	for {
+		timer := time.NewTimer(d.deliverPending(webhookID))
+		select {
+		case <-wakeup:
+		case <-timer.C:
+		case <-d.ctx.Done():
+			timer.Stop()
+			return
+		}
+		timer.Stop()
+	}
+}
+
+// deliverPending sends the pending deliveries of a webhook in order, and returns the time to wait before the next attempt
+// The deliveries of a disabled webhook are kept pending until it is enabled again
+func (d *Dispatcher) deliverPending(webhookID int64) time.Duration {
+	for d.ctx.Err() == nil {
+		webhook, found := d.webhook(webhookID)
+		if !found || !webhook.Enabled {
+			return d.pollInterval
+		}
+
+		delivery, found, err := R().NextDelivery(webhookID)
+		if err != nil {
+			zap.L().Error("Cannot get the next issue webhook delivery", zap.Int64("webhookID", webhookID), zap.Error(err))
+			return d.pollInterval
+		}
+		if !found {
+			return d.pollInterval
+		}
+		if wait := time.Until(delivery.NextAttemptTS); wait > 0 {
+			return wait
+		}
+
+		err = d.post(webhook, delivery)
+		if err == nil {
+			if err = R().DeleteDelivery(delivery.ID); err != nil {
+				zap.L().Error("Cannot delete the issue webhook delivery", zap.Int64("deliveryID", delivery.ID), zap.Error(err))
+				return d.pollInterval
+			}
+			continue
+		}
+
+		delivery.Attempts++
+		delivery.LastError = err.Error()
+		if delivery.Attempts > d.retries {
+			delivery.State = DeliveryFailed
+			zap.L().Warn("Issue webhook delivery failed", zap.Int64("webhookID", webhookID), zap.String("eventID", delivery.EventID),
+				zap.String("type", string(delivery.EventType)), zap.Error(err))
+		} else {
+			delivery.NextAttemptTS = time.Now().Add(d.backoff * time.Duration(1<<(delivery.Attempts-1)))
+		}
+		if err = R().UpdateDelivery(delivery); err != nil {
+			zap.L().Error("Cannot update the issue webhook delivery", zap.Int64("deliveryID", delivery.ID), zap.Error(err))
+			return d.pollInterval
+		}
+	}
+	return d.pollInterval
+}
+
+func (d *Dispatcher) webhook(id int64) (model.IssueWebhook, bool) {
+	webhooks, err := d.Webhooks()
+	if err != nil {
+		zap.L().Error("Cannot get issue webhooks", zap.Error(err))
+		return model.IssueWebhook{}, false
+	}
+	for _, webhook := range webhooks {
+		if webhook.ID == id {
+			return webhook, true
+		}
+	}
+	return model.IssueWebhook{}, false
+}
+
+func (d *Dispatcher) post(webhook model.IssueWebhook, delivery Delivery) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderDelivery, delivery.EventID)
+	if webhook.Secret != "" {
+		req.Header.Set(HeaderSignature, Signature(webhook.Secret, delivery.Payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// CheckURL checks that the host of a webhook URL resolves to public addresses only
+func (d *Dispatcher) CheckURL(rawURL string) error {
+	if d.allowPrivateHosts {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(context.Background(), u.Hostname())
+	if err != nil {
+		return fmt.Errorf("cannot resolve the webhook host: %w", err)
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip.IP) {
+			return ErrPrivateHost
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) controlAddress(network string, address string, _ syscall.RawConn) error {
+	if d.allowPrivateHosts {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return ErrPrivateHost
+	}
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast())
+}
+
+// Signature returns the HMAC-SHA256 signature of a payload, as sent in the X-Myrtea-Signature header
+func Signature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var (
+	_globalDispatcherMu sync.RWMutex
+	_globalDispatcher   *Dispatcher
+)
+
+// D is used to access the global webhook dispatcher singleton
+func D() *Dispatcher {
+	_globalDispatcherMu.RLock()
+	defer _globalDispatcherMu.RUnlock()
+
+	dispatcher := _globalDispatcher
+	return dispatcher
+}
+
+// ReplaceGlobalDispatcher affect a new dispatcher to the global webhook dispatcher singleton
+func ReplaceGlobalDispatcher(dispatcher *Dispatcher) func() {
+	_globalDispatcherMu.Lock()
+	defer _globalDispatcherMu.Unlock()
+
+	prev := _globalDispatcher
+	_globalDispatcher = dispatcher
+	return func() { ReplaceGlobalDispatcher(prev) }
+}
+
+// Invalidate clears the cached list of the webhooks of the global dispatcher, if any
+func Invalidate() {
+	if d := D(); d != nil {
+		d.Invalidate()
+	}
+}
+
+// CheckURL checks a webhook URL with the global dispatcher, if any
+func CheckURL(rawURL string) error {
+	if d := D(); d != nil {
+		return d.CheckURL(rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+// outboxRepository is an in-memory deliveries outbox, with a single webhook
+type outboxRepository struct {
+	Repository
+	webhook    model.IssueWebhook
+	deliveries []Delivery
+}
+
+func (r *outboxRepository) GetAll() ([]model.IssueWebhook, error) {
+	return []model.IssueWebhook{r.webhook}, nil
+}
+
+func (r *outboxRepository) CreateDeliveries(deliveries []Delivery) error {
+	for _, delivery := range deliveries {
+		delivery.ID = int64(len(r.deliveries) + 1)
+		delivery.State = DeliveryPending
+		r.deliveries = append(r.deliveries, delivery)
+	}
+	return nil
+}
+
+func (r *outboxRepository) NextDelivery(webhookID int64) (Delivery, bool, error) {
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID && delivery.State == DeliveryPending {
+			return delivery, true, nil
+		}
+	}
+	return Delivery{}, false, nil
+}
+
+func (r *outboxRepository) UpdateDelivery(delivery Delivery) error {
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == delivery.ID {
+			r.deliveries[i] = delivery
+		}
+	}
+	return nil
+}
+
+func (r *outboxRepository) DeleteDelivery(id int64) error {
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == id {
+			r.deliveries = append(r.deliveries[:i], r.deliveries[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestDispatcherDeliverPending(t *testing.T) {
+	received := make([]string, 0)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderSignature) != Signature("secret", body) {
+			t.Errorf("unexpected signature %s", r.Header.Get(HeaderSignature))
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received = append(received, r.Header.Get(HeaderEvent))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repository := &outboxRepository{webhook: model.IssueWebhook{ID: 1, URL: server.URL, Secret: "secret", Enabled: true}}
+	defer ReplaceGlobals(repository)()
+
+	dispatcher := NewDispatcher(time.Second, 1, true)
+	dispatcher.backoff = time.Millisecond
+	created := model.NewIssueEvent(model.IssueEventCreated, model.Issue{ID: 1}, "")
+	closed := model.NewIssueEvent(model.IssueEventClosed, model.Issue{ID: 1}, "admin")
+	_ = repository.CreateDeliveries([]Delivery{
+		{WebhookID: 1, EventID: created.ID, EventType: created.Type, Payload: []byte(`{"type":"created"}`)},
+		{WebhookID: 1, EventID: closed.ID, EventType: closed.Type, Payload: []byte(`{"type":"closed"}`)},
+	})
+
+	// The first delivery fails and is retried after the backoff, the second one waits for it
+	if wait := dispatcher.deliverPending(1); wait <= 0 || wait > time.Millisecond {
+		t.Errorf("expected to wait for the backoff, got %s", wait)
+	}
+	if len(received) != 0 || repository.deliveries[0].Attempts != 1 {
+		t.Errorf("unexpected deliveries %v %+v", received, repository.deliveries)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	dispatcher.deliverPending(1)
+	if len(received) != 2 || received[0] != string(model.IssueEventCreated) || received[1] != string(model.IssueEventClosed) {
+		t.Errorf("expected the deliveries in order, got %v", received)
+	}
+	if len(repository.deliveries) != 0 {
+		t.Errorf("expected an empty outbox, got %+v", repository.deliveries)
+	}
+}
+
+func TestDispatcherFailedDelivery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	repository := &outboxRepository{webhook: model.IssueWebhook{ID: 1, URL: server.URL, Enabled: true}}
+	defer ReplaceGlobals(repository)()
+
+	// The dispatcher is stopped so that no worker delivers the event in the background
+	dispatcher := NewDispatcher(time.Second, 0, true)
+	dispatcher.Stop()
+	dispatcher.Enqueue(model.NewIssueEvent(model.IssueEventCreated, model.Issue{ID: 1}, ""))
+	if len(repository.deliveries) != 1 {
+		t.Fatalf("expected the event to be persisted, got %+v", repository.deliveries)
+	}
+
+	dispatcher = NewDispatcher(time.Second, 0, true)
+	dispatcher.deliverPending(1)
+	if repository.deliveries[0].State != DeliveryFailed || repository.deliveries[0].LastError == "" {
+		t.Errorf("expected a failed delivery without retry, got %+v", repository.deliveries[0])
+	}
+}
+
+func TestDispatcherPrivateHosts(t *testing.T) {
+	dispatcher := NewDispatcher(time.Second, 0, false)
+	for _, u := range []string{"http://127.0.0.1:8080/hook", "http://localhost/hook", "https://10.1.2.3/hook", "http://[::1]/hook", "http://169.254.169.254/"} {
+		if err := dispatcher.CheckURL(u); err == nil {
+			t.Errorf("expected %s to be rejected", u)
+		}
+	}
+	if err := dispatcher.controlAddress("tcp", "192.168.1.10:443", nil); err != ErrPrivateHost {
+		t.Errorf("expected a private address to be refused on dial, got %v", err)
+	}
+	if err := dispatcher.controlAddress("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("expected a public address to be allowed, got %v", err)
+	}
+}
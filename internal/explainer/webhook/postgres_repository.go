@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+const (
+	table           = "issue_webhook_v1"
+	deliveriesTable = "issue_webhook_delivery_v1"
+)
+
+var columns = []string{"id", "name", "url", "secret", "event_types", "enabled"}
+
+var deliveryColumns = []string{"id", "webhook_id", "event_id", "event_type", "payload", "state", "attempts", "next_attempt_at", "last_error"}
+
+// PostgresRepository is a repository containing the issue webhooks based on a PSQL database and
+// implementing the repository interface
+type PostgresRepository struct {
+	conn *sqlx.DB
+}
+
+// NewPostgresRepository returns a new instance of PostgresRepository
+func NewPostgresRepository(dbClient *sqlx.DB) Repository {
+	r := PostgresRepository{
+		conn: dbClient,
+	}
+	var repo Repository = &r
+	return repo
+}
+
+// Get returns an issue webhook by its ID
+func (r *PostgresRepository) Get(id int64) (model.IssueWebhook, bool, error) {
+	webhooks, err := r.query(newStatement().Select(columns...).From(table).Where(sq.Eq{"id": id}))
+	if err != nil {
+		return model.IssueWebhook{}, false, err
+	}
+	if len(webhooks) == 0 {
+		return model.IssueWebhook{}, false, nil
+	}
+	return webhooks[0], true, nil
+}
+
+// GetAll returns all the issue webhooks
+func (r *PostgresRepository) GetAll() ([]model.IssueWebhook, error) {
+	return r.query(newStatement().Select(columns...).From(table).OrderBy("id"))
+}
+
+func (r *PostgresRepository) query(statement sq.SelectBuilder) ([]model.IssueWebhook, error) {
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]model.IssueWebhook, 0)
+	for rows.Next() {
+		var webhook model.IssueWebhook
+		var eventTypes []byte
+		if err = rows.Scan(&webhook.ID, &webhook.Name, &webhook.URL, &webhook.Secret, &eventTypes, &webhook.Enabled); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(eventTypes, &webhook.EventTypes); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// Create creates a new issue webhook
+func (r *PostgresRepository) Create(webhook model.IssueWebhook) (int64, error) {
+	if _, err := webhook.IsValid(); err != nil {
+		return -1, err
+	}
+	eventTypes, err := marshalEventTypes(webhook.EventTypes)
+	if err != nil {
+		return -1, err
+	}
+
+	var id int64
+	err = newStatement().
+		Insert(table).
+		Columns("name", "url", "secret", "event_types", "enabled").
+		Values(webhook.Name, webhook.URL, webhook.Secret, eventTypes, webhook.Enabled).
+		Suffix("RETURNING \"id\"").
+		RunWith(r.conn.DB).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// Update updates an issue webhook, its secret is kept if the new one is empty
+func (r *PostgresRepository) Update(webhook model.IssueWebhook) error {
+	if _, err := webhook.IsValid(); err != nil {
+		return err
+	}
+	eventTypes, err := marshalEventTypes(webhook.EventTypes)
+	if err != nil {
+		return err
+	}
+
+	statement := newStatement().
+		Update(table).
+		Set("name", webhook.Name).
+		Set("url", webhook.URL).
+		Set("event_types", eventTypes).
+		Set("enabled", webhook.Enabled).
+		Where(sq.Eq{"id": webhook.ID})
+	if webhook.Secret != "" {
+		statement = statement.Set("secret", webhook.Secret)
+	}
+	res, err := statement.RunWith(r.conn.DB).Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// Delete deletes an issue webhook
+func (r *PostgresRepository) Delete(id int64) error {
+	res, err := newStatement().Delete(table).Where(sq.Eq{"id": id}).RunWith(r.conn.DB).Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// CreateDeliveries adds some deliveries to the outbox
+func (r *PostgresRepository) CreateDeliveries(deliveries []Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	ts := time.Now().Truncate(1 * time.Millisecond).UTC()
+	statement := newStatement().
+		Insert(deliveriesTable).
+		Columns("webhook_id", "event_id", "event_type", "payload", "state", "attempts", "next_attempt_at", "last_error", "created_at")
+	for _, delivery := range deliveries {
+		nextAttemptTS := delivery.NextAttemptTS
+		if nextAttemptTS.IsZero() {
+			nextAttemptTS = ts
+		}
+		statement = statement.Values(delivery.WebhookID, delivery.EventID, string(delivery.EventType), string(delivery.Payload),
+			DeliveryPending, 0, nextAttemptTS, "", ts)
+	}
+	_, err := statement.RunWith(r.conn.DB).Exec()
+	return err
+}
+
+// NextDelivery returns the oldest pending delivery of a webhook
+func (r *PostgresRepository) NextDelivery(webhookID int64) (Delivery, bool, error) {
+	rows, err := newStatement().
+		Select(deliveryColumns...).
+		From(deliveriesTable).
+		Where(sq.Eq{"webhook_id": webhookID, "state": DeliveryPending}).
+		OrderBy("id").
+		Limit(1).
+		RunWith(r.conn.DB).
+		Query()
+	if err != nil {
+		return Delivery{}, false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return Delivery{}, false, rows.Err()
+	}
+	var delivery Delivery
+	var eventType string
+	err = rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &eventType, &delivery.Payload, &delivery.State,
+		&delivery.Attempts, &delivery.NextAttemptTS, &delivery.LastError)
+	if err != nil {
+		return Delivery{}, false, err
+	}
+	delivery.EventType = model.IssueEventType(eventType)
+	return delivery, true, nil
+}
+
+// UpdateDelivery updates the state and the attempts of a delivery
+func (r *PostgresRepository) UpdateDelivery(delivery Delivery) error {
+	res, err := newStatement().
+		Update(deliveriesTable).
+		Set("state", delivery.State).
+		Set("attempts", delivery.Attempts).
+		Set("next_attempt_at", delivery.NextAttemptTS).
+		Set("last_error", delivery.LastError).
+		Where(sq.Eq{"id": delivery.ID}).
+		RunWith(r.conn.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// DeleteDelivery removes a delivery from the outbox, once delivered
+func (r *PostgresRepository) DeleteDelivery(id int64) error {
+	_, err := newStatement().Delete(deliveriesTable).Where(sq.Eq{"id": id}).RunWith(r.conn.DB).Exec()
+	return err
+}
+
+func marshalEventTypes(eventTypes []model.IssueEventType) (string, error) {
+	if eventTypes == nil {
+		eventTypes = make([]model.IssueEventType, 0)
+	}
+	b, err := json.Marshal(eventTypes)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func checkAffected(res sql.Result) error {
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i != 1 {
+		return errors.New("no row updated (or multiple row updated) instead of 1 row")
+	}
+	return nil
+}
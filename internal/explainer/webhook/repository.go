@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+)
+
+// States of a delivery in the outbox
+const (
+	DeliveryPending = "pending"
+	DeliveryFailed  = "failed"
+)
+
+// Delivery is an issue event waiting in the outbox to be delivered to a webhook
+// The pending deliveries of a webhook are sent one at a time, in the order of the events
+type Delivery struct {
+	ID            int64
+	WebhookID     int64
+	EventID       string
+	EventType     model.IssueEventType
+	Payload       []byte
+	State         string
+	Attempts      int
+	NextAttemptTS time.Time
+	LastError     string
+}
+
+// Repository is a storage interface which can be implemented by multiple backend
+// (in-memory map, sql database, in-memory cache, file system, ...)
+// It allows standard CRUD operation on the issue webhooks, and stores their deliveries outbox
+type Repository interface {
+	Get(id int64) (model.IssueWebhook, bool, error)
+	GetAll() ([]model.IssueWebhook, error)
+	Create(webhook model.IssueWebhook) (int64, error)
+	Update(webhook model.IssueWebhook) error
+	Delete(id int64) error
+
+	// Deliveries outbox
+	CreateDeliveries(deliveries []Delivery) error
+	NextDelivery(webhookID int64) (Delivery, bool, error)
+	UpdateDelivery(delivery Delivery) error
+	DeleteDelivery(id int64) error
+}
+
+var (
+	_globalRepositoryMu sync.RWMutex
+	_globalRepository   Repository
+)
+
+// R is used to access the global repository singleton
+func R() Repository {
+	_globalRepositoryMu.RLock()
+	defer _globalRepositoryMu.RUnlock()
+
+	repository := _globalRepository
+	return repository
+}
+
+// ReplaceGlobals affect a new repository to the global repository singleton
+func ReplaceGlobals(repository Repository) func() {
+	_globalRepositoryMu.Lock()
+	defer _globalRepositoryMu.Unlock()
+
+	prev := _globalRepository
+	_globalRepository = repository
+	return func() { ReplaceGlobals(prev) }
+}
+
+// newStatement creates a new SQL statement builder with Dollar placeholder format
+func newStatement() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
@@ -319,6 +319,12 @@ func ExportFactHits(ti time.Time, f engine.Fact, placeholders map[string]interfa
 	// Change the behaviour of the Fact
 	f.Intent.Operator = engine.Select
 
+	placeholders, err := fact.ResolveParameters(f.ID, placeholders)
+	if err != nil {
+		zap.L().Error("ResolveParameters", zap.Error(err))
+		return nil, err
+	}
+
 	widgetData, err := fact.ExecuteFact(ti, f, 0, 0, placeholders, nhit, offset, false)
 	if err != nil {
 		zap.L().Error("ExecuteFact", zap.Error(err))
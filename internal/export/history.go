@@ -0,0 +1,84 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+)
+
+// Formats of a situation history stream
+const (
+	// HistoryFormatNDJSON writes one JSON record per line (newline delimited JSON)
+	HistoryFormatNDJSON = "ndjson"
+	// HistoryFormatJSON writes a single JSON array of records, chunk by chunk
+	HistoryFormatJSON = "json"
+)
+
+// HistoryStreamWriter writes batches of situation history records to a stream as soon as they are read,
+// so a whole history range never has to be loaded in memory
+type HistoryStreamWriter struct {
+	w       io.Writer
+	format  string
+	encoder *json.Encoder
+	count   int
+}
+
+// NewHistoryStreamWriter returns a history stream writer of the format (ndjson by default)
+func NewHistoryStreamWriter(w io.Writer, format string) (*HistoryStreamWriter, error) {
+	switch format {
+	case "":
+		format = HistoryFormatNDJSON
+	case HistoryFormatNDJSON, HistoryFormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown history stream format '%s'", format)
+	}
+	return &HistoryStreamWriter{w: w, format: format, encoder: json.NewEncoder(w)}, nil
+}
+
+// ContentType returns the content type of the stream
+func (s *HistoryStreamWriter) ContentType() string {
+	if s.format == HistoryFormatJSON {
+		return "application/json"
+	}
+	return "application/x-ndjson"
+}
+
+// Write writes a batch of records
+func (s *HistoryStreamWriter) Write(records []search.SituationHistoryRecord) error {
+	for _, record := range records {
+		if s.format == HistoryFormatJSON {
+			separator := ","
+			if s.count == 0 {
+				separator = "["
+			}
+			if _, err := io.WriteString(s.w, separator); err != nil {
+				return err
+			}
+		}
+		if err := s.encoder.Encode(record); err != nil {
+			return err
+		}
+		s.count++
+	}
+	return nil
+}
+
+// Close terminates the stream (ie. the JSON array), it must be called once every batch has been written
+func (s *HistoryStreamWriter) Close() error {
+	if s.format != HistoryFormatJSON {
+		return nil
+	}
+	end := "]"
+	if s.count == 0 {
+		end = "[]"
+	}
+	_, err := io.WriteString(s.w, end)
+	return err
+}
+
+// Count returns the number of records written
+func (s *HistoryStreamWriter) Count() int {
+	return s.count
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+)
+
+func TestHistoryStreamWriterNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	s, err := NewHistoryStreamWriter(&buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ContentType() != "application/x-ndjson" {
+		t.Errorf("unexpected content type %s", s.ContentType())
+	}
+	if err = s.Write([]search.SituationHistoryRecord{{SituationID: 1}, {SituationID: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Write([]search.SituationHistoryRecord{{SituationID: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || s.Count() != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		var record search.SituationHistoryRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record.SituationID != int64(i+1) {
+			t.Errorf("expected situation %d, got %d", i+1, record.SituationID)
+		}
+	}
+}
+
+func TestHistoryStreamWriterJSON(t *testing.T) {
+	var buf bytes.Buffer
+	s, _ := NewHistoryStreamWriter(&buf, HistoryFormatJSON)
+	s.Write([]search.SituationHistoryRecord{{SituationID: 1}})
+	s.Write([]search.SituationHistoryRecord{{SituationID: 2}})
+	s.Close()
+
+	var records []search.SituationHistoryRecord
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1].SituationID != 2 {
+		t.Errorf("unexpected records %+v", records)
+	}
+
+	buf.Reset()
+	s, _ = NewHistoryStreamWriter(&buf, HistoryFormatJSON)
+	s.Close()
+	if buf.String() != "[]" {
+		t.Errorf("expected an empty array, got %s", buf.String())
+	}
+}
+
+func TestHistoryStreamWriterFormat(t *testing.T) {
+	if _, err := NewHistoryStreamWriter(&bytes.Buffer{}, "csv"); err == nil {
+		t.Error("expected an error with an unknown format")
+	}
+}
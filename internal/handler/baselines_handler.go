@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/baseline"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// GetBaselines godoc
+//
+//	@Id				GetBaselines
+//
+//	@Summary		Get all built-in baseline definitions
+//	@Description	Get all built-in baseline definitions
+//	@Tags			Baselines
+//	@Produce		json
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		baseline.Baseline	"list of baselines"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/baselines [get]
+func GetBaselines(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeBaseline, permissions.All, permissions.ActionList)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	baselines, err := baseline.R().GetAll()
+	if err != nil {
+		zap.L().Error("Error getting baselines", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, baselines)
+}
+
+// GetBaseline godoc
+//
+//	@Id				GetBaseline
+//
+//	@Summary		Get a built-in baseline definition
+//	@Description	Get a built-in baseline definition
+//	@Tags			Baselines
+//	@Produce		json
+//	@Param			id	path	int	true	"Baseline ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	baseline.Baseline	"baseline"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/baselines/{id} [get]
+func GetBaseline(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idBaseline, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing baseline id", zap.String("baselineID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeBaseline, strconv.FormatInt(idBaseline, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	b, found, err := baseline.R().Get(idBaseline)
+	if err != nil {
+		zap.L().Error("Cannot get baseline", zap.Int64("baselineID", idBaseline), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("Baseline does not exists", zap.Int64("baselineID", idBaseline))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("baseline not found"))
+		return
+	}
+
+	httputil.JSON(w, r, b)
+}
+
+// PostBaseline godoc
+//
+//	@Id				PostBaseline
+//
+//	@Summary		Create a new built-in baseline
+//	@Description	Create a new built-in baseline, its values are computed by the baseline scheduler job
+//	@Tags			Baselines
+//	@Accept			json
+//	@Produce		json
+//	@Param			baseline	body	baseline.Baseline	true	"Baseline definition"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	baseline.Baseline	"created baseline with ID"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/baselines [post]
+func PostBaseline(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeBaseline, permissions.All, permissions.ActionCreate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var b baseline.Baseline
+	err := json.NewDecoder(r.Body).Decode(&b)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling baseline", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if err := b.Validate(); err != nil {
+		zap.L().Warn("Baseline validation failed", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	id, err := baseline.R().Create(b)
+	if err != nil {
+		zap.L().Error("Cannot create baseline", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBInsertFailed, err)
+		return
+	}
+
+	b.ID = id
+	httputil.JSON(w, r, b)
+}
+
+// PutBaseline godoc
+//
+//	@Id				PutBaseline
+//
+//	@Summary		Update a built-in baseline
+//	@Description	Update a built-in baseline, its values are recomputed on the next baseline scheduler job
+//	@Tags			Baselines
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	int					true	"Baseline ID"
+//	@Param			baseline	body	baseline.Baseline	true	"Baseline definition"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	baseline.Baseline	"updated baseline"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/baselines/{id} [put]
+func PutBaseline(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idBaseline, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing baseline id", zap.String("baselineID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeBaseline, strconv.FormatInt(idBaseline, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var b baseline.Baseline
+	err = json.NewDecoder(r.Body).Decode(&b)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling baseline", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	b.ID = idBaseline
+	if err := b.Validate(); err != nil {
+		zap.L().Warn("Baseline validation failed", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	err = baseline.R().Update(b)
+	if err != nil {
+		zap.L().Error("Cannot update baseline", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, b)
+}
+
+// DeleteBaseline godoc
+//
+//	@Id				DeleteBaseline
+//
+//	@Summary		Delete a built-in baseline
+//	@Description	Delete a built-in baseline and its values
+//	@Tags			Baselines
+//	@Param			id	path	int	true	"Baseline ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/baselines/{id} [delete]
+func DeleteBaseline(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idBaseline, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing baseline id", zap.String("baselineID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeBaseline, strconv.FormatInt(idBaseline, 10), permissions.ActionDelete)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	err = baseline.R().Delete(idBaseline)
+	if err != nil {
+		zap.L().Error("Cannot delete baseline", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+
+	httputil.OK(w, r)
+}
@@ -0,0 +1,551 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/catalog"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// GetCatalogRootCauses godoc
+//
+//	@Id				GetCatalogRootCauses
+//
+//	@Summary		Get all the catalog rootcauses
+//	@Description	Get all the catalog rootcauses with their actions
+//	@Tags			Catalog
+//	@Produce		json
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		model.CatalogRootCause	"list of catalog rootcauses"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/catalog/rootcauses [get]
+func GetCatalogRootCauses(w http.ResponseWriter, r *http.Request) {
+	rootCauses, err := catalog.R().GetAllRootCauses()
+	if err != nil {
+		zap.L().Error("Error getting catalog rootcauses", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, rootCauses)
+}
+
+// GetCatalogRootCause godoc
+//
+//	@Id				GetCatalogRootCause
+//
+//	@Summary		Get a catalog rootcause
+//	@Description	Get a catalog rootcause with its actions
+//	@Tags			Catalog
+//	@Produce		json
+//	@Param			id	path	int	true	"Catalog rootcause ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.CatalogRootCause	"catalog rootcause"
+//	@Failure		400	{object}	httputil.APIError		"Bad Request"
+//	@Failure		404	{object}	httputil.APIError		"Not Found"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/catalog/rootcauses/{id} [get]
+func GetCatalogRootCause(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idRootCause, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing catalog rootcause id", zap.String("rootcauseID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	rootCause, found, err := catalog.R().GetRootCause(idRootCause)
+	if err != nil {
+		zap.L().Error("Cannot get catalog rootcause", zap.Int64("rootcauseID", idRootCause), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("Catalog rootcause does not exists", zap.Int64("rootcauseID", idRootCause))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("catalog rootcause not found"))
+		return
+	}
+
+	httputil.JSON(w, r, rootCause)
+}
+
+// PostCatalogRootCause godoc
+//
+//	@Id				PostCatalogRootCause
+//
+//	@Summary		Create a catalog rootcause
+//	@Description	Create a catalog rootcause with its actions
+//	@Tags			Catalog
+//	@Accept			json
+//	@Produce		json
+//	@Param			rootcause	body	model.CatalogRootCause	true	"Catalog rootcause"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.CatalogRootCause	"created catalog rootcause"
+//	@Failure		400	{object}	httputil.APIError		"Bad Request"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/catalog/rootcauses [post]
+func PostCatalogRootCause(w http.ResponseWriter, r *http.Request) {
+	var rootCause model.CatalogRootCause
+	err := json.NewDecoder(r.Body).Decode(&rootCause)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling catalog rootcause", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if ok, err := rootCause.IsValid(); !ok {
+		zap.L().Warn("Catalog rootcause is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	id, err := catalog.R().CreateRootCause(rootCause)
+	if err != nil {
+		zap.L().Error("Cannot create catalog rootcause", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBInsertFailed, err)
+		return
+	}
+
+	writeCatalogRootCause(w, r, id)
+}
+
+// PutCatalogRootCause godoc
+//
+//	@Id				PutCatalogRootCause
+//
+//	@Summary		Update a catalog rootcause
+//	@Description	Update the name and description of a catalog rootcause, and of the rootcauses of its linked situations (the actions are not updated)
+//	@Tags			Catalog
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	int						true	"Catalog rootcause ID"
+//	@Param			rootcause	body	model.CatalogRootCause	true	"Catalog rootcause"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.CatalogRootCause	"updated catalog rootcause"
+//	@Failure		400	{object}	httputil.APIError		"Bad Request"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/catalog/rootcauses/{id} [put]
+func PutCatalogRootCause(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idRootCause, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing catalog rootcause id", zap.String("rootcauseID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	var rootCause model.CatalogRootCause
+	err = json.NewDecoder(r.Body).Decode(&rootCause)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling catalog rootcause", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	rootCause.ID = idRootCause
+	rootCause.Actions = nil
+	if ok, err := rootCause.IsValid(); !ok {
+		zap.L().Warn("Catalog rootcause is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	err = catalog.R().UpdateRootCause(rootCause)
+	if err != nil {
+		zap.L().Error("Cannot update catalog rootcause", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+
+	writeCatalogRootCause(w, r, idRootCause)
+}
+
+// DeleteCatalogRootCause godoc
+//
+//	@Id				DeleteCatalogRootCause
+//
+//	@Summary		Delete a catalog rootcause
+//	@Description	Delete a catalog rootcause and its actions, and remove them from the linked situations.
+//	@Description	The rootcauses and actions of the situations used in an issue resolution are kept as local ones.
+//	@Tags			Catalog
+//	@Param			id	path	int	true	"Catalog rootcause ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/catalog/rootcauses/{id} [delete]
+func DeleteCatalogRootCause(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idRootCause, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing catalog rootcause id", zap.String("rootcauseID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	err = catalog.R().DeleteRootCause(idRootCause)
+	if err != nil {
+		zap.L().Error("Cannot delete catalog rootcause", zap.Int64("rootcauseID", idRootCause), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+
+	httputil.OK(w, r)
+}
+
+// PostCatalogAction godoc
+//
+//	@Id				PostCatalogAction
+//
+//	@Summary		Create a catalog action
+//	@Description	Create an action of a catalog rootcause, which is added to all its linked situations
+//	@Tags			Catalog
+//	@Accept			json
+//	@Produce		json
+//	@Param			action	body	model.CatalogAction	true	"Catalog action"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.CatalogAction	"created catalog action"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/catalog/actions [post]
+func PostCatalogAction(w http.ResponseWriter, r *http.Request) {
+	var action model.CatalogAction
+	err := json.NewDecoder(r.Body).Decode(&action)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling catalog action", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if ok, err := action.IsValid(); !ok {
+		zap.L().Warn("Catalog action is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	id, err := catalog.R().CreateAction(action)
+	if err != nil {
+		zap.L().Error("Cannot create catalog action", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBInsertFailed, err)
+		return
+	}
+
+	action.ID = id
+	httputil.JSON(w, r, action)
+}
+
+// PutCatalogAction godoc
+//
+//	@Id				PutCatalogAction
+//
+//	@Summary		Update a catalog action
+//	@Description	Update the name and description of a catalog action, and of the actions of its linked situations
+//	@Tags			Catalog
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	int					true	"Catalog action ID"
+//	@Param			action	body	model.CatalogAction	true	"Catalog action"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.CatalogAction	"updated catalog action"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/catalog/actions/{id} [put]
+func PutCatalogAction(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idAction, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing catalog action id", zap.String("actionID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	existing, found, err := catalog.R().GetAction(idAction)
+	if err != nil {
+		zap.L().Error("Cannot get catalog action", zap.Int64("actionID", idAction), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("Catalog action does not exists", zap.Int64("actionID", idAction))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("catalog action not found"))
+		return
+	}
+
+	var action model.CatalogAction
+	err = json.NewDecoder(r.Body).Decode(&action)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling catalog action", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	// An action cannot be moved to another catalog rootcause
+	action.ID = idAction
+	action.CatalogRootCauseID = existing.CatalogRootCauseID
+	if ok, err := action.IsValid(); !ok {
+		zap.L().Warn("Catalog action is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	err = catalog.R().UpdateAction(action)
+	if err != nil {
+		zap.L().Error("Cannot update catalog action", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, action)
+}
+
+// DeleteCatalogAction godoc
+//
+//	@Id				DeleteCatalogAction
+//
+//	@Summary		Delete a catalog action
+//	@Description	Delete a catalog action and remove it from the linked situations.
+//	@Description	The actions of the situations used in an issue resolution are kept as local ones.
+//	@Tags			Catalog
+//	@Param			id	path	int	true	"Catalog action ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/catalog/actions/{id} [delete]
+func DeleteCatalogAction(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idAction, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing catalog action id", zap.String("actionID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	err = catalog.R().DeleteAction(idAction)
+	if err != nil {
+		zap.L().Error("Cannot delete catalog action", zap.Int64("actionID", idAction), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+
+	httputil.OK(w, r)
+}
+
+// GetCatalogRootCauseLinks godoc
+//
+//	@Id				GetCatalogRootCauseLinks
+//
+//	@Summary		Get the situations and rules linked to a catalog rootcause
+//	@Description	Get the situations and rules linked to a catalog rootcause, with the ID of their rootcause
+//	@Tags			Catalog
+//	@Produce		json
+//	@Param			id	path	int	true	"Catalog rootcause ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		model.CatalogLink	"list of links"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/catalog/rootcauses/{id}/links [get]
+func GetCatalogRootCauseLinks(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idRootCause, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing catalog rootcause id", zap.String("rootcauseID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	links, err := catalog.R().GetLinks(idRootCause)
+	if err != nil {
+		zap.L().Error("Cannot get catalog rootcause links", zap.Int64("rootcauseID", idRootCause), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, links)
+}
+
+// PostCatalogRootCauseLink godoc
+//
+//	@Id				PostCatalogRootCauseLink
+//
+//	@Summary		Link a catalog rootcause to a situation and rule
+//	@Description	Add a catalog rootcause and its actions to a situation and rule.
+//	@Description	An existing rootcause (or action) of the situation and rule with the same name is attached to the catalog.
+//	@Tags			Catalog
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	int					true	"Catalog rootcause ID"
+//	@Param			link	body	model.CatalogLink	true	"Situation and rule"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.CatalogLink	"link with the rootcause ID of the situation"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/catalog/rootcauses/{id}/links [post]
+func PostCatalogRootCauseLink(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idRootCause, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing catalog rootcause id", zap.String("rootcauseID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	var link model.CatalogLink
+	err = json.NewDecoder(r.Body).Decode(&link)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling catalog link", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if ok, err := link.IsValid(); !ok {
+		zap.L().Warn("Catalog link is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	_, found, err := catalog.R().GetRootCause(idRootCause)
+	if err != nil {
+		zap.L().Error("Cannot get catalog rootcause", zap.Int64("rootcauseID", idRootCause), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("Catalog rootcause does not exists", zap.Int64("rootcauseID", idRootCause))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("catalog rootcause not found"))
+		return
+	}
+
+	link.RootCauseID, err = catalog.R().Link(idRootCause, link.SituationID, link.RuleID)
+	if err != nil {
+		zap.L().Error("Cannot link catalog rootcause", zap.Int64("rootcauseID", idRootCause), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBInsertFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, link)
+}
+
+// DeleteCatalogRootCauseLink godoc
+//
+//	@Id				DeleteCatalogRootCauseLink
+//
+//	@Summary		Unlink a catalog rootcause from a situation and rule
+//	@Description	Remove a catalog rootcause and its actions from a situation and rule.
+//	@Description	The rootcause and actions of the situation used in an issue resolution are kept as local ones.
+//	@Tags			Catalog
+//	@Param			id			path	int	true	"Catalog rootcause ID"
+//	@Param			situationid	query	int	true	"Situation ID"
+//	@Param			ruleid		query	int	true	"Rule ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/catalog/rootcauses/{id}/links [delete]
+func DeleteCatalogRootCauseLink(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idRootCause, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing catalog rootcause id", zap.String("rootcauseID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	situationID, err := strconv.ParseInt(r.URL.Query().Get("situationid"), 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing situationid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+	ruleID, err := strconv.ParseInt(r.URL.Query().Get("ruleid"), 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing ruleid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	found, err := catalog.R().Unlink(idRootCause, situationID, ruleID)
+	if err != nil {
+		zap.L().Error("Cannot unlink catalog rootcause", zap.Int64("rootcauseID", idRootCause), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+	if !found {
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("catalog rootcause is not linked to this situation and rule"))
+		return
+	}
+
+	httputil.OK(w, r)
+}
+
+// GetCatalogStats godoc
+//
+//	@Id				GetCatalogStats
+//
+//	@Summary		Get the catalog resolution statistics
+//	@Description	Get the usage of the catalog rootcauses and actions in the issues resolutions, on all their linked situations or on a single situation
+//	@Tags			Catalog
+//	@Produce		json
+//	@Param			situationid	query	int	false	"Situation ID (all the situations if not set)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		model.CatalogRootCauseStats	"catalog statistics"
+//	@Failure		400	{object}	httputil.APIError			"Bad Request"
+//	@Failure		500	{object}	httputil.APIError			"Internal Server Error"
+//	@Router			/engine/catalog/stats [get]
+func GetCatalogStats(w http.ResponseWriter, r *http.Request) {
+	var situationID int64
+	if s := r.URL.Query().Get("situationid"); s != "" {
+		var err error
+		situationID, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			zap.L().Warn("Error on parsing situationid", zap.String("situationid", s), zap.Error(err))
+			httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+			return
+		}
+	}
+
+	stats, err := explainer.GetCatalogStats(situationID)
+	if err != nil {
+		zap.L().Error("Cannot get catalog statistics", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, stats)
+}
+
+func writeCatalogRootCause(w http.ResponseWriter, r *http.Request, id int64) {
+	rootCause, found, err := catalog.R().GetRootCause(id)
+	if err != nil {
+		zap.L().Error("Cannot get catalog rootcause", zap.Int64("rootcauseID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("catalog rootcause not found"))
+		return
+	}
+	httputil.JSON(w, r, rootCause)
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/fact"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// GetFactCacheStats godoc
+//
+//	@Id				GetFactCacheStats
+//
+//	@Summary		Get the fact result cache statistics
+//	@Description	Get the fact result cache configuration, number of entries, hits and misses
+//	@Tags			Facts
+//	@Produce		json
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	fact.CacheStats		"Cache statistics"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Router			/engine/facts/cache/stats [get]
+func GetFactCacheStats(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, permissions.All, permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	httputil.JSON(w, r, fact.Cache().Stats())
+}
+
+// GetFactCache godoc
+//
+//	@Id				GetFactCache
+//
+//	@Summary		Get the result cache configuration of a fact
+//	@Description	Get the dedicated result cache TTL of a fact (empty if the fact uses the default TTL)
+//	@Tags			Facts
+//	@Produce		json
+//	@Param			id	path	int	true	"Fact ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	fact.CacheConfig	"Cache configuration"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Router			/engine/facts/{id}/cache [get]
+func GetFactCache(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	httputil.JSON(w, r, fact.Cache().Config(idFact))
+}
+
+// PutFactCache godoc
+//
+//	@Id				PutFactCache
+//
+//	@Summary		Set the result cache configuration of a fact
+//	@Description	Set the dedicated result cache TTL of a fact ("0s" disables the cache, empty falls back on the default TTL)
+//	@Tags			Facts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	int					true	"Fact ID"
+//	@Param			config	body	fact.CacheConfig	true	"Cache configuration (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	fact.CacheConfig	"Cache configuration"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/facts/{id}/cache [put]
+func PutFactCache(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var config fact.CacheConfig
+	err = json.NewDecoder(r.Body).Decode(&config)
+	if err != nil {
+		zap.L().Warn("Fact cache configuration json decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	ttl, err := config.Duration()
+	if err != nil {
+		zap.L().Warn("Fact cache ttl is invalid", zap.String("ttl", config.TTL), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingDuration, err)
+		return
+	}
+
+	_, found, err := fact.R().Get(idFact)
+	if err != nil {
+		zap.L().Error("Cannot retrieve fact", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("fact does not exists", zap.Int64("factID", idFact))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, fmt.Errorf("fact not found with id %d", idFact))
+		return
+	}
+
+	err = fact.R().SetCacheTTL(idFact, ttl)
+	if err != nil {
+		zap.L().Error("Error while updating the fact cache ttl", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+	fact.Cache().SetTTL(idFact, ttl)
+
+	httputil.JSON(w, r, fact.Cache().Config(idFact))
+}
+
+// DeleteFactCache godoc
+//
+//	@Id				DeleteFactCache
+//
+//	@Summary		Invalidate the cached results of a fact
+//	@Description	Remove every cached results of a fact. The cache configuration is kept.
+//	@Tags			Facts
+//	@Produce		json
+//	@Param			id	path	int	true	"Fact ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Router			/engine/facts/{id}/cache [delete]
+func DeleteFactCache(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	fact.Cache().Invalidate(idFact)
+
+	httputil.OK(w, r)
+}
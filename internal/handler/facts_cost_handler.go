@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/fact"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+	"go.uber.org/zap"
+)
+
+// FactValidation is the result of a fact validation including its cost estimate
+type FactValidation struct {
+	Fact engine.Fact        `json:"fact"`
+	Cost *fact.CostEstimate `json:"cost"`
+}
+
+// checkFactCost estimates the cost of a fact definition before it is saved, when the cost limits are enforced
+// The fact is estimated with the default values of its parameter definitions (the stored ones if defs is nil).
+// It returns an error if the fact exceeds the limits (or its cost cannot be estimated) in block mode,
+// and sets a warning header in warn mode. A fact with a required parameter without default value can't be estimated,
+// it is only reported with a warning header whatever the mode.
+func checkFactCost(w http.ResponseWriter, f engine.Fact, defs fact.ParameterDefinitions) error {
+	mode := fact.GetCostLimits().Mode
+	if mode == fact.CostModeOff {
+		return nil
+	}
+
+	parameters, missing, err := factCostParameters(f, defs)
+	if err == nil && len(missing) > 0 {
+		zap.L().Warn("Cannot estimate the fact cost without parameter values", zap.String("fact", f.Name), zap.Strings("errors", missing))
+		w.Header().Set("X-Fact-Cost-Warnings", "cannot estimate the fact cost: "+strings.Join(missing, ", "))
+		return nil
+	}
+
+	var estimate fact.CostEstimate
+	if err == nil {
+		estimate, err = fact.EstimateResolvedCost(time.Now(), f, parameters, 0, false)
+	}
+	if err != nil {
+		if mode == fact.CostModeBlock {
+			return fmt.Errorf("cannot estimate the fact cost: %w", err)
+		}
+		zap.L().Warn("Cannot estimate the fact cost", zap.String("fact", f.Name), zap.Error(err))
+		w.Header().Set("X-Fact-Cost-Warnings", "cannot estimate the fact cost")
+		return nil
+	}
+	if estimate.Blocked {
+		return estimate.Err()
+	}
+	if len(estimate.Warnings) > 0 {
+		zap.L().Warn("Fact exceeds the cost limits", zap.String("fact", f.Name), zap.Strings("warnings", estimate.Warnings))
+		w.Header().Set("X-Fact-Cost-Warnings", strings.Join(estimate.Warnings, ", "))
+	}
+	return nil
+}
+
+// factCostParameters returns the default values of the parameter definitions of a fact definition, and the
+// errors of the parameters without value. The stored parameter definitions of the fact are used if defs is nil.
+func factCostParameters(f engine.Fact, defs fact.ParameterDefinitions) (map[string]interface{}, []string, error) {
+	if defs == nil && f.ID > 0 {
+		var err error
+		if defs, err = fact.R().GetParameterDefinitions(f.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	parameters, _, errs := defs.Bind(make(map[string]interface{}))
+	return parameters, errs, nil
+}
+
+// GetFactCost godoc
+//
+//	@Id				GetFactCost
+//
+//	@Summary		Estimate the cost of a fact execution
+//	@Description	Estimate the indices touched, the date range and the bucket cardinality of a fact execution, and check them against the configured limits
+//	@Tags			Facts
+//	@Produce		json
+//	@Param			id				path	int		true	"Fact ID"
+//	@Param			time			query	string	false	"Timestamp used for the fact execution"
+//	@Param			nhit			query	int		false	"Hit per page"
+//	@Param			count			query	bool	false	"Count the documents matched by the fact query"
+//	@Param			factParameters	query	string	false	"Declared fact parameters, url encoded (format: key1=value1&key2=value2)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	fact.CostEstimate	"Cost estimate"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/facts/{id}/cost [get]
+func GetFactCost(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	t, err := QueryParamToOptionalTime(r, "time", time.Now())
+	if err != nil {
+		zap.L().Warn("Parse input time", zap.Error(err), zap.String("rawTime", r.URL.Query().Get("time")))
+		httputil.Error(w, r, httputil.ErrAPIParsingDateTime, err)
+		return
+	}
+
+	nhit, err := QueryParamToOptionalInt(r, "nhit", 0)
+	if err != nil {
+		zap.L().Warn("Parse input nhit", zap.Error(err), zap.String("rawNhit", r.URL.Query().Get("nhit")))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	count, err := QueryParamToOptionalBool(r, "count", false)
+	if err != nil {
+		zap.L().Warn("Parse input count", zap.Error(err), zap.String("rawCount", r.URL.Query().Get("count")))
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+		return
+	}
+
+	factParameters, err := ParseFactParameters(r.URL.Query().Get("factParameters"))
+	if err != nil {
+		zap.L().Warn("Parse input factParameters", zap.Error(err), zap.String("raw factParameters", r.URL.Query().Get("factParameters")))
+		httputil.Error(w, r, httputil.ErrAPIParsingKeyValue, err)
+		return
+	}
+
+	f, found, err := fact.R().Get(idFact)
+	if err != nil {
+		zap.L().Error("Cannot retrieve fact", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("fact does not exists", zap.Int64("factID", idFact))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, err)
+		return
+	}
+
+	estimate, err := fact.EstimateCost(t, f, factParameters, nhit, count)
+	if err != nil {
+		zap.L().Error("Cannot estimate the fact cost", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIProcessError, err)
+		return
+	}
+
+	httputil.JSON(w, r, estimate)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
@@ -118,21 +119,22 @@ func GetFact(w http.ResponseWriter, r *http.Request) {
 //	@Id				ValidateFact
 //
 //	@Summary		Validate a new fact definition
-//	@Description	Validate a new fact definition
+//	@Description	Validate a new fact definition. With estimate=true, the response also contains the fact cost estimate.
 //	@Tags			Facts
 //	@Accept			json
 //	@Produce		json
-//	@Param			fact	body	interface{}	true	"Fact definition (json)"
+//	@Param			fact		body	interface{}	true	"Fact definition (json)"
+//	@Param			estimate	query	bool		false	"Estimate the fact cost (indices, date range, buckets)"
+//	@Param			count		query	bool		false	"Count the documents matched by the fact query (with estimate=true)"
 //	@Security		Bearer
 //	@Security		ApiKeyAuth
-//	@Success		200	{object}	engine.Fact			"Fact definition"
+//	@Success		200	{object}	engine.Fact			"Fact definition (or handler.FactValidation with estimate=true)"
 //	@Failure		400	{object}	httputil.APIError	"Bad Request"
 //	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
 //	@Router			/engine/facts/validate [post]
 func ValidateFact(w http.ResponseWriter, r *http.Request) {
 
-	var newFact engine.Fact
-	err := json.NewDecoder(r.Body).Decode(&newFact)
+	newFact, parameterDefinitions, err := decodeFactDefinition(r)
 	if err != nil {
 		zap.L().Warn("Fact definition json decode", zap.Error(err))
 		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
@@ -145,7 +147,58 @@ func ValidateFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.JSON(w, r, newFact)
+	if ok, err := parameterDefinitions.IsValid(); !ok {
+		zap.L().Warn("Fact parameter definitions are invalid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	estimate, err := QueryParamToOptionalBool(r, "estimate", false)
+	if err != nil {
+		zap.L().Warn("Parse input estimate", zap.Error(err), zap.String("rawEstimate", r.URL.Query().Get("estimate")))
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+		return
+	}
+	if !estimate {
+		if err = checkFactCost(w, newFact, parameterDefinitions); err != nil {
+			zap.L().Warn("Fact definition refused by the cost limits", zap.Error(err))
+			httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+			return
+		}
+		httputil.JSON(w, r, newFact)
+		return
+	}
+
+	count, err := QueryParamToOptionalBool(r, "count", false)
+	if err != nil {
+		zap.L().Warn("Parse input count", zap.Error(err), zap.String("rawCount", r.URL.Query().Get("count")))
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+		return
+	}
+
+	parameters, missing, err := factCostParameters(newFact, parameterDefinitions)
+	if err == nil && len(missing) > 0 {
+		err = fmt.Errorf("cannot estimate the fact cost: %s", strings.Join(missing, ", "))
+	}
+	if err != nil {
+		zap.L().Warn("Cannot estimate the fact cost", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	cost, err := fact.EstimateResolvedCost(time.Now(), newFact, parameters, 0, count)
+	if err != nil {
+		zap.L().Warn("Cannot estimate the fact cost", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+	if cost.Blocked {
+		zap.L().Warn("Fact definition exceeds the cost limits", zap.Strings("warnings", cost.Warnings))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, cost.Err())
+		return
+	}
+
+	httputil.JSON(w, r, FactValidation{Fact: newFact, Cost: &cost})
 }
 
 // PostFact godoc
@@ -172,8 +225,7 @@ func PostFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var newFact engine.Fact
-	err := json.NewDecoder(r.Body).Decode(&newFact)
+	newFact, parameterDefinitions, err := decodeFactDefinition(r)
 	if err != nil {
 		zap.L().Warn("Fact definition json decode", zap.Error(err))
 		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
@@ -186,6 +238,18 @@ func PostFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if ok, err := parameterDefinitions.IsValid(); !ok {
+		zap.L().Warn("Fact parameter definitions are invalid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	if err = checkFactCost(w, newFact, parameterDefinitions); err != nil {
+		zap.L().Warn("Fact definition refused by the cost limits", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
 	newFactID, err := fact.R().Create(newFact)
 	if err != nil {
 		zap.L().Error("Error while creating the Fact", zap.Any("fact", newFact), zap.Error(err))
@@ -193,6 +257,15 @@ func PostFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if parameterDefinitions != nil {
+		err = fact.R().SetParameterDefinitions(newFactID, parameterDefinitions)
+		if err != nil {
+			zap.L().Error("Error while creating the Fact parameter definitions", zap.Int64("factID", newFactID), zap.Error(err))
+			httputil.Error(w, r, httputil.ErrAPIDBInsertFailed, err)
+			return
+		}
+	}
+
 	f, found, err := fact.R().Get(newFactID)
 	if err != nil {
 		zap.L().Error("Error while fetch the created fact", zap.Any("newfactID", newFactID), zap.Any("newfact", newFact), zap.Error(err))
@@ -240,8 +313,7 @@ func PutFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var newFact engine.Fact
-	err = json.NewDecoder(r.Body).Decode(&newFact)
+	newFact, parameterDefinitions, err := decodeFactDefinition(r)
 	if err != nil {
 		zap.L().Warn("Fact definition json decode", zap.Error(err))
 		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
@@ -255,6 +327,18 @@ func PutFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if ok, err := parameterDefinitions.IsValid(); !ok {
+		zap.L().Warn("Fact parameter definitions are invalid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	if err = checkFactCost(w, newFact, parameterDefinitions); err != nil {
+		zap.L().Warn("Fact definition refused by the cost limits", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
 	err = fact.R().Update(idFact, newFact)
 	if err != nil {
 		zap.L().Error("Error while updating the Fact", zap.Int64("idFact", idFact), zap.Error(err))
@@ -262,6 +346,17 @@ func PutFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if parameterDefinitions != nil {
+		err = fact.R().SetParameterDefinitions(idFact, parameterDefinitions)
+		if err != nil {
+			zap.L().Error("Error while updating the Fact parameter definitions", zap.Int64("idFact", idFact), zap.Error(err))
+			httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+			return
+		}
+	}
+
+	fact.Cache().Invalidate(idFact)
+
 	f, found, err := fact.R().Get(idFact)
 	if err != nil {
 		zap.L().Error("Error while fetch the created fact", zap.Any("factID", idFact), zap.Any("newfact", newFact), zap.Error(err))
@@ -282,14 +377,16 @@ func PutFact(w http.ResponseWriter, r *http.Request) {
 //	@Id				DeleteFact
 //
 //	@Summary		Delete a fact definition
-//	@Description	Delete a fact definition
+//	@Description	Delete a fact definition. The deletion is refused if the fact is still in use (see GET /engine/facts/{id}/usages), unless force=true.
 //	@Tags			Facts
 //	@Produce		json
-//	@Param			id	path	int	true	"Fact ID"
+//	@Param			id		path	int		true	"Fact ID"
+//	@Param			force	query	bool	false	"Delete the fact even if it is still in use"
 //	@Security		Bearer
 //	@Security		ApiKeyAuth
 //	@Success		200	"Status OK"
 //	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		409	{object}	httputil.APIError	"Fact still in use"
 //	@Router			/engine/facts/{id} [delete]
 func DeleteFact(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -306,6 +403,35 @@ func DeleteFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	force, err := QueryParamToOptionalBool(r, "force", false)
+	if err != nil {
+		zap.L().Warn("Parse input force", zap.Error(err), zap.String("rawForce", r.URL.Query().Get("force")))
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+		return
+	}
+
+	if !force {
+		f, found, err := fact.R().Get(idFact)
+		if err != nil {
+			zap.L().Error("Cannot retrieve fact", zap.Int64("factID", idFact), zap.Error(err))
+			httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+			return
+		}
+		if found {
+			usages, err := getFactUsages(f)
+			if err != nil {
+				zap.L().Error("Cannot retrieve fact usages", zap.Int64("factID", idFact), zap.Error(err))
+				httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+				return
+			}
+			if usages.InUse() {
+				zap.L().Warn("Fact is still in use", zap.Int64("factID", idFact))
+				httputil.Error(w, r, httputil.ErrAPIResourceInUse, fmt.Errorf("fact %d is still in use (see /engine/facts/%d/usages), use force=true to delete it anyway", idFact, idFact))
+				return
+			}
+		}
+	}
+
 	err = fact.R().Delete(idFact)
 	if err != nil {
 		zap.L().Error("Error while deleting the Fact", zap.Int64("factID", idFact), zap.Error(err))
@@ -313,6 +439,8 @@ func DeleteFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	fact.Cache().Invalidate(idFact)
+
 	httputil.OK(w, r)
 }
 
@@ -330,6 +458,7 @@ func DeleteFact(w http.ResponseWriter, r *http.Request) {
 //	@Param			nhit			query	int		false	"Hit per page"
 //	@Param			offset			query	int		false	"Offset number"
 //	@Param			placeholders	query	string	false	"Placeholders (format: key1:value1,key2:value2)"
+//	@Param			factParameters	query	string	false	"Declared fact parameters, url encoded (format: key1=value1&key2=value2)"
 //	@Param			debug			query	string	false	"Debug true/false"
 //	@Security		Bearer
 //	@Security		ApiKeyAuth
@@ -369,6 +498,16 @@ func ExecuteFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	factParameters, err := ParseFactParameters(r.URL.Query().Get("factParameters"))
+	if err != nil {
+		zap.L().Warn("Parse input factParameters", zap.Error(err), zap.String("raw factParameters", r.URL.Query().Get("factParameters")))
+		httputil.Error(w, r, httputil.ErrAPIParsingKeyValue, err)
+		return
+	}
+	for key, param := range factParameters {
+		placeholders[key] = param
+	}
+
 	byName := false
 	_byName := r.URL.Query().Get("byName")
 	if _byName == "true" {
@@ -390,6 +529,13 @@ func ExecuteFact(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	placeholders, err = fact.ResolveParameters(f.ID, placeholders)
+	if err != nil {
+		zap.L().Warn("Invalid fact parameters", zap.Int64("factID", f.ID), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+		return
+	}
+
 	data, err := fact.ExecuteFact(t, f, 0, 0, placeholders, nhit, offset, false)
 	if err != nil {
 		zap.L().Error("Cannot execute fact", zap.Error(err))
@@ -398,10 +544,10 @@ func ExecuteFact(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if data.Aggregates != nil {
-		pluginBaseline, err := baseline.P()
+		baselineService, err := baseline.S()
 		if err == nil {
 			// value, err := pluginBaseline.Baseline.GetBaselineValue(0, f.ID, situationID, situationInstanceID, t)
-			values, err := pluginBaseline.BaselineService.GetBaselineValues(-1, f.ID, 0, 0, t)
+			values, err := baselineService.GetBaselineValues(-1, f.ID, 0, 0, t)
 			if err != nil {
 				zap.L().Error("Cannot fetch fact baselines", zap.Int64("id", f.ID), zap.Error(err))
 			}
@@ -535,6 +681,13 @@ func ExecuteFactOrGetHits(w http.ResponseWriter, r *http.Request) {
 
 	t := time.Now().Truncate(1 * time.Second).UTC()
 
+	placeholders, err = fact.ResolveParameters(f.ID, placeholders)
+	if err != nil {
+		zap.L().Warn("Invalid fact parameters", zap.Int64("factID", f.ID), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+		return
+	}
+
 	data, err = fact.ExecuteFact(t, f, 0, 0, placeholders, request.Nhit, request.Offset, !*request.Debug)
 	if err != nil {
 		zap.L().Error("Cannot execute fact", zap.Error(err))
@@ -543,9 +696,9 @@ func ExecuteFactOrGetHits(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !request.HitsOnly && data.Aggregates != nil {
-		pluginBaseline, err := baseline.P()
+		baselineService, err := baseline.S()
 		if err == nil {
-			values, err := pluginBaseline.BaselineService.GetBaselineValues(-1, f.ID, 0, 0, t)
+			values, err := baselineService.GetBaselineValues(-1, f.ID, 0, 0, t)
 			if err != nil {
 				zap.L().Error("Cannot fetch fact baselines", zap.Int64("id", f.ID), zap.Error(err))
 			}
@@ -634,6 +787,13 @@ func BuildAndExecuteFact(w http.ResponseWriter, r *http.Request) {
 		zap.L().Debug("Debugging fact", zap.Any("newFact", newFact))
 	}
 
+	placeholders, err = fact.ResolveParameters(newFact.ID, placeholders)
+	if err != nil {
+		zap.L().Warn("Invalid fact parameters", zap.Int64("factID", newFact.ID), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+		return
+	}
+
 	item, err := fact.ExecuteFact(t, newFact, 0, 0, placeholders, nhit, offset, false)
 	if err != nil {
 		zap.L().Error("Cannot execute fact", zap.Error(err))
@@ -805,6 +965,13 @@ func GetFactHits(w http.ResponseWriter, r *http.Request) {
 	// Change the behaviour of the Fact
 	f.Intent.Operator = engine.Select
 
+	placeholders, err = fact.ResolveParameters(f.ID, placeholders)
+	if err != nil {
+		zap.L().Warn("Invalid fact parameters", zap.Int64("factID", f.ID), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+		return
+	}
+
 	data, err = fact.ExecuteFact(t, f, 0, 0, placeholders, nhit, offset, false)
 	if err != nil {
 		zap.L().Error("Cannot execute fact", zap.Error(err))
@@ -139,6 +139,34 @@ func TestPutFact(t *testing.T) {
 	}
 }
 
+func TestFactCostParameters(t *testing.T) {
+	initGlobalRepository()
+	err := fact.R().SetParameterDefinitions(1, fact.ParameterDefinitions{
+		{Name: "country", Type: fact.ParameterTypeString, Required: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The definitions of the request are used instead of the stored ones
+	parameters, missing, err := factCostParameters(engine.Fact{ID: 1}, fact.ParameterDefinitions{
+		{Name: "country", Type: fact.ParameterTypeString, Required: true, Default: "FR"},
+	})
+	if err != nil || len(missing) != 0 || parameters["country"] != "FR" {
+		t.Errorf("expected the default value of the request definitions, got %v %v (%v)", parameters, missing, err)
+	}
+
+	// The stored definitions are used if the request doesn't provide any
+	if _, missing, err = factCostParameters(engine.Fact{ID: 1}, nil); err != nil || len(missing) != 1 {
+		t.Errorf("expected the required parameter without default to be reported, got %v (%v)", missing, err)
+	}
+
+	// A new fact without definitions has no parameters
+	if parameters, missing, err = factCostParameters(engine.Fact{}, nil); err != nil || len(parameters) != 0 || len(missing) != 0 {
+		t.Errorf("expected no parameters, got %v %v (%v)", parameters, missing, err)
+	}
+}
+
 func TestPutFactInvalidBody(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping DB test in short mode")
@@ -168,6 +196,7 @@ func TestDeleteFact(t *testing.T) {
 		t.Skip("Skipping DB test in short mode")
 	}
 	initGlobalRepository()
+	defer initGlobalUsageRepositories(usageSituations{}, nil)()
 	user := users.UserWithPermissions{Permissions: []permissions.Permission{permissions.New(permissions.TypeFact, "1", permissions.ActionDelete)}}
 	rr := tests.BuildTestHandler(t, "DELETE", "/fact/1", ``, "/fact/{id}", DeleteFact, user)
 	tests.CheckTestHandler(t, rr, http.StatusOK, ``)
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/fact"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/situation"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+	"go.uber.org/zap"
+)
+
+// factDefinition is a fact definition sent along its optional parameter definitions
+type factDefinition struct {
+	engine.Fact
+	ParameterDefinitions fact.ParameterDefinitions `json:"parameterDefinitions"`
+}
+
+// decodeFactDefinition decodes a fact definition and its optional parameter definitions from the request body
+// The returned parameter definitions are nil if the key "parameterDefinitions" is not provided
+func decodeFactDefinition(r *http.Request) (engine.Fact, fact.ParameterDefinitions, error) {
+	var definition factDefinition
+	if err := json.NewDecoder(r.Body).Decode(&definition); err != nil {
+		return engine.Fact{}, nil, err
+	}
+	return definition.Fact, definition.ParameterDefinitions, nil
+}
+
+// GetFactParameters godoc
+//
+//	@Id				GetFactParameters
+//
+//	@Summary		Get the parameter definitions of a fact
+//	@Description	Get the typed parameters (name, type, default, allowed values) declared on a fact
+//	@Tags			Facts
+//	@Produce		json
+//	@Param			id	path	int	true	"Fact ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		fact.ParameterDefinition	"Parameter definitions"
+//	@Failure		400	{object}	httputil.APIError			"Bad Request"
+//	@Failure		500	{object}	httputil.APIError			"Internal Server Error"
+//	@Router			/engine/facts/{id}/parameters [get]
+func GetFactParameters(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	defs, err := fact.R().GetParameterDefinitions(idFact)
+	if err != nil {
+		zap.L().Error("Cannot retrieve fact parameter definitions", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, defs)
+}
+
+// PutFactParameters godoc
+//
+//	@Id				PutFactParameters
+//
+//	@Summary		Replace the parameter definitions of a fact
+//	@Description	Replace the typed parameters (name, type, default, allowed values) declared on a fact
+//	@Tags			Facts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	int							true	"Fact ID"
+//	@Param			parameters	body	[]fact.ParameterDefinition	true	"Parameter definitions (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		fact.ParameterDefinition	"Parameter definitions"
+//	@Failure		400	{object}	httputil.APIError			"Bad Request"
+//	@Failure		500	{object}	httputil.APIError			"Internal Server Error"
+//	@Router			/engine/facts/{id}/parameters [put]
+func PutFactParameters(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var defs fact.ParameterDefinitions
+	err = json.NewDecoder(r.Body).Decode(&defs)
+	if err != nil {
+		zap.L().Warn("Fact parameter definitions json decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if ok, err := defs.IsValid(); !ok {
+		zap.L().Warn("Fact parameter definitions are invalid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	_, found, err := fact.R().Get(idFact)
+	if err != nil {
+		zap.L().Error("Cannot retrieve fact", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("fact does not exists", zap.Int64("factID", idFact))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, fmt.Errorf("fact not found with id %d", idFact))
+		return
+	}
+
+	err = fact.R().SetParameterDefinitions(idFact, defs)
+	if err != nil {
+		zap.L().Error("Error while updating the fact parameter definitions", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+
+	fact.Cache().Invalidate(idFact)
+
+	httputil.JSON(w, r, EnsureSlice(defs))
+}
+
+// GetFactParameterBindings godoc
+//
+//	@Id				GetFactParameterBindings
+//
+//	@Summary		Get the parameter values bound to a fact
+//	@Description	Report which situations and template instances bind which values to the parameters declared on a fact
+//	@Tags			Facts
+//	@Produce		json
+//	@Param			id	path	int	true	"Fact ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		fact.ParameterBinding	"Parameter bindings"
+//	@Failure		400	{object}	httputil.APIError		"Bad Request"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/facts/{id}/parameters/bindings [get]
+func GetFactParameterBindings(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	defs, err := fact.R().GetParameterDefinitions(idFact)
+	if err != nil {
+		zap.L().Error("Cannot retrieve fact parameter definitions", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	bindings, err := getFactParameterBindings(idFact, defs)
+	if err != nil {
+		zap.L().Error("Cannot build fact parameter bindings", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, bindings)
+}
+
+// getFactParameterBindings reports, for each situation and template instance using the fact,
+// which values are bound to each declared parameter
+func getFactParameterBindings(factID int64, defs fact.ParameterDefinitions) ([]fact.ParameterBinding, error) {
+	situations, err := situation.R().GetSituationsByFactID(factID, false, time.Now(), true)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(situations, func(i, j int) bool { return situations[i].ID < situations[j].ID })
+
+	bindings := make([]fact.ParameterBinding, 0)
+	for _, s := range situations {
+		if !s.IsTemplate {
+			bindings = append(bindings, buildFactParameterBinding(defs, s, situation.TemplateInstance{}))
+			continue
+		}
+
+		instances, err := situation.R().GetAllTemplateInstances(s.ID, true)
+		if err != nil {
+			return nil, err
+		}
+		instanceIDs := make([]int64, 0, len(instances))
+		for instanceID := range instances {
+			instanceIDs = append(instanceIDs, instanceID)
+		}
+		sort.Slice(instanceIDs, func(i, j int) bool { return instanceIDs[i] < instanceIDs[j] })
+		for _, instanceID := range instanceIDs {
+			bindings = append(bindings, buildFactParameterBinding(defs, s, instances[instanceID]))
+		}
+	}
+	return bindings, nil
+}
+
+func buildFactParameterBinding(defs fact.ParameterDefinitions, s situation.Situation, ti situation.TemplateInstance) fact.ParameterBinding {
+	parameters := make(map[string]interface{})
+	for key, value := range s.Parameters {
+		parameters[key] = value
+	}
+	for key, value := range ti.Parameters {
+		parameters[key] = value
+	}
+
+	values, defaulted, errs := defs.Bind(parameters)
+	return fact.ParameterBinding{
+		SituationID:           s.ID,
+		SituationName:         s.Name,
+		SituationInstanceID:   ti.ID,
+		SituationInstanceName: ti.Name,
+		Values:                values,
+		Defaulted:             defaulted,
+		Errors:                errs,
+	}
+}
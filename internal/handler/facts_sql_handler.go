@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/fact"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// GetFactSQLConnections godoc
+//
+//	@Id				GetFactSQLConnections
+//
+//	@Summary		Get the SQL connections usable by SQL facts
+//	@Description	Get the names of the configured SQL connections usable by SQL facts
+//	@Tags			Facts
+//	@Produce		json
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		string				"Connection names"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Router			/engine/facts/sql/connections [get]
+func GetFactSQLConnections(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, permissions.All, permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	httputil.JSON(w, r, fact.GetSQLConnectionNames())
+}
+
+// GetFactSQL godoc
+//
+//	@Id				GetFactSQL
+//
+//	@Summary		Get the SQL definition of a fact
+//	@Description	Get the read-only SQL query executed instead of an elasticsearch query for a SQL fact
+//	@Tags			Facts
+//	@Produce		json
+//	@Param			id	path	int	true	"Fact ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	fact.SQLDefinition	"SQL definition"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Router			/engine/facts/{id}/sql [get]
+func GetFactSQL(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	def, found, err := fact.R().GetSQLDefinition(idFact)
+	if err != nil {
+		zap.L().Error("Cannot retrieve fact sql definition", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, fmt.Errorf("fact %d is not a sql fact", idFact))
+		return
+	}
+
+	httputil.JSON(w, r, def)
+}
+
+// PutFactSQL godoc
+//
+//	@Id				PutFactSQL
+//
+//	@Summary		Set the SQL definition of a fact
+//	@Description	Turn a fact into a SQL fact, executing a read-only SQL query instead of an elasticsearch query
+//	@Tags			Facts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	int					true	"Fact ID"
+//	@Param			sql	body	fact.SQLDefinition	true	"SQL definition (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	fact.SQLDefinition	"SQL definition"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/facts/{id}/sql [put]
+func PutFactSQL(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var def fact.SQLDefinition
+	err = json.NewDecoder(r.Body).Decode(&def)
+	if err != nil {
+		zap.L().Warn("Fact sql definition json decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if ok, err := def.IsValid(); !ok {
+		zap.L().Warn("Fact sql definition is invalid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+	if _, ok := fact.GetSQLConnection(def.Connection); !ok {
+		zap.L().Warn("Fact sql connection not found", zap.String("connection", def.Connection))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, fmt.Errorf("sql connection %s not found", def.Connection))
+		return
+	}
+
+	_, found, err := fact.R().Get(idFact)
+	if err != nil {
+		zap.L().Error("Cannot retrieve fact", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("fact does not exists", zap.Int64("factID", idFact))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, fmt.Errorf("fact not found with id %d", idFact))
+		return
+	}
+
+	err = fact.R().SetSQLDefinition(idFact, &def)
+	if err != nil {
+		zap.L().Error("Error while updating the fact sql definition", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+
+	fact.SetSQLDefinitionInMemory(idFact, &def)
+	fact.Cache().Invalidate(idFact)
+
+	httputil.JSON(w, r, def)
+}
+
+// DeleteFactSQL godoc
+//
+//	@Id				DeleteFactSQL
+//
+//	@Summary		Remove the SQL definition of a fact
+//	@Description	Remove the SQL definition of a fact, which is executed against elasticsearch again
+//	@Tags			Facts
+//	@Produce		json
+//	@Param			id	path	int	true	"Fact ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/facts/{id}/sql [delete]
+func DeleteFactSQL(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	err = fact.R().SetSQLDefinition(idFact, nil)
+	if err != nil {
+		zap.L().Error("Error while deleting the fact sql definition", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+
+	fact.SetSQLDefinitionInMemory(idFact, nil)
+	fact.Cache().Invalidate(idFact)
+
+	httputil.OK(w, r)
+}
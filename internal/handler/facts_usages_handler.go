@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/rule"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/scheduler"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/fact"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/situation"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+	"go.uber.org/zap"
+)
+
+// GetFactUsages godoc
+//
+//	@Id				GetFactUsages
+//
+//	@Summary		Get the resources using a fact
+//	@Description	List the situations, template instances, expression facts, rules and schedules depending on a fact. Exports are not listed, as no export template bound to a fact is stored (exports are one-off requests).
+//	@Tags			Facts
+//	@Produce		json
+//	@Param			id	path	int	true	"Fact ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	fact.Usages			"Fact usages"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/facts/{id}/usages [get]
+func GetFactUsages(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idFact, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing fact id", zap.String("factID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeFact, strconv.FormatInt(idFact, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	f, found, err := fact.R().Get(idFact)
+	if err != nil {
+		zap.L().Error("Cannot retrieve fact", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("fact does not exists", zap.Int64("factID", idFact))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, err)
+		return
+	}
+
+	usages, err := getFactUsages(f)
+	if err != nil {
+		zap.L().Error("Cannot retrieve fact usages", zap.Int64("factID", idFact), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, usages)
+}
+
+// getFactUsages lists every resource depending on a fact
+func getFactUsages(f engine.Fact) (fact.Usages, error) {
+	usages := fact.NewUsages(f.ID, f.Name)
+	references := fact.NameMatcher(f.Name)
+
+	situations, err := situation.R().GetSituationsByFactID(f.ID, false, time.Now())
+	if err != nil {
+		return usages, err
+	}
+	sort.Slice(situations, func(i, j int) bool { return situations[i].ID < situations[j].ID })
+	for _, s := range situations {
+		usages.Situations = append(usages.Situations, fact.Usage{ID: s.ID, Name: s.Name})
+		if !s.IsTemplate {
+			continue
+		}
+
+		instances, err := situation.R().GetAllTemplateInstances(s.ID)
+		if err != nil {
+			return usages, err
+		}
+		for _, instance := range instances {
+			usages.TemplateInstances = append(usages.TemplateInstances, fact.TemplateInstanceUsage{
+				ID:            instance.ID,
+				Name:          instance.Name,
+				SituationID:   s.ID,
+				SituationName: s.Name,
+			})
+		}
+	}
+	sort.Slice(usages.TemplateInstances, func(i, j int) bool {
+		return usages.TemplateInstances[i].ID < usages.TemplateInstances[j].ID
+	})
+
+	// Expression facts can reference facts from any situation by their name
+	allSituations, err := situation.R().GetAll()
+	if err != nil {
+		return usages, err
+	}
+	for _, s := range allSituations {
+		for _, expressionFact := range s.ExpressionFacts {
+			if references(expressionFact.Expression) {
+				usages.ExpressionFacts = append(usages.ExpressionFacts, fact.ExpressionFactUsage{
+					SituationID:   s.ID,
+					SituationName: s.Name,
+					Name:          expressionFact.Name,
+					Expression:    expressionFact.Expression,
+				})
+			}
+		}
+	}
+	sort.SliceStable(usages.ExpressionFacts, func(i, j int) bool {
+		return usages.ExpressionFacts[i].SituationID < usages.ExpressionFacts[j].SituationID
+	})
+
+	rules, err := rule.R().GetAll()
+	if err != nil {
+		return usages, err
+	}
+	for _, rl := range rules {
+		for _, c := range rl.Cases {
+			condition, err := json.Marshal(c.Condition)
+			if err != nil {
+				return usages, err
+			}
+			if references(string(condition)) {
+				usages.Rules = append(usages.Rules, fact.Usage{ID: rl.ID, Name: rl.Name})
+				break
+			}
+		}
+	}
+	sort.Slice(usages.Rules, func(i, j int) bool { return usages.Rules[i].ID < usages.Rules[j].ID })
+
+	schedules, err := scheduler.GetSchedulesByFactID(f.ID)
+	if err != nil {
+		return usages, err
+	}
+	for _, schedule := range schedules {
+		usages.Schedules = append(usages.Schedules, fact.Usage{ID: schedule.ID, Name: schedule.Name})
+	}
+
+	return usages, nil
+}
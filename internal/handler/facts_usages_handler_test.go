@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/rule"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/scheduler"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/tests"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/fact"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/users"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/situation"
+)
+
+// usageSituations is an in-memory situation repository, with the template instances of each situation
+type usageSituations struct {
+	situation.Repository
+	situations map[int64]situation.Situation
+	instances  map[int64]map[int64]situation.TemplateInstance
+}
+
+func (r usageSituations) GetAll(parseGlobalVariables ...bool) (map[int64]situation.Situation, error) {
+	return r.situations, nil
+}
+
+func (r usageSituations) GetSituationsByFactID(factID int64, ignoreIsObject bool, ts time.Time, parseGlobalVariables ...bool) ([]situation.Situation, error) {
+	situations := make([]situation.Situation, 0)
+	for _, s := range r.situations {
+		for _, id := range s.Facts {
+			if id == factID {
+				situations = append(situations, s)
+				break
+			}
+		}
+	}
+	return situations, nil
+}
+
+func (r usageSituations) GetAllTemplateInstances(situationID int64, parseParameters ...bool) (map[int64]situation.TemplateInstance, error) {
+	return r.instances[situationID], nil
+}
+
+type usageRules struct {
+	rule.Repository
+}
+
+func (r usageRules) GetAll() (map[int64]rule.Rule, error) {
+	return map[int64]rule.Rule{}, nil
+}
+
+type usageSchedules struct {
+	scheduler.Repository
+	schedules map[int64]scheduler.InternalSchedule
+}
+
+func (r usageSchedules) GetAll() (map[int64]scheduler.InternalSchedule, error) {
+	return r.schedules, nil
+}
+
+// initGlobalUsageRepositories replaces the repositories of the resources using the facts, and returns their restore function
+func initGlobalUsageRepositories(situations usageSituations, schedules map[int64]scheduler.InternalSchedule) func() {
+	restoreSituations := situation.ReplaceGlobals(situations)
+	restoreRules := rule.ReplaceGlobals(usageRules{})
+	restoreSchedules := scheduler.ReplaceGlobalRepository(usageSchedules{schedules: schedules})
+	return func() {
+		restoreSituations()
+		restoreRules()
+		restoreSchedules()
+	}
+}
+
+// initGlobalUsages makes the fact test1 used by a situation template and its instance, an expression fact and a schedule
+func initGlobalUsages() func() {
+	return initGlobalUsageRepositories(usageSituations{
+		situations: map[int64]situation.Situation{
+			10: {ID: 10, Name: "situation10", Facts: []int64{1}, IsTemplate: true},
+			11: {ID: 11, Name: "situation11", Facts: []int64{2}, ExpressionFacts: []situation.ExpressionFact{
+				{Name: "ratio", Expression: "test1 / test2"},
+			}},
+		},
+		instances: map[int64]map[int64]situation.TemplateInstance{
+			10: {100: {ID: 100, Name: "instance100", SituationID: 10}},
+		},
+	}, map[int64]scheduler.InternalSchedule{
+		20: {ID: 20, Name: "schedule20", JobType: "fact", Job: scheduler.FactCalculationJob{FactIds: []int64{1, 2}}},
+	})
+}
+
+func TestGetFactUsages(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB test in short mode")
+	}
+	initGlobalRepository()
+	defer initGlobalUsages()()
+	user := users.UserWithPermissions{Permissions: []permissions.Permission{permissions.New(permissions.TypeFact, "1", permissions.ActionGet)}}
+	rr := tests.BuildTestHandler(t, "GET", "/fact/1/usages", ``, "/fact/{id}/usages", GetFactUsages, user)
+	tests.CheckTestHandler(t, rr, http.StatusOK,
+		`{"factId":1,"factName":"test1","situations":[{"id":10,"name":"situation10"}],`+
+			`"templateInstances":[{"id":100,"name":"instance100","situationId":10,"situationName":"situation10"}],`+
+			`"expressionFacts":[{"situationId":11,"situationName":"situation11","name":"ratio","expression":"test1 / test2"}],`+
+			`"rules":[],"schedules":[{"id":20,"name":"schedule20"}]}`+"\n")
+}
+
+func TestDeleteFactInUse(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB test in short mode")
+	}
+	initGlobalRepository()
+	defer initGlobalUsages()()
+	user := users.UserWithPermissions{Permissions: []permissions.Permission{permissions.New(permissions.TypeFact, "1", permissions.ActionDelete)}}
+	rr := tests.BuildTestHandler(t, "DELETE", "/fact/1", ``, "/fact/{id}", DeleteFact, user)
+	tests.CheckTestHandler(t, rr, http.StatusConflict, `{"requestID":"","status":409,"type":"ResourceError","code":2005,"message":"Resource is still in use and cannot be deleted"}`+"\n")
+
+	if _, found, _ := fact.R().Get(1); !found {
+		t.Error("Fact test1 should not have been deleted")
+	}
+}
+
+func TestDeleteFactInUseForced(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping DB test in short mode")
+	}
+	initGlobalRepository()
+	defer initGlobalUsages()()
+	user := users.UserWithPermissions{Permissions: []permissions.Permission{permissions.New(permissions.TypeFact, "1", permissions.ActionDelete)}}
+	rr := tests.BuildTestHandler(t, "DELETE", "/fact/1?force=true", ``, "/fact/{id}", DeleteFact, user)
+	tests.CheckTestHandler(t, rr, http.StatusOK, ``)
+
+	if _, found, _ := fact.R().Get(1); found {
+		t.Error("Fact test1 should have been deleted")
+	}
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/history"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// GetHistoryArchives godoc
+//
+//	@Id				GetHistoryArchives
+//
+//	@Summary		List the history archives
+//	@Description	List the days of history archived on disk before their purge, and whether they are rehydrated
+//	@Tags			History_archives
+//	@Produce		json
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		history.HistoryArchive	"list of archived days"
+//	@Failure		403	{object}	httputil.APIError		"Forbidden"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/history/archives [get]
+func GetHistoryArchives(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituation, permissions.All, permissions.ActionSearch)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	archives, err := history.S().ListArchives()
+	if err != nil {
+		zap.L().Error("Cannot list the history archives", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIProcessError, err)
+		return
+	}
+
+	httputil.JSON(w, r, archives)
+}
+
+// RehydrateHistoryArchives godoc
+//
+//	@Id				RehydrateHistoryArchives
+//
+//	@Summary		Rehydrate history archives
+//	@Description	Load the archived days of a range in the read-only archive tables, where they can be queried with /engine/history/archives/search
+//	@Tags			History_archives
+//	@Accept			json
+//	@Produce		json
+//	@Param			range	body	history.HistoryArchiveRange	true	"range of days (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	map[string]int		"number of rehydrated days"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/history/archives/rehydrate [post]
+func RehydrateHistoryArchives(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituation, permissions.All, permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var archiveRange history.HistoryArchiveRange
+	if err := json.NewDecoder(r.Body).Decode(&archiveRange); err != nil {
+		zap.L().Warn("History archive range json decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+	if err := archiveRange.IsValid(); err != nil {
+		zap.L().Warn("History archive range is invalid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	rehydrated, err := history.S().RehydrateArchives(archiveRange.From, archiveRange.To)
+	if err != nil {
+		zap.L().Error("Cannot rehydrate the history archives", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIProcessError, err)
+		return
+	}
+
+	httputil.JSON(w, r, map[string]int{"rehydrated": rehydrated})
+}
+
+// EvictHistoryArchives godoc
+//
+//	@Id				EvictHistoryArchives
+//
+//	@Summary		Evict rehydrated history archives
+//	@Description	Remove the rehydrated days of a range from the archive tables, the archive files are kept
+//	@Tags			History_archives
+//	@Produce		json
+//	@Param			from	query	string	true	"time.Time"
+//	@Param			to		query	string	true	"time.Time"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	map[string]int64	"number of evicted rows"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/history/archives/rehydrate [delete]
+func EvictHistoryArchives(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituation, permissions.All, permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	from, err := ParseTime(r.URL.Query().Get("from"))
+	if err != nil {
+		zap.L().Warn("Parse input from", zap.Error(err), zap.String("rawfrom", r.URL.Query().Get("from")))
+		httputil.Error(w, r, httputil.ErrAPIParsingDateTime, err)
+		return
+	}
+	to, err := ParseTime(r.URL.Query().Get("to"))
+	if err != nil {
+		zap.L().Warn("Parse input to", zap.Error(err), zap.String("rawto", r.URL.Query().Get("to")))
+		httputil.Error(w, r, httputil.ErrAPIParsingDateTime, err)
+		return
+	}
+	if err = (history.HistoryArchiveRange{From: from, To: to}).IsValid(); err != nil {
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+		return
+	}
+
+	evicted, err := history.S().EvictArchives(from, to)
+	if err != nil {
+		zap.L().Error("Cannot evict the history archives", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, map[string]int64{"evicted": evicted})
+}
+
+// SearchHistoryArchives godoc
+//
+//	@Id				SearchHistoryArchives
+//
+//	@Summary		query the rehydrated history archives
+//	@Description	query the rehydrated history archives, with the same options as /engine/search
+//	@Tags			History_archives
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body	search.Query	true	"query (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	search.QueryPage	"query result"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/history/archives/search [post]
+func SearchHistoryArchives(w http.ResponseWriter, r *http.Request) {
+	var query search.Query
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		zap.L().Warn("Search query json decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if !checkSearchSituations(w, r, query.Situations) {
+		return
+	}
+
+	page, err := history.S().SearchArchives(query)
+	if err != nil {
+		zap.L().Error("Cannot execute archive search query", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, page)
+}
@@ -2,8 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
 
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/history"
@@ -60,6 +63,7 @@ func GetFactResultForTodayByCriteria(w http.ResponseWriter, r *http.Request) {
 //	@Summary		Get Fact Result by Date Criteria
 //
 //	@Description	Fetches the result of a historical fact based on provided criteria within specified date range. The dates should be in the format "2006-01-02 15:04:05".
+//	@Description	With an optional downSampling, the results are aggregated by time bucket (read from the history rollups when they cover the range).
 //	@Tags			Facts_history
 //	@Accept			json
 //	@Produce		json
@@ -86,7 +90,7 @@ func GetFactResultByDateCriteria(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := history.S().HistoryFactsQuerier.GetFactResultByDate(param)
+	result, err := history.S().GetFactResultByDate(param)
 	if err != nil {
 		zap.L().Warn("error getting fact history by date", zap.Error(err))
 		httputil.Error(w, r, httputil.ErrAPIProcessError, err)
@@ -147,6 +151,7 @@ func GetFactExprResultForTodayByCriteria(w http.ResponseWriter, r *http.Request)
 //	@Description	Fetches the result of a historical fact expression based on provided criteria within specified date range.
 //
 // The dates should be in the format "2006-01-02 15:04:05".
+// With an optional downSampling, the values are aggregated by time bucket (read from the history rollups when they cover the range).
 //
 //	@Tags			situation_history
 //	@Accept			json
@@ -174,7 +179,7 @@ func GetFactExprResultByDateCriteria(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := history.S().HistorySituationsQuerier.GetFactExprResultByDate(param)
+	result, err := history.S().GetFactExprResultByDate(param)
 	if err != nil {
 		zap.L().Warn("error getting fact expression history by date", zap.Error(err))
 		httputil.Error(w, r, httputil.ErrAPIProcessError, err)
@@ -183,3 +188,50 @@ func GetFactExprResultByDateCriteria(w http.ResponseWriter, r *http.Request) {
 
 	httputil.JSON(w, r, result)
 }
+
+// CompareHistory godoc
+//
+//	@Id				CompareHistory
+//
+//	@Summary		Compare the history of a situation instance between two time windows
+//	@Description	Down-samples both windows to the latest record of each time bucket, aligns the buckets by their offset from the window start,
+//	@Description	and returns the deltas and percent changes of each fact and expression fact.
+//	@Tags			situation_history
+//	@Accept			json
+//	@Produce		json
+//	@Param			comparison	body	history.HistoryComparison	true	"situation instance, time windows and granularity (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		history.HistoryComparisonPoint	"aligned series"
+//	@Failure		400	{object}	httputil.APIError				"Bad Request"
+//	@Failure		403	{object}	httputil.APIError				"Forbidden"
+//	@Failure		500	{object}	httputil.APIError				"Internal Server Error"
+//	@Router			/engine/history/compare [post]
+func CompareHistory(w http.ResponseWriter, r *http.Request) {
+	var comparison history.HistoryComparison
+	if err := json.NewDecoder(r.Body).Decode(&comparison); err != nil {
+		zap.L().Warn("History comparison json decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+	if ok, err := comparison.IsValid(); !ok {
+		zap.L().Warn("History comparison is invalid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituation, strconv.FormatInt(comparison.SituationID, 10), permissions.ActionSearch)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	result, err := history.S().Compare(comparison)
+	if err != nil {
+		zap.L().Error("Cannot compare the history", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIProcessError, err)
+		return
+	}
+
+	httputil.JSON(w, r, result)
+}
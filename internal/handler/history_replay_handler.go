@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/scheduler"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// ReplayHistory godoc
+//
+//	@Id				ReplayHistory
+//
+//	@Summary		Replay the evaluations of situations over a past range
+//	@Description	Re-run the facts, expression facts and rules of the situation history of a range in an in-memory sandbox (7 days at most).
+//	@Description	The history is not updated and no task is performed, the replay returns the issues which would have been created with the current rules.
+//	@Tags			History
+//	@Accept			json
+//	@Produce		json
+//	@Param			replay	body	scheduler.FactReplay	true	"replay definition (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	scheduler.ReplayResult	"replayed evaluations and issues"
+//	@Failure		400	{object}	httputil.APIError		"Bad Request"
+//	@Failure		403	{object}	httputil.APIError		"Forbidden"
+//	@Failure		404	{object}	httputil.APIError		"Not Found"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/history/replay [post]
+func ReplayHistory(w http.ResponseWriter, r *http.Request) {
+	var replay scheduler.FactReplay
+	if err := json.NewDecoder(r.Body).Decode(&replay); err != nil {
+		zap.L().Warn("Replay json decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+	if ok, err := replay.IsValid(); !ok {
+		zap.L().Warn("Replay is invalid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	if !checkSearchSituations(w, r, replay.Situations) {
+		return
+	}
+	userCtx, _ := GetUserFromContext(r)
+	for _, ruleID := range replay.RuleIDs {
+		if !userCtx.HasPermission(permissions.New(permissions.TypeRule, strconv.FormatInt(ruleID, 10), permissions.ActionGet)) {
+			httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+			return
+		}
+	}
+
+	result, err := replay.Run(r.Context())
+	if err != nil {
+		zap.L().Error("Cannot replay the history", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIProcessError, err)
+		return
+	}
+
+	httputil.JSON(w, r, result)
+}
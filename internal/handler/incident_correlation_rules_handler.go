@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/incident"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// GetIncidentCorrelationRules godoc
+//
+//	@Id				GetIncidentCorrelationRules
+//
+//	@Summary		Get all incident correlation rules
+//	@Description	Get all incident correlation rules
+//	@Tags			Incidents
+//	@Produce		json
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		model.IncidentCorrelationRule	"list of incident correlation rules"
+//	@Failure		403	{object}	httputil.APIError				"Forbidden"
+//	@Failure		500	{object}	httputil.APIError				"Internal Server Error"
+//	@Router			/engine/incident_correlation_rules [get]
+func GetIncidentCorrelationRules(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIncident, permissions.All, permissions.ActionList)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	rules, err := incident.R().GetAllRules()
+	if err != nil {
+		zap.L().Error("Error getting incident correlation rules", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, rules)
+}
+
+// GetIncidentCorrelationRule godoc
+//
+//	@Id				GetIncidentCorrelationRule
+//
+//	@Summary		Get an incident correlation rule
+//	@Description	Get an incident correlation rule
+//	@Tags			Incidents
+//	@Produce		json
+//	@Param			id	path	int	true	"Incident correlation rule ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IncidentCorrelationRule	"incident correlation rule"
+//	@Failure		400	{object}	httputil.APIError				"Bad Request"
+//	@Failure		403	{object}	httputil.APIError				"Forbidden"
+//	@Failure		404	{object}	httputil.APIError				"Not Found"
+//	@Failure		500	{object}	httputil.APIError				"Internal Server Error"
+//	@Router			/engine/incident_correlation_rules/{id} [get]
+func GetIncidentCorrelationRule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idRule, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing incident correlation rule id", zap.String("ruleID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIncident, strconv.FormatInt(idRule, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	rule, found, err := incident.R().GetRule(idRule)
+	if err != nil {
+		zap.L().Error("Cannot get incident correlation rule", zap.Int64("ruleID", idRule), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("Incident correlation rule does not exists", zap.Int64("ruleID", idRule))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("incident correlation rule not found"))
+		return
+	}
+
+	httputil.JSON(w, r, rule)
+}
+
+// PostIncidentCorrelationRule godoc
+//
+//	@Id				PostIncidentCorrelationRule
+//
+//	@Summary		Create a new incident correlation rule
+//	@Description	Create a new incident correlation rule, the enabled rules are applied by order of ID on each new issue
+//	@Tags			Incidents
+//	@Accept			json
+//	@Produce		json
+//	@Param			rule	body	model.IncidentCorrelationRule	true	"Incident correlation rule"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IncidentCorrelationRule	"created incident correlation rule with ID"
+//	@Failure		400	{object}	httputil.APIError				"Bad Request"
+//	@Failure		403	{object}	httputil.APIError				"Forbidden"
+//	@Failure		500	{object}	httputil.APIError				"Internal Server Error"
+//	@Router			/engine/incident_correlation_rules [post]
+func PostIncidentCorrelationRule(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIncident, permissions.All, permissions.ActionCreate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var rule model.IncidentCorrelationRule
+	err := json.NewDecoder(r.Body).Decode(&rule)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling incident correlation rule", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if ok, err := rule.IsValid(); !ok {
+		zap.L().Warn("Incident correlation rule is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	id, err := incident.R().CreateRule(rule)
+	if err != nil {
+		zap.L().Error("Cannot create incident correlation rule", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBInsertFailed, err)
+		return
+	}
+
+	rule.ID = id
+	httputil.JSON(w, r, rule)
+}
+
+// PutIncidentCorrelationRule godoc
+//
+//	@Id				PutIncidentCorrelationRule
+//
+//	@Summary		Update an incident correlation rule
+//	@Description	Update an incident correlation rule
+//	@Tags			Incidents
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	int								true	"Incident correlation rule ID"
+//	@Param			rule	body	model.IncidentCorrelationRule	true	"Incident correlation rule"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IncidentCorrelationRule	"updated incident correlation rule"
+//	@Failure		400	{object}	httputil.APIError				"Bad Request"
+//	@Failure		403	{object}	httputil.APIError				"Forbidden"
+//	@Failure		500	{object}	httputil.APIError				"Internal Server Error"
+//	@Router			/engine/incident_correlation_rules/{id} [put]
+func PutIncidentCorrelationRule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idRule, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing incident correlation rule id", zap.String("ruleID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIncident, strconv.FormatInt(idRule, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var rule model.IncidentCorrelationRule
+	err = json.NewDecoder(r.Body).Decode(&rule)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling incident correlation rule", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	rule.ID = idRule
+	if ok, err := rule.IsValid(); !ok {
+		zap.L().Warn("Incident correlation rule is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	err = incident.R().UpdateRule(rule)
+	if err != nil {
+		zap.L().Error("Cannot update incident correlation rule", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, rule)
+}
+
+// DeleteIncidentCorrelationRule godoc
+//
+//	@Id				DeleteIncidentCorrelationRule
+//
+//	@Summary		Delete an incident correlation rule, its incidents become manual incidents
+//	@Description	Delete an incident correlation rule, its incidents become manual incidents
+//	@Tags			Incidents
+//	@Param			id	path	int	true	"Incident correlation rule ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError				"Bad Request"
+//	@Failure		403	{object}	httputil.APIError				"Forbidden"
+//	@Failure		500	{object}	httputil.APIError				"Internal Server Error"
+//	@Router			/engine/incident_correlation_rules/{id} [delete]
+func DeleteIncidentCorrelationRule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idRule, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing incident correlation rule id", zap.String("ruleID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIncident, strconv.FormatInt(idRule, 10), permissions.ActionDelete)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	err = incident.R().DeleteRule(idRule)
+	if err != nil {
+		zap.L().Error("Cannot delete incident correlation rule", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+
+	httputil.OK(w, r)
+}
@@ -0,0 +1,401 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/incident"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/users"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"github.com/myrteametrics/myrtea-sdk/v5/postgres"
+	"go.uber.org/zap"
+)
+
+// GetIncidents godoc
+//
+//	@Id				GetIncidents
+//
+//	@Summary		Get the incidents
+//	@Description	Get the incidents grouping the issues, the most recent first
+//	@Tags			Incidents
+//	@Produce		json
+//	@Param			states	query	string	false	"Incident states (comma separated) (Available: open, closed)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		model.Incident		"list of incidents"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/incidents [get]
+func GetIncidents(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIncident, permissions.All, permissions.ActionList)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	states := make([]model.IncidentState, 0)
+	if statesParam := r.URL.Query().Get("states"); statesParam != "" {
+		for _, state := range strings.Split(statesParam, ",") {
+			states = append(states, model.IncidentState(state))
+		}
+	}
+
+	incidents, err := incident.R().GetByStates(states)
+	if err != nil {
+		zap.L().Error("Error getting incidents", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, incidents)
+}
+
+// GetIncident godoc
+//
+//	@Id				GetIncident
+//
+//	@Summary		Get an incident
+//	@Description	Get an incident with the IDs of its issues
+//	@Tags			Incidents
+//	@Produce		json
+//	@Param			id	path	int	true	"Incident ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.Incident		"incident"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/incidents/{id} [get]
+func GetIncident(w http.ResponseWriter, r *http.Request) {
+	inc, ok := getIncidentFromRequest(w, r, permissions.ActionGet)
+	if !ok {
+		return
+	}
+
+	httputil.JSON(w, r, inc)
+}
+
+// PostIncident godoc
+//
+//	@Id				PostIncident
+//
+//	@Summary		Create a manual incident
+//	@Description	Create a manual incident grouping some issues, the issues are removed from their previous incident
+//	@Tags			Incidents
+//	@Accept			json
+//	@Produce		json
+//	@Param			incident	body	interface{}	true	"Incident name and issues (example: {\"name\": \"outage\", \"issueIds\": [1, 2]})"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.Incident		"created incident"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/incidents [post]
+func PostIncident(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIncident, permissions.All, permissions.ActionCreate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var body struct {
+		Name     string  `json:"name"`
+		IssueIDs []int64 `json:"issueIds"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		zap.L().Warn("Body decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	id, err := explainer.CreateIncident(body.Name, body.IssueIDs)
+	if err != nil {
+		handleIncidentError(w, r, "CreateIncident", err, httputil.ErrAPIDBInsertFailed)
+		return
+	}
+
+	writeIncident(w, r, id)
+}
+
+// PostIncidentMerge godoc
+//
+//	@Id				PostIncidentMerge
+//
+//	@Summary		Merge incidents
+//	@Description	Move the issues of some open incidents into an open incident, the merged incidents are deleted
+//	@Tags			Incidents
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	int			true	"Incident ID"
+//	@Param			incidents	body	interface{}	true	"Incidents to merge (example: {\"incidentIds\": [2, 3]})"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.Incident		"merged incident"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/incidents/{id}/merge [post]
+func PostIncidentMerge(w http.ResponseWriter, r *http.Request) {
+	inc, ok := getIncidentFromRequest(w, r, permissions.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		IncidentIDs []int64 `json:"incidentIds"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		zap.L().Warn("Body decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	for _, id := range body.IncidentIDs {
+		if !userCtx.HasPermission(permissions.New(permissions.TypeIncident, strconv.FormatInt(id, 10), permissions.ActionDelete)) {
+			httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+			return
+		}
+	}
+
+	err = explainer.MergeIncidents(inc, body.IncidentIDs)
+	if err != nil {
+		handleIncidentError(w, r, "MergeIncidents", err, httputil.ErrAPIDBUpdateFailed)
+		return
+	}
+
+	writeIncident(w, r, inc.ID)
+}
+
+// PostIncidentSplit godoc
+//
+//	@Id				PostIncidentSplit
+//
+//	@Summary		Split an incident
+//	@Description	Move some issues of an open incident into a new manual incident
+//	@Tags			Incidents
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	int			true	"Incident ID"
+//	@Param			issues	body	interface{}	true	"Issues to split and name of the new incident (example: {\"issueIds\": [5], \"name\": \"other outage\"})"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.Incident		"new incident"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/incidents/{id}/split [post]
+func PostIncidentSplit(w http.ResponseWriter, r *http.Request) {
+	inc, ok := getIncidentFromRequest(w, r, permissions.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Name     string  `json:"name"`
+		IssueIDs []int64 `json:"issueIds"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		zap.L().Warn("Body decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	id, err := explainer.SplitIncident(inc, body.IssueIDs, body.Name)
+	if err != nil {
+		handleIncidentError(w, r, "SplitIncident", err, httputil.ErrAPIDBUpdateFailed)
+		return
+	}
+
+	writeIncident(w, r, id)
+}
+
+// PostIncidentCloseWithFeedback godoc
+//
+//	@Id				PostIncidentCloseWithFeedback
+//
+//	@Summary		Close an incident with a feedback
+//	@Description	Close all the open and draft issues of an incident with the same rootcauses/actions feedback, then close the incident
+//	@Description	The selected rootcause and actions are matched by name on the issues of the other situations or rules (and created as custom ones if needed)
+//	@Tags			Incidents
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	int							true	"Incident ID"
+//	@Param			isFakeAlert	path	bool						true	"Indicates if the closed issues were false positives (true) or real alerts (false)"
+//	@Param			feedback	body	model.FrontRecommendation	true	"Recommendation tree with the selected rootcause and actions (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.CloseIssuesStatus	"closing status of the issues"
+//	@Failure		400	{object}	httputil.APIError		"Bad Request"
+//	@Failure		403	{object}	httputil.APIError		"Forbidden"
+//	@Failure		404	{object}	httputil.APIError		"Not Found"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/incidents/{id}/feedback/{isFakeAlert} [post]
+func PostIncidentCloseWithFeedback(w http.ResponseWriter, r *http.Request) {
+	inc, ok := getIncidentFromRequest(w, r, permissions.ActionUpdate)
+	if !ok {
+		return
+	}
+
+	isFakeAlertParam := chi.URLParam(r, "isFakeAlert")
+	isFakeAlert, err := strconv.ParseBool(isFakeAlertParam)
+	if err != nil {
+		zap.L().Warn("Error on parsing isFakeAlert parameter", zap.Int64("incidentID", inc.ID), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	allowed, err := hasIncidentIssuesPermission(userCtx, inc, permissions.ActionGet)
+	if err != nil {
+		zap.L().Error("Cannot retrieve incident issues", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !allowed {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission on the incident issues"))
+		return
+	}
+
+	var feedback model.FrontRecommendation
+	err = json.NewDecoder(r.Body).Decode(&feedback)
+	if err != nil {
+		zap.L().Warn("Body decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	status, err := explainer.CloseIncidentWithFeedback(postgres.DB(), inc, feedback, userCtx.User, isFakeAlert)
+	if err != nil {
+		handleIncidentError(w, r, "CloseIncidentWithFeedback", err, httputil.ErrAPIDBUpdateFailed)
+		return
+	}
+
+	httputil.JSON(w, r, status)
+}
+
+// DeleteIncident godoc
+//
+//	@Id				DeleteIncident
+//
+//	@Summary		Delete an incident
+//	@Description	Delete an incident, its issues are kept
+//	@Tags			Incidents
+//	@Param			id	path	int	true	"Incident ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/incidents/{id} [delete]
+func DeleteIncident(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idIncident, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing incident id", zap.String("incidentID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIncident, strconv.FormatInt(idIncident, 10), permissions.ActionDelete)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	err = incident.R().Delete(idIncident)
+	if err != nil {
+		zap.L().Error("Cannot delete incident", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+
+	httputil.OK(w, r)
+}
+
+// getIncidentFromRequest returns the incident of the request id parameter, and writes the error response if it cannot be used
+func getIncidentFromRequest(w http.ResponseWriter, r *http.Request, action string) (model.Incident, bool) {
+	id := chi.URLParam(r, "id")
+	idIncident, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing incident id", zap.String("incidentID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return model.Incident{}, false
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIncident, strconv.FormatInt(idIncident, 10), action)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return model.Incident{}, false
+	}
+
+	inc, found, err := incident.R().Get(idIncident)
+	if err != nil {
+		zap.L().Error("Cannot get incident", zap.Int64("incidentID", idIncident), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return model.Incident{}, false
+	}
+	if !found {
+		zap.L().Warn("Incident does not exists", zap.Int64("incidentID", idIncident))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("incident not found"))
+		return model.Incident{}, false
+	}
+	return inc, true
+}
+
+// hasIncidentIssuesPermission checks the user permission on the situations of all the issues of an incident
+func hasIncidentIssuesPermission(userCtx users.UserWithPermissions, inc model.Incident, action string) (bool, error) {
+	if userCtx.HasPermission(permissions.New(permissions.TypeSituationIssues, permissions.All, action)) {
+		return true, nil
+	}
+	for _, issueID := range inc.IssueIDs {
+		issue, found, err := issues.R().Get(issueID)
+		if err != nil {
+			return false, err
+		}
+		if found && !userCtx.HasPermission(permissions.New(permissions.TypeSituationIssues, strconv.FormatInt(issue.SituationID, 10), action)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func handleIncidentError(w http.ResponseWriter, r *http.Request, operation string, err error, apiError httputil.APIError) {
+	if errors.Is(err, explainer.ErrInvalidIncidentOperation) {
+		zap.L().Warn(operation, zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+	zap.L().Error(operation, zap.Error(err))
+	httputil.Error(w, r, apiError, err)
+}
+
+func writeIncident(w http.ResponseWriter, r *http.Request, id int64) {
+	inc, found, err := incident.R().Get(id)
+	if err != nil {
+		zap.L().Error("Cannot get incident", zap.Int64("incidentID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFoundAfterInsert, errors.New("incident not found"))
+		return
+	}
+	httputil.JSON(w, r, inc)
+}
@@ -0,0 +1,405 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/comment"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// IssueCommentRequest is the content of a new (or edited) issue comment
+type IssueCommentRequest struct {
+	ParentID *int64 `json:"parentId,omitempty"` // comment answered by the new comment (ignored on edition)
+	Content  string `json:"content"`
+}
+
+// GetIssueComments godoc
+//
+//	@Id				GetIssueComments
+//
+//	@Summary		Get the comment threads of an issue
+//	@Description	Get the comments of an issue in chronological order, with their attachments and their answers nested in replies
+//	@Tags			Issues
+//	@Produce		json
+//	@Param			id	path	string	true	"Issue ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		model.IssueComment	"list of comment threads"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issues/{id}/comments [get]
+func GetIssueComments(w http.ResponseWriter, r *http.Request) {
+	issue, ok := getIssueForComments(w, r)
+	if !ok {
+		return
+	}
+
+	comments, err := explainer.GetIssueComments(issue.ID)
+	if err != nil {
+		zap.L().Error("Cannot retrieve issue comments", zap.Int64("issueID", issue.ID), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, comments)
+}
+
+// PostIssueComment godoc
+//
+//	@Id				PostIssueComment
+//
+//	@Summary		Add a comment to an issue
+//	@Description	Add a comment to an issue, or an answer to a comment if parentId is set.
+//	@Description	The users mentioned in the content (ie. "@login") are notified.
+//	@Tags			Issues
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string						true	"Issue ID"
+//	@Param			comment	body	handler.IssueCommentRequest	true	"Comment (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueComment	"comment"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issues/{id}/comments [post]
+func PostIssueComment(w http.ResponseWriter, r *http.Request) {
+	issue, ok := getIssueForComments(w, r)
+	if !ok {
+		return
+	}
+
+	var request IssueCommentRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		zap.L().Warn("Body decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if ok, err := (model.IssueComment{IssueID: issue.ID, Author: userCtx.User.Login, Content: request.Content}).IsValid(); !ok {
+		zap.L().Warn("Invalid issue comment", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	newComment, err := explainer.CreateIssueComment(issue, request.ParentID, request.Content, userCtx.User)
+	if err != nil {
+		handleIssueCommentError(w, r, "Cannot create issue comment", err, httputil.ErrAPIDBInsertFailed)
+		return
+	}
+
+	httputil.JSON(w, r, newComment)
+}
+
+// PutIssueComment godoc
+//
+//	@Id				PutIssueComment
+//
+//	@Summary		Edit a comment of an issue
+//	@Description	Edit the content of a comment of an issue (only by its author). Only the newly mentioned users are notified.
+//	@Tags			Issues
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	string						true	"Issue ID"
+//	@Param			commentid	path	string						true	"Comment ID"
+//	@Param			comment		body	handler.IssueCommentRequest	true	"Comment (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueComment	"comment"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issues/{id}/comments/{commentid} [put]
+func PutIssueComment(w http.ResponseWriter, r *http.Request) {
+	issue, ok := getIssueForComments(w, r)
+	if !ok {
+		return
+	}
+	commentID, ok := getURLParamInt64(w, r, "commentid")
+	if !ok {
+		return
+	}
+
+	var request IssueCommentRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		zap.L().Warn("Body decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if ok, err := (model.IssueComment{ID: commentID, IssueID: issue.ID, Author: userCtx.User.Login, Content: request.Content}).IsValid(); !ok {
+		zap.L().Warn("Invalid issue comment", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	editedComment, err := explainer.EditIssueComment(issue, commentID, request.Content, userCtx.User)
+	if err != nil {
+		handleIssueCommentError(w, r, "Cannot edit issue comment", err, httputil.ErrAPIDBUpdateFailed)
+		return
+	}
+
+	httputil.JSON(w, r, editedComment)
+}
+
+// DeleteIssueComment godoc
+//
+//	@Id				DeleteIssueComment
+//
+//	@Summary		Delete a comment of an issue
+//	@Description	Delete a comment of an issue (only by its author), with all its answers and attachments
+//	@Tags			Issues
+//	@Produce		json
+//	@Param			id			path	string	true	"Issue ID"
+//	@Param			commentid	path	string	true	"Comment ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issues/{id}/comments/{commentid} [delete]
+func DeleteIssueComment(w http.ResponseWriter, r *http.Request) {
+	issue, ok := getIssueForComments(w, r)
+	if !ok {
+		return
+	}
+	commentID, ok := getURLParamInt64(w, r, "commentid")
+	if !ok {
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	err := explainer.DeleteIssueComment(issue, commentID, userCtx.User)
+	if err != nil {
+		handleIssueCommentError(w, r, "Cannot delete issue comment", err, httputil.ErrAPIDBDeleteFailed)
+		return
+	}
+
+	httputil.OK(w, r)
+}
+
+// issueAttachmentMultipartOverhead is the size allowed for the multipart headers and boundaries around an attached file
+const issueAttachmentMultipartOverhead = 64 << 10
+
+// PostIssueCommentAttachment godoc
+//
+//	@Id				PostIssueCommentAttachment
+//
+//	@Summary		Attach a file to a comment of an issue
+//	@Description	Attach a file (ie. a screenshot or a CSV extract) to a comment of an issue (only by its author).
+//	@Description	The file is stored on the local disk of the engine, and its size is limited by ISSUE_ATTACHMENTS_MAX_SIZE.
+//	@Tags			Issues
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			id			path		string	true	"Issue ID"
+//	@Param			commentid	path		string	true	"Comment ID"
+//	@Param			file		formData	file	true	"Attached file"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueCommentAttachment	"attachment"
+//	@Failure		400	{object}	httputil.APIError				"Bad Request"
+//	@Failure		403	{object}	httputil.APIError				"Forbidden"
+//	@Failure		404	{object}	httputil.APIError				"Not Found"
+//	@Failure		413	{object}	httputil.APIError				"Request Entity Too Large"
+//	@Failure		500	{object}	httputil.APIError				"Internal Server Error"
+//	@Router			/engine/issues/{id}/comments/{commentid}/attachments [post]
+func PostIssueCommentAttachment(w http.ResponseWriter, r *http.Request) {
+	issue, ok := getIssueForComments(w, r)
+	if !ok {
+		return
+	}
+	commentID, ok := getURLParamInt64(w, r, "commentid")
+	if !ok {
+		return
+	}
+
+	// The body is limited before being parsed, so that an oversized upload is never buffered to the disk
+	if store := comment.S(); store != nil && store.MaxSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, store.MaxSize+issueAttachmentMultipartOverhead)
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			zap.L().Warn("Attached file is too large", zap.Error(err))
+			httputil.Error(w, r, httputil.ErrAPIRequestTooLarge, err)
+			return
+		}
+		zap.L().Warn("Cannot read the attached file", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIMissingParam, err)
+		return
+	}
+	defer file.Close()
+
+	userCtx, _ := GetUserFromContext(r)
+	attachment, err := explainer.AddIssueCommentAttachment(issue, commentID, header.Filename, header.Header.Get("Content-Type"), file, userCtx.User)
+	if err != nil {
+		handleIssueCommentError(w, r, "Cannot attach file to issue comment", err, httputil.ErrAPIDBInsertFailed)
+		return
+	}
+
+	httputil.JSON(w, r, attachment)
+}
+
+// DownloadIssueCommentAttachment godoc
+//
+//	@Id				DownloadIssueCommentAttachment
+//
+//	@Summary		Download a file attached to a comment of an issue
+//	@Description	Download a file attached to a comment of an issue
+//	@Tags			Issues
+//	@Produce		octet-stream
+//	@Param			id				path	string	true	"Issue ID"
+//	@Param			commentid		path	string	true	"Comment ID"
+//	@Param			attachmentid	path	string	true	"Attachment ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{file}		result				file
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issues/{id}/comments/{commentid}/attachments/{attachmentid} [get]
+func DownloadIssueCommentAttachment(w http.ResponseWriter, r *http.Request) {
+	issue, ok := getIssueForComments(w, r)
+	if !ok {
+		return
+	}
+	commentID, ok := getURLParamInt64(w, r, "commentid")
+	if !ok {
+		return
+	}
+	attachmentID, ok := getURLParamInt64(w, r, "attachmentid")
+	if !ok {
+		return
+	}
+
+	attachment, path, err := explainer.GetIssueCommentAttachment(issue, commentID, attachmentID)
+	if err != nil {
+		handleIssueCommentError(w, r, "Cannot get issue comment attachment", err, httputil.ErrAPIDBSelectFailed)
+		return
+	}
+
+	httputil.StreamFile(path, attachment.FileName, w, r)
+}
+
+// DeleteIssueCommentAttachment godoc
+//
+//	@Id				DeleteIssueCommentAttachment
+//
+//	@Summary		Delete a file attached to a comment of an issue
+//	@Description	Delete a file attached to a comment of an issue (only by the comment author)
+//	@Tags			Issues
+//	@Produce		json
+//	@Param			id				path	string	true	"Issue ID"
+//	@Param			commentid		path	string	true	"Comment ID"
+//	@Param			attachmentid	path	string	true	"Attachment ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issues/{id}/comments/{commentid}/attachments/{attachmentid} [delete]
+func DeleteIssueCommentAttachment(w http.ResponseWriter, r *http.Request) {
+	issue, ok := getIssueForComments(w, r)
+	if !ok {
+		return
+	}
+	commentID, ok := getURLParamInt64(w, r, "commentid")
+	if !ok {
+		return
+	}
+	attachmentID, ok := getURLParamInt64(w, r, "attachmentid")
+	if !ok {
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	err := explainer.DeleteIssueCommentAttachment(issue, commentID, attachmentID, userCtx.User)
+	if err != nil {
+		handleIssueCommentError(w, r, "Cannot delete issue comment attachment", err, httputil.ErrAPIDBDeleteFailed)
+		return
+	}
+
+	httputil.OK(w, r)
+}
+
+// getIssueForComments returns the issue of the request id parameter, and writes the error response if the user cannot read it
+func getIssueForComments(w http.ResponseWriter, r *http.Request) (model.Issue, bool) {
+	idIssue, ok := getURLParamInt64(w, r, "id")
+	if !ok {
+		return model.Issue{}, false
+	}
+
+	issue, found, err := issues.R().Get(idIssue)
+	if err != nil {
+		zap.L().Error("Cannot retrieve issue", zap.Int64("issueID", idIssue), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return model.Issue{}, false
+	}
+	if !found {
+		zap.L().Warn("Issue does not exists", zap.Int64("issueID", idIssue))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, issues.ErrIssueNotFound)
+		return model.Issue{}, false
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituationIssues, strconv.FormatInt(issue.SituationID, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return model.Issue{}, false
+	}
+	return issue, true
+}
+
+// getURLParamInt64 returns an integer URL parameter, and writes the error response if it cannot be parsed
+func getURLParamInt64(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	param := chi.URLParam(r, name)
+	value, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing url parameter", zap.String(name, param), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return 0, false
+	}
+	return value, true
+}
+
+func handleIssueCommentError(w http.ResponseWriter, r *http.Request, operation string, err error, apiError httputil.APIError) {
+	switch {
+	case errors.Is(err, comment.ErrCommentNotFound), errors.Is(err, comment.ErrAttachmentNotFound):
+		zap.L().Warn(operation, zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, err)
+	case errors.Is(err, comment.ErrNotAuthor):
+		zap.L().Warn(operation, zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, err)
+	case errors.Is(err, comment.ErrInvalidParent):
+		zap.L().Warn(operation, zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+	case errors.Is(err, comment.ErrAttachmentTooLarge):
+		zap.L().Warn(operation, zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIRequestTooLarge, err)
+	default:
+		zap.L().Error(operation, zap.Error(err))
+		httputil.Error(w, r, apiError, err)
+	}
+}
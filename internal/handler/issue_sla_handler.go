@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/sla"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// GetIssueSLAs godoc
+//
+//	@Id				GetIssueSLAs
+//
+//	@Summary		Get all issue SLA targets
+//	@Description	Get all issue SLA targets
+//	@Tags			IssueSLAs
+//	@Produce		json
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		model.IssueSLA		"list of issue SLA targets"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issue_slas [get]
+func GetIssueSLAs(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIssueSLA, permissions.All, permissions.ActionList)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	slas, err := sla.R().GetAll()
+	if err != nil {
+		zap.L().Error("Error getting issue SLAs", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, slas)
+}
+
+// GetIssueSLA godoc
+//
+//	@Id				GetIssueSLA
+//
+//	@Summary		Get an issue SLA target
+//	@Description	Get an issue SLA target
+//	@Tags			IssueSLAs
+//	@Produce		json
+//	@Param			id	path	int	true	"Issue SLA ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueSLA		"issue SLA target"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issue_slas/{id} [get]
+func GetIssueSLA(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idSLA, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing issue SLA id", zap.String("slaID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIssueSLA, strconv.FormatInt(idSLA, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	s, found, err := sla.R().Get(idSLA)
+	if err != nil {
+		zap.L().Error("Cannot get issue SLA", zap.Int64("slaID", idSLA), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("Issue SLA does not exists", zap.Int64("slaID", idSLA))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("issue SLA not found"))
+		return
+	}
+
+	httputil.JSON(w, r, s)
+}
+
+// PostIssueSLA godoc
+//
+//	@Id				PostIssueSLA
+//
+//	@Summary		Create a new issue SLA target
+//	@Description	Create a new issue SLA target for a situation and/or a level (none means every situation or level)
+//	@Tags			IssueSLAs
+//	@Accept			json
+//	@Produce		json
+//	@Param			sla	body	model.IssueSLA	true	"Issue SLA target"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueSLA		"created issue SLA target with ID"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issue_slas [post]
+func PostIssueSLA(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIssueSLA, permissions.All, permissions.ActionCreate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var s model.IssueSLA
+	err := json.NewDecoder(r.Body).Decode(&s)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling issue SLA", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if ok, err := s.IsValid(); !ok {
+		zap.L().Warn("Issue SLA is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	id, err := sla.R().Create(s)
+	if err != nil {
+		zap.L().Error("Cannot create issue SLA", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBInsertFailed, err)
+		return
+	}
+
+	s.ID = id
+	httputil.JSON(w, r, s)
+}
+
+// PutIssueSLA godoc
+//
+//	@Id				PutIssueSLA
+//
+//	@Summary		Update an issue SLA target
+//	@Description	Update an issue SLA target
+//	@Tags			IssueSLAs
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	int				true	"Issue SLA ID"
+//	@Param			sla	body	model.IssueSLA	true	"Issue SLA target"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueSLA		"updated issue SLA target"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issue_slas/{id} [put]
+func PutIssueSLA(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idSLA, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing issue SLA id", zap.String("slaID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIssueSLA, strconv.FormatInt(idSLA, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var s model.IssueSLA
+	err = json.NewDecoder(r.Body).Decode(&s)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling issue SLA", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	s.ID = idSLA
+	if ok, err := s.IsValid(); !ok {
+		zap.L().Warn("Issue SLA is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	err = sla.R().Update(s)
+	if err != nil {
+		zap.L().Error("Cannot update issue SLA", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, s)
+}
+
+// DeleteIssueSLA godoc
+//
+//	@Id				DeleteIssueSLA
+//
+//	@Summary		Delete an issue SLA target
+//	@Description	Delete an issue SLA target
+//	@Tags			IssueSLAs
+//	@Param			id	path	int	true	"Issue SLA ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issue_slas/{id} [delete]
+func DeleteIssueSLA(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idSLA, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing issue SLA id", zap.String("slaID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIssueSLA, strconv.FormatInt(idSLA, 10), permissions.ActionDelete)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	err = sla.R().Delete(idSLA)
+	if err != nil {
+		zap.L().Error("Cannot delete issue SLA", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+
+	httputil.OK(w, r)
+}
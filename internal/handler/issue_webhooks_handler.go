@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/webhook"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// GetIssueWebhooks godoc
+//
+//	@Id				GetIssueWebhooks
+//
+//	@Summary		Get all issue webhooks
+//	@Description	Get all issue webhooks (without their secret)
+//	@Tags			Issues
+//	@Produce		json
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		model.IssueWebhook	"list of issue webhooks"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issue_webhooks [get]
+func GetIssueWebhooks(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIssueWebhook, permissions.All, permissions.ActionList)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	webhooks, err := webhook.R().GetAll()
+	if err != nil {
+		zap.L().Error("Error getting issue webhooks", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	httputil.JSON(w, r, webhooks)
+}
+
+// GetIssueWebhook godoc
+//
+//	@Id				GetIssueWebhook
+//
+//	@Summary		Get an issue webhook
+//	@Description	Get an issue webhook (without its secret)
+//	@Tags			Issues
+//	@Produce		json
+//	@Param			id	path	int	true	"Issue webhook ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueWebhook	"issue webhook"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issue_webhooks/{id} [get]
+func GetIssueWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idWebhook, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing issue webhook id", zap.String("webhookID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIssueWebhook, strconv.FormatInt(idWebhook, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	hook, found, err := webhook.R().Get(idWebhook)
+	if err != nil {
+		zap.L().Error("Cannot get issue webhook", zap.Int64("webhookID", idWebhook), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("Issue webhook does not exists", zap.Int64("webhookID", idWebhook))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("issue webhook not found"))
+		return
+	}
+
+	hook.Secret = ""
+	httputil.JSON(w, r, hook)
+}
+
+// PostIssueWebhook godoc
+//
+//	@Id				PostIssueWebhook
+//
+//	@Summary		Create a new issue webhook
+//	@Description	Create a new issue webhook, receiving the issue events of its event types (all the types if empty)
+//	@Description	When a secret is set, each delivery is signed with an HMAC-SHA256 of the payload in the X-Myrtea-Signature header
+//	@Description	The events are delivered in order, and the URL must resolve to a public address
+//	@Tags			Issues
+//	@Accept			json
+//	@Produce		json
+//	@Param			webhook	body	model.IssueWebhook	true	"Issue webhook"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueWebhook	"created issue webhook with ID"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issue_webhooks [post]
+func PostIssueWebhook(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIssueWebhook, permissions.All, permissions.ActionCreate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var hook model.IssueWebhook
+	err := json.NewDecoder(r.Body).Decode(&hook)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling issue webhook", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if ok, err := hook.IsValid(); !ok {
+		zap.L().Warn("Issue webhook is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+	if err = webhook.CheckURL(hook.URL); err != nil {
+		zap.L().Warn("Issue webhook URL is not allowed", zap.String("url", hook.URL), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	id, err := webhook.R().Create(hook)
+	if err != nil {
+		zap.L().Error("Cannot create issue webhook", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBInsertFailed, err)
+		return
+	}
+
+	webhook.Invalidate()
+	hook.ID = id
+	hook.Secret = ""
+	httputil.JSON(w, r, hook)
+}
+
+// PutIssueWebhook godoc
+//
+//	@Id				PutIssueWebhook
+//
+//	@Summary		Update an issue webhook
+//	@Description	Update an issue webhook, its secret is kept if the new one is empty
+//	@Tags			Issues
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	int					true	"Issue webhook ID"
+//	@Param			webhook	body	model.IssueWebhook	true	"Issue webhook"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueWebhook	"updated issue webhook"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issue_webhooks/{id} [put]
+func PutIssueWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idWebhook, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing issue webhook id", zap.String("webhookID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIssueWebhook, strconv.FormatInt(idWebhook, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var hook model.IssueWebhook
+	err = json.NewDecoder(r.Body).Decode(&hook)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling issue webhook", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	hook.ID = idWebhook
+	if ok, err := hook.IsValid(); !ok {
+		zap.L().Warn("Issue webhook is not valid", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+	if err = webhook.CheckURL(hook.URL); err != nil {
+		zap.L().Warn("Issue webhook URL is not allowed", zap.String("url", hook.URL), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	err = webhook.R().Update(hook)
+	if err != nil {
+		zap.L().Error("Cannot update issue webhook", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+
+	webhook.Invalidate()
+	hook.Secret = ""
+	httputil.JSON(w, r, hook)
+}
+
+// DeleteIssueWebhook godoc
+//
+//	@Id				DeleteIssueWebhook
+//
+//	@Summary		Delete an issue webhook
+//	@Description	Delete an issue webhook
+//	@Tags			Issues
+//	@Param			id	path	int	true	"Issue webhook ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/issue_webhooks/{id} [delete]
+func DeleteIssueWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idWebhook, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing issue webhook id", zap.String("webhookID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeIssueWebhook, strconv.FormatInt(idWebhook, 10), permissions.ActionDelete)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	err = webhook.R().Delete(idWebhook)
+	if err != nil {
+		zap.L().Error("Cannot delete issue webhook", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+
+	webhook.Invalidate()
+	httputil.OK(w, r)
+}
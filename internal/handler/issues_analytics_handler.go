@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// GetIssuesAnalytics godoc
+//
+//	@Id				GetIssuesAnalytics
+//
+//	@Summary		Get the issues analytics
+//	@Description	Get the counts by state, the mean time to acknowledge (MTTA) and to resolve (MTTR), the false alert ratio and the top rootcauses
+//	@Description	of the issues created over a time range, grouped by situation, rule, level, functional situation or tag.
+//	@Description	The mean times are in seconds. An issue can belong to many functional situations or tags.
+//	@Tags			Issues
+//	@Produce		json
+//	@Param			from	query	string	false	"Start date (default: 30 days ago) (example: 2024-05-10T00:00:00.000+02:00)"
+//	@Param			to		query	string	false	"End date, excluded (default: now)"
+//	@Param			groupby	query	string	false	"Grouping (default: situation)"	Enums(situation, rule, level, functional_situation, tag)
+//	@Param			top		query	int		false	"Number of rootcauses per group (default: 5)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueAnalytics	"issues analytics"
+//	@Failure		400	{object}	httputil.APIError		"Bad Request"
+//	@Failure		403	{object}	httputil.APIError		"Forbidden"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/issues/analytics [get]
+func GetIssuesAnalytics(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituationIssues, permissions.All, permissions.ActionList)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	now := time.Now()
+	from, err := QueryParamToOptionalTime(r, "from", now.AddDate(0, 0, -30))
+	if err != nil {
+		zap.L().Warn("Error on parsing from date", zap.String("from", r.URL.Query().Get("from")), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingDateTime, err)
+		return
+	}
+	to, err := QueryParamToOptionalTime(r, "to", now)
+	if err != nil {
+		zap.L().Warn("Error on parsing to date", zap.String("to", r.URL.Query().Get("to")), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingDateTime, err)
+		return
+	}
+	if !from.Before(to) {
+		zap.L().Warn("Invalid analytics time range", zap.Time("from", from), zap.Time("to", to))
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, errors.New("from must be before to"))
+		return
+	}
+
+	groupBy := model.IssueAnalyticsBySituation
+	if param := r.URL.Query().Get("groupby"); param != "" {
+		groupBy, err = model.ToIssueAnalyticsGroupBy(param)
+		if err != nil {
+			zap.L().Warn("Error on parsing group by", zap.String("groupby", param), zap.Error(err))
+			httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+			return
+		}
+	}
+
+	top, err := QueryParamToOptionalInt(r, "top", 5)
+	if err != nil || top < 0 {
+		zap.L().Warn("Error on parsing top", zap.String("top", r.URL.Query().Get("top")), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, errors.New("top must be a positive integer"))
+		return
+	}
+
+	var situationIDs []int64
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituationIssues, permissions.All, permissions.ActionGet)) {
+		situationIDs = userCtx.GetMatchingResourceIDsInt64(permissions.New(permissions.TypeSituationIssues, permissions.All, permissions.ActionGet))
+		if situationIDs == nil {
+			situationIDs = make([]int64, 0)
+		}
+	}
+
+	analytics, err := explainer.GetIssueAnalytics(groupBy, from, to, situationIDs, top)
+	if err != nil {
+		zap.L().Error("Error getting issues analytics", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, analytics)
+}
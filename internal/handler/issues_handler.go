@@ -3,11 +3,13 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/users"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
 
 	"github.com/go-chi/chi/v5"
@@ -15,6 +17,7 @@ import (
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
 	"github.com/myrteametrics/myrtea-sdk/v5/postgres"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
@@ -118,7 +121,7 @@ func GetIssuesByStatesByPageUnProtected(w http.ResponseWriter, r *http.Request)
 
 	var issuesSlice []model.Issue
 	var total int
-	issuesSlice, total, err = issues.R().GetByStateByPage(states, searchOptions)
+	issuesSlice, total, err = issues.R().GetByStateByPage(states, searchOptions, "")
 
 	if err != nil {
 		zap.L().Error("Error on getting issues", zap.Error(err))
@@ -145,7 +148,8 @@ func GetIssuesByStatesByPageUnProtected(w http.ResponseWriter, r *http.Request)
 //	@Param			states	query	string	true	"Issue states (comma separated) (Available: open, draft, closedfeedback, closednofeedback, closedtimeout)"
 //	@Param			limit	query	string	false	"Result limit (default: 50)"
 //	@Param			offset	query	string	false	"Result offset (default: 0)"
-//	@Param			sort_by	query	string	false	"Result offset (example: 'sort_by=desc(last_modified),asc(id)')"
+//	@Param			sort_by		query	string	false	"Result offset (example: 'sort_by=desc(last_modified),asc(id)')"
+//	@Param			assignedto	query	string	false	"Only the issues assigned to this user login ('me' for the current user)"
 //	@Security		Bearer
 //	@Security		ApiKeyAuth
 //	@Success		200	"Status OK"
@@ -199,13 +203,18 @@ func GetIssuesByStatesByPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	assignedTo := r.URL.Query().Get("assignedto")
+	if assignedTo == "me" {
+		assignedTo = userCtx.Login
+	}
+
 	var issuesSlice []model.Issue
 	var total int
 	if userCtx.HasPermission(permissions.New(permissions.TypeSituationIssues, permissions.All, permissions.ActionGet)) {
-		issuesSlice, total, err = issues.R().GetByStateByPage(states, searchOptions)
+		issuesSlice, total, err = issues.R().GetByStateByPage(states, searchOptions, assignedTo)
 	} else {
 		situationIDs := userCtx.GetMatchingResourceIDsInt64(permissions.New(permissions.TypeSituationIssues, permissions.All, permissions.ActionGet))
-		issuesSlice, total, err = issues.R().GetByStateByPageBySituationIDs(states, searchOptions, situationIDs)
+		issuesSlice, total, err = issues.R().GetByStateByPageBySituationIDs(states, searchOptions, situationIDs, assignedTo)
 	}
 	if err != nil {
 		zap.L().Error("Error on getting issues", zap.Error(err))
@@ -215,7 +224,7 @@ func GetIssuesByStatesByPage(w http.ResponseWriter, r *http.Request) {
 
 	paginatedResource := model.PaginatedResource{
 		Total: total,
-		Items: issuesSlice,
+		Items: explainer.EnrichIssuesSLA(issuesSlice),
 	}
 
 	httputil.JSON(w, r, paginatedResource)
@@ -262,7 +271,156 @@ func GetIssue(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.JSON(w, r, issue)
+	httputil.JSON(w, r, explainer.EnrichIssuesSLA([]model.Issue{issue})[0])
+}
+
+// PutIssueAssignee godoc
+//
+//	@Id				PutIssueAssignee
+//
+//	@Summary		Assign an issue
+//	@Description	Assign, reassign or unassign (null assignee) an issue, the change is recorded in the issue audit trail. The assignee must be a known user; with an OIDC or SAML authentication, only the current user can be assigned.
+//	@Tags			Issues
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path	int			true	"Issue ID"
+//	@Param			assignee	body	interface{}	true	"Assignee login (example: {\"assignee\": \"john\"})"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueAuditEntry	"Status OK"
+//	@Failure		400	{object}	httputil.APIError		"Bad Request"
+//	@Failure		404	{object}	httputil.APIError		"Not Found"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/issues/{id}/assignee [put]
+func PutIssueAssignee(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idIssue, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing issue id", zap.String("issueID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	issue, found, err := issues.R().Get(idIssue)
+	if err != nil {
+		zap.L().Error("Cannot retrieve issue", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("issue does not exists", zap.String("issueID", id))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituationIssues, strconv.FormatInt(issue.SituationID, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var body struct {
+		Assignee *string `json:"assignee"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		zap.L().Warn("Body decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+	if body.Assignee != nil && strings.TrimSpace(*body.Assignee) == "" {
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, errors.New("empty assignee"))
+		return
+	}
+	if body.Assignee != nil && *body.Assignee != userCtx.Login {
+		// The users are only stored by the engine with the basic authentication, and are managed by the identity provider
+		// otherwise: only the current user, authenticated by the identity provider, can then be assigned
+		if mode := viper.GetString("AUTHENTICATION_MODE"); mode != "BASIC" {
+			zap.L().Warn("assignee cannot be validated", zap.String("assignee", *body.Assignee), zap.String("mode", mode))
+			httputil.Error(w, r, httputil.ErrAPIResourceInvalid,
+				fmt.Errorf("the assignee %s cannot be validated with the %s authentication, only the current user can be assigned", *body.Assignee, mode))
+			return
+		}
+		_, found, err := users.R().GetByLogin(*body.Assignee)
+		if err != nil {
+			zap.L().Error("Cannot retrieve assignee", zap.String("assignee", *body.Assignee), zap.Error(err))
+			httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+			return
+		}
+		if !found {
+			zap.L().Warn("assignee does not exists", zap.String("assignee", *body.Assignee))
+			httputil.Error(w, r, httputil.ErrAPIResourceInvalid, fmt.Errorf("unknown assignee %s", *body.Assignee))
+			return
+		}
+	}
+
+	entry, err := explainer.AssignIssue(issue, body.Assignee, userCtx.User)
+	if errors.Is(err, issues.ErrIssueNotFound) {
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, err)
+		return
+	}
+	if errors.Is(err, explainer.ErrIssueClosed) {
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+	if err != nil {
+		zap.L().Error("Cannot assign issue", zap.Int64("issueID", idIssue), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, entry)
+}
+
+// GetIssueAudit godoc
+//
+//	@Id				GetIssueAudit
+//
+//	@Summary		Get an issue audit trail
+//	@Description	Get the assignments of an issue, in chronological order
+//	@Tags			Issues
+//	@Produce		json
+//	@Param			id	path	int	true	"Issue ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		model.IssueAuditEntry	"Status OK"
+//	@Failure		400	{object}	httputil.APIError		"Bad Request"
+//	@Router			/engine/issues/{id}/audit [get]
+func GetIssueAudit(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idIssue, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing issue id", zap.String("issueID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	issue, found, err := issues.R().Get(idIssue)
+	if err != nil {
+		zap.L().Error("Cannot retrieve issue", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("issue does not exists", zap.String("issueID", id))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituationIssues, strconv.FormatInt(issue.SituationID, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	entries, err := issues.R().GetAudit(idIssue)
+	if err != nil {
+		zap.L().Error("Cannot retrieve issue audit", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, entries)
 }
 
 // GetIssueHistory godoc
@@ -872,6 +1030,7 @@ func PostIssueDetectionFeedback(w http.ResponseWriter, r *http.Request) {
 //	@Security		ApiKeyAuth
 //	@Success		200	"Status OK"
 //	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
 //	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
 //	@Router			/engine/issues/{id}/comment [put]
 func UpdateIssueComment(w http.ResponseWriter, r *http.Request) {
@@ -909,7 +1068,13 @@ func UpdateIssueComment(w http.ResponseWriter, r *http.Request) {
 
 	//zap.L().Info("UpdateComment", zap.String("comment", comment.Comment))
 
-	err = issues.R().UpdateComment(postgres.DB(), idIssue, comment.Comment)
+	userCtx, _ := GetUserFromContext(r)
+	err = explainer.UpdateIssueComment(idIssue, comment.Comment, userCtx.User)
+	if errors.Is(err, issues.ErrIssueNotFound) {
+		zap.L().Warn("Issue does not exists", zap.Int64("issueID", idIssue))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, err)
+		return
+	}
 	if err != nil {
 		zap.L().Error("Cannot update issue comment", zap.Error(err))
 		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
@@ -1011,8 +1176,82 @@ func SearchIssuesByName(w http.ResponseWriter, r *http.Request) {
 
 	paginatedResource := model.PaginatedResource{
 		Total: total,
-		Items: issuesSlice,
+		Items: explainer.EnrichIssuesSLA(issuesSlice),
 	}
 
 	httputil.JSON(w, r, paginatedResource)
 }
+
+// SearchIssues godoc
+//
+//	@Id				SearchIssues
+//
+//	@Summary		Full-text and faceted issues search
+//	@Description	Search the issues with a free text (matched on the issue name and comment, the comment threads and the rootcause and action labels of the resolution)
+//	@Description	and filters on the situations, template instances, tags, functional situations, levels, states, assignees, date ranges and detection rating.
+//	@Description	The requested facets count the matching issues per value, ignoring the filter of their own dimension.
+//	@Tags			Issues
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body	model.IssueSearchQuery	true	"Search query (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	model.IssueSearchResult	"Status OK"
+//	@Failure		400	{object}	httputil.APIError		"Bad Request"
+//	@Failure		403	{object}	httputil.APIError		"Forbidden"
+//	@Failure		500	{object}	httputil.APIError		"Internal Server Error"
+//	@Router			/engine/issues/search [post]
+func SearchIssues(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituationIssues, permissions.All, permissions.ActionList)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var query model.IssueSearchQuery
+	err := json.NewDecoder(r.Body).Decode(&query)
+	if err != nil {
+		zap.L().Warn("Body decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+	if ok, err := query.IsValid(); !ok {
+		zap.L().Warn("Invalid issues search query", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+	for _, sortBy := range query.SortBy {
+		if !isAllowedSortByField(sortBy.Field) {
+			zap.L().Warn("Invalid issues search sort field", zap.String("field", sortBy.Field))
+			httputil.Error(w, r, httputil.ErrAPIParsingSortBy, errors.New("invalid sort field "+sortBy.Field))
+			return
+		}
+	}
+
+	var situationIDs []int64
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituationIssues, permissions.All, permissions.ActionGet)) {
+		situationIDs = userCtx.GetMatchingResourceIDsInt64(permissions.New(permissions.TypeSituationIssues, permissions.All, permissions.ActionGet))
+		if situationIDs == nil {
+			situationIDs = make([]int64, 0)
+		}
+	}
+
+	result, err := issues.R().Search(query, situationIDs)
+	if err != nil {
+		zap.L().Error("Error on searching issues", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	result.Items = explainer.EnrichIssuesSLA(result.Items)
+
+	httputil.JSON(w, r, result)
+}
+
+func isAllowedSortByField(field string) bool {
+	for _, allowed := range allowedSortByFields {
+		if field == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/maintenance"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
+	"go.uber.org/zap"
+)
+
+// GetMaintenanceWindows godoc
+//
+//	@Id				GetMaintenanceWindows
+//
+//	@Summary		Get all maintenance windows
+//	@Description	Get all maintenance windows, the most recent first
+//	@Tags			Maintenance
+//	@Produce		json
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		maintenance.Window	"list of maintenance windows"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/maintenance_windows [get]
+func GetMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeMaintenance, permissions.All, permissions.ActionList)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	windows, err := maintenance.R().GetAll()
+	if err != nil {
+		zap.L().Error("Error getting maintenance windows", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, windows)
+}
+
+// GetMaintenanceWindow godoc
+//
+//	@Id				GetMaintenanceWindow
+//
+//	@Summary		Get a maintenance window
+//	@Description	Get a maintenance window
+//	@Tags			Maintenance
+//	@Produce		json
+//	@Param			id	path	int	true	"Maintenance window ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	maintenance.Window	"maintenance window"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/maintenance_windows/{id} [get]
+func GetMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idWindow, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing maintenance window id", zap.String("windowID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeMaintenance, strconv.FormatInt(idWindow, 10), permissions.ActionGet)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	window, found, err := maintenance.R().Get(idWindow)
+	if err != nil {
+		zap.L().Error("Cannot get maintenance window", zap.Int64("windowID", idWindow), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+	if !found {
+		zap.L().Warn("Maintenance window does not exists", zap.Int64("windowID", idWindow))
+		httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, errors.New("maintenance window not found"))
+		return
+	}
+
+	httputil.JSON(w, r, window)
+}
+
+// PostMaintenanceWindow godoc
+//
+//	@Id				PostMaintenanceWindow
+//
+//	@Summary		Create a new maintenance window
+//	@Description	Create a new maintenance window, the create-issue, notify and situation-reporting actions of its scope are suppressed between its start and end
+//	@Tags			Maintenance
+//	@Accept			json
+//	@Produce		json
+//	@Param			window	body	maintenance.Window	true	"Maintenance window"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	maintenance.Window	"created maintenance window with ID"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/maintenance_windows [post]
+func PostMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeMaintenance, permissions.All, permissions.ActionCreate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var window maintenance.Window
+	err := json.NewDecoder(r.Body).Decode(&window)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling maintenance window", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if err := window.Validate(); err != nil {
+		zap.L().Warn("Maintenance window validation failed", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	window.CreatedBy = userCtx.Login
+	id, err := maintenance.R().Create(window)
+	if err != nil {
+		zap.L().Error("Cannot create maintenance window", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBInsertFailed, err)
+		return
+	}
+
+	window, _, err = maintenance.R().Get(id)
+	if err != nil {
+		zap.L().Error("Cannot get created maintenance window", zap.Int64("windowID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, window)
+}
+
+// PutMaintenanceWindow godoc
+//
+//	@Id				PutMaintenanceWindow
+//
+//	@Summary		Update a maintenance window
+//	@Description	Update a maintenance window, the actions it already suppressed are kept
+//	@Tags			Maintenance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	int					true	"Maintenance window ID"
+//	@Param			window	body	maintenance.Window	true	"Maintenance window"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	maintenance.Window	"updated maintenance window"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/maintenance_windows/{id} [put]
+func PutMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idWindow, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing maintenance window id", zap.String("windowID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeMaintenance, strconv.FormatInt(idWindow, 10), permissions.ActionUpdate)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	var window maintenance.Window
+	err = json.NewDecoder(r.Body).Decode(&window)
+	if err != nil {
+		zap.L().Warn("Error on unmarshalling maintenance window", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	window.ID = idWindow
+	if err := window.Validate(); err != nil {
+		zap.L().Warn("Maintenance window validation failed", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIResourceInvalid, err)
+		return
+	}
+
+	err = maintenance.R().Update(window)
+	if err != nil {
+		zap.L().Error("Cannot update maintenance window", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBUpdateFailed, err)
+		return
+	}
+
+	window, _, err = maintenance.R().Get(idWindow)
+	if err != nil {
+		zap.L().Error("Cannot get updated maintenance window", zap.Int64("windowID", idWindow), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, window)
+}
+
+// DeleteMaintenanceWindow godoc
+//
+//	@Id				DeleteMaintenanceWindow
+//
+//	@Summary		Delete a maintenance window
+//	@Description	Delete a maintenance window, the actions it suppressed are kept
+//	@Tags			Maintenance
+//	@Param			id	path	int	true	"Maintenance window ID"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	"Status OK"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/maintenance_windows/{id} [delete]
+func DeleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	idWindow, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		zap.L().Warn("Error on parsing maintenance window id", zap.String("windowID", id), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeMaintenance, strconv.FormatInt(idWindow, 10), permissions.ActionDelete)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	err = maintenance.R().Delete(idWindow)
+	if err != nil {
+		zap.L().Error("Cannot delete maintenance window", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBDeleteFailed, err)
+		return
+	}
+
+	httputil.OK(w, r)
+}
+
+// GetSuppressedActions godoc
+//
+//	@Id				GetSuppressedActions
+//
+//	@Summary		Get the actions suppressed by the maintenance windows
+//	@Description	Get the create-issue, notify and situation-reporting actions which would have fired without the maintenance windows, the most recent first
+//	@Tags			Maintenance
+//	@Produce		json
+//	@Param			windowid			query	int		false	"Maintenance window ID"
+//	@Param			situationid			query	int		false	"Situation ID"
+//	@Param			templateinstanceid	query	int		false	"Situation template instance ID"
+//	@Param			from				query	string	false	"Start date (example: 2024-05-10T00:00:00.000+02:00)"
+//	@Param			to					query	string	false	"End date (excluded)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		maintenance.SuppressedAction	"list of suppressed actions"
+//	@Failure		400	{object}	httputil.APIError				"Bad Request"
+//	@Failure		403	{object}	httputil.APIError				"Forbidden"
+//	@Failure		500	{object}	httputil.APIError				"Internal Server Error"
+//	@Router			/engine/maintenance_windows/suppressed_actions [get]
+func GetSuppressedActions(w http.ResponseWriter, r *http.Request) {
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeMaintenance, permissions.All, permissions.ActionList)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	windowID, err := QueryParamToOptionalInt64(r, "windowid", 0)
+	if err != nil {
+		zap.L().Warn("Error on parsing maintenance window id", zap.String("windowid", r.URL.Query().Get("windowid")), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+	situationID, err := QueryParamToOptionalInt64(r, "situationid", 0)
+	if err != nil {
+		zap.L().Warn("Error on parsing situation id", zap.String("situationid", r.URL.Query().Get("situationid")), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+	templateInstanceID, err := QueryParamToOptionalInt64(r, "templateinstanceid", 0)
+	if err != nil {
+		zap.L().Warn("Error on parsing template instance id", zap.String("templateinstanceid", r.URL.Query().Get("templateinstanceid")), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingInteger, err)
+		return
+	}
+	from, err := QueryParamToOptionalTime(r, "from", time.Time{})
+	if err != nil {
+		zap.L().Warn("Error on parsing from date", zap.String("from", r.URL.Query().Get("from")), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingDateTime, err)
+		return
+	}
+	to, err := QueryParamToOptionalTime(r, "to", time.Time{})
+	if err != nil {
+		zap.L().Warn("Error on parsing to date", zap.String("to", r.URL.Query().Get("to")), zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIParsingDateTime, err)
+		return
+	}
+
+	filter := maintenance.SuppressedActionFilter{
+		WindowID:           windowID,
+		SituationID:        situationID,
+		TemplateInstanceID: templateInstanceID,
+		From:               from,
+		To:                 to,
+	}
+	actions, err := maintenance.R().GetSuppressedActions(filter)
+	if err != nil {
+		zap.L().Error("Error getting suppressed actions", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, actions)
+}
@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/export"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/situation"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/utils/httputil"
 
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/history"
@@ -64,6 +68,73 @@ func baseSearchOptions(w http.ResponseWriter, r *http.Request) (history.GetHisto
 	return options, httputil.APIError{}, nil
 }
 
+// Search godoc
+//
+//	@Id				Search
+//
+//	@Summary		query situations history data
+//	@Description	query the history of several situations and instances at once, with field projections, down-sampling and cursor pagination
+//	@Description	Down-sampling operations: first, latest, sum, max, min, avg and percentiles (ie. p50, p95, p99)
+//	@Tags			Search
+//	@Accept			json
+//	@Produce		json
+//	@Param			query	body	search.Query	true	"query (json)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	search.QueryPage	"query result"
+//	@Failure		400	{object}	httputil.APIError	"Bad Request"
+//	@Failure		403	{object}	httputil.APIError	"Forbidden"
+//	@Failure		404	{object}	httputil.APIError	"Not Found"
+//	@Failure		500	{object}	httputil.APIError	"Internal Server Error"
+//	@Router			/engine/search [post]
+func Search(w http.ResponseWriter, r *http.Request) {
+	var query search.Query
+	err := json.NewDecoder(r.Body).Decode(&query)
+	if err != nil {
+		zap.L().Warn("Search query json decode", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDecodeJSONBody, err)
+		return
+	}
+
+	if !checkSearchSituations(w, r, query.Situations) {
+		return
+	}
+
+	page, err := history.S().Search(query)
+	if err != nil {
+		zap.L().Error("Cannot execute search query", zap.Error(err))
+		httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+		return
+	}
+
+	httputil.JSON(w, r, page)
+}
+
+// checkSearchSituations checks that the selected situations exist and can be searched by the user
+// It renders the error and returns false otherwise
+func checkSearchSituations(w http.ResponseWriter, r *http.Request, selectors []search.SituationSelector) bool {
+	userCtx, _ := GetUserFromContext(r)
+	for _, selector := range selectors {
+		if !userCtx.HasPermission(permissions.New(permissions.TypeSituation, strconv.FormatInt(selector.SituationID, 10), permissions.ActionSearch)) {
+			httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+			return false
+		}
+
+		_, found, err := situation.R().Get(selector.SituationID)
+		if err != nil {
+			zap.L().Error("Cannot retrieve situation", zap.Int64("situationID", selector.SituationID), zap.Error(err))
+			httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+			return false
+		}
+		if !found {
+			zap.L().Warn("Situation does not exists", zap.Int64("situationID", selector.SituationID))
+			httputil.Error(w, r, httputil.ErrAPIDBResourceNotFound, fmt.Errorf("situation %d not found", selector.SituationID))
+			return false
+		}
+	}
+	return true
+}
+
 // SearchLast Search godoc
 //
 //	@Id				SearchLast Search
@@ -211,22 +282,9 @@ func SearchLastByCustomInterval(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	interval, err := time.ParseDuration(r.URL.Query().Get("interval"))
+	interval, referenceDate, apiError, err := customIntervalParams(r)
 	if err != nil {
-		zap.L().Warn("Error on parsing interval", zap.String("interval", r.URL.Query().Get("interval")), zap.Error(err))
-		httputil.Error(w, r, httputil.ErrAPIParsingDuration, fmt.Errorf("interval %s is not supported", r.URL.Query().Get("interval")))
-		return
-	}
-	if interval < time.Minute {
-		zap.L().Warn("Too small interval", zap.Duration("interval", interval))
-		httputil.Error(w, r, httputil.ErrAPIParsingDuration, fmt.Errorf("interval %s is too small (<1min)", interval))
-		return
-	}
-
-	referenceDate, err := QueryParamToTime(r, "referencedate")
-	if err != nil {
-		zap.L().Warn("Parse input mindate", zap.Error(err), zap.String("mindate", r.URL.Query().Get("mindate")))
-		httputil.Error(w, r, httputil.ErrAPIParsingDateTime, err)
+		httputil.Error(w, r, apiError, err)
 		return
 	}
 
@@ -257,3 +315,113 @@ func SearchLastByCustomInterval(w http.ResponseWriter, r *http.Request) {
 
 	httputil.JSON(w, r, result)
 }
+
+// SearchLastByCustomIntervalStream godoc
+//
+//	@Id				SearchLastByCustomIntervalStream
+//
+//	@Summary		query situation history data by custom interval, as a stream
+//	@Description	streams the records of /engine/search/last/bycustominterval as soon as they are read, without loading the whole range in memory
+//	@Description	The records are written as newline delimited JSON (one record per line), or as a single JSON array with format=json
+//	@Description	The stream is not bounded by the request timeout, and stops when the client disconnects
+//	@Tags			Search
+//	@Produce		json
+//	@Param			situationid				query	int		false	"situationid"
+//	@Param			situationinstanceid		query	[]int	false	"situationinstanceid"
+//	@Param			maxdate					query	string	false	"time.Time"
+//	@Param			mindate					query	string	false	"time.Time"
+//	@Param			referencedate			query	string	true	"time.Time"
+//	@Param			interval				query	string	true	"time.Duration"
+//	@Param			format					query	string	false	"ndjson (default) or json"
+//	@Param			includeCalendarStatus	query	bool	false	"if true, adds calendar status flags (isNowOutsideCalendar and wereRulesOutsideCalendar)"
+//	@Security		Bearer
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		search.SituationHistoryRecord	"situation history records"
+//	@Failure		500	{object}	httputil.APIError				"Internal Server Error"
+//	@Router			/engine/search/last/bycustominterval/stream [get]
+func SearchLastByCustomIntervalStream(w http.ResponseWriter, r *http.Request) {
+
+	options, apiError, err := baseSearchOptions(w, r)
+	if err != nil {
+		httputil.Error(w, r, apiError, err)
+		return
+	}
+
+	interval, referenceDate, apiError, err := customIntervalParams(r)
+	if err != nil {
+		httputil.Error(w, r, apiError, err)
+		return
+	}
+
+	userCtx, _ := GetUserFromContext(r)
+	if !userCtx.HasPermission(permissions.New(permissions.TypeSituation, strconv.FormatInt(options.SituationID, 10), permissions.ActionSearch)) {
+		httputil.Error(w, r, httputil.ErrAPISecurityNoPermissions, errors.New("missing permission"))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httputil.Error(w, r, httputil.ErrAPIProcessError, errors.New("expected http.ResponseWriter to be an http.Flusher"))
+		return
+	}
+
+	stream, err := export.NewHistoryStreamWriter(w, r.URL.Query().Get("format"))
+	if err != nil {
+		httputil.Error(w, r, httputil.ErrAPIUnexpectedParamValue, err)
+		return
+	}
+
+	started := false
+	err = history.S().StreamHistorySituationsByCustomInterval(r.Context(), options, interval, referenceDate, func(records []search.SituationHistoryRecord) error {
+		if !started {
+			w.Header().Set("Content-Type", stream.ContentType())
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if err := stream.Write(records); err != nil {
+			return err
+		}
+		// Flush data to be sent directly to the client
+		flusher.Flush()
+		return nil
+	})
+	if err != nil {
+		if !started {
+			httputil.Error(w, r, httputil.ErrAPIDBSelectFailed, err)
+			return
+		}
+		// The response has already started, the stream is truncated
+		zap.L().Warn("History stream interrupted", zap.Int("records", stream.Count()), zap.Error(err))
+		return
+	}
+
+	if !started {
+		w.Header().Set("Content-Type", stream.ContentType())
+		w.WriteHeader(http.StatusOK)
+	}
+	if err = stream.Close(); err != nil {
+		zap.L().Warn("History stream close", zap.Error(err))
+	}
+}
+
+// customIntervalParams parses the interval and referencedate query parameters of the custom interval searches
+func customIntervalParams(r *http.Request) (time.Duration, time.Time, httputil.APIError, error) {
+	interval, err := time.ParseDuration(r.URL.Query().Get("interval"))
+	if err != nil {
+		zap.L().Warn("Error on parsing interval", zap.String("interval", r.URL.Query().Get("interval")), zap.Error(err))
+		return 0, time.Time{}, httputil.ErrAPIParsingDuration, fmt.Errorf("interval %s is not supported", r.URL.Query().Get("interval"))
+	}
+	if interval < time.Minute {
+		zap.L().Warn("Too small interval", zap.Duration("interval", interval))
+		return 0, time.Time{}, httputil.ErrAPIParsingDuration, fmt.Errorf("interval %s is too small (<1min)", interval)
+	}
+
+	referenceDate, err := QueryParamToTime(r, "referencedate")
+	if err != nil {
+		zap.L().Warn("Parse input mindate", zap.Error(err), zap.String("mindate", r.URL.Query().Get("mindate")))
+		return 0, time.Time{}, httputil.ErrAPIParsingDateTime, err
+	}
+
+	return interval, referenceDate, httputil.APIError{}, nil
+}
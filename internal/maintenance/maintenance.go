@@ -0,0 +1,130 @@
+package maintenance
+
+import (
+	"errors"
+	"time"
+)
+
+// Window is a planned maintenance, during which the create-issue, notify and situation-reporting actions
+// of the situations in its scope are suppressed (the facts and the situations history are still computed)
+type Window struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Reason    string    `json:"reason"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Scope     Scope     `json:"scope"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+// Scope is the set of situations covered by a maintenance window
+// A situation is covered if it matches any of the listed situations, template instances, functional situations or tags
+type Scope struct {
+	All                    bool    `json:"all,omitempty"` // covers every situation
+	SituationIDs           []int64 `json:"situationIds,omitempty"`
+	TemplateInstanceIDs    []int64 `json:"templateInstanceIds,omitempty"`
+	FunctionalSituationIDs []int64 `json:"functionalSituationIds,omitempty"`
+	TagIDs                 []int64 `json:"tagIds,omitempty"`
+}
+
+// Target is an evaluated situation (and template instance), with its functional situations and tags
+type Target struct {
+	SituationID            int64
+	TemplateInstanceID     int64
+	FunctionalSituationIDs []int64
+	TagIDs                 []int64
+}
+
+// SuppressedAction is an action which would have fired without a maintenance window
+type SuppressedAction struct {
+	ID                 int64                  `json:"id"`
+	WindowID           int64                  `json:"windowId"`
+	TS                 time.Time              `json:"ts"`
+	Action             string                 `json:"action"`
+	SituationID        int64                  `json:"situationId"`
+	TemplateInstanceID int64                  `json:"templateInstanceId"`
+	SituationHistoryID int64                  `json:"situationHistoryId"`
+	RuleID             int64                  `json:"ruleId"`
+	RuleVersion        int64                  `json:"ruleVersion"`
+	CaseName           string                 `json:"caseName"`
+	Parameters         map[string]interface{} `json:"parameters"`
+}
+
+// SuppressedActionFilter filters the suppressed actions, the zero values are ignored
+type SuppressedActionFilter struct {
+	WindowID           int64
+	SituationID        int64
+	TemplateInstanceID int64
+	From               time.Time
+	To                 time.Time
+}
+
+// Validate checks if the maintenance window is valid
+func (w Window) Validate() error {
+	if w.Name == "" {
+		return errors.New("maintenance window name is required")
+	}
+	if w.Reason == "" {
+		return errors.New("maintenance window reason is required")
+	}
+	if w.Start.IsZero() || w.End.IsZero() {
+		return errors.New("maintenance window start and end are required")
+	}
+	if !w.End.After(w.Start) {
+		return errors.New("maintenance window end must be after its start")
+	}
+	if w.Scope.IsEmpty() {
+		return errors.New("maintenance window scope is required (use all to cover every situation)")
+	}
+	return nil
+}
+
+// IsActive returns true if the maintenance window covers a timestamp, its end is excluded
+func (w Window) IsActive(ts time.Time) bool {
+	return !ts.Before(w.Start) && ts.Before(w.End)
+}
+
+// IsEmpty returns true if the scope covers no situation
+func (s Scope) IsEmpty() bool {
+	return !s.All && len(s.SituationIDs) == 0 && len(s.TemplateInstanceIDs) == 0 &&
+		len(s.FunctionalSituationIDs) == 0 && len(s.TagIDs) == 0
+}
+
+// NeedsMemberships returns true if the scope matching requires the functional situations and tags of the target
+func (s Scope) NeedsMemberships() bool {
+	return !s.All && (len(s.FunctionalSituationIDs) > 0 || len(s.TagIDs) > 0)
+}
+
+// Matches returns true if the scope covers a target
+func (s Scope) Matches(target Target) bool {
+	if s.All {
+		return true
+	}
+	if contains(s.SituationIDs, target.SituationID) {
+		return true
+	}
+	if target.TemplateInstanceID != 0 && contains(s.TemplateInstanceIDs, target.TemplateInstanceID) {
+		return true
+	}
+	for _, id := range target.FunctionalSituationIDs {
+		if contains(s.FunctionalSituationIDs, id) {
+			return true
+		}
+	}
+	for _, id := range target.TagIDs {
+		if contains(s.TagIDs, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
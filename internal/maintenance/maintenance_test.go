@@ -0,0 +1,79 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowValidate(t *testing.T) {
+	start := time.Date(2024, 5, 10, 22, 0, 0, 0, time.UTC)
+	valid := Window{Name: "database upgrade", Reason: "planned upgrade", Start: start, End: start.Add(2 * time.Hour), Scope: Scope{SituationIDs: []int64{1}}}
+	if err := valid.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	invalids := []Window{
+		{Reason: "planned upgrade", Start: start, End: start.Add(time.Hour), Scope: Scope{All: true}},
+		{Name: "database upgrade", Start: start, End: start.Add(time.Hour), Scope: Scope{All: true}},
+		{Name: "database upgrade", Reason: "planned upgrade", Start: start, Scope: Scope{All: true}},
+		{Name: "database upgrade", Reason: "planned upgrade", Start: start, End: start, Scope: Scope{All: true}},
+		{Name: "database upgrade", Reason: "planned upgrade", Start: start, End: start.Add(time.Hour)},
+	}
+	for _, w := range invalids {
+		if err := w.Validate(); err == nil {
+			t.Errorf("expected window %+v to be invalid", w)
+		}
+	}
+}
+
+func TestWindowIsActive(t *testing.T) {
+	start := time.Date(2024, 5, 10, 22, 0, 0, 0, time.UTC)
+	w := Window{Start: start, End: start.Add(2 * time.Hour)}
+	if !w.IsActive(start) || !w.IsActive(start.Add(time.Hour)) {
+		t.Error("expected the window to be active from its start")
+	}
+	if w.IsActive(start.Add(-time.Second)) || w.IsActive(start.Add(2*time.Hour)) {
+		t.Error("expected the window to be inactive before its start and from its end")
+	}
+}
+
+func TestScopeMatches(t *testing.T) {
+	target := Target{SituationID: 1, TemplateInstanceID: 10, FunctionalSituationIDs: []int64{100}, TagIDs: []int64{1000}}
+
+	matching := []Scope{
+		{All: true},
+		{SituationIDs: []int64{2, 1}},
+		{TemplateInstanceIDs: []int64{10}},
+		{FunctionalSituationIDs: []int64{100}},
+		{TagIDs: []int64{1000}},
+	}
+	for _, s := range matching {
+		if !s.Matches(target) {
+			t.Errorf("expected scope %+v to match", s)
+		}
+	}
+
+	notMatching := []Scope{
+		{},
+		{SituationIDs: []int64{2}},
+		{TemplateInstanceIDs: []int64{11}, FunctionalSituationIDs: []int64{101}, TagIDs: []int64{1001}},
+	}
+	for _, s := range notMatching {
+		if s.Matches(target) {
+			t.Errorf("expected scope %+v not to match", s)
+		}
+	}
+
+	if (Scope{TemplateInstanceIDs: []int64{0}}).Matches(Target{SituationID: 1}) {
+		t.Error("expected a situation without template instance not to match an instance scope")
+	}
+}
+
+func TestScopeNeedsMemberships(t *testing.T) {
+	if (Scope{SituationIDs: []int64{1}}).NeedsMemberships() || (Scope{All: true, TagIDs: []int64{1}}).NeedsMemberships() {
+		t.Error("expected no membership lookup without tags or functional situations")
+	}
+	if !(Scope{TagIDs: []int64{1}}).NeedsMemberships() || !(Scope{FunctionalSituationIDs: []int64{1}}).NeedsMemberships() {
+		t.Error("expected a membership lookup with tags or functional situations")
+	}
+}
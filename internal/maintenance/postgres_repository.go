@@ -0,0 +1,222 @@
+package maintenance
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	table           = "maintenance_window_v1"
+	suppressedTable = "maintenance_suppressed_action_v1"
+)
+
+var windowColumns = []string{"id", "name", "reason", "start_date", "end_date", "scope", "created_by", "created_at"}
+
+var suppressedColumns = []string{"id", "coalesce(window_id, 0)", "ts", "action", "situation_id", "template_instance_id",
+	"situation_history_id", "rule_id", "rule_version", "case_name", "parameters"}
+
+// PostgresRepository is a repository containing the maintenance windows based on a PSQL database
+// and implementing the repository interface
+type PostgresRepository struct {
+	conn *sqlx.DB
+}
+
+// NewPostgresRepository returns a new instance of PostgresRepository
+func NewPostgresRepository(dbClient *sqlx.DB) Repository {
+	r := PostgresRepository{
+		conn: dbClient,
+	}
+	var ifm Repository = &r
+	return ifm
+}
+
+// Create creates a new maintenance window in the repository
+func (r *PostgresRepository) Create(window Window) (int64, error) {
+	if err := window.Validate(); err != nil {
+		return -1, err
+	}
+	scope, err := json.Marshal(window.Scope)
+	if err != nil {
+		return -1, err
+	}
+
+	var id int64
+	err = newStatement().
+		Insert(table).
+		Columns("name", "reason", "start_date", "end_date", "scope", "created_by", "created_at").
+		Values(window.Name, window.Reason, window.Start.UTC(), window.End.UTC(), string(scope), window.CreatedBy,
+			time.Now().Truncate(1*time.Millisecond).UTC()).
+		Suffix("RETURNING \"id\"").
+		RunWith(r.conn.DB).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// Get returns a maintenance window by its ID
+func (r *PostgresRepository) Get(id int64) (Window, bool, error) {
+	windows, err := r.query(newStatement().Select(windowColumns...).From(table).Where(sq.Eq{"id": id}))
+	if err != nil {
+		return Window{}, false, err
+	}
+	if len(windows) == 0 {
+		return Window{}, false, nil
+	}
+	return windows[0], true, nil
+}
+
+// GetAll returns all the maintenance windows, the most recent first
+func (r *PostgresRepository) GetAll() ([]Window, error) {
+	return r.query(newStatement().Select(windowColumns...).From(table).OrderBy("start_date desc", "id desc"))
+}
+
+// GetActive returns the maintenance windows active at a timestamp
+func (r *PostgresRepository) GetActive(ts time.Time) ([]Window, error) {
+	return r.query(newStatement().Select(windowColumns...).From(table).
+		Where(sq.LtOrEq{"start_date": ts}).
+		Where(sq.Gt{"end_date": ts}).
+		OrderBy("id"))
+}
+
+func (r *PostgresRepository) query(statement sq.SelectBuilder) ([]Window, error) {
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := make([]Window, 0)
+	for rows.Next() {
+		var w Window
+		var scope []byte
+		err = rows.Scan(&w.ID, &w.Name, &w.Reason, &w.Start, &w.End, &scope, &w.CreatedBy, &w.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(scope, &w.Scope); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+// Update updates a maintenance window, the actions already suppressed are kept
+func (r *PostgresRepository) Update(window Window) error {
+	if err := window.Validate(); err != nil {
+		return err
+	}
+	scope, err := json.Marshal(window.Scope)
+	if err != nil {
+		return err
+	}
+
+	res, err := newStatement().
+		Update(table).
+		Set("name", window.Name).
+		Set("reason", window.Reason).
+		Set("start_date", window.Start.UTC()).
+		Set("end_date", window.End.UTC()).
+		Set("scope", string(scope)).
+		Where(sq.Eq{"id": window.ID}).
+		RunWith(r.conn.DB).
+		Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// Delete deletes a maintenance window, its suppressed actions are kept without window
+func (r *PostgresRepository) Delete(id int64) error {
+	res, err := newStatement().Delete(table).Where(sq.Eq{"id": id}).RunWith(r.conn.DB).Exec()
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+// CreateSuppressedAction records an action suppressed by a maintenance window
+func (r *PostgresRepository) CreateSuppressedAction(action SuppressedAction) (int64, error) {
+	parameters, err := json.Marshal(action.Parameters)
+	if err != nil {
+		return -1, err
+	}
+
+	var id int64
+	err = newStatement().
+		Insert(suppressedTable).
+		Columns("window_id", "ts", "action", "situation_id", "template_instance_id", "situation_history_id",
+			"rule_id", "rule_version", "case_name", "parameters").
+		Values(action.WindowID, action.TS.UTC(), action.Action, action.SituationID, action.TemplateInstanceID, action.SituationHistoryID,
+			action.RuleID, action.RuleVersion, action.CaseName, string(parameters)).
+		Suffix("RETURNING \"id\"").
+		RunWith(r.conn.DB).
+		QueryRow().
+		Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// GetSuppressedActions returns the suppressed actions matching a filter, the most recent first
+func (r *PostgresRepository) GetSuppressedActions(filter SuppressedActionFilter) ([]SuppressedAction, error) {
+	statement := newStatement().Select(suppressedColumns...).From(suppressedTable).OrderBy("ts desc", "id desc")
+	if filter.WindowID != 0 {
+		statement = statement.Where(sq.Eq{"window_id": filter.WindowID})
+	}
+	if filter.SituationID != 0 {
+		statement = statement.Where(sq.Eq{"situation_id": filter.SituationID})
+	}
+	if filter.TemplateInstanceID != 0 {
+		statement = statement.Where(sq.Eq{"template_instance_id": filter.TemplateInstanceID})
+	}
+	if !filter.From.IsZero() {
+		statement = statement.Where(sq.GtOrEq{"ts": filter.From})
+	}
+	if !filter.To.IsZero() {
+		statement = statement.Where(sq.Lt{"ts": filter.To})
+	}
+
+	rows, err := statement.RunWith(r.conn.DB).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := make([]SuppressedAction, 0)
+	for rows.Next() {
+		var a SuppressedAction
+		var parameters []byte
+		err = rows.Scan(&a.ID, &a.WindowID, &a.TS, &a.Action, &a.SituationID, &a.TemplateInstanceID,
+			&a.SituationHistoryID, &a.RuleID, &a.RuleVersion, &a.CaseName, &parameters)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(parameters, &a.Parameters); err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
+
+func checkAffected(res sql.Result) error {
+	i, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if i != 1 {
+		return errors.New("no row updated (or multiple row updated) instead of 1 row")
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package maintenance
+
+import (
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/tag"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/functionalsituation"
+)
+
+// FindWindow returns the first maintenance window active at ts whose scope covers a situation and template instance
+// The functional situations and tags of the situation are only loaded if an active window needs them
+func FindWindow(situationID int64, templateInstanceID int64, ts time.Time) (Window, bool, error) {
+	windows, err := R().GetActive(ts)
+	if err != nil {
+		return Window{}, false, err
+	}
+
+	target := Target{SituationID: situationID, TemplateInstanceID: templateInstanceID}
+	membershipsLoaded := false
+	for _, window := range windows {
+		if window.Scope.NeedsMemberships() && !membershipsLoaded {
+			if target, err = loadMemberships(target); err != nil {
+				return Window{}, false, err
+			}
+			membershipsLoaded = true
+		}
+		if window.Scope.Matches(target) {
+			return window, true, nil
+		}
+	}
+	return Window{}, false, nil
+}
+
+// loadMemberships adds the functional situations and the tags of the situation and template instance of a target
+func loadMemberships(target Target) (Target, error) {
+	if functionalsituation.R() != nil {
+		ids, err := functionalsituation.R().GetIDsBySituation(target.SituationID, target.TemplateInstanceID)
+		if err != nil {
+			return target, err
+		}
+		target.FunctionalSituationIDs = ids
+	}
+
+	if tag.R() != nil {
+		tags, err := tag.R().GetTagsBySituationId(target.SituationID)
+		if err != nil {
+			return target, err
+		}
+		if target.TemplateInstanceID != 0 {
+			instanceTags, err := tag.R().GetTagsByTemplateInstanceId(target.TemplateInstanceID)
+			if err != nil {
+				return target, err
+			}
+			tags = append(tags, instanceTags...)
+		}
+		for _, t := range tags {
+			target.TagIDs = append(target.TagIDs, t.Id)
+		}
+	}
+	return target, nil
+}
@@ -0,0 +1,93 @@
+package model
+
+import "errors"
+
+// CatalogRootCause is a rootcause shared by many situations and rules
+// Each linked situation and rule gets its own rootcause (and actions), kept in sync with the catalog
+type CatalogRootCause struct {
+	ID          int64           `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Actions     []CatalogAction `json:"actions"`
+}
+
+// CatalogAction is an action of a catalog rootcause
+type CatalogAction struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Description        string `json:"description"`
+	CatalogRootCauseID int64  `json:"catalogRootCauseId"`
+}
+
+// CatalogLink is a situation and rule linked to a catalog rootcause
+type CatalogLink struct {
+	SituationID int64 `json:"situationId"`
+	RuleID      int64 `json:"ruleId"`
+	RootCauseID int64 `json:"rootCauseId"` // rootcause of the situation and rule
+}
+
+// CatalogRootCauseStats is the usage of a catalog rootcause in the issues resolutions, on all its linked situations
+type CatalogRootCauseStats struct {
+	ID          int64                `json:"id"`
+	Name        string               `json:"name"`
+	Occurrences int64                `json:"occurrences"`
+	UsageRate   float64              `json:"usageRate"`
+	Actions     []CatalogActionStats `json:"actions"`
+}
+
+// CatalogActionStats is the usage of a catalog action in the issues resolutions, on all its linked situations
+type CatalogActionStats struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Occurrences int64   `json:"occurrences"`
+	UsageRate   float64 `json:"usageRate"`
+}
+
+// IsValid checks if a catalog rootcause is valid and has no missing mandatory fields
+func (rootCause CatalogRootCause) IsValid() (bool, error) {
+	if rootCause.Name == "" {
+		return false, errors.New("missing Name")
+	}
+	if rootCause.Description == "" {
+		return false, errors.New("missing Description")
+	}
+	names := make(map[string]bool, len(rootCause.Actions))
+	for _, action := range rootCause.Actions {
+		if action.Name == "" {
+			return false, errors.New("missing action Name")
+		}
+		if action.Description == "" {
+			return false, errors.New("missing action Description")
+		}
+		if names[action.Name] {
+			return false, errors.New("duplicate action Name " + action.Name)
+		}
+		names[action.Name] = true
+	}
+	return true, nil
+}
+
+// IsValid checks if a catalog action is valid and has no missing mandatory fields
+func (action CatalogAction) IsValid() (bool, error) {
+	if action.Name == "" {
+		return false, errors.New("missing Name")
+	}
+	if action.Description == "" {
+		return false, errors.New("missing Description")
+	}
+	if action.CatalogRootCauseID == 0 {
+		return false, errors.New("missing CatalogRootCauseID (or 0 value)")
+	}
+	return true, nil
+}
+
+// IsValid checks if a catalog link is valid and has no missing mandatory fields
+func (link CatalogLink) IsValid() (bool, error) {
+	if link.SituationID == 0 {
+		return false, errors.New("missing SituationID (or 0 value)")
+	}
+	if link.RuleID == 0 {
+		return false, errors.New("missing RuleID (or 0 value)")
+	}
+	return true, nil
+}
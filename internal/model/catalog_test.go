@@ -0,0 +1,37 @@
+package model
+
+import "testing"
+
+func TestCatalogRootCauseIsValid(t *testing.T) {
+	valid := CatalogRootCause{Name: "Carrier strike", Description: "d", Actions: []CatalogAction{{Name: "Reroute", Description: "d"}}}
+	if ok, err := valid.IsValid(); !ok {
+		t.Error(err)
+	}
+
+	invalids := []CatalogRootCause{
+		{Description: "d"},
+		{Name: "Carrier strike"},
+		{Name: "Carrier strike", Description: "d", Actions: []CatalogAction{{Description: "d"}}},
+		{Name: "Carrier strike", Description: "d", Actions: []CatalogAction{{Name: "Reroute", Description: "d"}, {Name: "Reroute", Description: "d"}}},
+	}
+	for _, rootCause := range invalids {
+		if ok, _ := rootCause.IsValid(); ok {
+			t.Errorf("expected rootcause %+v to be invalid", rootCause)
+		}
+	}
+}
+
+func TestCatalogActionAndLinkIsValid(t *testing.T) {
+	if ok, _ := (CatalogAction{Name: "Reroute", Description: "d"}).IsValid(); ok {
+		t.Error("expected an action without catalog rootcause to be invalid")
+	}
+	if ok, err := (CatalogAction{Name: "Reroute", Description: "d", CatalogRootCauseID: 1}).IsValid(); !ok {
+		t.Error(err)
+	}
+	if ok, _ := (CatalogLink{SituationID: 1}).IsValid(); ok {
+		t.Error("expected a link without rule to be invalid")
+	}
+	if ok, err := (CatalogLink{SituationID: 1, RuleID: 2}).IsValid(); !ok {
+		t.Error(err)
+	}
+}
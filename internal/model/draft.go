@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 // FrontDraft is a type alias used for FrontRecommendation persistence
 // It allow an easier recognition inside the different functions to distinguish User Draft and generated Recommendation
 type FrontDraft = FrontRecommendation
@@ -9,30 +11,79 @@ type FrontDraft = FrontRecommendation
 type FrontRecommendation struct {
 	ConcurrencyUUID string            `json:"uuid"`
 	Tree            []*FrontRootCause `json:"tree"`
+	SimilarIssues   []SimilarIssue    `json:"similarIssues,omitempty"`
 }
 
 // FrontRootCause represent a single rootcause and its actions
 type FrontRootCause struct {
-	ID              int64          `json:"id"`
-	Name            string         `json:"name"`
-	Description     string         `json:"description"`
-	Selected        bool           `json:"selected"`
-	Custom          bool           `json:"custom"`
-	Occurrence      int64          `json:"occurrence"`
-	UsageRate       float64        `json:"usageRate"`
-	ClusteringScore float64        `json:"clusteringScore"`
-	Actions         []*FrontAction `json:"actions"`
+	ID              int64                `json:"id"`
+	Name            string               `json:"name"`
+	Description     string               `json:"description"`
+	Selected        bool                 `json:"selected"`
+	Custom          bool                 `json:"custom"`
+	Occurrence      int64                `json:"occurrence"`
+	UsageRate       float64              `json:"usageRate"`
+	ClusteringScore float64              `json:"clusteringScore"`
+	Score           *RecommendationScore `json:"score,omitempty"`
+	Actions         []*FrontAction       `json:"actions"`
 }
 
 // FrontAction represent a single action
 type FrontAction struct {
-	ID          int64   `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Selected    bool    `json:"selected"`
-	Custom      bool    `json:"custom"`
-	Occurrence  int64   `json:"occurrence"`
-	UsageRate   float64 `json:"usageRate"`
+	ID          int64                `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Selected    bool                 `json:"selected"`
+	Custom      bool                 `json:"custom"`
+	Occurrence  int64                `json:"occurrence"`
+	UsageRate   float64              `json:"usageRate"`
+	Score       *RecommendationScore `json:"score,omitempty"`
+}
+
+// SelectByName returns a copy of the recommendation tree with a rootcause and its actions selected by name (case insensitive)
+// It is used to apply the feedback of an issue to another one of a different situation or rule,
+// the rootcause and actions missing from the tree are added as custom ones
+func (recommendation FrontRecommendation) SelectByName(rootCause FrontRootCause, actions []*FrontAction) FrontRecommendation {
+	result := FrontRecommendation{
+		ConcurrencyUUID: recommendation.ConcurrencyUUID,
+		Tree:            make([]*FrontRootCause, 0, len(recommendation.Tree)+1),
+		SimilarIssues:   recommendation.SimilarIssues,
+	}
+
+	var selected *FrontRootCause
+	for _, rc := range recommendation.Tree {
+		c := *rc
+		c.Selected = false
+		c.Actions = make([]*FrontAction, 0, len(rc.Actions))
+		for _, a := range rc.Actions {
+			ca := *a
+			ca.Selected = false
+			c.Actions = append(c.Actions, &ca)
+		}
+		if selected == nil && strings.EqualFold(c.Name, rootCause.Name) {
+			selected = &c
+		}
+		result.Tree = append(result.Tree, &c)
+	}
+	if selected == nil {
+		selected = &FrontRootCause{ID: -1, Name: rootCause.Name, Description: rootCause.Description, Custom: true, Actions: make([]*FrontAction, 0)}
+		result.Tree = append(result.Tree, selected)
+	}
+	selected.Selected = true
+
+	for _, action := range actions {
+		found := false
+		for _, a := range selected.Actions {
+			if strings.EqualFold(a.Name, action.Name) {
+				a.Selected, found = true, true
+				break
+			}
+		}
+		if !found {
+			selected.Actions = append(selected.Actions, &FrontAction{ID: -1, Name: action.Name, Description: action.Description, Selected: true, Custom: true})
+		}
+	}
+	return result
 }
 
 // Ids of Issues to draft
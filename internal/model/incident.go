@@ -0,0 +1,151 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IncidentState is the state of an incident
+type IncidentState string
+
+const (
+	// IncidentOpen is the state of an incident with open or draft issues
+	IncidentOpen IncidentState = "open"
+	// IncidentClosed is the state of an incident which issues have been closed
+	IncidentClosed IncidentState = "closed"
+)
+
+// Incident groups the issues of a same upstream problem, so they can be handled at once
+type Incident struct {
+	ID                 int64         `json:"id"`
+	Name               string        `json:"name"`
+	State              IncidentState `json:"state" enums:"open,closed"`
+	CorrelationRuleID  *int64        `json:"correlationRuleId,omitempty"` // nil for a manual incident
+	CorrelationKey     string        `json:"correlationKey,omitempty"`
+	CreationTS         time.Time     `json:"createdAt"`
+	LastModificationTS time.Time     `json:"lastModified"`
+	LastIssueTS        time.Time     `json:"lastIssueAt"` // situation date of the last issue
+	ClosedAt           *time.Time    `json:"closedAt,omitempty"`
+	CloseBy            *string       `json:"closedBy,omitempty"`
+	IssueIDs           []int64       `json:"issueIds"`
+}
+
+// Correlation keys of an incident correlation rule
+const (
+	CorrelationKeyRule                = "rule"
+	CorrelationKeySituation           = "situation"
+	CorrelationKeyLevel               = "level"
+	CorrelationKeyFunctionalSituation = "functional_situation"
+	// CorrelationKeyParameterPrefix is followed by the name of a template instance parameter (ie. parameter:country)
+	CorrelationKeyParameterPrefix = "parameter:"
+)
+
+// IncidentCorrelationRule groups automatically the new issues sharing the same values on all the keys into an incident,
+// as long as their situation date is within the time window after the one of the last issue of the incident
+type IncidentCorrelationRule struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Enabled bool     `json:"enabled"`
+	Keys    []string `json:"keys" example:"rule,parameter:country"`
+	Window  string   `json:"window" example:"15m"`
+}
+
+// IssueCorrelationAttributes are the attributes of an issue which are not stored on the issue itself
+type IssueCorrelationAttributes struct {
+	FunctionalSituationIDs []int64
+	Parameters             map[string]interface{}
+}
+
+// IsValid checks if an incident correlation rule is valid and has no missing mandatory fields
+func (rule IncidentCorrelationRule) IsValid() (bool, error) {
+	if rule.Name == "" {
+		return false, errors.New("missing Name")
+	}
+	if len(rule.Keys) == 0 {
+		return false, errors.New("missing Keys")
+	}
+	seen := make(map[string]bool)
+	for _, key := range rule.Keys {
+		switch {
+		case key == CorrelationKeyRule, key == CorrelationKeySituation, key == CorrelationKeyLevel, key == CorrelationKeyFunctionalSituation:
+		case strings.HasPrefix(key, CorrelationKeyParameterPrefix) && len(key) > len(CorrelationKeyParameterPrefix):
+		default:
+			return false, fmt.Errorf("invalid key '%s'", key)
+		}
+		if seen[key] {
+			return false, fmt.Errorf("duplicate key '%s'", key)
+		}
+		seen[key] = true
+	}
+	if _, err := rule.WindowDuration(); err != nil {
+		return false, fmt.Errorf("invalid Window: %s", err)
+	}
+	return true, nil
+}
+
+// WindowDuration returns the time window of the rule
+func (rule IncidentCorrelationRule) WindowDuration() (time.Duration, error) {
+	d, err := time.ParseDuration(rule.Window)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, errors.New("must be positive")
+	}
+	return d, nil
+}
+
+// NeedsAttributes returns true if the rule uses the functional situations or the parameters of the issues
+func (rule IncidentCorrelationRule) NeedsAttributes() bool {
+	for _, key := range rule.Keys {
+		if key == CorrelationKeyFunctionalSituation || strings.HasPrefix(key, CorrelationKeyParameterPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CorrelationKey returns the values of the rule keys for an issue
+// It returns false if the issue has no value for one of the keys (ie. a missing parameter), the issue is then not correlated by the rule
+func (rule IncidentCorrelationRule) CorrelationKey(issue Issue, attributes IssueCorrelationAttributes) (string, bool) {
+	parts := make([]string, 0, len(rule.Keys))
+	for _, key := range rule.Keys {
+		var value string
+		switch {
+		case key == CorrelationKeyRule:
+			if issue.Rule.RuleID == 0 {
+				return "", false
+			}
+			value = strconv.FormatInt(issue.Rule.RuleID, 10)
+		case key == CorrelationKeySituation:
+			value = strconv.FormatInt(issue.SituationID, 10)
+		case key == CorrelationKeyLevel:
+			value = issue.Level.String()
+		case key == CorrelationKeyFunctionalSituation:
+			if len(attributes.FunctionalSituationIDs) == 0 {
+				return "", false
+			}
+			ids := append([]int64{}, attributes.FunctionalSituationIDs...)
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			s := make([]string, 0, len(ids))
+			for _, id := range ids {
+				s = append(s, strconv.FormatInt(id, 10))
+			}
+			value = strings.Join(s, ",")
+		case strings.HasPrefix(key, CorrelationKeyParameterPrefix):
+			v, ok := attributes.Parameters[strings.TrimPrefix(key, CorrelationKeyParameterPrefix)]
+			if !ok || v == nil {
+				return "", false
+			}
+			value = fmt.Sprint(v)
+		default:
+			return "", false
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, "|"), true
+}
@@ -0,0 +1,86 @@
+package model
+
+import "testing"
+
+func TestIncidentCorrelationRuleIsValid(t *testing.T) {
+	valid := IncidentCorrelationRule{Name: "outage", Keys: []string{CorrelationKeyRule, "parameter:country"}, Window: "15m"}
+	if ok, err := valid.IsValid(); !ok {
+		t.Error(err)
+	}
+
+	invalids := []IncidentCorrelationRule{
+		{Keys: []string{CorrelationKeyRule}, Window: "15m"},
+		{Name: "a", Window: "15m"},
+		{Name: "a", Keys: []string{"unknown"}, Window: "15m"},
+		{Name: "a", Keys: []string{"parameter:"}, Window: "15m"},
+		{Name: "a", Keys: []string{CorrelationKeyRule, CorrelationKeyRule}, Window: "15m"},
+		{Name: "a", Keys: []string{CorrelationKeyRule}, Window: "0s"},
+		{Name: "a", Keys: []string{CorrelationKeyRule}},
+	}
+	for _, rule := range invalids {
+		if ok, _ := rule.IsValid(); ok {
+			t.Errorf("expected rule %+v to be invalid", rule)
+		}
+	}
+}
+
+func TestIncidentCorrelationKey(t *testing.T) {
+	rule := IncidentCorrelationRule{Keys: []string{CorrelationKeyRule, CorrelationKeyFunctionalSituation, "parameter:country"}}
+	issue := Issue{SituationID: 1, Level: Critical, Rule: RuleData{RuleID: 12}}
+
+	key, ok := rule.CorrelationKey(issue, IssueCorrelationAttributes{
+		FunctionalSituationIDs: []int64{4, 2},
+		Parameters:             map[string]interface{}{"country": "FR"},
+	})
+	if !ok || key != "rule=12|functional_situation=2,4|parameter:country=FR" {
+		t.Errorf("unexpected key %s", key)
+	}
+
+	if _, ok = rule.CorrelationKey(issue, IssueCorrelationAttributes{FunctionalSituationIDs: []int64{2}}); ok {
+		t.Error("expected no key without the parameter")
+	}
+	if _, ok = rule.CorrelationKey(Issue{SituationID: 1}, IssueCorrelationAttributes{
+		FunctionalSituationIDs: []int64{2},
+		Parameters:             map[string]interface{}{"country": "FR"},
+	}); ok {
+		t.Error("expected no key without rule")
+	}
+
+	rule = IncidentCorrelationRule{Keys: []string{CorrelationKeySituation, CorrelationKeyLevel}}
+	if rule.NeedsAttributes() {
+		t.Error("expected a rule without attributes")
+	}
+	if key, _ = rule.CorrelationKey(issue, IssueCorrelationAttributes{}); key != "situation=1|level=critical" {
+		t.Errorf("unexpected key %s", key)
+	}
+}
+
+func TestFrontRecommendationSelectByName(t *testing.T) {
+	tree := FrontRecommendation{ConcurrencyUUID: "uuid", Tree: []*FrontRootCause{
+		{ID: 1, Name: "Network", Selected: true, Actions: []*FrontAction{{ID: 10, Name: "Restart", Selected: true}}},
+		{ID: 2, Name: "Disk", Actions: []*FrontAction{{ID: 20, Name: "Clean"}, {ID: 21, Name: "Extend"}}},
+	}}
+
+	result := tree.SelectByName(FrontRootCause{Name: "disk"}, []*FrontAction{{Name: "extend"}, {Name: "Alert", Description: "d"}})
+	if result.ConcurrencyUUID != "uuid" || len(result.Tree) != 2 {
+		t.Fatalf("unexpected tree %+v", result)
+	}
+	if result.Tree[0].Selected || result.Tree[0].Actions[0].Selected {
+		t.Error("expected the previous selection to be cleared")
+	}
+	if !tree.Tree[0].Selected {
+		t.Error("expected the source tree to be unchanged")
+	}
+	disk := result.Tree[1]
+	if !disk.Selected || disk.Custom || disk.Actions[0].Selected || !disk.Actions[1].Selected {
+		t.Errorf("unexpected rootcause %+v", disk)
+	}
+	if len(disk.Actions) != 3 || !disk.Actions[2].Custom || !disk.Actions[2].Selected || disk.Actions[2].Description != "d" {
+		t.Errorf("expected a custom action, got %+v", disk.Actions)
+	}
+
+	result = tree.SelectByName(FrontRootCause{Name: "Power"}, []*FrontAction{{Name: "Restart"}})
+	if len(result.Tree) != 3 || !result.Tree[2].Custom || !result.Tree[2].Selected || !result.Tree[2].Actions[0].Custom {
+		t.Errorf("expected a custom rootcause, got %+v", result.Tree)
+	}
+}
@@ -0,0 +1,185 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// IssueAnalyticsGroupBy is the grouping of the issue analytics
+type IssueAnalyticsGroupBy string
+
+const (
+	// IssueAnalyticsBySituation groups the issues by situation
+	IssueAnalyticsBySituation IssueAnalyticsGroupBy = "situation"
+	// IssueAnalyticsByRule groups the issues by rule
+	IssueAnalyticsByRule IssueAnalyticsGroupBy = "rule"
+	// IssueAnalyticsByLevel groups the issues by level
+	IssueAnalyticsByLevel IssueAnalyticsGroupBy = "level"
+	// IssueAnalyticsByFunctionalSituation groups the issues by functional situation (an issue can belong to many groups)
+	IssueAnalyticsByFunctionalSituation IssueAnalyticsGroupBy = "functional_situation"
+	// IssueAnalyticsByTag groups the issues by tag (an issue can belong to many groups)
+	IssueAnalyticsByTag IssueAnalyticsGroupBy = "tag"
+)
+
+// ToIssueAnalyticsGroupBy returns the issue analytics grouping of its string representation
+func ToIssueAnalyticsGroupBy(s string) (IssueAnalyticsGroupBy, error) {
+	switch groupBy := IssueAnalyticsGroupBy(s); groupBy {
+	case IssueAnalyticsBySituation, IssueAnalyticsByRule, IssueAnalyticsByLevel, IssueAnalyticsByFunctionalSituation, IssueAnalyticsByTag:
+		return groupBy, nil
+	}
+	return "", fmt.Errorf("invalid group by '%s' (situation, rule, level, functional_situation or tag required)", s)
+}
+
+// IssueAnalytics is the aggregate reporting of the issues created over a time range
+type IssueAnalytics struct {
+	GroupBy IssueAnalyticsGroupBy `json:"groupBy" enums:"situation,rule,level,functional_situation,tag"`
+	From    time.Time             `json:"from"`
+	To      time.Time             `json:"to"`
+	Groups  []IssueAnalyticsGroup `json:"groups"`
+}
+
+// IssueAnalyticsGroup is the aggregate reporting of a group of issues
+// The mean times are in seconds, and are omitted if no issue of the group was acknowledged (or resolved)
+type IssueAnalyticsGroup struct {
+	Key             string                    `json:"key"`
+	Name            string                    `json:"name"`
+	Total           int64                     `json:"total"`
+	CountByState    map[string]int64          `json:"countByState"`
+	MTTA            *float64                  `json:"mtta,omitempty"`            // mean time to acknowledge (first assignment)
+	MTTR            *float64                  `json:"mttr,omitempty"`            // mean time to resolve (closure, as in the SLA status)
+	FalseAlertRatio *float64                  `json:"falseAlertRatio,omitempty"` // rejected feedbacks on all the feedbacks
+	TopRootCauses   []IssueAnalyticsRootCause `json:"topRootCauses"`
+}
+
+// IssueAnalyticsRootCause is a rootcause of the resolutions of a group of issues
+// The rootcauses of different situations are merged by name
+type IssueAnalyticsRootCause struct {
+	Name        string  `json:"name"`
+	Occurrences int64   `json:"occurrences"`
+	Rate        float64 `json:"rate"` // occurrences on the number of resolved issues of the group
+}
+
+// IssueAnalyticsRecord is an issue of a group, as loaded for the analytics
+type IssueAnalyticsRecord struct {
+	IssueID        int64
+	GroupKey       string
+	GroupName      string
+	State          IssueState
+	CreationTS     time.Time
+	AcknowledgedAt *time.Time
+	ClosedAt       *time.Time
+	// LastModificationTS is the resolution time of the issues closed without closing time
+	LastModificationTS time.Time
+}
+
+type issueAnalyticsAccumulator struct {
+	group           IssueAnalyticsGroup
+	acknowledgedSum float64
+	acknowledgedN   int64
+	resolvedSum     float64
+	resolvedN       int64
+	confirmed       int64
+	rejected        int64
+	resolvedIssues  int64
+	rootCauses      map[string]int64
+	seenIssueIDs    map[int64]bool
+}
+
+// BuildIssueAnalyticsGroups aggregates the issue records by group, the groups are sorted by decreasing total
+// rootCauses are the rootcause names of the resolution of each issue, and only the top rootcauses of each group are kept
+func BuildIssueAnalyticsGroups(records []IssueAnalyticsRecord, rootCauses map[int64][]string, top int) []IssueAnalyticsGroup {
+	accumulators := make(map[string]*issueAnalyticsAccumulator)
+	keys := make([]string, 0)
+	for _, record := range records {
+		acc, ok := accumulators[record.GroupKey]
+		if !ok {
+			acc = &issueAnalyticsAccumulator{
+				group:        IssueAnalyticsGroup{Key: record.GroupKey, Name: record.GroupName, CountByState: make(map[string]int64)},
+				rootCauses:   make(map[string]int64),
+				seenIssueIDs: make(map[int64]bool),
+			}
+			accumulators[record.GroupKey] = acc
+			keys = append(keys, record.GroupKey)
+		}
+		if acc.seenIssueIDs[record.IssueID] {
+			continue
+		}
+		acc.seenIssueIDs[record.IssueID] = true
+		acc.add(record, rootCauses[record.IssueID])
+	}
+
+	groups := make([]IssueAnalyticsGroup, 0, len(keys))
+	for _, key := range keys {
+		groups = append(groups, accumulators[key].build(top))
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Total != groups[j].Total {
+			return groups[i].Total > groups[j].Total
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+func (acc *issueAnalyticsAccumulator) add(record IssueAnalyticsRecord, rootCauses []string) {
+	acc.group.Total++
+	acc.group.CountByState[record.State.String()]++
+
+	if record.AcknowledgedAt != nil {
+		acc.acknowledgedSum += record.AcknowledgedAt.Sub(record.CreationTS).Seconds()
+		acc.acknowledgedN++
+	}
+	if resolved := resolvedAt(record.State, record.ClosedAt, record.LastModificationTS); resolved != nil {
+		acc.resolvedSum += resolved.Sub(record.CreationTS).Seconds()
+		acc.resolvedN++
+	}
+	switch record.State {
+	case ClosedFeedbackConfirmed, ClosedConfirmed:
+		acc.confirmed++
+	case ClosedFeedbackRejected, ClosedRejected:
+		acc.rejected++
+	}
+
+	if len(rootCauses) > 0 {
+		acc.resolvedIssues++
+	}
+	for _, name := range rootCauses {
+		acc.rootCauses[name]++
+	}
+}
+
+func (acc *issueAnalyticsAccumulator) build(top int) IssueAnalyticsGroup {
+	group := acc.group
+	if acc.acknowledgedN > 0 {
+		mtta := acc.acknowledgedSum / float64(acc.acknowledgedN)
+		group.MTTA = &mtta
+	}
+	if acc.resolvedN > 0 {
+		mttr := acc.resolvedSum / float64(acc.resolvedN)
+		group.MTTR = &mttr
+	}
+	if feedbacks := acc.confirmed + acc.rejected; feedbacks > 0 {
+		ratio := float64(acc.rejected) / float64(feedbacks)
+		group.FalseAlertRatio = &ratio
+	}
+
+	group.TopRootCauses = make([]IssueAnalyticsRootCause, 0, len(acc.rootCauses))
+	for name, occurrences := range acc.rootCauses {
+		group.TopRootCauses = append(group.TopRootCauses, IssueAnalyticsRootCause{
+			Name:        name,
+			Occurrences: occurrences,
+			Rate:        float64(occurrences) / float64(acc.resolvedIssues),
+		})
+	}
+	sort.Slice(group.TopRootCauses, func(i, j int) bool {
+		if group.TopRootCauses[i].Occurrences != group.TopRootCauses[j].Occurrences {
+			return group.TopRootCauses[i].Occurrences > group.TopRootCauses[j].Occurrences
+		}
+		return group.TopRootCauses[i].Name < group.TopRootCauses[j].Name
+	})
+	if top >= 0 && len(group.TopRootCauses) > top {
+		group.TopRootCauses = group.TopRootCauses[:top]
+	}
+	return group
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToIssueAnalyticsGroupBy(t *testing.T) {
+	if groupBy, err := ToIssueAnalyticsGroupBy("functional_situation"); err != nil || groupBy != IssueAnalyticsByFunctionalSituation {
+		t.Errorf("unexpected group by %s, %v", groupBy, err)
+	}
+	if _, err := ToIssueAnalyticsGroupBy("user"); err == nil {
+		t.Error("expected an unknown group by to be invalid")
+	}
+}
+
+func TestBuildIssueAnalyticsGroups(t *testing.T) {
+	created := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		ts := created.Add(d)
+		return &ts
+	}
+
+	records := []IssueAnalyticsRecord{
+		{IssueID: 1, GroupKey: "1", GroupName: "situation 1", State: ClosedFeedbackConfirmed, CreationTS: created, AcknowledgedAt: at(time.Minute), ClosedAt: at(time.Hour)},
+		{IssueID: 2, GroupKey: "1", GroupName: "situation 1", State: ClosedFeedbackRejected, CreationTS: created, AcknowledgedAt: at(3 * time.Minute), ClosedAt: at(3 * time.Hour)},
+		{IssueID: 3, GroupKey: "1", GroupName: "situation 1", State: ClosedFeedbackConfirmed, CreationTS: created, LastModificationTS: *at(2 * time.Hour)},
+		{IssueID: 4, GroupKey: "1", GroupName: "situation 1", State: Open, CreationTS: created, LastModificationTS: *at(time.Hour)},
+		{IssueID: 4, GroupKey: "1", GroupName: "situation 1", State: Open, CreationTS: created, LastModificationTS: *at(time.Hour)}, // linked twice to the group
+		{IssueID: 5, GroupKey: "2", GroupName: "situation 2", State: ClosedDiscard, CreationTS: created, LastModificationTS: *at(30 * time.Minute)},
+	}
+	rootCauses := map[int64][]string{
+		1: {"network"},
+		2: {"network"},
+		3: {"disk"},
+	}
+
+	groups := BuildIssueAnalyticsGroups(records, rootCauses, 1)
+	if len(groups) != 2 || groups[0].Key != "1" || groups[1].Key != "2" {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+
+	g := groups[0]
+	if g.Total != 4 || g.CountByState["closedfeedbackconfirmed"] != 2 || g.CountByState["closedfeedbackrejected"] != 1 || g.CountByState["open"] != 1 {
+		t.Errorf("unexpected counts %d %v", g.Total, g.CountByState)
+	}
+	if g.MTTA == nil || *g.MTTA != 120 {
+		t.Errorf("unexpected MTTA %v", g.MTTA)
+	}
+	if g.MTTR == nil || *g.MTTR != 7200 {
+		t.Errorf("unexpected MTTR %v", g.MTTR)
+	}
+	if g.FalseAlertRatio == nil || *g.FalseAlertRatio != 1.0/3 {
+		t.Errorf("unexpected false alert ratio %v", g.FalseAlertRatio)
+	}
+	if len(g.TopRootCauses) != 1 || g.TopRootCauses[0].Name != "network" || g.TopRootCauses[0].Occurrences != 2 || g.TopRootCauses[0].Rate != 2.0/3 {
+		t.Errorf("unexpected top rootcauses %+v", g.TopRootCauses)
+	}
+
+	// An issue closed without closing time is resolved at its last modification, as in its SLA status
+	g = groups[1]
+	if g.MTTR == nil || *g.MTTR != 1800 {
+		t.Errorf("unexpected MTTR %v", g.MTTR)
+	}
+	if g.MTTA != nil || g.FalseAlertRatio != nil || len(g.TopRootCauses) != 0 {
+		t.Errorf("expected no MTTA, ratio nor rootcauses without data, got %+v", g)
+	}
+}
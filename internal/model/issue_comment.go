@@ -0,0 +1,123 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// IssueCommentMaxLength is the maximum length of the content of an issue comment
+const IssueCommentMaxLength = 10000
+
+// issueCommentMentionRegexp matches the user mentions of a comment content (ie. "@jdoe"), not preceded by a word character (ie. "jdoe@example.com")
+var issueCommentMentionRegexp = regexp.MustCompile(`(?:^|[^\w@.])@([\w][\w.\-]*[\w]|[\w])`)
+
+// IssueComment is a comment of an issue conversation
+// A comment is an answer of another comment of the same issue if ParentID is set
+type IssueComment struct {
+	ID          int64                    `json:"id"`
+	IssueID     int64                    `json:"issueId"`
+	ParentID    *int64                   `json:"parentId,omitempty"`
+	Author      string                   `json:"author"`
+	Content     string                   `json:"content"`
+	Mentions    []string                 `json:"mentions"`
+	CreatedAt   time.Time                `json:"createdAt"`
+	EditedAt    *time.Time               `json:"editedAt,omitempty"`
+	Attachments []IssueCommentAttachment `json:"attachments"`
+	Replies     []IssueComment           `json:"replies,omitempty"`
+}
+
+// IssueCommentAttachment is a file attached to an issue comment, stored on the local disk
+type IssueCommentAttachment struct {
+	ID          int64     `json:"id"`
+	CommentID   int64     `json:"commentId"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	StorageName string    `json:"-"` // name of the file in the attachments directory
+	Author      string    `json:"author"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// IsValid checks if an issue comment is valid and has no missing mandatory fields
+func (comment IssueComment) IsValid() (bool, error) {
+	if comment.IssueID == 0 {
+		return false, errors.New("missing IssueID (or 0 value)")
+	}
+	if comment.Author == "" {
+		return false, errors.New("missing Author")
+	}
+	if comment.Content == "" {
+		return false, errors.New("missing Content")
+	}
+	if len(comment.Content) > IssueCommentMaxLength {
+		return false, errors.New("Content is too long")
+	}
+	if comment.ParentID != nil && *comment.ParentID == comment.ID {
+		return false, errors.New("a comment cannot answer itself")
+	}
+	return true, nil
+}
+
+// ParseIssueCommentMentions returns the distinct user logins mentioned in a comment content, in order of appearance
+func ParseIssueCommentMentions(content string) []string {
+	mentions := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range issueCommentMentionRegexp.FindAllStringSubmatch(content, -1) {
+		login := match[1]
+		if seen[login] {
+			continue
+		}
+		seen[login] = true
+		mentions = append(mentions, login)
+	}
+	return mentions
+}
+
+// NewIssueCommentMentions returns the mentions of an edited comment content which were not in the previous content
+func NewIssueCommentMentions(previous []string, content string) []string {
+	known := make(map[string]bool, len(previous))
+	for _, login := range previous {
+		known[login] = true
+	}
+	mentions := make([]string, 0)
+	for _, login := range ParseIssueCommentMentions(content) {
+		if !known[login] {
+			mentions = append(mentions, login)
+		}
+	}
+	return mentions
+}
+
+// BuildIssueCommentThreads nests the answers in their parent comment
+// The comments must be sorted chronologically, and the answers of an unknown parent are kept as root comments
+func BuildIssueCommentThreads(comments []IssueComment) []IssueComment {
+	children := make(map[int64][]IssueComment)
+	known := make(map[int64]bool, len(comments))
+	for _, comment := range comments {
+		known[comment.ID] = true
+	}
+	roots := make([]IssueComment, 0)
+	for _, comment := range comments {
+		if comment.ParentID != nil && known[*comment.ParentID] {
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+		} else {
+			roots = append(roots, comment)
+		}
+	}
+
+	var nest func(comment IssueComment, depth int) IssueComment
+	nest = func(comment IssueComment, depth int) IssueComment {
+		if depth > len(comments) { // protects against cycles
+			return comment
+		}
+		for _, child := range children[comment.ID] {
+			comment.Replies = append(comment.Replies, nest(child, depth+1))
+		}
+		return comment
+	}
+	for i, root := range roots {
+		roots[i] = nest(root, 0)
+	}
+	return roots
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIssueCommentMentions(t *testing.T) {
+	mentions := ParseIssueCommentMentions("@jdoe can you check with @a.smith, @jdoe and @ops-team? (not john@example.com)")
+	if !reflect.DeepEqual(mentions, []string{"jdoe", "a.smith", "ops-team"}) {
+		t.Errorf("unexpected mentions %v", mentions)
+	}
+	if mentions := ParseIssueCommentMentions("no mention."); len(mentions) != 0 {
+		t.Errorf("unexpected mentions %v", mentions)
+	}
+}
+
+func TestNewIssueCommentMentions(t *testing.T) {
+	mentions := NewIssueCommentMentions([]string{"jdoe"}, "@jdoe @asmith")
+	if !reflect.DeepEqual(mentions, []string{"asmith"}) {
+		t.Errorf("unexpected new mentions %v", mentions)
+	}
+}
+
+func TestIssueCommentIsValid(t *testing.T) {
+	comment := IssueComment{IssueID: 1, Author: "jdoe", Content: "hello"}
+	if ok, err := comment.IsValid(); !ok {
+		t.Errorf("expected a valid comment, got %v", err)
+	}
+	comment.Content = ""
+	if ok, _ := comment.IsValid(); ok {
+		t.Error("expected a comment without content to be invalid")
+	}
+}
+
+func TestBuildIssueCommentThreads(t *testing.T) {
+	parent := func(id int64) *int64 { return &id }
+	comments := []IssueComment{
+		{ID: 1, Content: "first"},
+		{ID: 2, ParentID: parent(1), Content: "answer"},
+		{ID: 3, Content: "second"},
+		{ID: 4, ParentID: parent(2), Content: "answer of answer"},
+		{ID: 5, ParentID: parent(99), Content: "orphan"},
+	}
+
+	threads := BuildIssueCommentThreads(comments)
+	if len(threads) != 3 || threads[0].ID != 1 || threads[1].ID != 3 || threads[2].ID != 5 {
+		t.Fatalf("unexpected threads %+v", threads)
+	}
+	if len(threads[0].Replies) != 1 || threads[0].Replies[0].ID != 2 {
+		t.Fatalf("unexpected replies %+v", threads[0].Replies)
+	}
+	if len(threads[0].Replies[0].Replies) != 1 || threads[0].Replies[0].Replies[0].ID != 4 {
+		t.Errorf("unexpected nested replies %+v", threads[0].Replies[0].Replies)
+	}
+}
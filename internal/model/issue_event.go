@@ -0,0 +1,126 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// IssueEventType is the type of an issue lifecycle event
+type IssueEventType string
+
+const (
+	// IssueEventCreated is emitted when an issue is created
+	IssueEventCreated IssueEventType = "created"
+	// IssueEventLevelChanged is emitted when an issue is created with another level than the open issues with the same key
+	IssueEventLevelChanged IssueEventType = "level_changed"
+	// IssueEventAssigned is emitted when an issue is assigned or unassigned
+	IssueEventAssigned IssueEventType = "assigned"
+	// IssueEventDrafted is emitted when a resolution draft is saved on an issue
+	IssueEventDrafted IssueEventType = "drafted"
+	// IssueEventClosed is emitted when an issue is closed, with its closed state
+	IssueEventClosed IssueEventType = "closed"
+	// IssueEventCommented is emitted when the comment of an issue is updated, or when a thread comment is added or edited
+	IssueEventCommented IssueEventType = "commented"
+	// IssueEventReopened is emitted when a closed issue is reopened by a new detection within the flapping window
+	IssueEventReopened IssueEventType = "reopened"
+)
+
+// IssueEventTypes are all the issue event types
+var IssueEventTypes = []IssueEventType{
+	IssueEventCreated, IssueEventLevelChanged, IssueEventAssigned, IssueEventDrafted, IssueEventClosed, IssueEventCommented,
+	IssueEventReopened,
+}
+
+// IsValid checks if an issue event type is known
+func (eventType IssueEventType) IsValid() bool {
+	for _, t := range IssueEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueEvent is an issue lifecycle event, delivered to the notifier clients and to the webhooks
+type IssueEvent struct {
+	ID            string         `json:"id"` // unique ID of the event, to deduplicate the deliveries
+	Type          IssueEventType `json:"type" enums:"created,level_changed,assigned,drafted,closed,commented,reopened"`
+	Timestamp     time.Time      `json:"timestamp"`
+	IssueID       int64          `json:"issueId"`
+	SituationID   int64          `json:"situationId"`
+	Issue         Issue          `json:"issue"` // issue after the event
+	PreviousLevel string         `json:"previousLevel,omitempty"`
+	State         string         `json:"state,omitempty"` // closed state of a closed event
+	AssignedTo    *string        `json:"assignedTo,omitempty"`
+	Comment       string         `json:"comment,omitempty"`
+	CommentID     int64          `json:"commentId,omitempty"` // thread comment of a commented event
+	User          string         `json:"user,omitempty"`      // login of the user at the origin of the event, empty for the engine
+}
+
+// NewIssueEvent returns a new issue event on an issue
+func NewIssueEvent(eventType IssueEventType, issue Issue, user string) IssueEvent {
+	return IssueEvent{
+		ID:          newIssueEventID(),
+		Type:        eventType,
+		Timestamp:   time.Now().Truncate(1 * time.Millisecond).UTC(),
+		IssueID:     issue.ID,
+		SituationID: issue.SituationID,
+		Issue:       issue,
+		User:        user,
+	}
+}
+
+func newIssueEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// IssueWebhook is an outbound webhook receiving the issue events, as a signed JSON POST request
+type IssueWebhook struct {
+	ID         int64            `json:"id"`
+	Name       string           `json:"name"`
+	URL        string           `json:"url"`
+	Secret     string           `json:"secret,omitempty"` // HMAC-SHA256 key of the payload signature, never returned
+	EventTypes []IssueEventType `json:"eventTypes"`       // all the event types if empty
+	Enabled    bool             `json:"enabled"`
+}
+
+// IsValid checks if an issue webhook is valid and has no missing mandatory fields
+func (webhook IssueWebhook) IsValid() (bool, error) {
+	if webhook.Name == "" {
+		return false, errors.New("missing Name")
+	}
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false, errors.New("invalid URL (absolute http or https URL required)")
+	}
+	for _, eventType := range webhook.EventTypes {
+		if !eventType.IsValid() {
+			return false, fmt.Errorf("invalid event type '%s'", eventType)
+		}
+	}
+	return true, nil
+}
+
+// Accepts returns true if the webhook is enabled and subscribed to the type of an event
+func (webhook IssueWebhook) Accepts(event IssueEvent) bool {
+	if !webhook.Enabled {
+		return false
+	}
+	if len(webhook.EventTypes) == 0 {
+		return true
+	}
+	for _, eventType := range webhook.EventTypes {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
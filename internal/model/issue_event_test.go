@@ -0,0 +1,41 @@
+package model
+
+import "testing"
+
+func TestIssueWebhookIsValid(t *testing.T) {
+	valid := IssueWebhook{Name: "ticketing", URL: "https://ticketing.example.com/hooks/myrtea", EventTypes: []IssueEventType{IssueEventCreated}}
+	if ok, err := valid.IsValid(); !ok {
+		t.Error(err)
+	}
+
+	invalids := []IssueWebhook{
+		{URL: "https://ticketing.example.com"},
+		{Name: "ticketing", URL: "ticketing.example.com"},
+		{Name: "ticketing", URL: "ftp://ticketing.example.com"},
+		{Name: "ticketing", URL: "https://ticketing.example.com", EventTypes: []IssueEventType{"unknown"}},
+	}
+	for _, webhook := range invalids {
+		if ok, _ := webhook.IsValid(); ok {
+			t.Errorf("expected webhook %+v to be invalid", webhook)
+		}
+	}
+}
+
+func TestIssueWebhookAccepts(t *testing.T) {
+	created := NewIssueEvent(IssueEventCreated, Issue{ID: 1, SituationID: 2}, "")
+	if created.ID == "" || created.IssueID != 1 || created.SituationID != 2 {
+		t.Errorf("unexpected event %+v", created)
+	}
+	closed := NewIssueEvent(IssueEventClosed, Issue{ID: 1}, "")
+
+	if (IssueWebhook{EventTypes: []IssueEventType{IssueEventCreated}}).Accepts(created) {
+		t.Error("expected a disabled webhook to accept no event")
+	}
+	webhook := IssueWebhook{Enabled: true, EventTypes: []IssueEventType{IssueEventCreated}}
+	if !webhook.Accepts(created) || webhook.Accepts(closed) {
+		t.Error("expected the webhook to accept only the created events")
+	}
+	if !(IssueWebhook{Enabled: true}).Accepts(closed) {
+		t.Error("expected a webhook without event types to accept all the events")
+	}
+}
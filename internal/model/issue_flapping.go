@@ -0,0 +1,78 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// FlappingMode is the handling of an issue detected again shortly after its closure
+type FlappingMode string
+
+const (
+	// FlappingModeReopen reopens the closed issue (if it was closed without feedback), otherwise links a new issue to it
+	FlappingModeReopen FlappingMode = "reopen"
+	// FlappingModeLink always creates a new issue, linked to the closed one
+	FlappingModeLink FlappingMode = "link"
+)
+
+// IssueFlappingOptions are the options of the issue flapping detection
+type IssueFlappingOptions struct {
+	Window                time.Duration // maximum delay between the closure and the new detection, 0 disables the detection
+	Mode                  FlappingMode
+	Threshold             int  // number of flaps from which an issue is flapping, 0 never flags an issue as flapping
+	SuppressNotifications bool // no created, reopened or level_changed events for the flapping issues
+}
+
+// IsValid checks if the flapping options are valid
+func (options IssueFlappingOptions) IsValid() (bool, error) {
+	if options.Window < 0 {
+		return false, fmt.Errorf("invalid negative flapping window %s", options.Window)
+	}
+	if options.Mode != FlappingModeReopen && options.Mode != FlappingModeLink {
+		return false, fmt.Errorf("invalid flapping mode '%s' (reopen or link required)", options.Mode)
+	}
+	if options.Threshold < 0 {
+		return false, fmt.Errorf("invalid negative flapping threshold %d", options.Threshold)
+	}
+	return true, nil
+}
+
+// Enabled returns true if the flapping detection is enabled
+func (options IssueFlappingOptions) Enabled() bool {
+	return options.Window > 0
+}
+
+// WindowStart returns the oldest closure date of an issue flapping with a detection at ts
+func (options IssueFlappingOptions) WindowStart(ts time.Time) time.Time {
+	return ts.Add(-options.Window)
+}
+
+// IsWithinWindow returns true if a closed issue was closed within the flapping window of a detection at ts
+func (options IssueFlappingOptions) IsWithinWindow(closed Issue, ts time.Time) bool {
+	if !options.Enabled() || !closed.State.IsClosed() {
+		return false
+	}
+	closedAt := closed.LastModificationTS
+	if closed.ClosedAt != nil {
+		closedAt = *closed.ClosedAt
+	}
+	return !closedAt.Before(options.WindowStart(ts))
+}
+
+// CanReopen returns true if a closed issue must be reopened instead of linked to a new issue
+// The issues closed with a feedback are never reopened, their resolution must stay as it was confirmed
+func (options IssueFlappingOptions) CanReopen(closed Issue) bool {
+	if options.Mode != FlappingModeReopen {
+		return false
+	}
+	switch closed.State {
+	case ClosedNoFeedback, ClosedTimeout, ClosedDiscard:
+		return true
+	}
+	return false
+}
+
+// IsFlapping returns true if an issue reached the flapping threshold
+func (options IssueFlappingOptions) IsFlapping(issue Issue) bool {
+	return options.Threshold > 0 && issue.FlapCount >= options.Threshold
+}
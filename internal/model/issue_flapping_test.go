@@ -0,0 +1,73 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIssueFlappingOptionsIsValid(t *testing.T) {
+	if ok, err := (IssueFlappingOptions{Window: time.Hour, Mode: FlappingModeLink, Threshold: 3}).IsValid(); !ok {
+		t.Error(err)
+	}
+	invalids := []IssueFlappingOptions{
+		{Window: -time.Hour, Mode: FlappingModeReopen},
+		{Window: time.Hour, Mode: "merge"},
+		{Window: time.Hour, Mode: FlappingModeReopen, Threshold: -1},
+	}
+	for _, options := range invalids {
+		if ok, _ := options.IsValid(); ok {
+			t.Errorf("expected options %+v to be invalid", options)
+		}
+	}
+}
+
+func TestIssueFlappingOptionsIsWithinWindow(t *testing.T) {
+	ts := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	closedAt := ts.Add(-30 * time.Minute)
+	closed := Issue{State: ClosedDiscard, ClosedAt: &closedAt}
+
+	options := IssueFlappingOptions{Window: time.Hour, Mode: FlappingModeReopen}
+	if !options.IsWithinWindow(closed, ts) {
+		t.Error("expected an issue closed 30 minutes ago to be within a 1 hour window")
+	}
+	if options.IsWithinWindow(closed, ts.Add(time.Hour)) {
+		t.Error("expected an issue closed 90 minutes ago to be outside a 1 hour window")
+	}
+	if (IssueFlappingOptions{Mode: FlappingModeReopen}).IsWithinWindow(closed, ts) {
+		t.Error("expected a disabled detection to never match")
+	}
+
+	// Issues closed by the tasks have no closure date
+	discarded := Issue{State: ClosedDiscard, LastModificationTS: closedAt}
+	if !options.IsWithinWindow(discarded, ts) {
+		t.Error("expected the last modification to be used without closure date")
+	}
+	if options.IsWithinWindow(Issue{State: Open, LastModificationTS: closedAt}, ts) {
+		t.Error("expected an open issue to never match")
+	}
+}
+
+func TestIssueFlappingOptionsCanReopen(t *testing.T) {
+	reopen := IssueFlappingOptions{Window: time.Hour, Mode: FlappingModeReopen}
+	if !reopen.CanReopen(Issue{State: ClosedNoFeedback}) || !reopen.CanReopen(Issue{State: ClosedTimeout}) {
+		t.Error("expected the issues closed without feedback to be reopened")
+	}
+	if reopen.CanReopen(Issue{State: ClosedFeedbackConfirmed}) || reopen.CanReopen(Issue{State: ClosedFeedbackRejected}) {
+		t.Error("expected the issues closed with a feedback to be linked")
+	}
+	link := IssueFlappingOptions{Window: time.Hour, Mode: FlappingModeLink}
+	if link.CanReopen(Issue{State: ClosedNoFeedback}) {
+		t.Error("expected the link mode to never reopen")
+	}
+}
+
+func TestIssueFlappingOptionsIsFlapping(t *testing.T) {
+	options := IssueFlappingOptions{Window: time.Hour, Mode: FlappingModeReopen, Threshold: 3}
+	if options.IsFlapping(Issue{FlapCount: 2}) || !options.IsFlapping(Issue{FlapCount: 3}) {
+		t.Error("unexpected flapping status around the threshold")
+	}
+	options.Threshold = 0
+	if options.IsFlapping(Issue{FlapCount: 10}) {
+		t.Error("expected a 0 threshold to never flag an issue as flapping")
+	}
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// IssueSearchFacet is a dimension on which the issues search results are counted
+type IssueSearchFacet string
+
+const (
+	// IssueFacetSituation counts the issues by situation
+	IssueFacetSituation IssueSearchFacet = "situation"
+	// IssueFacetTemplateInstance counts the issues by situation template instance
+	IssueFacetTemplateInstance IssueSearchFacet = "template_instance"
+	// IssueFacetTag counts the issues by tag (of their situation or template instance)
+	IssueFacetTag IssueSearchFacet = "tag"
+	// IssueFacetFunctionalSituation counts the issues by functional situation (of their situation or template instance)
+	IssueFacetFunctionalSituation IssueSearchFacet = "functional_situation"
+	// IssueFacetLevel counts the issues by level
+	IssueFacetLevel IssueSearchFacet = "level"
+	// IssueFacetState counts the issues by state
+	IssueFacetState IssueSearchFacet = "state"
+	// IssueFacetAssignee counts the issues by assignee (empty key for the unassigned issues)
+	IssueFacetAssignee IssueSearchFacet = "assignee"
+	// IssueFacetDetectionRating counts the issues by rounded detection rating ("unrated" key for the issues without rating)
+	IssueFacetDetectionRating IssueSearchFacet = "detection_rating"
+)
+
+// IssueSearchFacets are all the issues search facets
+var IssueSearchFacets = []IssueSearchFacet{
+	IssueFacetSituation, IssueFacetTemplateInstance, IssueFacetTag, IssueFacetFunctionalSituation,
+	IssueFacetLevel, IssueFacetState, IssueFacetAssignee, IssueFacetDetectionRating,
+}
+
+// Bounds of the pages and facets of the issues searches
+const (
+	IssueSearchMaxLimit         = 1000 // maximum number of issues returned by a search
+	IssueSearchDefaultFacetSize = 10   // default number of values returned per facet
+	IssueSearchMaxFacetSize     = 100  // maximum number of values returned per facet
+)
+
+// IsValid checks if an issues search facet is known
+func (facet IssueSearchFacet) IsValid() bool {
+	for _, f := range IssueSearchFacets {
+		if f == facet {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueSearchQuery is a full-text and faceted search on the issues
+// Text is matched (with the web search syntax: quoted phrases, "or", "-" exclusion) on the issue name and comment,
+// on the comment threads and on the rootcause and action labels of the resolution.
+// The filters of the same dimension are OR-ed, the dimensions are AND-ed.
+// The counts of a facet ignore the filter of its own dimension, so that the other values of the dimension stay selectable.
+type IssueSearchQuery struct {
+	Text                   string             `json:"text"`
+	SituationIDs           []int64            `json:"situationIds"`
+	TemplateInstanceIDs    []int64            `json:"templateInstanceIds"`
+	TagIDs                 []int64            `json:"tagIds"`
+	FunctionalSituationIDs []int64            `json:"functionalSituationIds"`
+	Levels                 []string           `json:"levels"`
+	States                 []string           `json:"states"`
+	AssignedTo             []string           `json:"assignedTo"` // empty login for the unassigned issues
+	CreatedFrom            *time.Time         `json:"createdFrom,omitempty"`
+	CreatedTo              *time.Time         `json:"createdTo,omitempty"` // excluded
+	ClosedFrom             *time.Time         `json:"closedFrom,omitempty"`
+	ClosedTo               *time.Time         `json:"closedTo,omitempty"` // excluded
+	MinDetectionRating     *float64           `json:"minDetectionRating,omitempty"`
+	MaxDetectionRating     *float64           `json:"maxDetectionRating,omitempty"`
+	Facets                 []IssueSearchFacet `json:"facets" enums:"situation,template_instance,tag,functional_situation,level,state,assignee,detection_rating"`
+	FacetSize              int                `json:"facetSize"` // number of values per facet, the most frequent first (default 10, at most 100)
+	Limit                  int                `json:"limit"`     // at most 1000
+	Offset                 int                `json:"offset"`
+	SortBy                 []SortOption       `json:"sortBy"` // by relevance then creation date (desc) if empty with a text, by creation date (desc) otherwise
+}
+
+// IssueSearchResult is a page of the issues matching a search, with the counts of the requested facets
+type IssueSearchResult struct {
+	Total  int                                    `json:"total"`
+	Items  []Issue                                `json:"items"`
+	Facets map[IssueSearchFacet][]IssueFacetValue `json:"facets"`
+}
+
+// IssueFacetValue is the number of issues matching a search for a value of a facet
+type IssueFacetValue struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// IsValid checks if an issues search query is valid
+func (query IssueSearchQuery) IsValid() (bool, error) {
+	for _, facet := range query.Facets {
+		if !facet.IsValid() {
+			return false, fmt.Errorf("invalid facet '%s'", facet)
+		}
+	}
+	for _, level := range query.Levels {
+		if ToIssueLevel(level) == 0 {
+			return false, fmt.Errorf("invalid level '%s'", level)
+		}
+	}
+	for _, state := range query.States {
+		if ToIssueState(state) == 0 {
+			return false, fmt.Errorf("invalid state '%s'", state)
+		}
+	}
+	if query.CreatedFrom != nil && query.CreatedTo != nil && !query.CreatedFrom.Before(*query.CreatedTo) {
+		return false, errors.New("createdFrom must be before createdTo")
+	}
+	if query.ClosedFrom != nil && query.ClosedTo != nil && !query.ClosedFrom.Before(*query.ClosedTo) {
+		return false, errors.New("closedFrom must be before closedTo")
+	}
+	if query.MinDetectionRating != nil && query.MaxDetectionRating != nil && *query.MinDetectionRating > *query.MaxDetectionRating {
+		return false, errors.New("minDetectionRating must not be greater than maxDetectionRating")
+	}
+	if query.FacetSize < 0 || query.Limit < 0 || query.Offset < 0 {
+		return false, errors.New("facetSize, limit and offset must be positive")
+	}
+	for _, sortBy := range query.SortBy {
+		if sortBy.Order != Asc && sortBy.Order != Desc {
+			return false, fmt.Errorf("invalid sort order on field '%s' (asc or desc required)", sortBy.Field)
+		}
+	}
+	return true, nil
+}
+
+// SearchOptions returns the pagination and sort options of an issues search query, the limit is capped to IssueSearchMaxLimit
+func (query IssueSearchQuery) SearchOptions() SearchOptions {
+	limit := query.Limit
+	if limit > IssueSearchMaxLimit {
+		limit = IssueSearchMaxLimit
+	}
+	return SearchOptions{Limit: limit, Offset: query.Offset, SortBy: query.SortBy}
+}
+
+// FacetLimit returns the number of values per facet of an issues search query, capped to IssueSearchMaxFacetSize
+func (query IssueSearchQuery) FacetLimit() int {
+	switch {
+	case query.FacetSize == 0:
+		return IssueSearchDefaultFacetSize
+	case query.FacetSize > IssueSearchMaxFacetSize:
+		return IssueSearchMaxFacetSize
+	default:
+		return query.FacetSize
+	}
+}
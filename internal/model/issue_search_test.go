@@ -0,0 +1,53 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIssueSearchQueryIsValid(t *testing.T) {
+	from := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	query := IssueSearchQuery{
+		Text:        "disk full",
+		Levels:      []string{"critical"},
+		States:      []string{"open", "draft"},
+		CreatedFrom: &from,
+		CreatedTo:   &to,
+		Facets:      []IssueSearchFacet{IssueFacetSituation, IssueFacetAssignee},
+		SortBy:      []SortOption{{Field: "created_at", Order: Desc}},
+	}
+	if ok, err := query.IsValid(); !ok {
+		t.Fatalf("expected a valid query, got %v", err)
+	}
+
+	invalid := []IssueSearchQuery{
+		{Facets: []IssueSearchFacet{"rule"}},
+		{Levels: []string{"major"}},
+		{States: []string{"closed"}},
+		{CreatedFrom: &to, CreatedTo: &from},
+		{MinDetectionRating: floatPtr(4), MaxDetectionRating: floatPtr(2)},
+		{Limit: -1},
+		{SortBy: []SortOption{{Field: "id"}}},
+	}
+	for i, q := range invalid {
+		if ok, _ := q.IsValid(); ok {
+			t.Errorf("expected the query %d to be invalid", i)
+		}
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestIssueSearchQueryLimits(t *testing.T) {
+	query := IssueSearchQuery{}
+	if query.FacetLimit() != IssueSearchDefaultFacetSize {
+		t.Errorf("expected the default facet size, got %d", query.FacetLimit())
+	}
+	query = IssueSearchQuery{FacetSize: 5000, Limit: 5000}
+	if query.FacetLimit() != IssueSearchMaxFacetSize || query.SearchOptions().Limit != IssueSearchMaxLimit {
+		t.Errorf("expected capped sizes, got %d and %d", query.FacetLimit(), query.SearchOptions().Limit)
+	}
+}
@@ -0,0 +1,199 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Actions of the issue audit trail
+const (
+	IssueAuditAssign   = "assign"
+	IssueAuditReassign = "reassign"
+	IssueAuditUnassign = "unassign"
+)
+
+// IssueAuditEntry is an entry of the audit trail of an issue
+type IssueAuditEntry struct {
+	ID            int64     `json:"id"`
+	IssueID       int64     `json:"issueId"`
+	TS            time.Time `json:"ts"`
+	User          string    `json:"user"`
+	Action        string    `json:"action" enums:"assign,reassign,unassign"`
+	PreviousValue *string   `json:"previousValue,omitempty"`
+	NewValue      *string   `json:"newValue,omitempty"`
+}
+
+// IssueAssignmentAction returns the audit action of a change of assignee (nil is unassigned)
+func IssueAssignmentAction(previous *string, assignee *string) (string, error) {
+	switch {
+	case previous == nil && assignee == nil:
+		return "", errors.New("the issue is not assigned")
+	case previous == nil:
+		return IssueAuditAssign, nil
+	case assignee == nil:
+		return IssueAuditUnassign, nil
+	case *previous == *assignee:
+		return "", fmt.Errorf("the issue is already assigned to %s", *assignee)
+	default:
+		return IssueAuditReassign, nil
+	}
+}
+
+// IssueSLA defines the time targets to acknowledge (assign) and to resolve (close) the issues of a situation and/or of a level
+// A target without situation or level applies to every situation or level, the most specific target applies to an issue
+type IssueSLA struct {
+	ID                int64      `json:"id"`
+	SituationID       int64      `json:"situationId"`                               // 0 applies to every situation
+	Level             IssueLevel `json:"level" swaggertype:"string"`                // empty applies to every level
+	AcknowledgeWithin string     `json:"acknowledgeWithin,omitempty" example:"30m"` // empty means no target
+	ResolveWithin     string     `json:"resolveWithin,omitempty" example:"4h"`      // empty means no target
+}
+
+// IssueSLAStatus is the SLA status of an issue
+// Durations are in seconds, and are only set once the issue has been acknowledged or resolved
+type IssueSLAStatus struct {
+	SLAID               int64      `json:"slaId"`
+	AcknowledgeDeadline *time.Time `json:"acknowledgeDeadline,omitempty"`
+	ResolveDeadline     *time.Time `json:"resolveDeadline,omitempty"`
+	TimeToAcknowledge   *float64   `json:"timeToAcknowledge,omitempty"`
+	TimeToResolve       *float64   `json:"timeToResolve,omitempty"`
+	AcknowledgeBreached bool       `json:"acknowledgeBreached"`
+	ResolveBreached     bool       `json:"resolveBreached"`
+}
+
+// IsValid checks if an issue SLA definition is valid and has no missing mandatory fields
+func (sla IssueSLA) IsValid() (bool, error) {
+	if sla.SituationID < 0 {
+		return false, errors.New("invalid SituationID")
+	}
+	if sla.AcknowledgeWithin == "" && sla.ResolveWithin == "" {
+		return false, errors.New("missing AcknowledgeWithin or ResolveWithin")
+	}
+	if _, _, err := sla.Targets(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Targets returns the acknowledge and resolve targets (0 if not defined)
+func (sla IssueSLA) Targets() (time.Duration, time.Duration, error) {
+	acknowledge, err := parseSLATarget(sla.AcknowledgeWithin)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid AcknowledgeWithin: %s", err)
+	}
+	resolve, err := parseSLATarget(sla.ResolveWithin)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid ResolveWithin: %s", err)
+	}
+	return acknowledge, resolve, nil
+}
+
+func parseSLATarget(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, errors.New("must be positive")
+	}
+	return d, nil
+}
+
+// Matches returns true if the SLA applies to the issue
+func (sla IssueSLA) Matches(issue Issue) bool {
+	return (sla.SituationID == 0 || sla.SituationID == issue.SituationID) && (sla.Level == 0 || sla.Level == issue.Level)
+}
+
+// specificity ranks the SLA matching an issue, a situation is more specific than a level
+func (sla IssueSLA) specificity() int {
+	s := 0
+	if sla.SituationID != 0 {
+		s += 2
+	}
+	if sla.Level != 0 {
+		s++
+	}
+	return s
+}
+
+// MatchIssueSLA returns the most specific SLA applying to an issue
+func MatchIssueSLA(slas []IssueSLA, issue Issue) (IssueSLA, bool) {
+	var match IssueSLA
+	found := false
+	for _, sla := range slas {
+		if !sla.Matches(issue) {
+			continue
+		}
+		if !found || sla.specificity() > match.specificity() {
+			match, found = sla, true
+		}
+	}
+	return match, found
+}
+
+// ResolvedAt returns the resolution time of a closed issue
+func (issue Issue) ResolvedAt() *time.Time {
+	return resolvedAt(issue.State, issue.ClosedAt, issue.LastModificationTS)
+}
+
+// resolvedAt is the resolution time of an issue, used by both the SLA status and the analytics
+// It is the closing time of a closed issue, or its last modification if it has been closed without closing time
+func resolvedAt(state IssueState, closedAt *time.Time, lastModificationTS time.Time) *time.Time {
+	if !state.IsClosed() {
+		return nil
+	}
+	if closedAt != nil {
+		return closedAt
+	}
+	// Timeouts and discards do not set the closing time
+	return &lastModificationTS
+}
+
+// Status computes the SLA status of an issue at now
+// An issue closed without being assigned is acknowledged by its resolution
+func (sla IssueSLA) Status(issue Issue, now time.Time) IssueSLAStatus {
+	status := IssueSLAStatus{SLAID: sla.ID}
+	acknowledgeWithin, resolveWithin, err := sla.Targets()
+	if err != nil {
+		return status
+	}
+
+	resolvedAt := issue.ResolvedAt()
+	acknowledgedAt := issue.AcknowledgedAt
+	if acknowledgedAt == nil {
+		acknowledgedAt = resolvedAt
+	}
+
+	if acknowledgedAt != nil {
+		d := acknowledgedAt.Sub(issue.CreationTS).Seconds()
+		status.TimeToAcknowledge = &d
+	}
+	if resolvedAt != nil {
+		d := resolvedAt.Sub(issue.CreationTS).Seconds()
+		status.TimeToResolve = &d
+	}
+
+	if acknowledgeWithin > 0 {
+		deadline := issue.CreationTS.Add(acknowledgeWithin)
+		status.AcknowledgeDeadline = &deadline
+		status.AcknowledgeBreached = breached(deadline, acknowledgedAt, now)
+	}
+	if resolveWithin > 0 {
+		deadline := issue.CreationTS.Add(resolveWithin)
+		status.ResolveDeadline = &deadline
+		status.ResolveBreached = breached(deadline, resolvedAt, now)
+	}
+	return status
+}
+
+// breached returns true if a deadline has been missed, or is already missed at now
+func breached(deadline time.Time, done *time.Time, now time.Time) bool {
+	if done != nil {
+		return done.After(deadline)
+	}
+	return now.After(deadline)
+}
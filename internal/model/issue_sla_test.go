@@ -0,0 +1,104 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchIssueSLA(t *testing.T) {
+	slas := []IssueSLA{
+		{ID: 1, ResolveWithin: "24h"},
+		{ID: 2, Level: Critical, ResolveWithin: "4h"},
+		{ID: 3, SituationID: 10, ResolveWithin: "8h"},
+		{ID: 4, SituationID: 10, Level: Critical, ResolveWithin: "1h"},
+	}
+
+	cases := []struct {
+		issue Issue
+		id    int64
+	}{
+		{Issue{SituationID: 1, Level: Warning}, 1},
+		{Issue{SituationID: 1, Level: Critical}, 2},
+		{Issue{SituationID: 10, Level: Warning}, 3},
+		{Issue{SituationID: 10, Level: Critical}, 4},
+	}
+	for _, c := range cases {
+		match, found := MatchIssueSLA(slas, c.issue)
+		if !found || match.ID != c.id {
+			t.Errorf("expected SLA %d for situation %d and level %s, got %d", c.id, c.issue.SituationID, c.issue.Level, match.ID)
+		}
+	}
+
+	if _, found := MatchIssueSLA(slas[1:], Issue{SituationID: 1, Level: Warning}); found {
+		t.Error("expected no SLA")
+	}
+}
+
+func TestIssueSLAStatus(t *testing.T) {
+	creation := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	sla := IssueSLA{ID: 1, AcknowledgeWithin: "30m", ResolveWithin: "4h"}
+
+	status := sla.Status(Issue{CreationTS: creation, State: Open}, creation.Add(time.Hour))
+	if !status.AcknowledgeBreached || status.ResolveBreached {
+		t.Errorf("unexpected breaches %+v", status)
+	}
+	if status.TimeToAcknowledge != nil || status.TimeToResolve != nil {
+		t.Errorf("unexpected durations %+v", status)
+	}
+
+	acknowledgedAt := creation.Add(10 * time.Minute)
+	closedAt := creation.Add(5 * time.Hour)
+	status = sla.Status(Issue{CreationTS: creation, State: ClosedFeedbackConfirmed, AcknowledgedAt: &acknowledgedAt, ClosedAt: &closedAt}, creation.Add(48*time.Hour))
+	if status.AcknowledgeBreached || !status.ResolveBreached {
+		t.Errorf("unexpected breaches %+v", status)
+	}
+	if *status.TimeToAcknowledge != 600 || *status.TimeToResolve != 18000 {
+		t.Errorf("unexpected durations %v %v", *status.TimeToAcknowledge, *status.TimeToResolve)
+	}
+	if !status.ResolveDeadline.Equal(creation.Add(4 * time.Hour)) {
+		t.Errorf("unexpected resolve deadline %s", status.ResolveDeadline)
+	}
+
+	// closed without assignment, the resolution acknowledges the issue
+	closedAt = creation.Add(20 * time.Minute)
+	status = sla.Status(Issue{CreationTS: creation, State: ClosedFeedbackConfirmed, ClosedAt: &closedAt}, creation.Add(48*time.Hour))
+	if status.AcknowledgeBreached || status.ResolveBreached || *status.TimeToAcknowledge != 1200 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestIssueSLAIsValid(t *testing.T) {
+	if ok, _ := (IssueSLA{}).IsValid(); ok {
+		t.Error("expected an SLA without target to be invalid")
+	}
+	if ok, _ := (IssueSLA{ResolveWithin: "-1h"}).IsValid(); ok {
+		t.Error("expected a negative target to be invalid")
+	}
+	if ok, _ := (IssueSLA{AcknowledgeWithin: "abc"}).IsValid(); ok {
+		t.Error("expected an unparsable target to be invalid")
+	}
+	if ok, err := (IssueSLA{SituationID: 1, AcknowledgeWithin: "15m"}).IsValid(); !ok {
+		t.Error(err)
+	}
+}
+
+func TestIssueAssignmentAction(t *testing.T) {
+	a, b := "a", "b"
+	cases := []struct {
+		previous *string
+		assignee *string
+		action   string
+	}{
+		{nil, &a, IssueAuditAssign},
+		{&a, &b, IssueAuditReassign},
+		{&a, nil, IssueAuditUnassign},
+		{nil, nil, ""},
+		{&a, &a, ""},
+	}
+	for _, c := range cases {
+		action, err := IssueAssignmentAction(c.previous, c.assignee)
+		if action != c.action || (c.action == "") != (err != nil) {
+			t.Errorf("unexpected action %s (%v), expected %s", action, err, c.action)
+		}
+	}
+}
@@ -13,6 +13,7 @@ type Action struct {
 	Name        string `json:"name,omitempty"`
 	Description string `json:"description,omitempty"`
 	RootCauseID int64  `json:"rootCauseId"`
+	CatalogID   *int64 `json:"catalogId,omitempty"` // catalog action of the linked rootcause, nil for a local action
 }
 
 // NewAction returns a new action
@@ -43,6 +44,7 @@ type RootCause struct {
 	Description string `json:"description,omitempty"`
 	SituationID int64  `json:"situationId"`
 	RuleID      int64  `json:"ruleId"`
+	CatalogID   *int64 `json:"catalogId,omitempty"` // catalog rootcause linked to the situation and rule, nil for a local rootcause
 }
 
 // NewRootCause returns a new action
@@ -91,6 +93,14 @@ type Issue struct {
 	ClosedAt           *time.Time `json:"closedAt,omitempty"`
 	CloseBy            *string    `json:"closedBy,omitempty"`
 	Comment            *string    `json:"comment,omitempty"`
+
+	AcknowledgedAt *time.Time      `json:"acknowledgedAt,omitempty"` // first assignment of the issue
+	SLA            *IssueSLAStatus `json:"sla,omitempty"`            // SLA status, set by the issue listings
+	IncidentID     *int64          `json:"incidentId,omitempty"`     // incident grouping the issue
+
+	FlapCount       int        `json:"flapCount,omitempty"`       // number of detections within the flapping window after a closure
+	LastFlapAt      *time.Time `json:"lastFlapAt,omitempty"`      // last reopening or linked creation
+	PreviousIssueID *int64     `json:"previousIssueId,omitempty"` // closed issue with the same key, when it was linked instead of reopened
 }
 
 // RuleData rule identification
@@ -0,0 +1,236 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// PastResolution is the feedback of a past issue of a situation, used to rank the recommendation tree of a new issue
+type PastResolution struct {
+	IssueID         int64
+	IssueName       string
+	ResolvedAt      time.Time
+	DetectionRating float64           // average detection rating of the issue, -1 if not rated
+	Facts           map[int64]float64 // numeric fact values at the issue time, by fact ID
+	RootCauseID     int64
+	RootCauseName   string
+	Actions         []SimilarIssueAction
+}
+
+// RankingOptions are the parameters of the recommendation ranking
+type RankingOptions struct {
+	HalfLife   time.Duration // age after which a resolution weights half as much, no decay if zero
+	Neighbours int           // number of similar past issues
+}
+
+// RecommendationScore explains the ranking score of a rootcause or an action
+type RecommendationScore struct {
+	Score       float64 `json:"score"`
+	Frequency   float64 `json:"frequency"`  // usage rate in the past resolutions, weighted by recency and detection rating
+	Similarity  float64 `json:"similarity"` // usage rate in the most similar past issues, -1 without comparable fact values
+	Neighbours  int     `json:"neighbours"` // number of similar past issues which used it
+	Explanation string  `json:"explanation"`
+}
+
+// SimilarIssue is a past issue close to the current one on the fact values, with its resolution
+type SimilarIssue struct {
+	IssueID       int64                `json:"issueId"`
+	Name          string               `json:"name"`
+	ResolvedAt    time.Time            `json:"resolvedAt"`
+	Distance      float64              `json:"distance"`
+	RootCauseID   int64                `json:"rootCauseId"`
+	RootCauseName string               `json:"rootCauseName"`
+	Actions       []SimilarIssueAction `json:"actions"`
+}
+
+// SimilarIssueAction is an action used to resolve a similar issue
+type SimilarIssueAction struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// RecommendationRanking is the result of the ranking of the past resolutions of a situation
+type RecommendationRanking struct {
+	RootCauses    map[int64]RecommendationScore
+	Actions       map[int64]RecommendationScore
+	SimilarIssues []SimilarIssue
+}
+
+// RankResolutions scores the rootcauses and actions of the past resolutions of a situation for an issue with some fact values
+// Each resolution weights less with its age and more with the detection rating of its issue (relative to the average rating).
+// The usage rate among the nearest past issues on the fact values (normalized euclidean distance) is averaged with the weighted frequency,
+// when at least one fact value can be compared.
+func RankResolutions(facts map[int64]float64, resolutions []PastResolution, now time.Time, options RankingOptions) RecommendationRanking {
+	ranking := RecommendationRanking{
+		RootCauses:    make(map[int64]RecommendationScore),
+		Actions:       make(map[int64]RecommendationScore),
+		SimilarIssues: make([]SimilarIssue, 0),
+	}
+	if len(resolutions) == 0 {
+		return ranking
+	}
+
+	ratingSum, ratingCount := 0.0, 0
+	for _, resolution := range resolutions {
+		if resolution.DetectionRating > 0 {
+			ratingSum += resolution.DetectionRating
+			ratingCount++
+		}
+	}
+
+	rootCauseWeights := make(map[int64]float64)
+	actionWeights := make(map[int64]float64)
+	total := 0.0
+	for _, resolution := range resolutions {
+		weight := recencyWeight(resolution.ResolvedAt, now, options.HalfLife)
+		if resolution.DetectionRating > 0 && ratingCount > 0 {
+			weight *= math.Max(0.25, math.Min(2, resolution.DetectionRating/(ratingSum/float64(ratingCount))))
+		}
+		total += weight
+		rootCauseWeights[resolution.RootCauseID] += weight
+		for _, action := range resolution.Actions {
+			actionWeights[action.ID] += weight
+		}
+	}
+
+	neighbours := nearestResolutions(facts, resolutions, options.Neighbours)
+	rootCauseNeighbours := make(map[int64]int)
+	actionNeighbours := make(map[int64]int)
+	rootCauseSimilarities := make(map[int64]float64)
+	actionSimilarities := make(map[int64]float64)
+	similarityTotal := 0.0
+	for _, neighbour := range neighbours {
+		similarity := 1 / (1 + neighbour.distance)
+		similarityTotal += similarity
+		rootCauseNeighbours[neighbour.resolution.RootCauseID]++
+		rootCauseSimilarities[neighbour.resolution.RootCauseID] += similarity
+		for _, action := range neighbour.resolution.Actions {
+			actionNeighbours[action.ID]++
+			actionSimilarities[action.ID] += similarity
+		}
+		ranking.SimilarIssues = append(ranking.SimilarIssues, SimilarIssue{
+			IssueID:       neighbour.resolution.IssueID,
+			Name:          neighbour.resolution.IssueName,
+			ResolvedAt:    neighbour.resolution.ResolvedAt,
+			Distance:      neighbour.distance,
+			RootCauseID:   neighbour.resolution.RootCauseID,
+			RootCauseName: neighbour.resolution.RootCauseName,
+			Actions:       neighbour.resolution.Actions,
+		})
+	}
+
+	for id, weight := range rootCauseWeights {
+		ranking.RootCauses[id] = newRecommendationScore(weight, total, rootCauseSimilarities[id], similarityTotal, rootCauseNeighbours[id], len(neighbours))
+	}
+	for id, weight := range actionWeights {
+		ranking.Actions[id] = newRecommendationScore(weight, total, actionSimilarities[id], similarityTotal, actionNeighbours[id], len(neighbours))
+	}
+	return ranking
+}
+
+func newRecommendationScore(weight, total, similarity, similarityTotal float64, neighbours, neighbourCount int) RecommendationScore {
+	score := RecommendationScore{Frequency: weight / total, Similarity: -1, Neighbours: neighbours}
+	if neighbourCount == 0 {
+		score.Score = score.Frequency
+		score.Explanation = fmt.Sprintf("used in %.0f%% of the past resolutions (weighted by recency and detection rating)", score.Frequency*100)
+		return score
+	}
+	score.Similarity = similarity / similarityTotal
+	score.Score = (score.Frequency + score.Similarity) / 2
+	score.Explanation = fmt.Sprintf("used in %.0f%% of the past resolutions (weighted by recency and detection rating) and in %d of the %d most similar past issues",
+		score.Frequency*100, neighbours, neighbourCount)
+	return score
+}
+
+// NoRecommendationScore returns the score of a rootcause or an action never used in the past resolutions
+func NoRecommendationScore(withNeighbours bool) RecommendationScore {
+	score := RecommendationScore{Similarity: -1, Explanation: "never used in the past resolutions"}
+	if withNeighbours {
+		score.Similarity = 0
+	}
+	return score
+}
+
+func recencyWeight(resolvedAt time.Time, now time.Time, halfLife time.Duration) float64 {
+	if halfLife <= 0 {
+		return 1
+	}
+	age := now.Sub(resolvedAt)
+	if age < 0 {
+		age = 0
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+type neighbourResolution struct {
+	resolution PastResolution
+	distance   float64
+}
+
+// nearestResolutions returns the past resolutions whose fact values are the closest to the current ones
+// Each fact is scaled by its standard deviation, the distance is averaged on the facts known by both issues
+func nearestResolutions(facts map[int64]float64, resolutions []PastResolution, k int) []neighbourResolution {
+	neighbours := make([]neighbourResolution, 0)
+	if k <= 0 || len(facts) == 0 {
+		return neighbours
+	}
+
+	deviations := make(map[int64]float64, len(facts))
+	for factID, value := range facts {
+		values := []float64{value}
+		for _, resolution := range resolutions {
+			if v, ok := resolution.Facts[factID]; ok {
+				values = append(values, v)
+			}
+		}
+		deviations[factID] = standardDeviation(values)
+	}
+
+	for _, resolution := range resolutions {
+		sum, count := 0.0, 0
+		for factID, value := range facts {
+			v, ok := resolution.Facts[factID]
+			if !ok {
+				continue
+			}
+			count++
+			if deviations[factID] > 0 {
+				d := (value - v) / deviations[factID]
+				sum += d * d
+			}
+		}
+		if count == 0 {
+			continue
+		}
+		neighbours = append(neighbours, neighbourResolution{resolution: resolution, distance: math.Sqrt(sum / float64(count))})
+	}
+
+	sort.SliceStable(neighbours, func(i, j int) bool {
+		if neighbours[i].distance != neighbours[j].distance {
+			return neighbours[i].distance < neighbours[j].distance
+		}
+		return neighbours[i].resolution.ResolvedAt.After(neighbours[j].resolution.ResolvedAt)
+	})
+	if len(neighbours) > k {
+		neighbours = neighbours[:k]
+	}
+	return neighbours
+}
+
+func standardDeviation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
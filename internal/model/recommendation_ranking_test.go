@@ -0,0 +1,77 @@
+package model
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRankResolutionsRecency(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	resolutions := []PastResolution{
+		{IssueID: 1, ResolvedAt: now.Add(-24 * time.Hour), DetectionRating: -1, RootCauseID: 1, Actions: []SimilarIssueAction{{ID: 10}}},
+		{IssueID: 2, ResolvedAt: now.Add(-30 * 24 * time.Hour), DetectionRating: -1, RootCauseID: 2, Actions: []SimilarIssueAction{{ID: 20}}},
+		{IssueID: 3, ResolvedAt: now.Add(-31 * 24 * time.Hour), DetectionRating: -1, RootCauseID: 2, Actions: []SimilarIssueAction{{ID: 20}}},
+	}
+
+	ranking := RankResolutions(nil, resolutions, now, RankingOptions{Neighbours: 5})
+	if ranking.RootCauses[2].Score <= ranking.RootCauses[1].Score {
+		t.Errorf("expected the most used rootcause first without decay, got %+v", ranking.RootCauses)
+	}
+	if ranking.RootCauses[1].Similarity != -1 || len(ranking.SimilarIssues) != 0 {
+		t.Errorf("expected no similarity without fact values, got %+v", ranking)
+	}
+
+	ranking = RankResolutions(nil, resolutions, now, RankingOptions{HalfLife: 24 * time.Hour})
+	if ranking.RootCauses[1].Score <= ranking.RootCauses[2].Score {
+		t.Errorf("expected the most recent rootcause first, got %+v", ranking.RootCauses)
+	}
+	if math.Abs(ranking.RootCauses[1].Score+ranking.RootCauses[2].Score-1) > 1e-9 {
+		t.Errorf("expected frequencies summing to 1, got %+v", ranking.RootCauses)
+	}
+	if ranking.Actions[10].Score != ranking.RootCauses[1].Score {
+		t.Errorf("unexpected action score %+v", ranking.Actions[10])
+	}
+}
+
+func TestRankResolutionsDetectionRating(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	resolutions := []PastResolution{
+		{IssueID: 1, ResolvedAt: now, DetectionRating: 5, RootCauseID: 1},
+		{IssueID: 2, ResolvedAt: now, DetectionRating: 1, RootCauseID: 2},
+	}
+
+	ranking := RankResolutions(nil, resolutions, now, RankingOptions{})
+	if ranking.RootCauses[1].Score <= ranking.RootCauses[2].Score {
+		t.Errorf("expected the best rated rootcause first, got %+v", ranking.RootCauses)
+	}
+}
+
+func TestRankResolutionsSimilarIssues(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	resolutions := []PastResolution{
+		{IssueID: 1, ResolvedAt: now, DetectionRating: -1, RootCauseID: 1, Facts: map[int64]float64{1: 10, 2: 1000}},
+		{IssueID: 2, ResolvedAt: now, DetectionRating: -1, RootCauseID: 1, Facts: map[int64]float64{1: 12, 2: 900}},
+		{IssueID: 3, ResolvedAt: now, DetectionRating: -1, RootCauseID: 2, Facts: map[int64]float64{1: 95, 2: 100}},
+		{IssueID: 4, ResolvedAt: now, DetectionRating: -1, RootCauseID: 2},
+	}
+
+	ranking := RankResolutions(map[int64]float64{1: 100, 2: 120}, resolutions, now, RankingOptions{Neighbours: 1})
+	if len(ranking.SimilarIssues) != 1 || ranking.SimilarIssues[0].IssueID != 3 {
+		t.Fatalf("expected issue 3 as the most similar one, got %+v", ranking.SimilarIssues)
+	}
+	if ranking.RootCauses[2].Similarity != 1 || ranking.RootCauses[1].Similarity != 0 || ranking.RootCauses[2].Neighbours != 1 {
+		t.Errorf("unexpected similarities %+v", ranking.RootCauses)
+	}
+	if ranking.RootCauses[2].Score <= ranking.RootCauses[1].Score {
+		t.Errorf("expected the rootcause of the similar issue first, got %+v", ranking.RootCauses)
+	}
+	if ranking.RootCauses[2].Explanation == "" {
+		t.Error("expected a score explanation")
+	}
+
+	ranking = RankResolutions(map[int64]float64{1: 100}, resolutions, now, RankingOptions{Neighbours: 10})
+	if len(ranking.SimilarIssues) != 3 {
+		t.Errorf("expected the issues without fact values to be ignored, got %+v", ranking.SimilarIssues)
+	}
+}
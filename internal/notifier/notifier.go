@@ -4,6 +4,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/permissions"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/security/users"
 
 	"github.com/google/uuid"
@@ -186,6 +187,15 @@ func (notifier *Notifier) SendToUserLogin(notif notification.Notification, login
 	return nil
 }
 
+// SendToPermitted send a notification to every connected client whose user has a permission
+func (notifier *Notifier) SendToPermitted(notif notification.Notification, permission permissions.Permission) {
+	for _, client := range notifier.clientManager.GetClients() {
+		if client.GetUser() != nil && client.GetUser().HasPermission(permission) {
+			notifier.sendToClient(notif, client)
+		}
+	}
+}
+
 // Send a byte slices to a specific websocket client
 func (notifier *Notifier) Send(message []byte, client Client) {
 	if client != nil {
@@ -114,6 +114,7 @@ func evaluateFactObjects(factObject engine.Fact, objects []map[string]interface{
 						HistorySituationID: historySituationNew.ID,
 						HistoryFactID:      historyFactNew.ID,
 						FactID:             historyFactNew.FactID,
+						Ts:                 historySituationNew.Ts,
 					})
 				}
 				err = history.S().HistorySituationFactsQuerier.Execute(history.S().HistorySituationFactsQuerier.Builder.InsertBulk(historySituationFactNew))
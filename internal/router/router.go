@@ -113,7 +113,6 @@ func New(config Config, services Services) *chi.Mux {
 	r.Use(chimiddleware.StripSlashes)
 	r.Use(chimiddleware.RedirectSlashes)
 	r.Use(chimiddleware.Recoverer)
-	r.Use(chimiddleware.Timeout(60 * time.Second))
 
 	if config.Production && config.Security {
 		// we use a custom zap logger to enrich logs with user information when available
@@ -158,9 +157,14 @@ func New(config Config, services Services) *chi.Mux {
 		}
 	}
 
+	// The streaming routes are the only ones not bounded by the request timeout, they can write a large response
+	// for longer and are only cancelled when the client disconnects
+	requestTimeout := chimiddleware.Timeout(60 * time.Second)
+
 	routes := func(r chi.Router) {
 		// Public routes
 		r.Group(func(rg chi.Router) {
+			rg.Use(requestTimeout)
 			applyLogger(rg)
 			rg.Use(SwaggerUICustomizationMiddleware)
 			rg.Get("/isalive", handler.IsAlive)
@@ -183,8 +187,18 @@ func New(config Config, services Services) *chi.Mux {
 			rg.Get("/engine/situations/{id}/instances/unprotected", handler.GetSituationTemplateInstancesUnprotected)
 		})
 
+		// Protected streaming routes
+		r.Group(func(rg chi.Router) {
+			if config.Security {
+				rg.Use(dynamicMiddleware)
+			}
+			applyLogger(rg)
+			rg.Get("/engine/search/last/bycustominterval/stream", handler.SearchLastByCustomIntervalStream)
+		})
+
 		// Protected routes
 		r.Group(func(rg chi.Router) {
+			rg.Use(requestTimeout)
 			if config.Security {
 				rg.Use(dynamicMiddleware)
 			}
@@ -205,6 +219,7 @@ func New(config Config, services Services) *chi.Mux {
 
 		// Admin Protection routes
 		r.Group(func(rg chi.Router) {
+			rg.Use(requestTimeout)
 			if config.Security {
 				// Same dynamic middleware as admin routes
 				rg.Use(dynamicMiddleware)
@@ -216,6 +231,7 @@ func New(config Config, services Services) *chi.Mux {
 
 		// System intra service Protection routes
 		r.Group(func(rg chi.Router) {
+			rg.Use(requestTimeout)
 			applyLogger(rg)
 			rg.Use(chimiddleware.SetHeader("Content-Type", "application/json"))
 			rg.Mount("/service", serviceRouter(services))
@@ -81,6 +81,19 @@ func engineRouter(services Services) http.Handler {
 	r.Post("/facts/build-and-execute", handler.BuildAndExecuteFact)
 	r.Get("/facts/{id}/hits", handler.GetFactHits) // ?time=2019-05-10T12:00:00.000 debug=<boolean>
 	r.Get("/facts/{id}/es", handler.FactToESQuery)
+	r.Get("/facts/{id}/parameters", handler.GetFactParameters)
+	r.Put("/facts/{id}/parameters", handler.PutFactParameters)
+	r.Get("/facts/{id}/parameters/bindings", handler.GetFactParameterBindings)
+	r.Get("/facts/{id}/cost", handler.GetFactCost)
+	r.Get("/facts/{id}/usages", handler.GetFactUsages)
+	r.Get("/facts/sql/connections", handler.GetFactSQLConnections)
+	r.Get("/facts/{id}/sql", handler.GetFactSQL)
+	r.Put("/facts/{id}/sql", handler.PutFactSQL)
+	r.Delete("/facts/{id}/sql", handler.DeleteFactSQL)
+	r.Get("/facts/cache/stats", handler.GetFactCacheStats)
+	r.Get("/facts/{id}/cache", handler.GetFactCache)
+	r.Put("/facts/{id}/cache", handler.PutFactCache)
+	r.Delete("/facts/{id}/cache", handler.DeleteFactCache)
 	r.Post("/facts/streamedexport", handler.ExportFactStreamed)
 
 	r.Get("/situations", handler.GetSituations)
@@ -163,7 +176,18 @@ func engineRouter(services Services) http.Handler {
 	r.Post("/issues/{id}/close", handler.PostIssueCloseWithoutFeedback)
 	r.Post("/issues/{id}/detection/feedback", handler.PostIssueDetectionFeedback)
 	r.Put("/issues/{id}/comment", handler.UpdateIssueComment)
+	r.Get("/issues/{id}/comments", handler.GetIssueComments)
+	r.Post("/issues/{id}/comments", handler.PostIssueComment)
+	r.Put("/issues/{id}/comments/{commentid}", handler.PutIssueComment)
+	r.Delete("/issues/{id}/comments/{commentid}", handler.DeleteIssueComment)
+	r.Post("/issues/{id}/comments/{commentid}/attachments", handler.PostIssueCommentAttachment)
+	r.Get("/issues/{id}/comments/{commentid}/attachments/{attachmentid}", handler.DownloadIssueCommentAttachment)
+	r.Delete("/issues/{id}/comments/{commentid}/attachments/{attachmentid}", handler.DeleteIssueCommentAttachment)
+	r.Put("/issues/{id}/assignee", handler.PutIssueAssignee)
+	r.Get("/issues/{id}/audit", handler.GetIssueAudit)
 	r.Get("/issues/search", handler.SearchIssuesByName)
+	r.Post("/issues/search", handler.SearchIssues)
+	r.Get("/issues/analytics", handler.GetIssuesAnalytics)
 
 	r.Post("/scheduler/start", handler.StartScheduler)
 	r.Post("/scheduler/trigger", handler.TriggerJobSchedule)
@@ -195,7 +219,20 @@ func engineRouter(services Services) http.Handler {
 	r.Put("/actions/{id}", handler.PutAction)
 	r.Delete("/actions/{id}", handler.DeleteAction)
 
-	// r.Post("/search", handler.Search)
+	r.Get("/catalog/rootcauses", handler.GetCatalogRootCauses)
+	r.Get("/catalog/rootcauses/{id}", handler.GetCatalogRootCause)
+	r.Post("/catalog/rootcauses", handler.PostCatalogRootCause)
+	r.Put("/catalog/rootcauses/{id}", handler.PutCatalogRootCause)
+	r.Delete("/catalog/rootcauses/{id}", handler.DeleteCatalogRootCause)
+	r.Get("/catalog/rootcauses/{id}/links", handler.GetCatalogRootCauseLinks)
+	r.Post("/catalog/rootcauses/{id}/links", handler.PostCatalogRootCauseLink)
+	r.Delete("/catalog/rootcauses/{id}/links", handler.DeleteCatalogRootCauseLink)
+	r.Post("/catalog/actions", handler.PostCatalogAction)
+	r.Put("/catalog/actions/{id}", handler.PutCatalogAction)
+	r.Delete("/catalog/actions/{id}", handler.DeleteCatalogAction)
+	r.Get("/catalog/stats", handler.GetCatalogStats)
+
+	r.Post("/search", handler.Search)
 	r.Get("/search/last", handler.SearchLast)
 	r.Get("/search/last/byinterval", handler.SearchLastByInterval)
 	r.Get("/search/last/bycustominterval", handler.SearchLastByCustomInterval)
@@ -204,6 +241,52 @@ func engineRouter(services Services) http.Handler {
 	r.Post("/history/facts/date/result", handler.GetFactResultByDateCriteria)
 	r.Post("/history/factexpr/today/result", handler.GetFactExprResultForTodayByCriteria)
 	r.Post("/history/factexpr/date/result", handler.GetFactExprResultByDateCriteria)
+	r.Post("/history/compare", handler.CompareHistory)
+
+	r.Get("/history/archives", handler.GetHistoryArchives)
+	r.Post("/history/archives/rehydrate", handler.RehydrateHistoryArchives)
+	r.Delete("/history/archives/rehydrate", handler.EvictHistoryArchives)
+	r.Post("/history/archives/search", handler.SearchHistoryArchives)
+	r.Post("/history/replay", handler.ReplayHistory)
+
+	r.Get("/baselines", handler.GetBaselines)
+	r.Get("/baselines/{id}", handler.GetBaseline)
+	r.Post("/baselines", handler.PostBaseline)
+	r.Put("/baselines/{id}", handler.PutBaseline)
+	r.Delete("/baselines/{id}", handler.DeleteBaseline)
+
+	r.Get("/maintenance_windows", handler.GetMaintenanceWindows)
+	r.Get("/maintenance_windows/suppressed_actions", handler.GetSuppressedActions)
+	r.Get("/maintenance_windows/{id}", handler.GetMaintenanceWindow)
+	r.Post("/maintenance_windows", handler.PostMaintenanceWindow)
+	r.Put("/maintenance_windows/{id}", handler.PutMaintenanceWindow)
+	r.Delete("/maintenance_windows/{id}", handler.DeleteMaintenanceWindow)
+
+	r.Get("/issue_slas", handler.GetIssueSLAs)
+	r.Get("/issue_slas/{id}", handler.GetIssueSLA)
+	r.Post("/issue_slas", handler.PostIssueSLA)
+	r.Put("/issue_slas/{id}", handler.PutIssueSLA)
+	r.Delete("/issue_slas/{id}", handler.DeleteIssueSLA)
+
+	r.Get("/incidents", handler.GetIncidents)
+	r.Get("/incidents/{id}", handler.GetIncident)
+	r.Post("/incidents", handler.PostIncident)
+	r.Post("/incidents/{id}/merge", handler.PostIncidentMerge)
+	r.Post("/incidents/{id}/split", handler.PostIncidentSplit)
+	r.Post("/incidents/{id}/feedback/{isFakeAlert}", handler.PostIncidentCloseWithFeedback)
+	r.Delete("/incidents/{id}", handler.DeleteIncident)
+
+	r.Get("/incident_correlation_rules", handler.GetIncidentCorrelationRules)
+	r.Get("/incident_correlation_rules/{id}", handler.GetIncidentCorrelationRule)
+	r.Post("/incident_correlation_rules", handler.PostIncidentCorrelationRule)
+	r.Put("/incident_correlation_rules/{id}", handler.PutIncidentCorrelationRule)
+	r.Delete("/incident_correlation_rules/{id}", handler.DeleteIncidentCorrelationRule)
+
+	r.Get("/issue_webhooks", handler.GetIssueWebhooks)
+	r.Get("/issue_webhooks/{id}", handler.GetIssueWebhook)
+	r.Post("/issue_webhooks", handler.PostIssueWebhook)
+	r.Put("/issue_webhooks/{id}", handler.PutIssueWebhook)
+	r.Delete("/issue_webhooks/{id}", handler.DeleteIssueWebhook)
 
 	r.Get("/calendars", handler.GetCalendars)
 	r.Get("/calendars/{id}", handler.GetCalendar)
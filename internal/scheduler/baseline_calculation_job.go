@@ -10,6 +10,7 @@ import (
 )
 
 // BaselineCalculationJob represent a scheduler job instance which process a group of baselines, and persist the result in postgresql
+// The baselines are built by the baseline plugin if it is running, or by the built-in baseline engine
 type BaselineCalculationJob struct {
 	BaselineIds []int64 `json:"baselineIds"`
 	Debug       bool    `json:"debug"`
@@ -38,10 +39,10 @@ func (job BaselineCalculationJob) Run() {
 
 	zap.L().Info("Baseline calculation job started", zap.Int64s("ids", job.BaselineIds))
 
-	pluginBaseline, err := baseline.P()
+	baselineService, err := baseline.S()
 	if err == nil {
 		for _, b := range job.BaselineIds {
-			err := pluginBaseline.BaselineService.BuildBaselineValues(b)
+			err := baselineService.BuildBaselineValues(b)
 			if err != nil {
 				zap.L().Error("BuildBaselineValues", zap.Int64("baselineID", b), zap.Error(err))
 				S().RemoveRunningJob(job.ScheduleID)
@@ -49,7 +50,7 @@ func (job BaselineCalculationJob) Run() {
 			}
 		}
 	} else {
-		zap.L().Warn("Cannot execute BaselineScheduleJob. Plugin is unavailable")
+		zap.L().Warn("Cannot execute BaselineScheduleJob. Baseline service is unavailable", zap.Error(err))
 	}
 
 	zap.L().Info("BaselineScheduleJob Ended", zap.Int64s("ids", job.BaselineIds))
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -368,7 +369,12 @@ func CalculateAndPersistFacts(t time.Time, job FactCalculationJob) (map[string]h
 
 		if !f.IsTemplate {
 			// execute fact, to get results
-			widgetData, err := fact.ExecuteFact(t, f, 0, 0, make(map[string]interface{}), 0, 0, false)
+			parameters, err := fact.ResolveParameters(f.ID, make(map[string]interface{}))
+			if err != nil {
+				zap.L().Error("Fact parameters resolution Error, skipping fact calculation...", zap.Int64("id", f.ID), zap.Error(err))
+				continue
+			}
+			widgetData, err := fact.ExecuteFact(t, f, 0, 0, parameters, 0, 0, false)
 			if err != nil {
 				zap.L().Error("Fact calculation Error, skipping fact calculation...", zap.Int64("id", f.ID), zap.Any("fact", f), zap.Error(err))
 				continue
@@ -418,7 +424,13 @@ func CalculateAndPersistFacts(t time.Time, job FactCalculationJob) (map[string]h
 					continue
 				}
 
-				widgetData, err := fact.ExecuteFact(t, fCopy, sh.SituationID, sh.SituationInstanceID, sh.Parameters, 0, 0, false)
+				parameters, err := fact.ResolveParameters(f.ID, sh.Parameters)
+				if err != nil {
+					zap.L().Error("Fact parameters resolution Error, skipping fact calculation...", zap.Int64("id", f.ID), zap.Int64("situationID", sh.SituationID),
+						zap.Int64("situationInstanceID", sh.SituationInstanceID), zap.Error(err))
+					continue
+				}
+				widgetData, err := fact.ExecuteFact(t, fCopy, sh.SituationID, sh.SituationInstanceID, parameters, 0, 0, false)
 				if err != nil {
 					zap.L().Error("Fact calculation Error, skipping fact calculation...", zap.Int64("id", f.ID), zap.Any("fact", f), zap.Error(err))
 					continue
@@ -508,53 +520,15 @@ func CalculateAndPersistSituations(localRuleEngine *ruleeng.RuleEngine, situatio
 			zap.L().Error("", zap.Error(err))
 		}
 
-		metadatas := make([]metadata.MetaData, 0)
-		agenda := evaluator.EvaluateRules(localRuleEngine, historySituationFlattenData, enabledRuleIDs)
-		var filteredAgenda []ruleeng.Action
-		var prev *history.HistorySituationsV4 = nil
-		for _, agen := range agenda {
-			if agen.GetName() == tasker.ActionSet {
-				context := tasker.BuildContextData(agen.GetMetaData())
-				for key, value := range agen.GetParameters() {
-					metadatas = append(metadatas, metadata.MetaData{
-						Key:         key,
-						Value:       value,
-						RuleID:      context.RuleID,
-						RuleVersion: context.RuleVersion,
-						CaseName:    context.CaseName,
-					})
-				}
-				continue
-			}
-			if !agen.GetCheckPrevSetAction() {
-				filteredAgenda = append(filteredAgenda, agen)
-				continue
-			}
-
-			if agen.GetName() == tasker.ActionCreateIssue || agen.GetName() == tasker.ActionSituationReporting {
-				// Load previous history if necessary
-				if prev == nil {
-					latestHistory, err := history.S().HistorySituationsQuerier.GetLatestHistory(situationToUpdate.SituationID, situationToUpdate.SituationInstanceID)
-					if err != nil {
-						filteredAgenda = append(filteredAgenda, agen)
-						continue
-					}
-					prev = &latestHistory
-				}
-				isCritical := false
-				for _, metadata := range prev.Metadatas {
-					if strings.EqualFold(metadata.Value.(string), model.Critical.String()) {
-						isCritical = true
-						break
-					}
-				}
-
-				if !isCritical {
-					filteredAgenda = append(filteredAgenda, agen)
-				}
-
+		knowledgeBase := history.S().RuleKnowledgeBase(historySituationFlattenData, situationToUpdate.SituationID, situationToUpdate.SituationInstanceID, situationToUpdate.Ts, historyFactsAll)
+		agenda := evaluator.EvaluateRules(localRuleEngine, knowledgeBase, enabledRuleIDs)
+		metadatas, filteredAgenda := filterAgenda(agenda, func() ([]metadata.MetaData, bool) {
+			latestHistory, err := history.S().HistorySituationsQuerier.GetLatestHistory(situationToUpdate.SituationID, situationToUpdate.SituationInstanceID)
+			if err != nil {
+				return nil, false
 			}
-		}
+			return latestHistory.Metadatas, true
+		})
 
 		// Build and insert HistorySituationV4
 		historySituationNew := history.HistorySituationsV4{
@@ -587,6 +561,7 @@ func CalculateAndPersistSituations(localRuleEngine *ruleeng.RuleEngine, situatio
 				HistorySituationID: historySituationNew.ID,
 				HistoryFactID:      historyFactNew.ID,
 				FactID:             historyFactNew.FactID,
+				Ts:                 historySituationNew.Ts,
 			})
 		}
 
@@ -876,6 +851,30 @@ func asFactCalculationJob(job InternalJob) (FactCalculationJob, bool) {
 	}
 }
 
+// GetSchedulesByFactID returns the fact calculation schedules including a fact
+func GetSchedulesByFactID(factID int64) ([]InternalSchedule, error) {
+	schedules, err := R().GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]InternalSchedule, 0)
+	for _, schedule := range schedules {
+		job, ok := extractFactCalculationJob(schedule)
+		if !ok {
+			continue
+		}
+		for _, id := range job.FactIds {
+			if id == factID {
+				result = append(result, schedule)
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
 func extractFactCalculationJob(schedule InternalSchedule) (FactCalculationJob, bool) {
 	switch typedJob := schedule.Job.(type) {
 	case FactCalculationJob:
@@ -897,3 +896,54 @@ func isFactBoostManagedSchedule(schedule InternalSchedule) bool {
 	factJob, ok := extractFactCalculationJob(schedule)
 	return ok && boostConfigured(factJob.JobBoostInfo)
 }
+
+// filterAgenda splits an evaluated agenda into the metadatas set by the rules and the actions to perform
+// An action checking the previous set action is only performed for the create-issue and situation-reporting actions,
+// and only if the previous evaluation of the situation instance was not critical (or is unknown).
+// prevMetadatas returns the metadatas of the previous evaluation, it is called once and only if needed
+func filterAgenda(agenda []ruleeng.Action, prevMetadatas func() ([]metadata.MetaData, bool)) ([]metadata.MetaData, []ruleeng.Action) {
+	metadatas := make([]metadata.MetaData, 0)
+	var filteredAgenda []ruleeng.Action
+	var prev []metadata.MetaData
+	prevLoaded, hasPrev := false, false
+	for _, agen := range agenda {
+		if agen.GetName() == tasker.ActionSet {
+			context := tasker.BuildContextData(agen.GetMetaData())
+			for key, value := range agen.GetParameters() {
+				metadatas = append(metadatas, metadata.MetaData{
+					Key:         key,
+					Value:       value,
+					RuleID:      context.RuleID,
+					RuleVersion: context.RuleVersion,
+					CaseName:    context.CaseName,
+				})
+			}
+			continue
+		}
+		if !agen.GetCheckPrevSetAction() {
+			filteredAgenda = append(filteredAgenda, agen)
+			continue
+		}
+		if agen.GetName() != tasker.ActionCreateIssue && agen.GetName() != tasker.ActionSituationReporting {
+			continue
+		}
+
+		if !prevLoaded {
+			prev, hasPrev = prevMetadatas()
+			prevLoaded = true
+		}
+		if !hasPrev || !isCritical(prev) {
+			filteredAgenda = append(filteredAgenda, agen)
+		}
+	}
+	return metadatas, filteredAgenda
+}
+
+func isCritical(metadatas []metadata.MetaData) bool {
+	for _, md := range metadatas {
+		if value, ok := md.Value.(string); ok && strings.EqualFold(value, model.Critical.String()) {
+			return true
+		}
+	}
+	return false
+}
@@ -208,34 +208,17 @@ func (job FactRecalculationJob) RecalculateAndUpdateFacts(factIDs []int64, facts
 		if !recalculate {
 			newFactHistory[fh.ID] = fh
 		} else {
-			b, _ := json.Marshal(facts[fh.FactID])
-			var f engine.Fact
-			json.Unmarshal(b, &f) // deep copy, calculateFact is doing non-immutable operation...
-
-			// historyFactID => historySituationFactID => historySituationID
-
-			// recalculate
 			parameters := make(map[string]interface{})
-			if f.IsTemplate {
+			if facts[fh.FactID].IsTemplate {
 				s := mapSituations[mapFactSituation[fh.ID]]
 				parameters = s.Parameters
 			}
 
-			widgetData, err := fact.ExecuteFact(fh.Ts, f, fh.SituationID, fh.SituationInstanceID, parameters, 0, 0, true)
+			newFH, err := recalculateHistoryFact(facts[fh.FactID], fh, parameters)
 			if err != nil {
 				zap.L().Error("fact.ExecuteFact", zap.Error(err))
 				continue
 			}
-
-			newFH := history.HistoryFactsV4{
-				ID:                  fh.ID,
-				FactID:              fh.FactID,
-				FactName:            fh.FactName,
-				SituationID:         fh.SituationID,
-				SituationInstanceID: fh.SituationInstanceID,
-				Ts:                  fh.Ts,
-				Result:              *widgetData.Aggregates,
-			}
 			err = history.S().HistoryFactsQuerier.Update(newFH)
 			if err != nil {
 				zap.L().Error("HistoryFactsQuerier.Update", zap.Error(err))
@@ -247,15 +230,44 @@ func (job FactRecalculationJob) RecalculateAndUpdateFacts(factIDs []int64, facts
 	return newFactHistory, nil
 }
 
+// recalculateHistoryFact executes again a fact at the time of a fact history record
+func recalculateHistoryFact(definition engine.Fact, fh history.HistoryFactsV4, parameters map[string]interface{}) (history.HistoryFactsV4, error) {
+	b, _ := json.Marshal(definition)
+	var f engine.Fact
+	json.Unmarshal(b, &f) // deep copy, calculateFact is doing non-immutable operation...
+
+	parameters, err := fact.ResolveParameters(f.ID, parameters)
+	if err != nil {
+		return history.HistoryFactsV4{}, err
+	}
+
+	widgetData, err := fact.ExecuteFact(fh.Ts, f, fh.SituationID, fh.SituationInstanceID, parameters, 0, 0, true)
+	if err != nil {
+		return history.HistoryFactsV4{}, err
+	}
+
+	return history.HistoryFactsV4{
+		ID:                  fh.ID,
+		FactID:              fh.FactID,
+		FactName:            fh.FactName,
+		SituationID:         fh.SituationID,
+		SituationInstanceID: fh.SituationInstanceID,
+		Ts:                  fh.Ts,
+		Result:              *widgetData.Aggregates,
+	}, nil
+}
+
 func (job FactRecalculationJob) RecalculateAndUpdateSituations(localRuleEngine *ruleeng.RuleEngine, s situation2.Situation, mapSituationFact map[int64][]int64,
 	historySituations []history.HistorySituationsV4, newFactHistory map[int64]history.HistoryFactsV4) error {
 
 	for _, sh := range historySituations {
 		historySituationFlattenData := make(map[string]interface{})
+		historyFacts := make([]history.HistoryFactsV4, 0)
 
 		// Get all corresponding fact history rows
 		for _, historyFactID := range mapSituationFact[sh.ID] {
 			historyFact := newFactHistory[historyFactID]
+			historyFacts = append(historyFacts, historyFact)
 			historyFactData, err := historyFact.Result.ToAbstractMap()
 			if err != nil {
 				zap.L().Error("", zap.Error(err))
@@ -285,7 +297,8 @@ func (job FactRecalculationJob) RecalculateAndUpdateSituations(localRuleEngine *
 		}
 
 		metadatas := make([]metadata.MetaData, 0)
-		agenda := evaluator.EvaluateRules(localRuleEngine, historySituationFlattenData, enabledRuleIDs)
+		knowledgeBase := history.S().RuleKnowledgeBase(historySituationFlattenData, sh.SituationID, sh.SituationInstanceID, sh.Ts, historyFacts)
+		agenda := evaluator.EvaluateRules(localRuleEngine, knowledgeBase, enabledRuleIDs)
 		for _, agen := range agenda {
 			if agen.GetName() == "set" {
 				context := tasker.BuildContextData(agen.GetMetaData())
@@ -63,10 +63,10 @@ func testInsertFactHistory(t *testing.T, situationID int64, instanceID int64, fa
 		idf4, _ := history.S().HistoryFactsQuerier.Insert(history.HistoryFactsV4{FactID: factID, SituationID: situationID, SituationInstanceID: instanceID, Ts: time.Date(2023, 1, 2, 18, 0, 0, 0, time.UTC), Result: res})
 
 		_ = history.S().HistorySituationFactsQuerier.Execute(history.S().HistorySituationFactsQuerier.Builder.InsertBulk([]history.HistorySituationFactsV4{
-			{HistorySituationID: situationHistoryIDs[0], HistoryFactID: idf1, FactID: factID},
-			{HistorySituationID: situationHistoryIDs[1], HistoryFactID: idf2, FactID: factID},
-			{HistorySituationID: situationHistoryIDs[2], HistoryFactID: idf3, FactID: factID},
-			{HistorySituationID: situationHistoryIDs[3], HistoryFactID: idf4, FactID: factID},
+			{HistorySituationID: situationHistoryIDs[0], HistoryFactID: idf1, FactID: factID, Ts: time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)},
+			{HistorySituationID: situationHistoryIDs[1], HistoryFactID: idf2, FactID: factID, Ts: time.Date(2023, 1, 1, 18, 0, 0, 0, time.UTC)},
+			{HistorySituationID: situationHistoryIDs[2], HistoryFactID: idf3, FactID: factID, Ts: time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)},
+			{HistorySituationID: situationHistoryIDs[3], HistoryFactID: idf4, FactID: factID, Ts: time.Date(2023, 1, 2, 18, 0, 0, 0, time.UTC)},
 		}))
 	}
 }
@@ -0,0 +1,306 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/evaluator"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/rule"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/tasker"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/fact"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/history"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/metadata"
+	situation2 "github.com/myrteametrics/myrtea-engine-api/v5/pkg/situation"
+	"github.com/myrteametrics/myrtea-sdk/v5/expression"
+	"github.com/myrteametrics/myrtea-sdk/v5/ruleeng"
+	"go.uber.org/zap"
+)
+
+// maxReplayRange bounds the range of a replay, so that it runs within the request timeout
+const maxReplayRange = 7 * 24 * time.Hour
+
+// FactReplay re-runs the evaluations of situations over a past range in an in-memory sandbox
+// Unlike FactRecalculationJob, the history is left untouched and no task is performed: the replay returns the evaluations
+// and the issues which would have been created with the current situation and rule definitions
+type FactReplay struct {
+	Situations         []search.SituationSelector `json:"situations"`
+	From               time.Time                  `json:"from"`
+	To                 time.Time                  `json:"to"`
+	RecalculateFacts   bool                       `json:"recalculateFacts"`   // executes again the facts, instead of reading their values in the history
+	RuleIDs            []int64                    `json:"ruleIds,omitempty"`  // replays these rules (even if disabled), instead of the enabled rules of the situations
+	IncludeEvaluations bool                       `json:"includeEvaluations"` // returns every evaluation, and not only the issues
+}
+
+// ReplayEvaluation is a replayed evaluation of a situation instance
+type ReplayEvaluation struct {
+	SituationID         int64                  `json:"situationId"`
+	SituationInstanceID int64                  `json:"situationInstanceId"`
+	Ts                  time.Time              `json:"ts"`
+	ExpressionFacts     map[string]interface{} `json:"expressionFacts"`
+	MetaDatas           []metadata.MetaData    `json:"metaDatas"`
+	Actions             []string               `json:"actions"`
+}
+
+// ReplayResult is the result of a replay
+type ReplayResult struct {
+	EvaluationCount int                   `json:"evaluationCount"`
+	Issues          []tasker.IssuePreview `json:"issues"`
+	Evaluations     []ReplayEvaluation    `json:"evaluations,omitempty"`
+	Errors          []string              `json:"errors,omitempty"`
+}
+
+// replaySandbox stands for the situation history and the issues during a replay
+type replaySandbox struct {
+	latest map[model.Key]ReplayEvaluation
+	issues map[string][]tasker.IssuePreview
+	result ReplayResult
+}
+
+// IsValid checks if a replay is valid
+func (replay FactReplay) IsValid() (bool, error) {
+	if len(replay.Situations) == 0 {
+		return false, errors.New("missing situations")
+	}
+	if replay.From.IsZero() || replay.To.IsZero() {
+		return false, errors.New("missing from or to")
+	}
+	if !replay.From.Before(replay.To) {
+		return false, errors.New("from must be before to")
+	}
+	if replay.To.Sub(replay.From) > maxReplayRange {
+		return false, errors.New("the replay range must not exceed 7 days")
+	}
+	return true, nil
+}
+
+// Run replays the evaluations of the situation history records of the range, in chronological order
+// The replay is stopped once the context is done
+func (replay FactReplay) Run(ctx context.Context) (ReplayResult, error) {
+	ruleEngine, err := replay.ruleEngine()
+	if err != nil {
+		return ReplayResult{}, err
+	}
+
+	historySituations, err := history.S().GetHistorySituationsRange(history.GetHistorySituationsOptions{
+		SituationID: -1, Situations: replay.Situations, FromTS: replay.From, ToTS: replay.To,
+	})
+	if err != nil {
+		return ReplayResult{}, err
+	}
+	sort.Slice(historySituations, func(i, j int) bool {
+		if historySituations[i].Ts.Equal(historySituations[j].Ts) {
+			return historySituations[i].ID < historySituations[j].ID
+		}
+		return historySituations[i].Ts.Before(historySituations[j].Ts)
+	})
+
+	historyFacts, mapSituationFact, mapFactSituation, err := FactRecalculationJob{}.FetchRecalculationData(historySituations)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+	mapSituations := make(map[int64]history.HistorySituationsV4)
+	for _, sh := range historySituations {
+		mapSituations[sh.ID] = sh
+	}
+
+	sandbox := &replaySandbox{
+		latest: make(map[model.Key]ReplayEvaluation),
+		issues: make(map[string][]tasker.IssuePreview),
+		result: ReplayResult{Issues: make([]tasker.IssuePreview, 0)},
+	}
+
+	mapFacts, err := replay.facts(ctx, historyFacts, mapFactSituation, mapSituations, sandbox)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+
+	situations := make(map[int64]situation2.Situation)
+	for _, sh := range historySituations {
+		if err := ctx.Err(); err != nil {
+			return ReplayResult{}, err
+		}
+		s, ok := situations[sh.SituationID]
+		if !ok {
+			var found bool
+			s, found, err = situation2.R().Get(sh.SituationID)
+			if err != nil {
+				return ReplayResult{}, err
+			}
+			if !found {
+				continue
+			}
+			situations[sh.SituationID] = s
+		}
+
+		facts := make([]history.HistoryFactsV4, 0)
+		for _, historyFactID := range mapSituationFact[sh.ID] {
+			if historyFact, ok := mapFacts[historyFactID]; ok {
+				facts = append(facts, historyFact)
+			}
+		}
+
+		if err := replay.evaluate(ruleEngine, sandbox, s, sh, facts); err != nil {
+			sandbox.result.Errors = append(sandbox.result.Errors, err.Error())
+		}
+	}
+
+	return sandbox.result, nil
+}
+
+// ruleEngine returns a local rule engine with the replayed rules
+func (replay FactReplay) ruleEngine() (*ruleeng.RuleEngine, error) {
+	if len(replay.RuleIDs) == 0 {
+		return evaluator.BuildLocalRuleEngine("standart")
+	}
+
+	ruleEngine := ruleeng.NewRuleEngine()
+	for _, ruleID := range replay.RuleIDs {
+		r, found, err := rule.R().Get(ruleID)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("rule %d not found", ruleID)
+		}
+		ruleEngine.InsertRule(&r)
+	}
+	return ruleEngine, nil
+}
+
+// facts returns the fact history records by ID, executed again if requested
+func (replay FactReplay) facts(ctx context.Context, historyFacts []history.HistoryFactsV4, mapFactSituation map[int64]int64,
+	mapSituations map[int64]history.HistorySituationsV4, sandbox *replaySandbox) (map[int64]history.HistoryFactsV4, error) {
+
+	mapFacts := make(map[int64]history.HistoryFactsV4)
+	if !replay.RecalculateFacts {
+		for _, historyFact := range historyFacts {
+			mapFacts[historyFact.ID] = historyFact
+		}
+		return mapFacts, nil
+	}
+
+	factIDs := make([]int64, 0)
+	for _, historyFact := range historyFacts {
+		factIDs = append(factIDs, historyFact.FactID)
+	}
+	facts, err := fact.R().GetAllByIDs(factIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fh := range historyFacts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f, ok := facts[fh.FactID]
+		if !ok {
+			continue
+		}
+		parameters := make(map[string]interface{})
+		if f.IsTemplate {
+			parameters = mapSituations[mapFactSituation[fh.ID]].Parameters
+		}
+		newFH, err := recalculateHistoryFact(f, fh, parameters)
+		if err != nil {
+			zap.L().Warn("Cannot replay fact", zap.Int64("factID", fh.FactID), zap.Time("ts", fh.Ts), zap.Error(err))
+			sandbox.result.Errors = append(sandbox.result.Errors, err.Error())
+			continue
+		}
+		mapFacts[newFH.ID] = newFH
+	}
+	return mapFacts, nil
+}
+
+// evaluate replays the evaluation of a situation history record and stores its result in the sandbox
+func (replay FactReplay) evaluate(ruleEngine *ruleeng.RuleEngine, sandbox *replaySandbox, s situation2.Situation,
+	sh history.HistorySituationsV4, historyFacts []history.HistoryFactsV4) error {
+
+	historySituationFlattenData := make(map[string]interface{})
+	for _, historyFact := range historyFacts {
+		historyFactData, err := historyFact.Result.ToAbstractMap()
+		if err != nil {
+			return err
+		}
+		historySituationFlattenData[historyFact.FactName] = historyFactData
+	}
+	for key, value := range sh.Parameters {
+		historySituationFlattenData[key] = value
+	}
+	for key, value := range expression.GetDateKeywords(sh.Ts) {
+		historySituationFlattenData[key] = value
+	}
+
+	expressionFacts := history.EvaluateExpressionFacts(s.ExpressionFacts, historySituationFlattenData)
+	for key, value := range expressionFacts {
+		historySituationFlattenData[key] = value
+	}
+
+	ruleIDs := replay.RuleIDs
+	if len(ruleIDs) == 0 {
+		var err error
+		ruleIDs, err = rule.R().GetEnabledRuleIDs(sh.SituationID, sh.Ts)
+		if err != nil {
+			return err
+		}
+	}
+
+	knowledgeBase := history.S().RuleKnowledgeBase(historySituationFlattenData, sh.SituationID, sh.SituationInstanceID, sh.Ts, historyFacts)
+	agenda := evaluator.EvaluateRules(ruleEngine, knowledgeBase, ruleIDs)
+
+	key := model.Key{SituationID: sh.SituationID, SituationInstanceID: sh.SituationInstanceID}
+	metadatas, filteredAgenda := filterAgenda(agenda, func() ([]metadata.MetaData, bool) {
+		prev, found := sandbox.latest[key]
+		return prev.MetaDatas, found
+	})
+
+	evaluation := ReplayEvaluation{
+		SituationID:         sh.SituationID,
+		SituationInstanceID: sh.SituationInstanceID,
+		Ts:                  sh.Ts,
+		ExpressionFacts:     expressionFacts,
+		MetaDatas:           metadatas,
+		Actions:             make([]string, 0),
+	}
+	batchContext := map[string]interface{}{
+		"situationID":        sh.SituationID,
+		"templateInstanceID": sh.SituationInstanceID,
+		"ts":                 sh.Ts,
+	}
+	for _, agen := range filteredAgenda {
+		evaluation.Actions = append(evaluation.Actions, agen.GetName())
+		if agen.GetName() != tasker.ActionCreateIssue {
+			continue
+		}
+		issue, err := tasker.PreviewCreateIssue(agen, batchContext)
+		if err != nil {
+			sandbox.result.Errors = append(sandbox.result.Errors, err.Error())
+			continue
+		}
+		if sandbox.create(issue) {
+			sandbox.result.Issues = append(sandbox.result.Issues, issue)
+		}
+	}
+
+	sandbox.latest[key] = evaluation
+	sandbox.result.EvaluationCount++
+	if replay.IncludeEvaluations {
+		sandbox.result.Evaluations = append(sandbox.result.Evaluations, evaluation)
+	}
+	return nil
+}
+
+// create stores a replayed issue, unless an issue with the same key and level is still open at this time
+func (sandbox *replaySandbox) create(issue tasker.IssuePreview) bool {
+	for _, existing := range sandbox.issues[issue.Key] {
+		if existing.Level == issue.Level && issue.SituationTS.Before(existing.ExpirationTS) {
+			return false
+		}
+	}
+	sandbox.issues[issue.Key] = append(sandbox.issues[issue.Key], issue)
+	return true
+}
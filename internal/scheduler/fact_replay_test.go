@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/tasker"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/metadata"
+	"github.com/myrteametrics/myrtea-sdk/v5/ruleeng"
+)
+
+func TestFactReplayIsValid(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	replay := FactReplay{Situations: []search.SituationSelector{{SituationID: 1}}, From: from, To: from.AddDate(0, 0, 7)}
+	if ok, err := replay.IsValid(); !ok {
+		t.Error(err)
+	}
+
+	invalid := []FactReplay{
+		{From: from, To: from.AddDate(0, 0, 7)},
+		{Situations: replay.Situations, To: from},
+		{Situations: replay.Situations, From: from, To: from},
+		{Situations: replay.Situations, From: from, To: from.AddDate(0, 0, 8)},
+	}
+	for _, r := range invalid {
+		if ok, _ := r.IsValid(); ok {
+			t.Errorf("expected %+v to be invalid", r)
+		}
+	}
+}
+
+func TestReplaySandboxCreate(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	sandbox := &replaySandbox{issues: make(map[string][]tasker.IssuePreview)}
+
+	issue := tasker.IssuePreview{Key: "1-2-late", Level: "critical", SituationTS: ts, ExpirationTS: ts.Add(time.Hour)}
+	if !sandbox.create(issue) {
+		t.Error("expected the first issue to be created")
+	}
+
+	issue.SituationTS, issue.ExpirationTS = ts.Add(30*time.Minute), ts.Add(90*time.Minute)
+	if sandbox.create(issue) {
+		t.Error("expected the issue to be skipped while the same issue is open")
+	}
+
+	issue.Level = "major"
+	if !sandbox.create(issue) {
+		t.Error("expected an issue with another level to be created")
+	}
+
+	issue.Level, issue.SituationTS = "critical", ts.Add(2*time.Hour)
+	if !sandbox.create(issue) {
+		t.Error("expected the issue to be created after the expiration of the previous one")
+	}
+}
+
+func TestIsCritical(t *testing.T) {
+	if !isCritical([]metadata.MetaData{{Key: "status", Value: "Critical"}}) {
+		t.Error("expected a critical metadata")
+	}
+	if isCritical([]metadata.MetaData{{Key: "status", Value: model.Info.String()}, {Key: "count", Value: 3.0}}) {
+		t.Error("expected no critical metadata")
+	}
+}
+
+// agendaAction is an evaluated rule action
+type agendaAction struct {
+	ruleeng.Action
+	name       string
+	parameters map[string]interface{}
+	checkPrev  bool
+}
+
+func (a agendaAction) GetName() string                       { return a.name }
+func (a agendaAction) GetParameters() map[string]interface{} { return a.parameters }
+func (a agendaAction) GetMetaData() map[string]interface{}   { return map[string]interface{}{} }
+func (a agendaAction) GetCheckPrevSetAction() bool           { return a.checkPrev }
+
+func TestFilterAgenda(t *testing.T) {
+	agenda := []ruleeng.Action{
+		agendaAction{name: tasker.ActionSet, parameters: map[string]interface{}{"status": "critical"}},
+		agendaAction{name: tasker.ActionCreateIssue, checkPrev: true},
+		agendaAction{name: tasker.ActionSituationReporting, checkPrev: true},
+		agendaAction{name: tasker.ActionNotify, checkPrev: true},
+		agendaAction{name: tasker.ActionNotify},
+	}
+
+	calls := 0
+	critical := func() ([]metadata.MetaData, bool) {
+		calls++
+		return []metadata.MetaData{{Key: "status", Value: "critical"}}, true
+	}
+	metadatas, filtered := filterAgenda(agenda, critical)
+	if len(metadatas) != 1 || metadatas[0].Key != "status" {
+		t.Errorf("unexpected metadatas %+v", metadatas)
+	}
+	if len(filtered) != 1 || filtered[0].GetName() != tasker.ActionNotify || filtered[0].GetCheckPrevSetAction() {
+		t.Errorf("expected only the action without previous check, got %+v", filtered)
+	}
+	if calls != 1 {
+		t.Errorf("expected the previous evaluation to be loaded once, got %d", calls)
+	}
+
+	// Without a previous evaluation, the create-issue and situation-reporting actions are performed, but not the others
+	_, filtered = filterAgenda(agenda, func() ([]metadata.MetaData, bool) { return nil, false })
+	names := make([]string, 0)
+	for _, agen := range filtered {
+		names = append(names, agen.GetName())
+	}
+	if len(names) != 3 || names[0] != tasker.ActionCreateIssue || names[1] != tasker.ActionSituationReporting || names[2] != tasker.ActionNotify {
+		t.Errorf("unexpected actions %v", names)
+	}
+}
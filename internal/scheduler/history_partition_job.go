@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/history"
+	"go.uber.org/zap"
+)
+
+// HistoryPartitionJob represent a scheduler job instance which creates the daily partitions of the history tables ahead,
+// and optionally drops the partitions older than the retention
+type HistoryPartitionJob struct {
+	Ahead      string `json:"ahead"`               // Range of the partitions created ahead of now
+	Retention  string `json:"retention,omitempty"` // Optional, partitions older than the retention are dropped with their issues
+	Archive    bool   `json:"archive,omitempty"`   // Optional, the partitions are archived to files before being dropped
+	ScheduleID int64  `json:"-"`
+}
+
+// IsValid checks if an internal schedule job definition is valid and has no missing mandatory fields
+func (job HistoryPartitionJob) IsValid() (bool, error) {
+	if _, err := parseDuration(job.Ahead); err != nil {
+		return false, errors.New(`Error parsing the Partition's Ahead`)
+	}
+	if job.Retention != "" {
+		if _, err := parseDuration(job.Retention); err != nil {
+			return false, errors.New(`Error parsing the Partition's Retention`)
+		}
+	}
+	return true, nil
+}
+
+// Run contains all the business logic of the job
+func (job HistoryPartitionJob) Run() {
+
+	if S().ExistingRunningJob(job.ScheduleID) {
+		zap.L().Info("Skipping Partition ScheduleJob because last execution is still running", zap.Int64("idSchedule", job.ScheduleID))
+		return
+	}
+	S().AddRunningJob(job.ScheduleID)
+	defer S().RemoveRunningJob(job.ScheduleID)
+
+	zap.L().Info("History partition job started", zap.Int64("idSchedule", job.ScheduleID))
+
+	ahead, err := parseDuration(job.Ahead)
+	if err != nil {
+		zap.L().Info("Error parsing the Partition's Ahead", zap.Error(err), zap.Int64("idSchedule", job.ScheduleID))
+		return
+	}
+
+	now := time.Now()
+	created, err := history.S().EnsureHistoryPartitions(now, now.Add(ahead))
+	if err != nil {
+		zap.L().Error("History partition job error", zap.Error(err), zap.Int64("idSchedule", job.ScheduleID))
+		return
+	}
+
+	if job.Retention != "" {
+		retention, err := parseDuration(job.Retention)
+		if err != nil {
+			zap.L().Info("Error parsing the Partition's Retention", zap.Error(err), zap.Int64("idSchedule", job.ScheduleID))
+			return
+		}
+
+		options := history.GetHistorySituationsOptions{
+			SituationID:    -1,
+			DeleteBeforeTs: now.Add(-1 * retention),
+			Archive:        job.Archive,
+		}
+		if err = history.S().PurgeHistory(options); err != nil {
+			zap.L().Error("History partition job error", zap.Error(err), zap.Int64("idSchedule", job.ScheduleID))
+			return
+		}
+	}
+
+	zap.L().Info("History partition job ended", zap.Int64("idSchedule", job.ScheduleID), zap.Int("created", created))
+}
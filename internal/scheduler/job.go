@@ -21,6 +21,8 @@ var jobTypes = map[string]struct{}{
 	"compact":           {},
 	"purge":             {},
 	"elastic_doc_purge": {},
+	"rollup":            {},
+	"partition":         {},
 }
 
 // InternalSchedule wrap a schedule
@@ -28,7 +30,7 @@ type InternalSchedule struct {
 	ID       int64       `json:"id"`
 	Name     string      `json:"name"`
 	CronExpr string      `json:"cronexpr" example:"0 */15 * * *"`
-	JobType  string      `json:"jobtype" enums:"fact,baseline,compact,purge,elastic_doc_purge"`
+	JobType  string      `json:"jobtype" enums:"fact,baseline,compact,purge,elastic_doc_purge,rollup,partition"`
 	Job      InternalJob `json:"job"`
 	Enabled  bool        `json:"enabled"`
 }
@@ -116,6 +118,16 @@ func UnmarshalInternalJob(t string, b json.RawMessage, scheduleID int64) (Intern
 		err = json.Unmarshal(b, &tJob)
 		tJob.ScheduleID = scheduleID
 		job = tJob
+	case "rollup":
+		var tJob RollupHistoryJob
+		err = json.Unmarshal(b, &tJob)
+		tJob.ScheduleID = scheduleID
+		job = tJob
+	case "partition":
+		var tJob HistoryPartitionJob
+		err = json.Unmarshal(b, &tJob)
+		tJob.ScheduleID = scheduleID
+		job = tJob
 
 	default:
 		zap.L().Error("unknown internal job type", zap.String("type", t))
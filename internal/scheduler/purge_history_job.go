@@ -9,11 +9,13 @@ import (
 )
 
 // PurgeHistoryJob represent a scheduler job instance which process a group of Purge history, and persist the result in postgresql
+// The whole history is purged by dropping the expired daily partitions, which are archived to files first when Archive is set
 type PurgeHistoryJob struct {
 	SituationID         int64             `json:"situationId"`
 	SituationInstanceID int64             `json:"situationInstanceId"`
 	ParameterFilters    map[string]string `json:"parameterFilters"`
 	DeleteBeforeTs      string            `json:"deleteBeforeTs"`
+	Archive             bool              `json:"archive,omitempty"`
 	ScheduleID          int64             `json:"-"`
 }
 
@@ -50,6 +52,7 @@ func (job PurgeHistoryJob) Run() {
 		SituationInstanceIDs: []int64{},
 		ParameterFilters:     make(map[string]interface{}),
 		DeleteBeforeTs:       time.Now().Add(-1 * DeleteBeforeTsDuration),
+		Archive:              job.Archive,
 	}
 
 	err = history.S().PurgeHistory(options)
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/history"
+	"go.uber.org/zap"
+)
+
+// RollupHistoryJob represent a scheduler job instance which materializes the hourly, daily and monthly rollups of the history
+// It should run before the history compaction, so that the rollups are computed from every intermediate record
+type RollupHistoryJob struct {
+	Lookback   string `json:"lookback"` // Range rolled up on the first run, subsequent runs resume where the previous one stopped
+	ScheduleID int64  `json:"-"`
+}
+
+// IsValid checks if an internal schedule job definition is valid and has no missing mandatory fields
+func (job RollupHistoryJob) IsValid() (bool, error) {
+	if _, err := parseDuration(job.Lookback); err != nil {
+		return false, errors.New(`Error parsing the Rollup's Lookback`)
+	}
+	return true, nil
+}
+
+// Run contains all the business logic of the job
+func (job RollupHistoryJob) Run() {
+
+	if S().ExistingRunningJob(job.ScheduleID) {
+		zap.L().Info("Skipping Rollup ScheduleJob because last execution is still running", zap.Int64("idSchedule", job.ScheduleID))
+		return
+	}
+	S().AddRunningJob(job.ScheduleID)
+	defer S().RemoveRunningJob(job.ScheduleID)
+
+	zap.L().Info("Rollup history job started", zap.Int64("idSchedule", job.ScheduleID))
+
+	lookback, err := parseDuration(job.Lookback)
+	if err != nil {
+		zap.L().Info("Error parsing the Rollup's Lookback", zap.Error(err), zap.Int64("idSchedule", job.ScheduleID))
+		return
+	}
+
+	coverage, err := history.S().HistoryRollupsQuerier.GetCoverage(history.RollupHour)
+	if err != nil {
+		zap.L().Error("Rollup History job error", zap.Error(err), zap.Int64("idSchedule", job.ScheduleID))
+		return
+	}
+
+	to := time.Now()
+	from := coverage.To
+	if from.IsZero() {
+		from = to.Add(-1 * lookback)
+	}
+
+	err = history.S().RollupHistory(from, to)
+	if err != nil {
+		zap.L().Error("Rollup History job error", zap.Error(err), zap.Int64("idSchedule", job.ScheduleID))
+		return
+	}
+
+	zap.L().Info("Rollup history job ended", zap.Int64("idSchedule", job.ScheduleID), zap.Time("from", from), zap.Time("to", to))
+}
@@ -0,0 +1,204 @@
+package search
+
+import (
+	"sort"
+	"time"
+)
+
+// BucketOf returns the start of the time bucket containing t
+// buckets must be sorted, and the first bucket is returned if t is before every bucket
+func BucketOf(buckets []time.Time, t time.Time) time.Time {
+	if len(buckets) == 0 {
+		return t
+	}
+	i := sort.Search(len(buckets), func(i int) bool { return buckets[i].After(t) })
+	if i == 0 {
+		return buckets[0]
+	}
+	return buckets[i-1]
+}
+
+// DownSample aggregates the records of each situation instance by time bucket with the down-sampling operation
+// The aggregated records are dated at the start of their bucket
+func DownSample(records []SituationHistoryRecord, buckets []time.Time, operation string) []SituationHistoryRecord {
+	type groupKey struct {
+		bucket              time.Time
+		situationID         int64
+		situationInstanceID int64
+	}
+
+	keys := make([]groupKey, 0)
+	groups := make(map[groupKey][]SituationHistoryRecord)
+	for _, record := range records {
+		key := groupKey{
+			bucket:              BucketOf(buckets, record.DateTime),
+			situationID:         record.SituationID,
+			situationInstanceID: record.SituationInstanceID,
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], record)
+	}
+
+	result := make([]SituationHistoryRecord, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, aggregateRecords(groups[key], key.bucket, operation))
+	}
+	return result
+}
+
+// aggregateRecords merges the records of a situation instance in a single record
+// Parameters are taken from the first record, numeric facts, expression facts and metadatas are aggregated
+func aggregateRecords(records []SituationHistoryRecord, bucket time.Time, operation string) SituationHistoryRecord {
+	out := records[0]
+	out.DateTime = bucket
+	out.Facts = nil
+	out.ExpressionFacts = nil
+	out.MetaData = nil
+
+	expressionFactsList := make([]map[string]interface{}, 0, len(records))
+	metaDataList := make([]map[string]interface{}, 0, len(records))
+	factNames := make(map[int64]string)
+	factIDs := make([]int64, 0)
+	factValues := make(map[int64][]map[string]interface{})
+	for _, record := range records {
+		if record.ExpressionFacts != nil {
+			expressionFactsList = append(expressionFactsList, record.ExpressionFacts)
+		}
+		if record.MetaData != nil {
+			metaDataList = append(metaDataList, record.MetaData)
+		}
+		for _, fact := range record.Facts {
+			if _, ok := factNames[fact.FactID]; !ok {
+				factNames[fact.FactID] = fact.FactName
+				factIDs = append(factIDs, fact.FactID)
+			}
+			data := make(map[string]interface{})
+			if fact.Value != nil {
+				data["value"] = fact.Value
+			}
+			if fact.DocCount != nil {
+				data["doc_count"] = fact.DocCount
+			}
+			factValues[fact.FactID] = append(factValues[fact.FactID], data)
+		}
+	}
+
+	if len(expressionFactsList) > 0 {
+		out.ExpressionFacts = make(map[string]interface{})
+		aggregate(expressionFactsList, operation, out.ExpressionFacts)
+	}
+	if len(metaDataList) > 0 {
+		out.MetaData = make(map[string]interface{})
+		aggregate(metaDataList, operation, out.MetaData)
+	}
+	for _, factID := range factIDs {
+		aggregation := make(map[string]interface{})
+		aggregate(factValues[factID], operation, aggregation)
+		out.Facts = append(out.Facts, FactHistoryRecord{
+			DateTime: bucket,
+			FactID:   factID,
+			FactName: factNames[factID],
+			Value:    aggregation["value"],
+			DocCount: aggregation["doc_count"],
+		})
+	}
+
+	return out
+}
+
+// NextBucket returns the start of the time bucket following the bucket starting at b
+func (d DownSampling) NextBucket(b time.Time) time.Time {
+	switch d.GranularitySpecial {
+	case "second":
+		return b.Add(time.Second)
+	case "minute":
+		return b.Add(time.Minute)
+	case "hour":
+		return b.Add(time.Hour)
+	case "day":
+		return b.AddDate(0, 0, 1)
+	case "week":
+		return b.AddDate(0, 0, 7)
+	case "month":
+		return b.AddDate(0, 1, 0)
+	case "quarter":
+		return b.AddDate(0, 3, 0)
+	case "year":
+		return b.AddDate(1, 0, 0)
+	default:
+		return b.Add(d.Granularity)
+	}
+}
+
+// Truncate returns the start of the time bucket containing t
+// Standard granularities are truncated in UTC, custom granularities are counted from origin
+func (d DownSampling) Truncate(t time.Time, origin time.Time) time.Time {
+	t = t.UTC()
+	switch d.GranularitySpecial {
+	case "second":
+		return t.Truncate(time.Second)
+	case "minute":
+		return t.Truncate(time.Minute)
+	case "hour":
+		return t.Truncate(time.Hour)
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case "week":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "quarter":
+		return time.Date(t.Year(), ((t.Month()-1)/3)*3+1, 1, 0, 0, 0, 0, time.UTC)
+	case "year":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if d.Granularity <= 0 {
+		return t
+	}
+	n := t.Sub(origin) / d.Granularity
+	if t.Before(origin) && t.Sub(origin)%d.Granularity != 0 {
+		n--
+	}
+	return origin.UTC().Add(n * d.Granularity)
+}
+
+// Percentile computes the p-th percentile of a list of values with a linear interpolation between the closest ranks
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// GroupByDateTime groups the records by date, using the date returned by dateTime for each record
+func GroupByDateTime(records []SituationHistoryRecord, dateTime func(SituationHistoryRecord) time.Time) QueryResult {
+	result := make(QueryResult, 0)
+	index := make(map[time.Time]int)
+	for _, record := range records {
+		dt := dateTime(record)
+		i, ok := index[dt]
+		if !ok {
+			i = len(result)
+			index[dt] = i
+			result = append(result, SituationHistoryRecords{DateTime: dt, Situations: make([]SituationHistoryRecord, 0)})
+		}
+		result[i].Situations = append(result[i].Situations, record)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DateTime.Before(result[j].DateTime)
+	})
+	return result
+}
@@ -0,0 +1,235 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	values := []float64{15, 20, 35, 40, 50}
+	if p := Percentile(values, 50); p != 35 {
+		t.Errorf("expected p50 = 35, got %v", p)
+	}
+	if p := Percentile(values, 100); p != 50 {
+		t.Errorf("expected p100 = 50, got %v", p)
+	}
+	if p := Percentile(values, 40); p != 29 {
+		t.Errorf("expected p40 = 29, got %v", p)
+	}
+	if p := Percentile([]float64{}, 50); p != 0 {
+		t.Errorf("expected 0 on empty values, got %v", p)
+	}
+	if values[0] != 15 || values[4] != 50 {
+		t.Error("Percentile must not modify the input values")
+	}
+}
+
+func TestAggregatePercentile(t *testing.T) {
+	dataList := []map[string]interface{}{
+		{"value": 1.0, "label": "a"},
+		{"value": 2.0, "label": "b"},
+		{"value": 3.0},
+	}
+	out := make(map[string]interface{})
+	aggregate(dataList, "p50", out)
+	if out["value"] != 2.0 {
+		t.Errorf("expected value 2, got %v", out["value"])
+	}
+	if out["label"] != "a" {
+		t.Errorf("expected the first non numeric value, got %v", out["label"])
+	}
+}
+
+func TestBucketOf(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	buckets := []time.Time{t0, t0.Add(time.Hour), t0.Add(2 * time.Hour)}
+
+	if b := BucketOf(buckets, t0.Add(90*time.Minute)); !b.Equal(buckets[1]) {
+		t.Errorf("expected bucket %v, got %v", buckets[1], b)
+	}
+	if b := BucketOf(buckets, t0.Add(2*time.Hour)); !b.Equal(buckets[2]) {
+		t.Errorf("expected bucket %v, got %v", buckets[2], b)
+	}
+	if b := BucketOf(buckets, t0.Add(-time.Minute)); !b.Equal(buckets[0]) {
+		t.Errorf("expected bucket %v, got %v", buckets[0], b)
+	}
+}
+
+func TestDownSample(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	buckets := []time.Time{t0, t0.Add(time.Hour)}
+	records := []SituationHistoryRecord{
+		{SituationID: 1, SituationInstanceID: 1, DateTime: t0.Add(10 * time.Minute),
+			ExpressionFacts: map[string]interface{}{"exp": 1.0},
+			Facts:           []FactHistoryRecord{{FactID: 1, FactName: "f1", Value: 10.0, DocCount: 10.0}}},
+		{SituationID: 1, SituationInstanceID: 1, DateTime: t0.Add(20 * time.Minute),
+			ExpressionFacts: map[string]interface{}{"exp": 3.0},
+			Facts:           []FactHistoryRecord{{FactID: 1, FactName: "f1", Value: 30.0, DocCount: 30.0}}},
+		{SituationID: 1, SituationInstanceID: 2, DateTime: t0.Add(20 * time.Minute),
+			ExpressionFacts: map[string]interface{}{"exp": 5.0}},
+		{SituationID: 1, SituationInstanceID: 1, DateTime: t0.Add(70 * time.Minute),
+			ExpressionFacts: map[string]interface{}{"exp": 7.0}},
+	}
+
+	result := DownSample(records, buckets, "sum")
+	if len(result) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(result))
+	}
+	if !result[0].DateTime.Equal(t0) || result[0].ExpressionFacts["exp"] != 4.0 {
+		t.Errorf("unexpected first record %+v", result[0])
+	}
+	if len(result[0].Facts) != 1 || result[0].Facts[0].Value != 40.0 || result[0].Facts[0].DocCount != 40.0 {
+		t.Errorf("unexpected first record facts %+v", result[0].Facts)
+	}
+	if result[1].SituationInstanceID != 2 || result[1].ExpressionFacts["exp"] != 5.0 {
+		t.Errorf("unexpected second record %+v", result[1])
+	}
+	if !result[2].DateTime.Equal(buckets[1]) || result[2].ExpressionFacts["exp"] != 7.0 {
+		t.Errorf("unexpected third record %+v", result[2])
+	}
+}
+
+func TestGroupByDateTime(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []SituationHistoryRecord{
+		{SituationID: 1, DateTime: t0.Add(time.Hour)},
+		{SituationID: 2, DateTime: t0},
+		{SituationID: 3, DateTime: t0.Add(time.Hour)},
+	}
+	result := GroupByDateTime(records, func(record SituationHistoryRecord) time.Time { return record.DateTime })
+	if len(result) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(result))
+	}
+	if !result[0].DateTime.Equal(t0) || len(result[0].Situations) != 1 {
+		t.Errorf("unexpected first group %+v", result[0])
+	}
+	if len(result[1].Situations) != 2 || result[1].Situations[0].SituationID != 1 || result[1].Situations[1].SituationID != 3 {
+		t.Errorf("unexpected second group %+v", result[1])
+	}
+}
+
+func TestQueryProject(t *testing.T) {
+	record := SituationHistoryRecord{
+		ExpressionFacts: map[string]interface{}{"a": 1.0, "b": 2.0},
+		MetaData:        map[string]interface{}{"m": "x"},
+		Parameters:      map[string]interface{}{"p": "y"},
+		Facts:           []FactHistoryRecord{{FactName: "f1"}, {FactName: "f2"}},
+	}
+	q := Query{Facts: []string{"f2"}, ExpressionFacts: "a", MetaData: false, Parameters: true}
+
+	projected := q.Project(record)
+	if len(projected.Facts) != 1 || projected.Facts[0].FactName != "f2" {
+		t.Errorf("unexpected facts %+v", projected.Facts)
+	}
+	if len(projected.ExpressionFacts) != 1 || projected.ExpressionFacts["a"] != 1.0 {
+		t.Errorf("unexpected expression facts %+v", projected.ExpressionFacts)
+	}
+	if projected.MetaData != nil {
+		t.Errorf("unexpected metadatas %+v", projected.MetaData)
+	}
+	if projected.Parameters["p"] != "y" {
+		t.Errorf("unexpected parameters %+v", projected.Parameters)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	c := Cursor{TS: time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC), ID: 42}
+	decoded, err := DecodeCursor(EncodeCursor(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.TS.Equal(c.TS) || decoded.ID != c.ID {
+		t.Errorf("expected %+v, got %+v", c, decoded)
+	}
+
+	if _, err := DecodeCursor("not a cursor"); err == nil {
+		t.Error("expected an error on an invalid cursor")
+	}
+	if c, err := DecodeCursor(""); err != nil || !c.TS.IsZero() {
+		t.Error("expected an empty cursor")
+	}
+}
+
+func TestQueryUnmarshal(t *testing.T) {
+	var q Query
+	err := json.Unmarshal([]byte(`{
+		"situations": [{"situationId": 1}, {"situationId": 2, "situationInstanceIds": [3, 4]}],
+		"start": "2026-01-01T00:00:00Z",
+		"range": "24h",
+		"facts": ["f1"],
+		"downSampling": {"granularity": "hour", "operation": "P95"},
+		"limit": 10
+	}`), &q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Situations) != 2 || len(q.Situations[1].SituationInstanceIDs) != 2 {
+		t.Errorf("unexpected situations %+v", q.Situations)
+	}
+	if !q.End.Equal(q.Start.Add(24 * time.Hour)) {
+		t.Errorf("unexpected end %v", q.End)
+	}
+	if p, ok := q.DownSampling.Percentile(); !ok || p != 95 || !q.DownSampling.IsAggregation() {
+		t.Errorf("unexpected downSampling %+v", q.DownSampling)
+	}
+	if q.PageLimit() != 10 {
+		t.Errorf("unexpected limit %d", q.PageLimit())
+	}
+
+	var legacy Query
+	err = json.Unmarshal([]byte(`{"situationId": 1, "situationInstanceId": 2, "time": "2026-01-01T00:00:00Z"}`), &legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(legacy.Situations) != 1 || legacy.Situations[0].SituationID != 1 || legacy.Situations[0].SituationInstanceIDs[0] != 2 {
+		t.Errorf("unexpected situations %+v", legacy.Situations)
+	}
+	if legacy.PageLimit() != DefaultQueryLimit {
+		t.Errorf("unexpected limit %d", legacy.PageLimit())
+	}
+
+	invalid := []string{
+		`{"start": "2026-01-01T00:00:00Z", "end": "2026-01-02T00:00:00Z"}`,
+		`{"situationId": 1, "start": "2026-01-02T00:00:00Z", "end": "2026-01-01T00:00:00Z"}`,
+		`{"situationId": 1, "start": "2026-01-01T00:00:00Z", "range": "1h", "downSampling": {"granularity": "1m", "operation": "p0"}}`,
+		`{"situationId": 1, "time": "2026-01-01T00:00:00Z", "downSampling": {"granularity": "day", "operation": "sum"}}`,
+		`{"situationId": 1, "time": "2026-01-01T00:00:00Z", "cursor": "invalid"}`,
+	}
+	for _, data := range invalid {
+		if err := json.Unmarshal([]byte(data), &Query{}); err == nil {
+			t.Errorf("expected an error on %s", data)
+		}
+	}
+}
+
+func TestDownSamplingTruncate(t *testing.T) {
+	ts := time.Date(2026, 5, 14, 10, 42, 0, 0, time.UTC) // thursday
+	origin := time.Date(2026, 5, 14, 0, 5, 0, 0, time.UTC)
+
+	cases := []struct {
+		d        DownSampling
+		expected time.Time
+	}{
+		{DownSampling{GranularitySpecial: "hour"}, time.Date(2026, 5, 14, 10, 0, 0, 0, time.UTC)},
+		{DownSampling{GranularitySpecial: "day"}, time.Date(2026, 5, 14, 0, 0, 0, 0, time.UTC)},
+		{DownSampling{GranularitySpecial: "week"}, time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC)},
+		{DownSampling{GranularitySpecial: "month"}, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{DownSampling{GranularitySpecial: "quarter"}, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{DownSampling{GranularitySpecial: "year"}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{DownSampling{Granularity: 4 * time.Hour}, time.Date(2026, 5, 14, 8, 5, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		if b := c.d.Truncate(ts, origin); !b.Equal(c.expected) {
+			t.Errorf("%+v: expected %v, got %v", c.d, c.expected, b)
+		}
+	}
+
+	d := DownSampling{Granularity: time.Hour}
+	if b := d.Truncate(origin.Add(-30*time.Minute), origin); !b.Equal(origin.Add(-time.Hour)) {
+		t.Errorf("expected %v, got %v", origin.Add(-time.Hour), b)
+	}
+	if next := (DownSampling{GranularitySpecial: "month"}).NextBucket(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !next.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected next bucket %v", next)
+	}
+}
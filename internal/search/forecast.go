@@ -0,0 +1,104 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Forecast methods
+const (
+	// ForecastLinear projects the values of the period with a least squares linear regression
+	ForecastLinear = "linear"
+	// ForecastSeasonal projects the values of the period with the increment of the baseline profile
+	ForecastSeasonal = "seasonal"
+)
+
+// DefaultForecastBandFactor is the number of standard errors of the linear forecast confidence band
+const DefaultForecastBandFactor = 2.0
+
+// ForecastOptions defines the projection of the fact values at the end of their period (within the day)
+type ForecastOptions struct {
+	Method     string   `json:"method" enums:"linear,seasonal"`
+	End        string   `json:"end" example:"18:00"`    // End of the period in the day (HH:MM), defaults to the end of the day
+	Timezone   string   `json:"timezone" example:"UTC"` // Timezone of the day, defaults to UTC
+	Baseline   string   `json:"baseline,omitempty"`     // Baseline used by the seasonal method, defaults to the first baseline of the fact
+	BandFactor float64  `json:"bandFactor,omitempty"`   // Width of the linear confidence band, in standard errors
+	Facts      []string `json:"facts,omitempty"`        // Forecasted facts, defaults to every fact
+}
+
+// Forecast is the projected value of a fact at the end of its period, with its confidence band
+type Forecast struct {
+	Method string    `json:"method"`
+	End    time.Time `json:"end"`
+	Value  float64   `json:"value"`
+	Lower  float64   `json:"lower"`
+	Upper  float64   `json:"upper"`
+}
+
+// Validate checks the forecast options
+func (o ForecastOptions) Validate() error {
+	if o.Method != "" && o.Method != ForecastLinear && o.Method != ForecastSeasonal {
+		return fmt.Errorf("unknown forecast method '%s'", o.Method)
+	}
+	if o.BandFactor < 0 {
+		return errors.New("the forecast bandFactor must be positive")
+	}
+	_, _, err := o.Period(time.Now())
+	return err
+}
+
+// ForecastMethod returns the forecast method, linear by default
+func (o ForecastOptions) ForecastMethod() string {
+	if o.Method == "" {
+		return ForecastLinear
+	}
+	return o.Method
+}
+
+// Factor returns the width of the linear confidence band
+func (o ForecastOptions) Factor() float64 {
+	if o.BandFactor == 0 {
+		return DefaultForecastBandFactor
+	}
+	return o.BandFactor
+}
+
+// Location returns the timezone of the forecast day
+func (o ForecastOptions) Location() (*time.Location, error) {
+	if o.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(o.Timezone)
+}
+
+// Period returns the start of the day of t and the end of the forecast period in this day
+func (o ForecastOptions) Period(t time.Time) (time.Time, time.Time, error) {
+	loc, err := o.Location()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	t = t.In(loc)
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	if o.End == "" || o.End == "24:00" {
+		return start, start.AddDate(0, 0, 1), nil
+	}
+	end, err := time.Parse("15:04", o.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid forecast end '%s', expecting HH:MM", o.End)
+	}
+	return start, time.Date(t.Year(), t.Month(), t.Day(), end.Hour(), end.Minute(), 0, 0, loc), nil
+}
+
+// Forecasted returns true if the fact is forecasted
+func (o ForecastOptions) Forecasted(factName string) bool {
+	if len(o.Facts) == 0 {
+		return true
+	}
+	for _, name := range o.Facts {
+		if name == factName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestForecastOptionsPeriod(t *testing.T) {
+	ts := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	start, end, err := ForecastOptions{End: "18:00"}.Period(ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !start.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected period %v %v", start, end)
+	}
+
+	_, end, err = ForecastOptions{}.Period(ts)
+	if err != nil || !end.Equal(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the end of the day, got %v (%v)", end, err)
+	}
+
+	if _, _, err = (ForecastOptions{End: "6pm"}).Period(ts); err == nil {
+		t.Error("expected an invalid end")
+	}
+}
+
+func TestForecastOptionsValidate(t *testing.T) {
+	if err := (ForecastOptions{Method: ForecastSeasonal, End: "18:00", Timezone: "UTC"}).Validate(); err != nil {
+		t.Error(err)
+	}
+	invalid := []ForecastOptions{
+		{Method: "arima"},
+		{BandFactor: -1},
+		{Timezone: "Not/AZone"},
+		{End: "25:00"},
+	}
+	for _, o := range invalid {
+		if err := o.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", o)
+		}
+	}
+}
+
+func TestForecastOptionsForecasted(t *testing.T) {
+	if !(ForecastOptions{}).Forecasted("parcels") {
+		t.Error("expected every fact to be forecasted")
+	}
+	o := ForecastOptions{Facts: []string{"parcels"}}
+	if !o.Forecasted("parcels") || o.Forecasted("trucks") {
+		t.Error("expected only the parcels to be forecasted")
+	}
+}
+
+func TestQueryForecastDownSampling(t *testing.T) {
+	var q Query
+	err := json.Unmarshal([]byte(`{"situationId":1,"start":"2026-03-02T00:00:00Z","end":"2026-03-03T00:00:00Z","forecast":{"end":"18:00"},"downSampling":{"granularity":"hour","operation":"avg"}}`), &q)
+	if err == nil {
+		t.Error("expected forecast to be incompatible with downSampling")
+	}
+	q = Query{}
+	err = json.Unmarshal([]byte(`{"situationId":1,"time":"2026-03-02T10:00:00Z","forecast":{"end":"18:00"}}`), &q)
+	if err != nil || q.Forecast == nil || q.Forecast.End != "18:00" {
+		t.Errorf("unexpected query %+v (%v)", q.Forecast, err)
+	}
+}
@@ -1,9 +1,11 @@
 package search
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,19 +14,56 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// DefaultQueryLimit is the page size used when the query has no limit
+	DefaultQueryLimit = 1000
+	// MaxQueryLimit is the maximum page size of a query
+	MaxQueryLimit = 10000
+)
+
 // Query is a struct used to represent a query
+//
+// Facts, ExpressionFacts, MetaData and Parameters are field projections: true (default) returns every field,
+// false returns none, and a string or a list of strings returns only the named fields.
+// Results are paginated with Limit and Cursor: without down-sampling a page contains at most Limit situation records,
+// with down-sampling a page contains at most Limit time buckets.
+// Forecast adds to each fact the projection of its value at the end of its period, it is not compatible with down-sampling.
 type Query struct {
-	SituationID         int64         `json:"situationId"`
-	SituationInstanceID int64         `json:"situationInstanceId"`
-	Time                time.Time     `json:"time"`
-	Start               time.Time     `json:"start"`
-	End                 time.Time     `json:"end"`
-	Range               time.Duration `json:"range"`
-	Facts               interface{}   `json:"facts"`
-	ExpressionFacts     interface{}   `json:"expressionFacts"`
-	MetaData            interface{}   `json:"metaDatas"`
-	Parameters          interface{}   `json:"parameters"`
-	DownSampling        DownSampling  `json:"downSampling"`
+	SituationID           int64               `json:"situationId"`
+	SituationInstanceID   int64               `json:"situationInstanceId"`
+	Situations            []SituationSelector `json:"situations"`
+	Time                  time.Time           `json:"time"`
+	Start                 time.Time           `json:"start"`
+	End                   time.Time           `json:"end"`
+	Range                 time.Duration       `json:"range"`
+	Facts                 interface{}         `json:"facts"`
+	ExpressionFacts       interface{}         `json:"expressionFacts"`
+	MetaData              interface{}         `json:"metaDatas"`
+	Parameters            interface{}         `json:"parameters"`
+	DownSampling          DownSampling        `json:"downSampling"`
+	IncludeCalendarStatus bool                `json:"includeCalendarStatus"`
+	Forecast              *ForecastOptions    `json:"forecast,omitempty"`
+	Limit                 int                 `json:"limit"`
+	Cursor                string              `json:"cursor"`
+}
+
+// SituationSelector selects a situation and optionally some of its template instances (all instances if empty)
+type SituationSelector struct {
+	SituationID          int64   `json:"situationId"`
+	SituationInstanceIDs []int64 `json:"situationInstanceIds"`
+}
+
+// QueryPage is a page of query results
+type QueryPage struct {
+	Results    QueryResult `json:"results"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
+
+// Cursor is the position of the last result of a page
+// ID is the last situation history record ID, and is not set when the query is down-sampled
+type Cursor struct {
+	TS time.Time `json:"ts"`
+	ID int64     `json:"id,omitempty"`
 }
 
 // DownSampling downscale definition
@@ -34,6 +73,67 @@ type DownSampling struct {
 	Operation          string        `json:"operation"`
 }
 
+// IsSet returns true if a down-sampling granularity is defined
+func (d DownSampling) IsSet() bool {
+	return d.Granularity != 0 || d.GranularitySpecial != ""
+}
+
+// IsAggregation returns true if the down-sampling operation aggregates the values of a time bucket,
+// instead of selecting the first or latest record
+func (d DownSampling) IsAggregation() bool {
+	return d.Operation != "" && d.Operation != "first" && d.Operation != "latest"
+}
+
+// Percentile returns the percentile computed by the down-sampling operation, if the operation is a percentile (ie. "p95")
+func (d DownSampling) Percentile() (float64, bool) {
+	return parsePercentile(d.Operation)
+}
+
+func parsePercentile(operation string) (float64, bool) {
+	if !strings.HasPrefix(operation, "p") {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(operation[1:], 64)
+	if err != nil || p <= 0 || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
+// PageLimit returns the page size of the query
+func (q Query) PageLimit() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultQueryLimit
+	case q.Limit > MaxQueryLimit:
+		return MaxQueryLimit
+	default:
+		return q.Limit
+	}
+}
+
+// EncodeCursor encodes a cursor in an opaque string
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes an opaque cursor string (an empty string returns an empty cursor)
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	if s == "" {
+		return c, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.TS.IsZero() {
+		return Cursor{}, errors.New("invalid cursor")
+	}
+	return c, nil
+}
+
 // ErrDatabase wraps a database error
 type ErrDatabase struct {
 	message string
@@ -95,19 +195,23 @@ func (d *DownSampling) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
-	if aux.Granularity == "year" || aux.Granularity == "quarter" || aux.Granularity == "month" || aux.Granularity == "day" ||
-		aux.Granularity == "hour" || aux.Granularity == "minute" || aux.Granularity == "second" {
+	if aux.Granularity == "year" || aux.Granularity == "quarter" || aux.Granularity == "month" || aux.Granularity == "week" ||
+		aux.Granularity == "day" || aux.Granularity == "hour" || aux.Granularity == "minute" || aux.Granularity == "second" {
 		d.GranularitySpecial = aux.Granularity
 	} else {
 		g, err := time.ParseDuration(strings.ToLower(aux.Granularity))
 		if err != nil {
 			return err
 		}
+		if g <= 0 {
+			return errors.New("the downSampling granularity must be positive")
+		}
 		d.Granularity = g
 	}
 
 	d.Operation = strings.ToLower(d.Operation)
-	if d.Operation != "first" && d.Operation != "latest" && d.Operation != "sum" && d.Operation != "max" && d.Operation != "min" && d.Operation != "avg" {
+	if _, ok := parsePercentile(d.Operation); !ok &&
+		d.Operation != "first" && d.Operation != "latest" && d.Operation != "sum" && d.Operation != "max" && d.Operation != "min" && d.Operation != "avg" {
 		return errors.New("unknown downSampling operation")
 	}
 
@@ -136,7 +240,7 @@ func (q *Query) UnmarshalJSON(data []byte) error {
 
 	timeIsZero, startIsZero, endIsZero := q.Time.IsZero(), q.Start.IsZero(), q.End.IsZero()
 
-	if !timeIsZero && q.DownSampling.Granularity != 0 {
+	if !timeIsZero && q.DownSampling.IsSet() {
 		return errors.New("the 'time' parameter is not compatible with downSampling")
 	}
 
@@ -162,14 +266,45 @@ func (q *Query) UnmarshalJSON(data []byte) error {
 		q.End = q.Start.Add(q.Range)
 	}
 
-	if startIsZero && q.Range == 0 && q.End.After(q.Start) {
+	if timeIsZero && q.Start.After(q.End) {
 		return errors.New("the 'end' dateTime should be after the start dateTime")
 	}
 
+	if len(q.Situations) == 0 && q.SituationID != 0 {
+		selector := SituationSelector{SituationID: q.SituationID}
+		if q.SituationInstanceID != 0 {
+			selector.SituationInstanceIDs = []int64{q.SituationInstanceID}
+		}
+		q.Situations = []SituationSelector{selector}
+	}
+	if len(q.Situations) == 0 {
+		return errors.New("missing 'situations' parameter")
+	}
+
+	if q.Forecast != nil {
+		if q.DownSampling.IsSet() {
+			return errors.New("the 'forecast' parameter is not compatible with downSampling")
+		}
+		if err := q.Forecast.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if q.Limit < 0 {
+		return errors.New("the 'limit' parameter should be positive")
+	}
+	if _, err := DecodeCursor(q.Cursor); err != nil {
+		return err
+	}
+
 	err := validateSourceFilterParameter(&q.Facts, "facts")
 	if err != nil {
 		return err
 	}
+	err = validateSourceFilterParameter(&q.ExpressionFacts, "expressionFacts")
+	if err != nil {
+		return err
+	}
 	err = validateSourceFilterParameter(&q.MetaData, "metaDatas")
 	if err != nil {
 		return err
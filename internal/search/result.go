@@ -48,6 +48,7 @@ type FactHistoryRecord struct {
 	DocCount  interface{}                       `json:"docCount,omitempty"`
 	Buckets   map[string][]*reader.Item         `json:"buckets,omitempty"`
 	Baselines map[string]baseline.BaselineValue `json:"baselines,omitempty"`
+	Forecast  *Forecast                         `json:"forecast,omitempty"`
 }
 
 type SituationHistoryCalendarRecord struct {
@@ -321,5 +321,81 @@ func aggregate(dataList []map[string]interface{}, operation string, out map[stri
 		for key, value := range sum {
 			out[key] = value / count[key]
 		}
+	default:
+		p, ok := parsePercentile(operation)
+		if !ok {
+			return
+		}
+		values := make(map[string][]float64, 0)
+		for _, data := range dataList {
+			for key, value := range data {
+				if val, ok := value.(float64); ok {
+					values[key] = append(values[key], val)
+				} else if _, ok := out[key]; !ok {
+					out[key] = value
+				}
+			}
+		}
+		for key, vals := range values {
+			out[key] = Percentile(vals, p)
+		}
+	}
+}
+
+// Project keeps only the fields of the record selected by the query projections
+func (q Query) Project(record SituationHistoryRecord) SituationHistoryRecord {
+	record.ExpressionFacts = projectKeys(record.ExpressionFacts, q.ExpressionFacts)
+	record.MetaData = projectKeys(record.MetaData, q.MetaData)
+	record.Parameters = projectKeys(record.Parameters, q.Parameters)
+
+	keys, all := projectionKeys(q.Facts)
+	if !all {
+		facts := make([]FactHistoryRecord, 0)
+		for _, fact := range record.Facts {
+			if _, ok := keys[fact.FactName]; ok {
+				facts = append(facts, fact)
+			}
+		}
+		record.Facts = facts
+	}
+	if len(record.Facts) == 0 {
+		record.Facts = nil
+	}
+	return record
+}
+
+// projectionKeys returns the keys selected by a projection source (bool, string or []string)
+// all is true when every key is selected
+func projectionKeys(source interface{}) (keys map[string]struct{}, all bool) {
+	keys = make(map[string]struct{})
+	switch value := source.(type) {
+	case nil:
+		return keys, true
+	case bool:
+		return keys, value
+	case string:
+		keys[value] = struct{}{}
+	case []string:
+		for _, key := range value {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys, false
+}
+
+func projectKeys(values map[string]interface{}, source interface{}) map[string]interface{} {
+	keys, all := projectionKeys(source)
+	if all {
+		return values
+	}
+	out := make(map[string]interface{})
+	for key, value := range values {
+		if _, ok := keys[key]; ok {
+			out[key] = value
+		}
+	}
+	if len(out) == 0 {
+		return nil
 	}
+	return out
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/events"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
 	"go.uber.org/zap"
@@ -55,9 +56,19 @@ func (task CloseAllIssuesTask) Perform(key string, context ContextData) error {
 		states = append(states, model.ToIssueState(s))
 	}
 
-	err := issues.R().ChangeState(key, states, model.ClosedDiscard)
+	closed, err := issues.R().ChangeState(key, states, model.ClosedDiscard)
 	if err != nil {
 		return err
 	}
+	publishClosedIssues(closed, model.ClosedDiscard)
 	return nil
 }
+
+// publishClosedIssues publishes the closed events of the issues closed by a task
+func publishClosedIssues(closed []model.Issue, state model.IssueState) {
+	for _, issue := range closed {
+		event := model.NewIssueEvent(model.IssueEventClosed, issue, "")
+		event.State = state.String()
+		events.Publish(event)
+	}
+}
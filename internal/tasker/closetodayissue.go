@@ -60,10 +60,11 @@ func (task CloseTodayIssuesTask) Perform(key string, context ContextData) error
 	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	to := from.Add(24 * time.Hour)
 
-	err = issues.R().ChangeStateBetweenDates(key, []model.IssueState{model.Open}, model.ClosedDiscard, from, to)
+	closed, err := issues.R().ChangeStateBetweenDates(key, []model.IssueState{model.Open}, model.ClosedDiscard, from, to)
 
 	if err != nil {
 		return err
 	}
+	publishClosedIssues(closed, model.ClosedDiscard)
 	return nil
 }
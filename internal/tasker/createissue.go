@@ -7,6 +7,7 @@ import (
 
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-sdk/v5/ruleeng"
 	"go.uber.org/zap"
 )
 
@@ -19,6 +20,46 @@ type CreateIssueTask struct {
 	IsNotification bool   `json:"isNotification"`
 }
 
+// IssuePreview is the issue a create-issue action would create, without creating it
+type IssuePreview struct {
+	Key                 string    `json:"key"`
+	Name                string    `json:"name"`
+	Level               string    `json:"level"`
+	SituationID         int64     `json:"situationId"`
+	SituationInstanceID int64     `json:"situationInstanceId"`
+	SituationTS         time.Time `json:"situationTs"`
+	ExpirationTS        time.Time `json:"expirationTs"`
+	RuleID              int64     `json:"ruleId"`
+	RuleVersion         int64     `json:"ruleVersion"`
+	CaseName            string    `json:"caseName"`
+}
+
+// PreviewCreateIssue returns the issue a create-issue action would create in a batch context
+func PreviewCreateIssue(action ruleeng.Action, batchContext map[string]interface{}) (IssuePreview, error) {
+	task, err := buildCreateIssueTask(action.GetParameters(), nil)
+	if err != nil {
+		return IssuePreview{}, err
+	}
+	timeoutDuration, err := time.ParseDuration(task.Timeout)
+	if err != nil {
+		return IssuePreview{}, err
+	}
+
+	taskContext := BuildContextData(action.GetMetaData(), batchContext)
+	return IssuePreview{
+		Key:                 buildTaskKey(taskContext, task),
+		Name:                task.Name,
+		Level:               model.ToIssueLevel(task.Level).String(),
+		SituationID:         taskContext.SituationID,
+		SituationInstanceID: taskContext.TemplateInstanceID,
+		SituationTS:         taskContext.TS,
+		ExpirationTS:        taskContext.TS.Add(timeoutDuration).UTC(),
+		RuleID:              taskContext.RuleID,
+		RuleVersion:         taskContext.RuleVersion,
+		CaseName:            taskContext.CaseName,
+	}, nil
+}
+
 func buildCreateIssueTask(parameters map[string]interface{}, boostInfo *model.JobBoostInfo) (CreateIssueTask, error) {
 	task := CreateIssueTask{}
 
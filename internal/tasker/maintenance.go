@@ -0,0 +1,72 @@
+package tasker
+
+import (
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/maintenance"
+	"github.com/myrteametrics/myrtea-sdk/v5/ruleeng"
+	"go.uber.org/zap"
+)
+
+// maintenanceChecker suppresses the actions of a batch covered by a maintenance window
+// The window covering the batch situation is resolved once, on the first suppressible action
+type maintenanceChecker struct {
+	resolved bool
+	found    bool
+	window   maintenance.Window
+}
+
+// isSuppressible returns true if an action is suppressed during the maintenance windows
+func isSuppressible(actionName string) bool {
+	switch actionName {
+	case ActionCreateIssue, ActionNotify, ActionSituationReporting:
+		return true
+	}
+	return false
+}
+
+// suppress returns true if an action is covered by a maintenance window, and records it as suppressed
+// The action is performed if the maintenance windows cannot be checked
+func (c *maintenanceChecker) suppress(action ruleeng.Action, taskContext ContextData) bool {
+	if !isSuppressible(action.GetName()) || maintenance.R() == nil {
+		return false
+	}
+
+	ts := taskContext.TS
+	if ts.IsZero() {
+		ts = time.Now().UTC()
+	}
+
+	if !c.resolved {
+		window, found, err := maintenance.FindWindow(taskContext.SituationID, taskContext.TemplateInstanceID, ts)
+		if err != nil {
+			zap.L().Error("Cannot check the maintenance windows, the action is performed", zap.Int64("situationID", taskContext.SituationID),
+				zap.Int64("templateInstanceID", taskContext.TemplateInstanceID), zap.Error(err))
+			return false
+		}
+		c.resolved, c.found, c.window = true, found, window
+	}
+	if !c.found {
+		return false
+	}
+
+	suppressed := maintenance.SuppressedAction{
+		WindowID:           c.window.ID,
+		TS:                 ts,
+		Action:             action.GetName(),
+		SituationID:        taskContext.SituationID,
+		TemplateInstanceID: taskContext.TemplateInstanceID,
+		SituationHistoryID: taskContext.SituationHistoryID,
+		RuleID:             taskContext.RuleID,
+		RuleVersion:        taskContext.RuleVersion,
+		CaseName:           taskContext.CaseName,
+		Parameters:         action.GetParameters(),
+	}
+	if _, err := maintenance.R().CreateSuppressedAction(suppressed); err != nil {
+		zap.L().Error("Cannot record the action suppressed by a maintenance window", zap.Int64("windowID", c.window.ID),
+			zap.String("action", action.GetName()), zap.Error(err))
+	}
+	zap.L().Debug("Action suppressed by a maintenance window", zap.Int64("windowID", c.window.ID), zap.String("action", action.GetName()),
+		zap.Int64("situationID", taskContext.SituationID), zap.Int64("templateInstanceID", taskContext.TemplateInstanceID))
+	return true
+}
@@ -17,6 +17,7 @@ type Task interface {
 // ApplyTasks applies the task of an evaluated situation
 func ApplyTasks(batch TaskBatch) (err error) {
 
+	checker := &maintenanceChecker{}
 	for _, action := range batch.Agenda {
 
 		switch action.GetName() {
@@ -29,6 +30,9 @@ func ApplyTasks(batch TaskBatch) (err error) {
 			}
 
 			taskContext := BuildContextData(action.GetMetaData(), batch.Context)
+			if checker.suppress(action, taskContext) {
+				continue
+			}
 			err = task.Perform(buildTaskKey(taskContext, task), taskContext)
 			if err != nil {
 				zap.L().Warn("Error while performing task CreateIssueTask", zap.Error(err))
@@ -68,6 +72,9 @@ func ApplyTasks(batch TaskBatch) (err error) {
 			}
 
 			taskContext := BuildContextData(action.GetMetaData(), batch.Context)
+			if checker.suppress(action, taskContext) {
+				continue
+			}
 			err = task.Perform(buildTaskKey(taskContext, task), taskContext)
 			if err != nil {
 				zap.L().Warn("Error while performing task NotifyTask", zap.Error(err))
@@ -81,6 +88,9 @@ func ApplyTasks(batch TaskBatch) (err error) {
 			}
 
 			taskContext := BuildContextData(action.GetMetaData(), batch.Context)
+			if checker.suppress(action, taskContext) {
+				continue
+			}
 			err = task.Perform(buildTaskKey(taskContext, task), taskContext)
 			if err != nil {
 				zap.L().Warn("Error while performing task SituationReportingTask", zap.Error(err))
@@ -92,6 +92,33 @@ const (
 		 last_modified timestamptz not null
 	);`
 
+	//FactParameterDefinitionDropTableV1 SQL statement for table drop
+	FactParameterDefinitionDropTableV1 string = `DROP TABLE IF EXISTS fact_parameter_definition_v1;`
+	// FactParameterDefinitionTableV1 SQL statement for the fact parameter definition table
+	FactParameterDefinitionTableV1 string = `create table fact_parameter_definition_v1 (
+		fact_id integer not null references fact_definition_v1 (id) on delete cascade primary key,
+		definitions jsonb not null default '[]'::jsonb,
+		last_modified timestamptz not null default now()
+	);`
+
+	//FactCacheConfigDropTableV1 SQL statement for table drop
+	FactCacheConfigDropTableV1 string = `DROP TABLE IF EXISTS fact_cache_config_v1;`
+	// FactCacheConfigTableV1 SQL statement for the fact result cache configuration table
+	FactCacheConfigTableV1 string = `create table fact_cache_config_v1 (
+		fact_id integer not null references fact_definition_v1 (id) on delete cascade primary key,
+		ttl_seconds bigint not null,
+		last_modified timestamptz not null default now()
+	);`
+
+	//FactSQLDefinitionDropTableV1 SQL statement for table drop
+	FactSQLDefinitionDropTableV1 string = `DROP TABLE IF EXISTS fact_sql_definition_v1;`
+	// FactSQLDefinitionTableV1 SQL statement for the fact SQL definition table
+	FactSQLDefinitionTableV1 string = `create table fact_sql_definition_v1 (
+		fact_id integer not null references fact_definition_v1 (id) on delete cascade primary key,
+		definition jsonb not null,
+		last_modified timestamptz not null default now()
+	);`
+
 	// SituationDefinitionDropTableV1 SQL statement for table drop
 	SituationDefinitionDropTableV1 string = `DROP TABLE IF EXISTS situation_definition_v1;`
 	// SituationDefinitionTableV1 SQL statement for the situation definition table
@@ -172,7 +199,12 @@ const (
 		assigned_to varchar(100),
 		closed_at timestamptz,
 		closed_by varchar(100),
-		comment text
+		comment text,
+		acknowledged_at timestamptz,
+		incident_id integer,
+		flap_count integer not null default 0,
+		last_flap_at timestamptz,
+		previous_issue_id integer
 	);`
 
 	// RefRootCauseDropTableV1 SQL statement for table drop
@@ -184,6 +216,7 @@ const (
 		description varchar(500) not null,
 		situation_id integer REFERENCES situation_definition_v1 (id),
 		rule_id integer REFERENCES rules_v1 (id),
+		catalog_id integer,
 		CONSTRAINT unq_name_situationid UNIQUE(name,situation_id)
 	);`
 
@@ -195,6 +228,7 @@ const (
 		name varchar(100) not null,
 		description varchar(500) not null,
 		rootcause_id integer REFERENCES ref_rootcause_v1 (id),
+		catalog_id integer,
 		CONSTRAINT unq_name_rootcauseid UNIQUE(name,rootcause_id)
 	);`
 
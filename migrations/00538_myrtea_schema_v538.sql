@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Typed parameters declared on a fact definition (name, type, default, allowed values)
+CREATE TABLE fact_parameter_definition_v1
+(
+    fact_id       INTEGER     NOT NULL REFERENCES fact_definition_v1 (id) ON DELETE CASCADE PRIMARY KEY,
+    definitions   JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    last_modified TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS fact_parameter_definition_v1;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Dedicated result cache TTL of a fact (0 disables the cache for the fact)
+CREATE TABLE fact_cache_config_v1
+(
+    fact_id       INTEGER     NOT NULL REFERENCES fact_definition_v1 (id) ON DELETE CASCADE PRIMARY KEY,
+    ttl_seconds   BIGINT      NOT NULL CHECK (ttl_seconds >= 0),
+    last_modified TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS fact_cache_config_v1;
+
+-- +goose StatementEnd
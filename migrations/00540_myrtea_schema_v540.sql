@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Read-only SQL query executed instead of an elasticsearch query for SQL facts
+CREATE TABLE fact_sql_definition_v1
+(
+    fact_id       INTEGER     NOT NULL REFERENCES fact_definition_v1 (id) ON DELETE CASCADE PRIMARY KEY,
+    definition    JSONB       NOT NULL,
+    last_modified TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS fact_sql_definition_v1;
+
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Hourly, daily and monthly aggregates of the fact values ('value' and 'doc_count') of the history
+CREATE TABLE IF NOT EXISTS fact_history_rollup_v1
+(
+    granularity           VARCHAR(10)      NOT NULL,
+    bucket                TIMESTAMPTZ      NOT NULL,
+    fact_id               INTEGER          NOT NULL,
+    situation_id          INTEGER          NOT NULL,
+    situation_instance_id INTEGER          NOT NULL,
+    value_key             VARCHAR(20)      NOT NULL,
+    count                 BIGINT           NOT NULL,
+    min                   DOUBLE PRECISION NOT NULL,
+    max                   DOUBLE PRECISION NOT NULL,
+    sum                   DOUBLE PRECISION NOT NULL,
+    first                 DOUBLE PRECISION NOT NULL,
+    last                  DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (granularity, bucket, situation_id, situation_instance_id, fact_id, value_key)
+);
+
+-- Hourly, daily and monthly aggregates of the numeric expression facts of the history
+CREATE TABLE IF NOT EXISTS expression_fact_history_rollup_v1
+(
+    granularity           VARCHAR(10)      NOT NULL,
+    bucket                TIMESTAMPTZ      NOT NULL,
+    situation_id          INTEGER          NOT NULL,
+    situation_instance_id INTEGER          NOT NULL,
+    name                  VARCHAR(100)     NOT NULL,
+    count                 BIGINT           NOT NULL,
+    min                   DOUBLE PRECISION NOT NULL,
+    max                   DOUBLE PRECISION NOT NULL,
+    sum                   DOUBLE PRECISION NOT NULL,
+    first                 DOUBLE PRECISION NOT NULL,
+    last                  DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (granularity, bucket, situation_id, situation_instance_id, name)
+);
+
+-- Range [rolled_up_from, rolled_up_to) already materialized for each rollup granularity
+CREATE TABLE IF NOT EXISTS history_rollup_state_v1
+(
+    granularity    VARCHAR(10) NOT NULL PRIMARY KEY,
+    rolled_up_from TIMESTAMPTZ NOT NULL,
+    rolled_up_to   TIMESTAMPTZ NOT NULL,
+    last_modified  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS history_rollup_state_v1;
+DROP TABLE IF EXISTS expression_fact_history_rollup_v1;
+DROP TABLE IF EXISTS fact_history_rollup_v1;
+
+-- +goose StatementEnd
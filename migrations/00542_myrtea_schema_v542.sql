@@ -0,0 +1,161 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- The history tables are partitioned by day on their timestamp (situation_fact_history_v5 uses the timestamp of its situation).
+-- The existing tables are kept as a single 'legacy' partition covering everything up to tomorrow, so the migration doesn't copy
+-- any row. Daily partitions are then created ahead by the 'partition' scheduler job, and the retention drops whole partitions.
+-- The expired rows of the legacy partition are deleted one by one by the purge, until the whole partition expires.
+-- Rows inserted outside of any daily partition land in the default partition, and are moved when their partition is created.
+
+-- The foreign keys on the history ids are dropped and not replaced: a foreign key can't reference a partitioned table
+-- by id only (the partition key must be part of the unique constraint), and would prevent dropping expired partitions.
+-- The purge keeps the references consistent instead: the issues are deleted before the history they were detected on,
+-- and the situation and fact history are only deleted once no link references them anymore.
+ALTER TABLE issues_v1 DROP CONSTRAINT IF EXISTS issues_v1_situation_history_id_fkey;
+ALTER TABLE situation_fact_history_v5 DROP CONSTRAINT IF EXISTS situation_fact_history_v5_situation_history_id_fkey;
+ALTER TABLE situation_fact_history_v5 DROP CONSTRAINT IF EXISTS situation_fact_history_v5_fact_history_id_fkey;
+
+-- Legacy links are all stored in the legacy partition, their timestamp is only used for partition routing
+ALTER TABLE situation_fact_history_v5 ADD COLUMN ts timestamptz NOT NULL DEFAULT '-infinity';
+ALTER TABLE situation_fact_history_v5 ALTER COLUMN ts DROP DEFAULT;
+
+ALTER TABLE fact_history_v5 RENAME TO fact_history_v5_legacy;
+ALTER INDEX idx_fact_history_v5_combo RENAME TO idx_fact_history_v5_legacy_combo;
+ALTER TABLE situation_history_v5 RENAME TO situation_history_v5_legacy;
+ALTER INDEX idx_situation_history_v5_combo RENAME TO idx_situation_history_v5_legacy_combo;
+ALTER TABLE situation_fact_history_v5 RENAME TO situation_fact_history_v5_legacy;
+ALTER INDEX idx_situation_fact_history_v5_situation_history_id RENAME TO idx_situation_fact_history_v5_legacy_situation_history_id;
+
+CREATE TABLE fact_history_v5
+(
+    id                    integer     NOT NULL DEFAULT nextval('fact_history_v5_id_seq'),
+    fact_id               integer,
+    situation_id          integer,
+    situation_instance_id integer,
+    ts                    timestamptz NOT NULL,
+    result                jsonb,
+    PRIMARY KEY (id, ts)
+) PARTITION BY RANGE (ts);
+ALTER SEQUENCE fact_history_v5_id_seq OWNED BY fact_history_v5.id;
+
+CREATE TABLE situation_history_v5
+(
+    id                    integer     NOT NULL DEFAULT nextval('situation_history_v5_id_seq'),
+    situation_id          integer,
+    situation_instance_id integer,
+    ts                    timestamptz NOT NULL,
+    parameters            json,
+    expression_facts      jsonb,
+    metadatas             json,
+    PRIMARY KEY (id, ts)
+) PARTITION BY RANGE (ts);
+ALTER SEQUENCE situation_history_v5_id_seq OWNED BY situation_history_v5.id;
+
+CREATE TABLE situation_fact_history_v5
+(
+    situation_history_id integer     NOT NULL,
+    fact_history_id      integer     NOT NULL,
+    fact_id              integer,
+    ts                   timestamptz NOT NULL,
+    PRIMARY KEY (situation_history_id, fact_history_id, ts)
+) PARTITION BY RANGE (ts);
+
+-- ATTACH scans the whole table under an exclusive lock to check the partition bound, unless a valid CHECK constraint
+-- already implies it. The constraint is validated first (which only blocks schema changes), and dropped once attached.
+DO
+$$
+    DECLARE
+        bound timestamptz := date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' + interval '1 day';
+        t     text;
+    BEGIN
+        FOREACH t IN ARRAY ARRAY ['fact_history_v5', 'situation_history_v5', 'situation_fact_history_v5']
+            LOOP
+                EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I CHECK (ts IS NOT NULL AND ts < %L) NOT VALID',
+                               t || '_legacy', t || '_legacy_bound', bound);
+                EXECUTE format('ALTER TABLE %I VALIDATE CONSTRAINT %I', t || '_legacy', t || '_legacy_bound');
+                EXECUTE format('ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (MINVALUE) TO (%L)',
+                               t, t || '_legacy', bound);
+                EXECUTE format('ALTER TABLE %I DROP CONSTRAINT %I', t || '_legacy', t || '_legacy_bound');
+            END LOOP;
+    END
+$$;
+
+CREATE TABLE fact_history_v5_default PARTITION OF fact_history_v5 DEFAULT;
+CREATE TABLE situation_history_v5_default PARTITION OF situation_history_v5 DEFAULT;
+CREATE TABLE situation_fact_history_v5_default PARTITION OF situation_fact_history_v5 DEFAULT;
+
+-- The identical legacy indexes are attached to the partitioned indexes instead of being rebuilt
+-- The fact_history_id index speeds up the orphans deletion of the history compaction
+CREATE INDEX idx_fact_history_v5_combo ON fact_history_v5 (fact_id, ts DESC) include (id);
+CREATE INDEX idx_situation_history_v5_combo ON situation_history_v5 (situation_id, situation_instance_id, ts DESC) include (id);
+CREATE INDEX idx_situation_fact_history_v5_situation_history_id ON situation_fact_history_v5 (situation_history_id);
+CREATE INDEX idx_situation_fact_history_v5_fact_history_id ON situation_fact_history_v5 (fact_history_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE fact_history_v5 RENAME TO fact_history_v5_partitioned;
+ALTER INDEX idx_fact_history_v5_combo RENAME TO idx_fact_history_v5_partitioned_combo;
+ALTER TABLE situation_history_v5 RENAME TO situation_history_v5_partitioned;
+ALTER INDEX idx_situation_history_v5_combo RENAME TO idx_situation_history_v5_partitioned_combo;
+ALTER TABLE situation_fact_history_v5 RENAME TO situation_fact_history_v5_partitioned;
+ALTER INDEX idx_situation_fact_history_v5_situation_history_id RENAME TO idx_situation_fact_history_v5_partitioned_situation_history_id;
+ALTER INDEX idx_situation_fact_history_v5_fact_history_id RENAME TO idx_situation_fact_history_v5_partitioned_fact_history_id;
+
+CREATE TABLE fact_history_v5
+(
+    id                    integer PRIMARY KEY DEFAULT nextval('fact_history_v5_id_seq'),
+    fact_id               integer,
+    situation_id          integer,
+    situation_instance_id integer,
+    ts                    timestamptz NOT NULL,
+    result                jsonb
+);
+INSERT INTO fact_history_v5 (id, fact_id, situation_id, situation_instance_id, ts, result)
+SELECT id, fact_id, situation_id, situation_instance_id, ts, result
+FROM fact_history_v5_partitioned;
+ALTER SEQUENCE fact_history_v5_id_seq OWNED BY fact_history_v5.id;
+
+CREATE TABLE situation_history_v5
+(
+    id                    integer PRIMARY KEY DEFAULT nextval('situation_history_v5_id_seq'),
+    situation_id          integer,
+    situation_instance_id integer,
+    ts                    timestamptz NOT NULL,
+    parameters            json,
+    expression_facts      jsonb,
+    metadatas             json
+);
+INSERT INTO situation_history_v5 (id, situation_id, situation_instance_id, ts, parameters, expression_facts, metadatas)
+SELECT id, situation_id, situation_instance_id, ts, parameters, expression_facts, metadatas
+FROM situation_history_v5_partitioned;
+ALTER SEQUENCE situation_history_v5_id_seq OWNED BY situation_history_v5.id;
+
+CREATE TABLE situation_fact_history_v5
+(
+    situation_history_id integer REFERENCES situation_history_v5 (id),
+    fact_history_id      integer REFERENCES fact_history_v5 (id),
+    fact_id              integer,
+    PRIMARY KEY (situation_history_id, fact_history_id)
+);
+INSERT INTO situation_fact_history_v5 (situation_history_id, fact_history_id, fact_id)
+SELECT sf.situation_history_id, sf.fact_history_id, sf.fact_id
+FROM situation_fact_history_v5_partitioned sf
+WHERE EXISTS (SELECT 1 FROM situation_history_v5 s WHERE s.id = sf.situation_history_id)
+  AND EXISTS (SELECT 1 FROM fact_history_v5 f WHERE f.id = sf.fact_history_id)
+ON CONFLICT DO NOTHING;
+
+DROP TABLE situation_fact_history_v5_partitioned;
+DROP TABLE situation_history_v5_partitioned;
+DROP TABLE fact_history_v5_partitioned;
+
+CREATE INDEX IF NOT EXISTS idx_fact_history_v5_combo ON fact_history_v5 (fact_id, ts DESC) include (id);
+CREATE INDEX IF NOT EXISTS idx_situation_fact_history_v5_situation_history_id ON situation_fact_history_v5 (situation_history_id);
+CREATE INDEX IF NOT EXISTS idx_situation_history_v5_combo ON situation_history_v5 (situation_id, situation_instance_id, ts DESC) include (id);
+
+ALTER TABLE issues_v1
+    ADD CONSTRAINT issues_v1_situation_history_id_fkey FOREIGN KEY (situation_history_id) REFERENCES situation_history_v5 (id) NOT VALID;
+
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Rehydrated history archives, read only by the archive search (never by the rules or the issues)
+CREATE TABLE IF NOT EXISTS situation_history_archive_v1
+(
+    id                    integer PRIMARY KEY,
+    situation_id          integer,
+    situation_instance_id integer,
+    ts                    timestamptz NOT NULL,
+    parameters            json,
+    expression_facts      jsonb,
+    metadatas             json
+);
+CREATE INDEX IF NOT EXISTS idx_situation_history_archive_v1_combo ON situation_history_archive_v1 (situation_id, situation_instance_id, ts DESC) include (id);
+
+CREATE TABLE IF NOT EXISTS fact_history_archive_v1
+(
+    id                    integer PRIMARY KEY,
+    fact_id               integer,
+    situation_id          integer,
+    situation_instance_id integer,
+    ts                    timestamptz NOT NULL,
+    result                jsonb
+);
+CREATE INDEX IF NOT EXISTS idx_fact_history_archive_v1_ts ON fact_history_archive_v1 (ts);
+
+CREATE TABLE IF NOT EXISTS situation_fact_history_archive_v1
+(
+    situation_history_id integer     NOT NULL,
+    fact_history_id      integer     NOT NULL,
+    fact_id              integer,
+    ts                   timestamptz NOT NULL,
+    PRIMARY KEY (situation_history_id, fact_history_id)
+);
+CREATE INDEX IF NOT EXISTS idx_situation_fact_history_archive_v1_ts ON situation_fact_history_archive_v1 (ts);
+
+-- Days of archives currently loaded in the archive tables
+CREATE TABLE IF NOT EXISTS history_archive_rehydration_v1
+(
+    day           date PRIMARY KEY,
+    rehydrated_at timestamptz NOT NULL
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS history_archive_rehydration_v1;
+DROP TABLE IF EXISTS situation_fact_history_archive_v1;
+DROP TABLE IF EXISTS fact_history_archive_v1;
+DROP TABLE IF EXISTS situation_history_archive_v1;
+
+-- +goose StatementEnd
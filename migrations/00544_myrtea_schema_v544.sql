@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Definitions of the built-in seasonal baselines, computed from the fact history
+CREATE TABLE IF NOT EXISTS baseline_v1
+(
+    id            SERIAL PRIMARY KEY,
+    name          VARCHAR(100)     NOT NULL UNIQUE,
+    fact_id       INTEGER          NOT NULL REFERENCES fact_definition_v1 (id) ON DELETE CASCADE,
+    situation_id  INTEGER REFERENCES situation_definition_v1 (id) ON DELETE CASCADE,
+    lookback_days INTEGER          NOT NULL,
+    band_factor   DOUBLE PRECISION NOT NULL,
+    timezone      VARCHAR(100)     NOT NULL DEFAULT '',
+    last_built_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_baseline_v1_fact_id ON baseline_v1 (fact_id);
+
+-- Seasonal values of the baselines, by situation instance, day of week (0 is sunday) and hour of day
+CREATE TABLE IF NOT EXISTS baseline_values_v1
+(
+    baseline_id           INTEGER          NOT NULL REFERENCES baseline_v1 (id) ON DELETE CASCADE,
+    situation_id          INTEGER          NOT NULL,
+    situation_instance_id INTEGER          NOT NULL,
+    day_of_week           SMALLINT         NOT NULL,
+    hour                  SMALLINT         NOT NULL,
+    count                 INTEGER          NOT NULL,
+    avg                   DOUBLE PRECISION NOT NULL,
+    std                   DOUBLE PRECISION NOT NULL,
+    median                DOUBLE PRECISION NOT NULL,
+    lower                 DOUBLE PRECISION NOT NULL,
+    upper                 DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (baseline_id, situation_id, situation_instance_id, day_of_week, hour)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS baseline_values_v1;
+DROP TABLE IF EXISTS baseline_v1;
+
+-- +goose StatementEnd
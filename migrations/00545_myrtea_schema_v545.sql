@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- First assignment of an issue (its assigned_at is reset by reassignments)
+ALTER TABLE issues_v1 ADD COLUMN IF NOT EXISTS acknowledged_at timestamptz;
+UPDATE issues_v1 SET acknowledged_at = assigned_at WHERE acknowledged_at IS NULL AND assigned_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_issues_v1_assigned_to ON issues_v1 (assigned_to);
+
+-- Audit trail of the issues (assignments, reassignments and unassignments)
+CREATE TABLE IF NOT EXISTS issue_audit_v1
+(
+    id             SERIAL PRIMARY KEY,
+    issue_id       INTEGER      NOT NULL REFERENCES issues_v1 (id) ON DELETE CASCADE,
+    ts             TIMESTAMPTZ  NOT NULL,
+    user_login     VARCHAR(100) NOT NULL,
+    action         VARCHAR(50)  NOT NULL,
+    previous_value TEXT,
+    new_value      TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_issue_audit_v1_issue_id ON issue_audit_v1 (issue_id, ts);
+
+-- SLA targets of the issues, by situation and/or level (NULL situation and empty level apply to all)
+CREATE TABLE IF NOT EXISTS issue_sla_v1
+(
+    id                 SERIAL PRIMARY KEY,
+    situation_id       INTEGER REFERENCES situation_definition_v1 (id) ON DELETE CASCADE,
+    level              VARCHAR(100) NOT NULL DEFAULT '',
+    acknowledge_within VARCHAR(50)  NOT NULL DEFAULT '',
+    resolve_within     VARCHAR(50)  NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issue_sla_v1_target ON issue_sla_v1 (COALESCE(situation_id, 0), level);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS issue_sla_v1;
+DROP TABLE IF EXISTS issue_audit_v1;
+DROP INDEX IF EXISTS idx_issues_v1_assigned_to;
+ALTER TABLE issues_v1 DROP COLUMN IF EXISTS acknowledged_at;
+
+-- +goose StatementEnd
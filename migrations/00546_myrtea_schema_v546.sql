@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Correlation rules grouping the issues into incidents (keys: rule, situation, level, functional_situation, parameter:<name>)
+CREATE TABLE IF NOT EXISTS incident_correlation_rule_v1
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    enabled     BOOLEAN      NOT NULL DEFAULT true,
+    keys        JSONB        NOT NULL DEFAULT '[]'::jsonb,
+    time_window VARCHAR(50)  NOT NULL
+);
+
+-- Incidents grouping the issues of a same upstream problem (created by a correlation rule, or manually)
+CREATE TABLE IF NOT EXISTS incident_v1
+(
+    id                  SERIAL PRIMARY KEY,
+    name                VARCHAR(255) NOT NULL,
+    state               VARCHAR(50)  NOT NULL,
+    correlation_rule_id INTEGER REFERENCES incident_correlation_rule_v1 (id) ON DELETE SET NULL,
+    correlation_key     TEXT         NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ  NOT NULL,
+    last_modified       TIMESTAMPTZ  NOT NULL,
+    last_issue_at       TIMESTAMPTZ  NOT NULL,
+    closed_at           TIMESTAMPTZ,
+    closed_by           VARCHAR(100)
+);
+CREATE INDEX IF NOT EXISTS idx_incident_v1_correlation ON incident_v1 (correlation_rule_id, correlation_key) WHERE state = 'open';
+
+ALTER TABLE issues_v1 ADD COLUMN IF NOT EXISTS incident_id INTEGER REFERENCES incident_v1 (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_issues_v1_incident_id ON issues_v1 (incident_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_issues_v1_incident_id;
+ALTER TABLE issues_v1 DROP COLUMN IF EXISTS incident_id;
+DROP TABLE IF EXISTS incident_v1;
+DROP TABLE IF EXISTS incident_correlation_rule_v1;
+
+-- +goose StatementEnd
@@ -0,0 +1,68 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Global rootcauses catalog, linked to many situations and rules
+CREATE TABLE IF NOT EXISTS ref_catalog_rootcause_v1
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(500) NOT NULL
+);
+
+-- Global actions catalog of a catalog rootcause
+CREATE TABLE IF NOT EXISTS ref_catalog_action_v1
+(
+    id                   SERIAL PRIMARY KEY,
+    name                 VARCHAR(100) NOT NULL,
+    description          VARCHAR(500) NOT NULL,
+    catalog_rootcause_id INTEGER      NOT NULL REFERENCES ref_catalog_rootcause_v1 (id) ON DELETE CASCADE,
+    CONSTRAINT unq_catalog_action_name_rootcauseid UNIQUE (name, catalog_rootcause_id)
+);
+
+-- The rootcauses and actions of a situation and rule linked to the catalog keep their own ids,
+-- so the resolutions and drafts are unchanged
+ALTER TABLE ref_rootcause_v1 ADD COLUMN IF NOT EXISTS catalog_id INTEGER REFERENCES ref_catalog_rootcause_v1 (id) ON DELETE SET NULL;
+ALTER TABLE ref_action_v1 ADD COLUMN IF NOT EXISTS catalog_id INTEGER REFERENCES ref_catalog_action_v1 (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_ref_rootcause_v1_catalog_id ON ref_rootcause_v1 (catalog_id);
+CREATE INDEX IF NOT EXISTS idx_ref_action_v1_catalog_id ON ref_action_v1 (catalog_id);
+
+-- Existing rootcauses and actions are grouped by name into the catalog (with the description of the oldest one)
+INSERT INTO ref_catalog_rootcause_v1 (name, description)
+SELECT DISTINCT ON (name) name, description
+FROM ref_rootcause_v1
+ORDER BY name, id
+ON CONFLICT (name) DO NOTHING;
+
+UPDATE ref_rootcause_v1 rc
+SET catalog_id = c.id
+FROM ref_catalog_rootcause_v1 c
+WHERE c.name = rc.name;
+
+INSERT INTO ref_catalog_action_v1 (name, description, catalog_rootcause_id)
+SELECT DISTINCT ON (rc.catalog_id, a.name) a.name, a.description, rc.catalog_id
+FROM ref_action_v1 a
+INNER JOIN ref_rootcause_v1 rc ON rc.id = a.rootcause_id
+WHERE rc.catalog_id IS NOT NULL
+ORDER BY rc.catalog_id, a.name, a.id
+ON CONFLICT (name, catalog_rootcause_id) DO NOTHING;
+
+UPDATE ref_action_v1 a
+SET catalog_id = c.id
+FROM ref_rootcause_v1 rc, ref_catalog_action_v1 c
+WHERE rc.id = a.rootcause_id
+  AND c.catalog_rootcause_id = rc.catalog_id
+  AND c.name = a.name;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_ref_action_v1_catalog_id;
+DROP INDEX IF EXISTS idx_ref_rootcause_v1_catalog_id;
+ALTER TABLE ref_action_v1 DROP COLUMN IF EXISTS catalog_id;
+ALTER TABLE ref_rootcause_v1 DROP COLUMN IF EXISTS catalog_id;
+DROP TABLE IF EXISTS ref_catalog_action_v1;
+DROP TABLE IF EXISTS ref_catalog_rootcause_v1;
+
+-- +goose StatementEnd
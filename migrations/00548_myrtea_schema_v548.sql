@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Outbound webhooks receiving the issue lifecycle events
+CREATE TABLE IF NOT EXISTS issue_webhook_v1
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    url         TEXT         NOT NULL,
+    secret      TEXT         NOT NULL DEFAULT '',
+    event_types JSONB        NOT NULL DEFAULT '[]',
+    enabled     BOOLEAN      NOT NULL DEFAULT TRUE
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS issue_webhook_v1;
+
+-- +goose StatementEnd
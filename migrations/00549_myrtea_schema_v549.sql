@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Flapping detection: issues detected again shortly after their closure are reopened or linked to the closed issue
+ALTER TABLE issues_v1 ADD COLUMN IF NOT EXISTS flap_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE issues_v1 ADD COLUMN IF NOT EXISTS last_flap_at TIMESTAMPTZ;
+ALTER TABLE issues_v1 ADD COLUMN IF NOT EXISTS previous_issue_id INTEGER REFERENCES issues_v1 (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_issues_v1_previous_issue_id ON issues_v1 (previous_issue_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_issues_v1_previous_issue_id;
+ALTER TABLE issues_v1 DROP COLUMN IF EXISTS previous_issue_id;
+ALTER TABLE issues_v1 DROP COLUMN IF EXISTS last_flap_at;
+ALTER TABLE issues_v1 DROP COLUMN IF EXISTS flap_count;
+
+-- +goose StatementEnd
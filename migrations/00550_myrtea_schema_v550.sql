@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Planned maintenance windows, suppressing the create-issue, notify and situation-reporting actions of their scope
+CREATE TABLE IF NOT EXISTS maintenance_window_v1
+(
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    reason     TEXT         NOT NULL,
+    start_date TIMESTAMPTZ  NOT NULL,
+    end_date   TIMESTAMPTZ  NOT NULL,
+    scope      JSONB        NOT NULL DEFAULT '{}',
+    created_by VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_maintenance_window_dates CHECK (end_date > start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_window_v1_dates ON maintenance_window_v1 (start_date, end_date);
+
+-- Actions which would have fired without a maintenance window, kept when the window is deleted
+CREATE TABLE IF NOT EXISTS maintenance_suppressed_action_v1
+(
+    id                   SERIAL PRIMARY KEY,
+    window_id            INTEGER      REFERENCES maintenance_window_v1 (id) ON DELETE SET NULL,
+    ts                   TIMESTAMPTZ  NOT NULL,
+    action               VARCHAR(100) NOT NULL,
+    situation_id         INTEGER      NOT NULL,
+    template_instance_id INTEGER      NOT NULL DEFAULT 0,
+    situation_history_id INTEGER      NOT NULL DEFAULT 0,
+    rule_id              INTEGER      NOT NULL DEFAULT 0,
+    rule_version         INTEGER      NOT NULL DEFAULT 0,
+    case_name            VARCHAR(100) NOT NULL DEFAULT '',
+    parameters           JSONB        NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_suppressed_action_v1_window_id ON maintenance_suppressed_action_v1 (window_id);
+CREATE INDEX IF NOT EXISTS idx_maintenance_suppressed_action_v1_situation_ts ON maintenance_suppressed_action_v1 (situation_id, ts);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS maintenance_suppressed_action_v1;
+DROP TABLE IF EXISTS maintenance_window_v1;
+
+-- +goose StatementEnd
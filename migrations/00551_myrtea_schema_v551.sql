@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Comment threads of the issues, an answer references its parent comment
+CREATE TABLE IF NOT EXISTS issue_comment_v1
+(
+    id         SERIAL PRIMARY KEY,
+    issue_id   INTEGER      NOT NULL REFERENCES issues_v1 (id) ON DELETE CASCADE,
+    parent_id  INTEGER      REFERENCES issue_comment_v1 (id) ON DELETE CASCADE,
+    author     VARCHAR(100) NOT NULL,
+    content    TEXT         NOT NULL,
+    mentions   JSONB        NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    edited_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_issue_comment_v1_issue_id ON issue_comment_v1 (issue_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_issue_comment_v1_parent_id ON issue_comment_v1 (parent_id);
+
+-- Files attached to the issue comments, stored in the attachments directory under their storage name
+CREATE TABLE IF NOT EXISTS issue_comment_attachment_v1
+(
+    id           SERIAL PRIMARY KEY,
+    comment_id   INTEGER      NOT NULL REFERENCES issue_comment_v1 (id) ON DELETE CASCADE,
+    file_name    VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    size         BIGINT       NOT NULL DEFAULT 0,
+    storage_name VARCHAR(100) NOT NULL UNIQUE,
+    author       VARCHAR(100) NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_issue_comment_attachment_v1_comment_id ON issue_comment_attachment_v1 (comment_id);
+
+-- The single comment of the issues (issues_v1.comment) isn't copied to their thread: it is still managed by
+-- PUT /issues/{id}/comment, and has no author to attribute the thread comment to
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS issue_comment_attachment_v1;
+DROP TABLE IF EXISTS issue_comment_v1;
+
+-- +goose StatementEnd
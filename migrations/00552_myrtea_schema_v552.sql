@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Full-text search on the issues, the expressions must match the ones of the issues search queries
+CREATE INDEX IF NOT EXISTS idx_issues_v1_text_search ON issues_v1 USING GIN (to_tsvector('simple', name || ' ' || coalesce(comment, '')));
+CREATE INDEX IF NOT EXISTS idx_issue_comment_v1_text_search ON issue_comment_v1 USING GIN (to_tsvector('simple', content));
+
+-- Issues search date ranges
+CREATE INDEX IF NOT EXISTS idx_issues_v1_created_at ON issues_v1 (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_issues_v1_created_at;
+DROP INDEX IF EXISTS idx_issue_comment_v1_text_search;
+DROP INDEX IF EXISTS idx_issues_v1_text_search;
+
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Outbox of the issue webhook deliveries, the pending deliveries of a webhook are sent in order (by id)
+-- A delivery is deleted once delivered, and kept as failed once its retries are exhausted
+CREATE TABLE IF NOT EXISTS issue_webhook_delivery_v1
+(
+    id              BIGSERIAL PRIMARY KEY,
+    webhook_id      INTEGER     NOT NULL REFERENCES issue_webhook_v1 (id) ON DELETE CASCADE,
+    event_id        TEXT        NOT NULL,
+    event_type      VARCHAR(30) NOT NULL,
+    payload         JSONB       NOT NULL,
+    state           VARCHAR(10) NOT NULL DEFAULT 'pending',
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_issue_webhook_delivery_v1_pending ON issue_webhook_delivery_v1 (webhook_id, id) WHERE state = 'pending';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS issue_webhook_delivery_v1;
+
+-- +goose StatementEnd
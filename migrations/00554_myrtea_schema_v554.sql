@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Full-text search on the resolution labels of the issues, the expressions must match the ones of the issues search queries
+CREATE INDEX IF NOT EXISTS idx_ref_rootcause_v1_text_search ON ref_rootcause_v1 USING GIN (to_tsvector('simple', name));
+CREATE INDEX IF NOT EXISTS idx_ref_action_v1_text_search ON ref_action_v1 USING GIN (to_tsvector('simple', name));
+
+-- Resolutions of the matching rootcauses and actions
+CREATE INDEX IF NOT EXISTS idx_issue_resolution_v1_rootcause_id ON issue_resolution_v1 (rootcause_id);
+CREATE INDEX IF NOT EXISTS idx_issue_resolution_v1_action_id ON issue_resolution_v1 (action_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_issue_resolution_v1_action_id;
+DROP INDEX IF EXISTS idx_issue_resolution_v1_rootcause_id;
+DROP INDEX IF EXISTS idx_ref_action_v1_text_search;
+DROP INDEX IF EXISTS idx_ref_rootcause_v1_text_search;
+
+-- +goose StatementEnd
@@ -0,0 +1,305 @@
+package fact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/metrics"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/reader"
+	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	_cacheHitsCounter   = _newRegisteredCacheCounter("fact_cache_hits_total", "number of fact executions served from the fact result cache")
+	_cacheMissesCounter = _newRegisteredCacheCounter("fact_cache_misses_total", "number of fact executions not found in the fact result cache")
+)
+
+func _newRegisteredCacheCounter(name string, help string) *stdprometheus.CounterVec {
+	counter := stdprometheus.NewCounterVec(stdprometheus.CounterOpts{
+		Namespace:   metrics.MetricNamespace,
+		ConstLabels: metrics.MetricPrometheusLabels,
+		Name:        name,
+		Help:        help,
+	}, []string{"fact"})
+
+	// Register metrics
+	stdprometheus.MustRegister(counter)
+
+	return counter
+}
+
+// CacheConfig is the per-fact result cache configuration
+// A TTL of "0s" disables the cache for the fact, regardless of the default TTL
+type CacheConfig struct {
+	FactID int64  `json:"factId"`
+	TTL    string `json:"ttl"`
+}
+
+// Duration parses the configured TTL
+// An empty TTL returns nil, meaning the fact falls back on the default TTL
+func (c CacheConfig) Duration() (*time.Duration, error) {
+	if c.TTL == "" {
+		return nil, nil
+	}
+	ttl, err := time.ParseDuration(c.TTL)
+	if err != nil {
+		return nil, err
+	}
+	if ttl < 0 {
+		return nil, errors.New("ttl must be positive")
+	}
+	return &ttl, nil
+}
+
+// CacheStats summarizes the state of the fact result cache
+type CacheStats struct {
+	Enabled    bool   `json:"enabled"`
+	DefaultTTL string `json:"defaultTtl"`
+	MaxEntries int    `json:"maxEntries"`
+	Entries    int    `json:"entries"`
+	Hits       int64  `json:"hits"`
+	Misses     int64  `json:"misses"`
+}
+
+type cacheEntry struct {
+	factID    int64
+	data      *reader.WidgetData
+	expiresAt time.Time
+}
+
+// ResultCache is an in-memory cache of fact execution results
+// Entries are keyed by the fact definition, the contextualized time bucket, the parameters and the searched indices
+type ResultCache struct {
+	mu         sync.RWMutex
+	enabled    bool
+	defaultTTL time.Duration
+	maxEntries int
+	ttls       map[int64]time.Duration
+	entries    map[string]cacheEntry
+	hits       int64
+	misses     int64
+}
+
+var (
+	_globalCacheMu sync.RWMutex
+	_globalCache   = NewResultCache(false, 0, 0)
+)
+
+// Cache is used to access the global fact result cache singleton
+func Cache() *ResultCache {
+	_globalCacheMu.RLock()
+	defer _globalCacheMu.RUnlock()
+
+	cache := _globalCache
+	return cache
+}
+
+// ReplaceGlobalCache affect a new cache to the global fact result cache singleton
+func ReplaceGlobalCache(cache *ResultCache) func() {
+	_globalCacheMu.Lock()
+	defer _globalCacheMu.Unlock()
+
+	prev := _globalCache
+	_globalCache = cache
+	return func() { ReplaceGlobalCache(prev) }
+}
+
+// NewResultCache returns a new fact result cache
+// If defaultTTL is 0, only the facts with a dedicated TTL are cached
+func NewResultCache(enabled bool, defaultTTL time.Duration, maxEntries int) *ResultCache {
+	return &ResultCache{
+		enabled:    enabled,
+		defaultTTL: defaultTTL,
+		maxEntries: maxEntries,
+		ttls:       make(map[int64]time.Duration),
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+// LoadTTLs replaces the per-fact TTL configuration
+func (c *ResultCache) LoadTTLs(ttls map[int64]time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttls = make(map[int64]time.Duration, len(ttls))
+	for factID, ttl := range ttls {
+		c.ttls[factID] = ttl
+	}
+}
+
+// SetTTL sets a dedicated TTL for a fact (nil to fallback on the default TTL) and invalidates its entries
+func (c *ResultCache) SetTTL(factID int64, ttl *time.Duration) {
+	c.mu.Lock()
+	if ttl == nil {
+		delete(c.ttls, factID)
+	} else {
+		c.ttls[factID] = *ttl
+	}
+	c.mu.Unlock()
+
+	c.Invalidate(factID)
+}
+
+// Config returns the cache configuration of a fact (an empty TTL if the fact uses the default TTL)
+func (c *ResultCache) Config(factID int64) CacheConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	config := CacheConfig{FactID: factID}
+	if ttl, ok := c.ttls[factID]; ok {
+		config.TTL = ttl.String()
+	}
+	return config
+}
+
+// TTL returns the TTL applied to a fact results (0 if results must not be cached)
+func (c *ResultCache) TTL(factID int64) time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.enabled || factID <= 0 {
+		return 0
+	}
+	if ttl, ok := c.ttls[factID]; ok {
+		return ttl
+	}
+	return c.defaultTTL
+}
+
+// Key builds the cache key of a fact execution
+// The time bucket is the execution time truncated to the fact TTL, so that every execution
+// within the same bucket shares the same results
+func (c *ResultCache) Key(f engine.Fact, ti time.Time, ttl time.Duration, parameters map[string]interface{},
+	indices []string, nhit int, offset int) (string, error) {
+
+	definition, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	params, err := json.Marshal(parameters)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(definition)
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatInt(ti.Truncate(ttl).Unix(), 10)))
+	h.Write([]byte{0})
+	h.Write(params)
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(indices, ",")))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(nhit) + "/" + strconv.Itoa(offset)))
+
+	return strconv.FormatInt(f.ID, 10) + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Get returns a copy of the cached results of a fact execution
+func (c *ResultCache) Get(factID int64, key string) (*reader.WidgetData, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	label := strconv.FormatInt(factID, 10)
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		if ok {
+			delete(c.entries, key)
+		}
+		c.misses++
+		_cacheMissesCounter.WithLabelValues(label).Inc()
+		return nil, false
+	}
+
+	c.hits++
+	_cacheHitsCounter.WithLabelValues(label).Inc()
+	return entry.data.Clone(), true
+}
+
+// Set stores a copy of the results of a fact execution
+func (c *ResultCache) Set(factID int64, key string, data *reader.WidgetData, ttl time.Duration) {
+	if data == nil || ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+	c.entries[key] = cacheEntry{
+		factID:    factID,
+		data:      data.Clone(),
+		expiresAt: time.Now().Add(ttl),
+	}
+}
+
+// evict removes expired entries, and the entries closest to expiration if the cache is still full
+// It must be called with the lock held
+func (c *ResultCache) evict() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	for len(c.entries) >= c.maxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for key, entry := range c.entries {
+			if oldestKey == "" || entry.expiresAt.Before(oldest) {
+				oldestKey, oldest = key, entry.expiresAt
+			}
+		}
+		delete(c.entries, oldestKey)
+	}
+}
+
+// Invalidate removes every cached results of a fact
+func (c *ResultCache) Invalidate(factID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := 0
+	for key, entry := range c.entries {
+		if entry.factID == factID {
+			delete(c.entries, key)
+			count++
+		}
+	}
+	if count > 0 {
+		zap.L().Debug("Fact cache invalidated", zap.Int64("factID", factID), zap.Int("entries", count))
+	}
+}
+
+// Purge removes every cached results
+func (c *ResultCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]cacheEntry)
+}
+
+// Stats returns the current statistics of the cache
+func (c *ResultCache) Stats() CacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return CacheStats{
+		Enabled:    c.enabled,
+		DefaultTTL: c.defaultTTL.String(),
+		MaxEntries: c.maxEntries,
+		Entries:    len(c.entries),
+		Hits:       c.hits,
+		Misses:     c.misses,
+	}
+}
@@ -0,0 +1,113 @@
+package fact
+
+import (
+	"testing"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/reader"
+	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+)
+
+func TestResultCacheTTL(t *testing.T) {
+	cache := NewResultCache(true, time.Minute, 0)
+	if ttl := cache.TTL(1); ttl != time.Minute {
+		t.Errorf("expected default ttl, got %s", ttl)
+	}
+
+	disabled := time.Duration(0)
+	cache.SetTTL(1, &disabled)
+	if ttl := cache.TTL(1); ttl != 0 {
+		t.Errorf("expected cache to be disabled for the fact, got %s", ttl)
+	}
+	if config := cache.Config(1); config.TTL != "0s" {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	cache.SetTTL(1, nil)
+	if ttl := cache.TTL(1); ttl != time.Minute {
+		t.Errorf("expected default ttl, got %s", ttl)
+	}
+
+	if ttl := NewResultCache(false, time.Minute, 0).TTL(1); ttl != 0 {
+		t.Errorf("expected disabled cache, got %s", ttl)
+	}
+}
+
+func TestResultCacheKey(t *testing.T) {
+	cache := NewResultCache(true, time.Minute, 0)
+	f := engine.Fact{ID: 1, Name: "test", Model: "model"}
+	ti := time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC)
+	params := map[string]interface{}{"a": "b"}
+	indices := []string{"index-1"}
+
+	key, err := cache.Key(f, ti, time.Minute, params, indices, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameBucket, _ := cache.Key(f, ti.Add(30*time.Second), time.Minute, params, indices, 0, 0)
+	if key != sameBucket {
+		t.Error("executions in the same time bucket should share the same key")
+	}
+
+	others := []struct {
+		f       engine.Fact
+		ti      time.Time
+		params  map[string]interface{}
+		indices []string
+	}{
+		{engine.Fact{ID: 1, Name: "test", Model: "other"}, ti, params, indices},
+		{f, ti.Add(time.Minute), params, indices},
+		{f, ti, map[string]interface{}{"a": "c"}, indices},
+		{f, ti, params, []string{"index-1", "index-2"}},
+	}
+	for i, other := range others {
+		otherKey, _ := cache.Key(other.f, other.ti, time.Minute, other.params, other.indices, 0, 0)
+		if otherKey == key {
+			t.Errorf("case %d: key should differ", i)
+		}
+	}
+}
+
+func TestResultCacheGetSet(t *testing.T) {
+	cache := NewResultCache(true, time.Minute, 2)
+
+	data := &reader.WidgetData{Aggregates: &reader.Item{Aggs: map[string]*reader.ItemAgg{"doc_count": {Value: 10}}}}
+	cache.Set(1, "1:a", data, time.Minute)
+
+	cached, found := cache.Get(1, "1:a")
+	if !found {
+		t.Fatal("entry should be found")
+	}
+	if cached.Aggregates.Aggs["doc_count"].Value != 10 {
+		t.Errorf("unexpected cached value %v", cached.Aggregates.Aggs["doc_count"].Value)
+	}
+	cached.Aggregates.Aggs["doc_count"].Value = 20
+	cached, _ = cache.Get(1, "1:a")
+	if cached.Aggregates.Aggs["doc_count"].Value != 10 {
+		t.Error("cached entry should not be modified by callers")
+	}
+
+	if _, found := cache.Get(1, "1:b"); found {
+		t.Error("entry should not be found")
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	cache.Set(1, "1:b", data, time.Minute)
+	cache.Set(2, "2:a", data, time.Minute)
+	if stats := cache.Stats(); stats.Entries != 2 {
+		t.Errorf("cache should not exceed its max entries, got %d", stats.Entries)
+	}
+
+	cache.Invalidate(2)
+	if _, found := cache.Get(2, "2:a"); found {
+		t.Error("entry should be invalidated")
+	}
+
+	cache.Set(3, "3:a", data, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, found := cache.Get(3, "3:a"); found {
+		t.Error("entry should be expired")
+	}
+}
@@ -0,0 +1,262 @@
+package fact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/myrteametrics/myrtea-sdk/v5/elasticsearch"
+	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// CostMode defines how the cost limits are enforced
+type CostMode string
+
+const (
+	// CostModeOff disables the enforcement of the cost limits
+	CostModeOff CostMode = "off"
+	// CostModeWarn only logs and reports the exceeded limits
+	CostModeWarn CostMode = "warn"
+	// CostModeBlock refuses the fact definitions and executions exceeding the limits
+	CostModeBlock CostMode = "block"
+)
+
+// defaultBucketSize is the number of buckets returned by elasticsearch when no size is specified
+const defaultBucketSize = 10
+
+// ErrCostLimitExceeded is returned when a fact exceeds the cost limits in block mode
+var ErrCostLimitExceeded = errors.New("fact cost limits exceeded")
+
+// CostLimits are the guardrails applied on fact definitions and executions
+// A limit set to 0 is not checked
+type CostLimits struct {
+	Mode       CostMode `json:"mode"`
+	MaxIndices int      `json:"maxIndices"`
+	MaxBuckets int64    `json:"maxBuckets"`
+	MaxHits    int      `json:"maxHits"`
+}
+
+// CostEstimate is the estimated cost of a fact execution
+type CostEstimate struct {
+	FactID            int64      `json:"factId,omitempty"`
+	Time              time.Time  `json:"time"`
+	Indices           []string   `json:"indices"`
+	IndexCount        int        `json:"indexCount"`
+	DateFrom          time.Time  `json:"dateFrom"`
+	DateTo            time.Time  `json:"dateTo"`
+	BucketCardinality int64      `json:"bucketCardinality"`
+	Hits              int        `json:"hits"`
+	MatchedDocuments  *int64     `json:"matchedDocuments,omitempty"`
+	Limits            CostLimits `json:"limits"`
+	Warnings          []string   `json:"warnings"`
+	Blocked           bool       `json:"blocked"`
+}
+
+// GetCostLimits returns the cost limits from the configuration
+func GetCostLimits() CostLimits {
+	mode := CostMode(strings.ToLower(viper.GetString("FACT_COST_MODE")))
+	switch mode {
+	case CostModeWarn, CostModeBlock:
+	default:
+		mode = CostModeOff
+	}
+	return CostLimits{
+		Mode:       mode,
+		MaxIndices: viper.GetInt("FACT_COST_MAX_INDICES"),
+		MaxBuckets: viper.GetInt64("FACT_COST_MAX_BUCKETS"),
+		MaxHits:    viper.GetInt("FACT_COST_MAX_NHIT"),
+	}
+}
+
+// EstimateCost estimates the cost of a fact execution at a given time, without executing its aggregations
+// If count is true, the number of documents matched by the fact query is fetched from elasticsearch
+func EstimateCost(ti time.Time, f engine.Fact, parameters map[string]interface{}, nhit int, count bool) (CostEstimate, error) {
+	parameters, err := ResolveParameters(f.ID, parameters)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	return EstimateResolvedCost(ti, f, parameters, nhit, count)
+}
+
+// EstimateResolvedCost estimates the cost of a fact execution like EstimateCost, the parameters must be resolved beforehand
+// It is used on the fact definitions which parameter definitions aren't stored yet
+func EstimateResolvedCost(ti time.Time, f engine.Fact, parameters map[string]interface{}, nhit int, count bool) (CostEstimate, error) {
+	indices := FindIndices(f, ti, false)
+
+	f.ContextualizeDimensions(ti)
+	err := f.ContextualizeCondition(ti, parameters)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+
+	searchRequest, err := elasticsearch.ConvertFactToSearchRequestV8(f, ti, parameters)
+	if err != nil {
+		zap.L().Error("ConvertFactToSearchRequestV8 failed", zap.Error(err))
+		return CostEstimate{}, err
+	}
+
+	depth := time.Duration(f.CalculationDepth) * 24 * time.Hour
+	if depth <= 0 {
+		depth = 24 * time.Hour
+	}
+
+	estimate := CostEstimate{
+		FactID:     f.ID,
+		Time:       ti,
+		Indices:    indices,
+		IndexCount: len(indices),
+		DateFrom:   ti.Add(-depth),
+		DateTo:     ti,
+		Hits:       nhit,
+		Warnings:   make([]string, 0),
+	}
+	estimate.BucketCardinality = aggregationsCardinality(searchRequest.Aggregations, depth)
+
+	if count {
+		response, err := elasticsearch.C().Count().
+			Index(strings.Join(indices, ",")).
+			Query(searchRequest.Query).
+			Do(context.Background())
+		if err != nil {
+			zap.L().Error("ES Count failed", zap.Error(err))
+			return CostEstimate{}, err
+		}
+		estimate.MatchedDocuments = &response.Count
+	}
+
+	estimate.Check(GetCostLimits())
+	return estimate, nil
+}
+
+// Check compares the estimate with the cost limits and reports the exceeded limits
+func (e *CostEstimate) Check(limits CostLimits) {
+	e.Limits = limits
+	e.Warnings = make([]string, 0)
+	if limits.MaxIndices > 0 && e.IndexCount > limits.MaxIndices {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("%d indices touched (max %d)", e.IndexCount, limits.MaxIndices))
+	}
+	if limits.MaxBuckets > 0 && e.BucketCardinality > limits.MaxBuckets {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("%d buckets estimated (max %d)", e.BucketCardinality, limits.MaxBuckets))
+	}
+	if limits.MaxHits > 0 && e.Hits > limits.MaxHits {
+		e.Warnings = append(e.Warnings, fmt.Sprintf("%d hits requested (max %d)", e.Hits, limits.MaxHits))
+	}
+	e.Blocked = limits.Mode == CostModeBlock && len(e.Warnings) > 0
+}
+
+// Err returns an error describing the exceeded limits if the estimate is blocked
+func (e CostEstimate) Err() error {
+	if !e.Blocked {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrCostLimitExceeded, strings.Join(e.Warnings, ", "))
+}
+
+// CheckHitsLimit checks the number of requested hits against the configured limit
+func CheckHitsLimit(f engine.Fact, nhit int) error {
+	limits := GetCostLimits()
+	if limits.Mode == CostModeOff || limits.MaxHits <= 0 || nhit <= limits.MaxHits {
+		return nil
+	}
+	if limits.Mode == CostModeWarn {
+		zap.L().Warn("Fact execution exceeds the hits limit", zap.String("fact", f.Name), zap.Int("nhit", nhit), zap.Int("max", limits.MaxHits))
+		return nil
+	}
+	return fmt.Errorf("%w: %d hits requested (max %d)", ErrCostLimitExceeded, nhit, limits.MaxHits)
+}
+
+// aggregationsCardinality estimates the maximum number of buckets generated by a set of sibling aggregations
+func aggregationsCardinality(aggs map[string]types.Aggregations, dateRange time.Duration) int64 {
+	total := int64(0)
+	for _, agg := range aggs {
+		buckets := aggregationCardinality(agg, dateRange)
+		if len(agg.Aggregations) > 0 {
+			if sub := aggregationsCardinality(agg.Aggregations, dateRange); sub > 0 {
+				buckets *= sub
+			}
+		}
+		total += buckets
+	}
+	return total
+}
+
+// aggregationCardinality estimates the number of buckets generated by a single aggregation (1 for metrics)
+func aggregationCardinality(agg types.Aggregations, dateRange time.Duration) int64 {
+	switch {
+	case agg.Terms != nil:
+		return sizeOrDefault(agg.Terms.Size)
+	case agg.MultiTerms != nil:
+		return sizeOrDefault(agg.MultiTerms.Size)
+	case agg.SignificantTerms != nil:
+		return sizeOrDefault(agg.SignificantTerms.Size)
+	case agg.Composite != nil:
+		return sizeOrDefault(agg.Composite.Size)
+	case agg.DateHistogram != nil:
+		interval := dateHistogramInterval(agg.DateHistogram)
+		if interval <= 0 {
+			return 1
+		}
+		return int64(dateRange/interval) + 1
+	default:
+		return 1
+	}
+}
+
+func sizeOrDefault(size *int) int64 {
+	if size == nil {
+		return defaultBucketSize
+	}
+	return int64(*size)
+}
+
+// dateHistogramInterval returns the approximate duration of a date histogram interval
+func dateHistogramInterval(agg *types.DateHistogramAggregation) time.Duration {
+	if agg.CalendarInterval != nil {
+		return parseInterval(agg.CalendarInterval.String())
+	}
+	if interval, ok := agg.FixedInterval.(string); ok {
+		return parseInterval(interval)
+	}
+	if interval, ok := agg.Interval.(string); ok {
+		return parseInterval(interval)
+	}
+	return 0
+}
+
+// parseInterval parses an elasticsearch interval ("1h", "30m", "1d", "day", "month", ...)
+func parseInterval(interval string) time.Duration {
+	day := 24 * time.Hour
+	switch interval {
+	case "second", "1s":
+		return time.Second
+	case "minute", "1m":
+		return time.Minute
+	case "hour", "1h":
+		return time.Hour
+	case "day", "1d":
+		return day
+	case "week", "1w":
+		return 7 * day
+	case "month", "1M":
+		return 30 * day
+	case "quarter", "1q":
+		return 91 * day
+	case "year", "1y":
+		return 365 * day
+	}
+	if strings.HasSuffix(interval, "d") {
+		if d, err := time.ParseDuration(strings.TrimSuffix(interval, "d") + "h"); err == nil {
+			return d * 24
+		}
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0
+	}
+	return d
+}
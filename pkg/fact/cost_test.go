@@ -0,0 +1,68 @@
+package fact
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/calendarinterval"
+)
+
+func TestAggregationsCardinality(t *testing.T) {
+	size := 50
+	aggs := map[string]types.Aggregations{
+		"by_carrier": {
+			Terms: &types.TermsAggregation{Size: &size},
+			Aggregations: map[string]types.Aggregations{
+				"by_hour": {DateHistogram: &types.DateHistogramAggregation{CalendarInterval: &calendarinterval.Hour}},
+			},
+		},
+		"by_site": {Terms: &types.TermsAggregation{}},
+		"total":   {Sum: &types.SumAggregation{}},
+	}
+
+	// 50 carriers * 25 hours + 10 sites + 1 metric
+	expected := int64(50*25 + 10 + 1)
+	if got := aggregationsCardinality(aggs, 24*time.Hour); got != expected {
+		t.Errorf("expected %d buckets, got %d", expected, got)
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	cases := map[string]time.Duration{
+		"hour": time.Hour,
+		"1m":   time.Minute,
+		"1M":   30 * 24 * time.Hour,
+		"30m":  30 * time.Minute,
+		"2d":   48 * time.Hour,
+		"abc":  0,
+	}
+	for interval, expected := range cases {
+		if got := parseInterval(interval); got != expected {
+			t.Errorf("interval %s: expected %s, got %s", interval, expected, got)
+		}
+	}
+}
+
+func TestCostEstimateCheck(t *testing.T) {
+	estimate := CostEstimate{IndexCount: 90, BucketCardinality: 100, Hits: 10}
+
+	estimate.Check(CostLimits{Mode: CostModeWarn, MaxIndices: 30, MaxBuckets: 1000, MaxHits: 100})
+	if len(estimate.Warnings) != 1 || estimate.Blocked || estimate.Err() != nil {
+		t.Errorf("expected a single warning without blocking, got %+v", estimate)
+	}
+
+	estimate.Check(CostLimits{Mode: CostModeBlock, MaxIndices: 30, MaxBuckets: 50, MaxHits: 5})
+	if len(estimate.Warnings) != 3 || !estimate.Blocked {
+		t.Errorf("expected 3 warnings and blocking, got %+v", estimate)
+	}
+	if err := estimate.Err(); !errors.Is(err, ErrCostLimitExceeded) {
+		t.Errorf("expected ErrCostLimitExceeded, got %v", err)
+	}
+
+	estimate.Check(CostLimits{Mode: CostModeBlock})
+	if len(estimate.Warnings) != 0 || estimate.Blocked {
+		t.Errorf("unlimited limits should not block, got %+v", estimate)
+	}
+}
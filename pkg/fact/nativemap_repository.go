@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/myrteametrics/myrtea-sdk/v5/engine"
 )
@@ -13,6 +14,9 @@ type NativeMapRepository struct {
 	mutex       sync.RWMutex
 	factsByID   map[int64]engine.Fact
 	factsByName map[string]engine.Fact
+	parameters  map[int64]ParameterDefinitions
+	cacheTTLs   map[int64]time.Duration
+	sql         map[int64]SQLDefinition
 	nextInt     func() int64
 }
 
@@ -29,6 +33,9 @@ func NewNativeMapRepository() Repository {
 	r := NativeMapRepository{
 		factsByID:   make(map[int64]engine.Fact, 0),
 		factsByName: make(map[string]engine.Fact, 0),
+		parameters:  make(map[int64]ParameterDefinitions, 0),
+		cacheTTLs:   make(map[int64]time.Duration, 0),
+		sql:         make(map[int64]SQLDefinition, 0),
 		nextInt:     intSeq(),
 	}
 
@@ -108,6 +115,9 @@ func (r *NativeMapRepository) Delete(id int64) error {
 	f := r.factsByID[id]
 	delete(r.factsByName, f.Name)
 	delete(r.factsByID, id)
+	delete(r.parameters, id)
+	delete(r.cacheTTLs, id)
+	delete(r.sql, id)
 	return nil
 }
 
@@ -128,6 +138,99 @@ func (r *NativeMapRepository) GetAllByIDs(ids []int64) (map[int64]engine.Fact, e
 	return factsByID, nil
 }
 
+// GetParameterDefinitions returns the parameter definitions declared on a fact
+func (r *NativeMapRepository) GetParameterDefinitions(id int64) (ParameterDefinitions, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	defs, found := r.parameters[id]
+	if !found {
+		return make(ParameterDefinitions, 0), nil
+	}
+	return defs, nil
+}
+
+// SetParameterDefinitions replaces the parameter definitions declared on a fact
+func (r *NativeMapRepository) SetParameterDefinitions(id int64, defs ParameterDefinitions) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.factsByID[id]; !ok {
+		return errors.New("fact does not exists for the ID:" + strconv.FormatInt(id, 10))
+	}
+	if len(defs) == 0 {
+		delete(r.parameters, id)
+		return nil
+	}
+	r.parameters[id] = defs
+	return nil
+}
+
+// GetCacheTTLs returns the dedicated result cache TTL of every fact
+func (r *NativeMapRepository) GetCacheTTLs() (map[int64]time.Duration, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	ttls := make(map[int64]time.Duration, len(r.cacheTTLs))
+	for id, ttl := range r.cacheTTLs {
+		ttls[id] = ttl
+	}
+	return ttls, nil
+}
+
+// SetCacheTTL sets the dedicated result cache TTL of a fact (nil to remove it)
+func (r *NativeMapRepository) SetCacheTTL(id int64, ttl *time.Duration) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.factsByID[id]; !ok {
+		return errors.New("fact does not exists for the ID:" + strconv.FormatInt(id, 10))
+	}
+	if ttl == nil {
+		delete(r.cacheTTLs, id)
+		return nil
+	}
+	r.cacheTTLs[id] = *ttl
+	return nil
+}
+
+// GetSQLDefinition returns the SQL definition of a fact
+func (r *NativeMapRepository) GetSQLDefinition(id int64) (SQLDefinition, bool, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	def, found := r.sql[id]
+	return def, found, nil
+}
+
+// GetSQLDefinitions returns the SQL definition of every SQL fact
+func (r *NativeMapRepository) GetSQLDefinitions() (map[int64]SQLDefinition, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	defs := make(map[int64]SQLDefinition, len(r.sql))
+	for id, def := range r.sql {
+		defs[id] = def
+	}
+	return defs, nil
+}
+
+// SetSQLDefinition sets the SQL definition of a fact (nil to remove it)
+func (r *NativeMapRepository) SetSQLDefinition(id int64, def *SQLDefinition) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.factsByID[id]; !ok {
+		return errors.New("fact does not exists for the ID:" + strconv.FormatInt(id, 10))
+	}
+	if def == nil {
+		delete(r.sql, id)
+		return nil
+	}
+	r.sql[id] = *def
+	return nil
+}
+
 func (r *NativeMapRepository) refreshNextIdGen() (int64, bool, error) {
 	return 0, false, nil
 }
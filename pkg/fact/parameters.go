@@ -0,0 +1,300 @@
+package fact
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ParameterType is the type of value expected by a fact parameter
+type ParameterType string
+
+const (
+	// ParameterTypeString is used for plain string parameters
+	ParameterTypeString ParameterType = "string"
+	// ParameterTypeInt is used for integer parameters
+	ParameterTypeInt ParameterType = "int"
+	// ParameterTypeFloat is used for floating point parameters
+	ParameterTypeFloat ParameterType = "float"
+	// ParameterTypeBool is used for boolean parameters
+	ParameterTypeBool ParameterType = "bool"
+	// ParameterTypeList is used for parameters holding a list of values (ex: terms filters)
+	ParameterTypeList ParameterType = "list"
+)
+
+// ParameterDefinition declares a typed parameter which can be bound by situations, template instances
+// or API callers when executing a fact
+type ParameterDefinition struct {
+	Name          string        `json:"name"`
+	Type          ParameterType `json:"type"`
+	Description   string        `json:"description,omitempty"`
+	Required      bool          `json:"required"`
+	Default       interface{}   `json:"default,omitempty"`
+	AllowedValues []interface{} `json:"allowedValues,omitempty"`
+}
+
+// ParameterDefinitions is the list of parameters declared on a fact
+type ParameterDefinitions []ParameterDefinition
+
+// ParameterBinding reports the parameter values bound to a fact by a situation or a template instance
+type ParameterBinding struct {
+	SituationID           int64                  `json:"situationId"`
+	SituationName         string                 `json:"situationName"`
+	SituationInstanceID   int64                  `json:"situationInstanceId,omitempty"`
+	SituationInstanceName string                 `json:"situationInstanceName,omitempty"`
+	Values                map[string]interface{} `json:"values"`
+	Defaulted             []string               `json:"defaulted"`
+	Errors                []string               `json:"errors"`
+}
+
+// IsValid checks if a parameter definition is valid and has no missing mandatory fields
+func (p ParameterDefinition) IsValid() (bool, error) {
+	if p.Name == "" {
+		return false, errors.New("missing Name")
+	}
+	switch p.Type {
+	case ParameterTypeString, ParameterTypeInt, ParameterTypeFloat, ParameterTypeBool, ParameterTypeList:
+	case "":
+		return false, fmt.Errorf("parameter %s: missing Type", p.Name)
+	default:
+		return false, fmt.Errorf("parameter %s: invalid Type %s", p.Name, p.Type)
+	}
+	if p.Type != ParameterTypeList {
+		for _, allowed := range p.AllowedValues {
+			if _, err := p.coerce(allowed); err != nil {
+				return false, fmt.Errorf("parameter %s: invalid allowed value: %s", p.Name, err.Error())
+			}
+		}
+	}
+	if p.Default != nil {
+		if _, err := p.Check(p.Default); err != nil {
+			return false, fmt.Errorf("parameter %s: invalid default value: %s", p.Name, err.Error())
+		}
+	}
+	return true, nil
+}
+
+// IsValid checks if all the parameter definitions are valid and have unique names
+func (defs ParameterDefinitions) IsValid() (bool, error) {
+	names := make(map[string]struct{}, len(defs))
+	for _, def := range defs {
+		if ok, err := def.IsValid(); !ok {
+			return false, err
+		}
+		if _, exists := names[def.Name]; exists {
+			return false, fmt.Errorf("parameter %s is declared more than once", def.Name)
+		}
+		names[def.Name] = struct{}{}
+	}
+	return true, nil
+}
+
+// Check coerces a raw value to the parameter type and ensures it is part of the allowed values (if any)
+func (p ParameterDefinition) Check(value interface{}) (interface{}, error) {
+	coerced, err := p.coerce(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.AllowedValues) == 0 {
+		return coerced, nil
+	}
+
+	candidates := []interface{}{coerced}
+	if p.Type == ParameterTypeList {
+		candidates = coerced.([]interface{})
+	}
+	for _, candidate := range candidates {
+		if !p.isAllowed(candidate) {
+			return nil, fmt.Errorf("value %v is not allowed", candidate)
+		}
+	}
+	return coerced, nil
+}
+
+func (p ParameterDefinition) isAllowed(value interface{}) bool {
+	for _, allowed := range p.AllowedValues {
+		if p.Type == ParameterTypeList {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				return true
+			}
+			continue
+		}
+		if coerced, err := p.coerce(allowed); err == nil && reflect.DeepEqual(coerced, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// coerce converts a raw value (possibly provided as a string in a query or in a situation definition)
+// to the go type associated with the parameter type
+func (p ParameterDefinition) coerce(value interface{}) (interface{}, error) {
+	switch p.Type {
+	case ParameterTypeString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		default:
+			return fmt.Sprint(v), nil
+		}
+
+	case ParameterTypeInt:
+		switch v := value.(type) {
+		case int:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case float64:
+			if v != float64(int64(v)) {
+				return nil, fmt.Errorf("value %v is not an integer", v)
+			}
+			return int64(v), nil
+		case string:
+			i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("value %s is not an integer", v)
+			}
+			return i, nil
+		}
+
+	case ParameterTypeFloat:
+		switch v := value.(type) {
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("value %s is not a float", v)
+			}
+			return f, nil
+		}
+
+	case ParameterTypeBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("value %s is not a boolean", v)
+			}
+			return b, nil
+		}
+
+	case ParameterTypeList:
+		switch v := value.(type) {
+		case []interface{}:
+			return v, nil
+		case []string:
+			list := make([]interface{}, 0, len(v))
+			for _, item := range v {
+				list = append(list, item)
+			}
+			return list, nil
+		case string:
+			list := make([]interface{}, 0)
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			return list, nil
+		}
+	}
+	return nil, fmt.Errorf("value %v cannot be converted to type %s", value, p.Type)
+}
+
+// Find returns the parameter definition with the given name
+func (defs ParameterDefinitions) Find(name string) (ParameterDefinition, bool) {
+	for _, def := range defs {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return ParameterDefinition{}, false
+}
+
+// resolve returns the value bound to the parameter, falling back on the default value if the
+// parameter is missing. It also reports if a value was found and if it is the default one.
+func (p ParameterDefinition) resolve(parameters map[string]interface{}) (interface{}, bool, bool, error) {
+	value, ok := parameters[p.Name]
+	if ok && value != nil {
+		checked, err := p.Check(value)
+		if err != nil {
+			return value, true, false, fmt.Errorf("parameter %s: %s", p.Name, err.Error())
+		}
+		return checked, true, false, nil
+	}
+	if p.Default != nil {
+		checked, err := p.Check(p.Default)
+		return checked, true, true, err
+	}
+	if p.Required {
+		return nil, false, false, fmt.Errorf("missing required parameter %s", p.Name)
+	}
+	return nil, false, false, nil
+}
+
+// Resolve checks the provided parameters against the definitions and returns a new map
+// containing the coerced values, with defaults applied on missing parameters.
+// Parameters which are not declared are kept untouched.
+func (defs ParameterDefinitions) Resolve(parameters map[string]interface{}) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(parameters)+len(defs))
+	for key, value := range parameters {
+		resolved[key] = value
+	}
+
+	for _, def := range defs {
+		value, found, _, err := def.resolve(parameters)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			resolved[def.Name] = value
+		}
+	}
+	return resolved, nil
+}
+
+// ResolveParameters resolves the parameters of a fact execution using the fact parameter definitions
+// If the fact has no declared parameters, the provided map is returned as-is
+func ResolveParameters(factID int64, parameters map[string]interface{}) (map[string]interface{}, error) {
+	if R() == nil || factID <= 0 {
+		return parameters, nil
+	}
+	defs, err := R().GetParameterDefinitions(factID)
+	if err != nil {
+		return nil, err
+	}
+	if len(defs) == 0 {
+		return parameters, nil
+	}
+	return defs.Resolve(parameters)
+}
+
+// Bind resolves the parameters bound by a situation (and its template instance) and reports
+// the values, the parameters falling back on their default values and the binding errors
+func (defs ParameterDefinitions) Bind(parameters map[string]interface{}) (map[string]interface{}, []string, []string) {
+	values := make(map[string]interface{})
+	defaulted := make([]string, 0)
+	errs := make([]string, 0)
+	for _, def := range defs {
+		value, found, isDefault, err := def.resolve(parameters)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		if isDefault {
+			defaulted = append(defaulted, def.Name)
+		}
+		if found {
+			values[def.Name] = value
+		}
+	}
+	return values, defaulted, errs
+}
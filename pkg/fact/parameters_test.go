@@ -0,0 +1,117 @@
+package fact
+
+import (
+	"testing"
+
+	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+)
+
+func TestParameterDefinitionIsValid(t *testing.T) {
+	valid := []ParameterDefinition{
+		{Name: "carrier", Type: ParameterTypeString},
+		{Name: "depth", Type: ParameterTypeInt, Default: float64(3)},
+		{Name: "threshold", Type: ParameterTypeFloat, Default: "0.5"},
+		{Name: "carrier", Type: ParameterTypeString, Default: "DHL", AllowedValues: []interface{}{"DHL", "UPS"}},
+		{Name: "sites", Type: ParameterTypeList, Default: []interface{}{"A"}, AllowedValues: []interface{}{"A", "B"}},
+	}
+	for _, def := range valid {
+		if ok, err := def.IsValid(); !ok {
+			t.Errorf("parameter %+v should be valid: %v", def, err)
+		}
+	}
+
+	invalid := []ParameterDefinition{
+		{Type: ParameterTypeString},
+		{Name: "carrier"},
+		{Name: "carrier", Type: "unknown"},
+		{Name: "depth", Type: ParameterTypeInt, Default: "abc"},
+		{Name: "depth", Type: ParameterTypeInt, Default: 1.5},
+		{Name: "carrier", Type: ParameterTypeString, Default: "FEDEX", AllowedValues: []interface{}{"DHL", "UPS"}},
+		{Name: "enabled", Type: ParameterTypeBool, AllowedValues: []interface{}{"maybe"}},
+	}
+	for _, def := range invalid {
+		if ok, _ := def.IsValid(); ok {
+			t.Errorf("parameter %+v should be invalid", def)
+		}
+	}
+}
+
+func TestParameterDefinitionsIsValidDuplicate(t *testing.T) {
+	defs := ParameterDefinitions{
+		{Name: "carrier", Type: ParameterTypeString},
+		{Name: "carrier", Type: ParameterTypeInt},
+	}
+	if ok, _ := defs.IsValid(); ok {
+		t.Error("duplicated parameter names should be invalid")
+	}
+}
+
+func TestParameterDefinitionsResolve(t *testing.T) {
+	defs := ParameterDefinitions{
+		{Name: "carrier", Type: ParameterTypeString, Default: "DHL", AllowedValues: []interface{}{"DHL", "UPS"}},
+		{Name: "depth", Type: ParameterTypeInt, Required: true},
+		{Name: "sites", Type: ParameterTypeList},
+		{Name: "optional", Type: ParameterTypeBool},
+	}
+
+	resolved, err := defs.Resolve(map[string]interface{}{"depth": "7", "sites": "A, B", "other": "kept"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved["carrier"] != "DHL" {
+		t.Errorf("expected default carrier, got %v", resolved["carrier"])
+	}
+	if resolved["depth"] != int64(7) {
+		t.Errorf("expected depth to be coerced to int64(7), got %#v", resolved["depth"])
+	}
+	if sites, ok := resolved["sites"].([]interface{}); !ok || len(sites) != 2 || sites[1] != "B" {
+		t.Errorf("unexpected sites %#v", resolved["sites"])
+	}
+	if resolved["other"] != "kept" {
+		t.Errorf("undeclared parameters should be kept, got %v", resolved["other"])
+	}
+	if _, ok := resolved["optional"]; ok {
+		t.Error("optional parameter without default should not be set")
+	}
+
+	if _, err := defs.Resolve(map[string]interface{}{}); err == nil {
+		t.Error("missing required parameter should return an error")
+	}
+	if _, err := defs.Resolve(map[string]interface{}{"depth": 1, "carrier": "FEDEX"}); err == nil {
+		t.Error("value not allowed should return an error")
+	}
+}
+
+func TestResolveParameters(t *testing.T) {
+	r := NewNativeMapRepository()
+	defer ReplaceGlobals(r)()
+
+	id, err := r.Create(engine.Fact{Name: "test_fact"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parameters := map[string]interface{}{"a": "b"}
+	resolved, err := ResolveParameters(id, parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 1 {
+		t.Errorf("parameters should be untouched without definitions, got %v", resolved)
+	}
+
+	err = r.SetParameterDefinitions(id, ParameterDefinitions{{Name: "carrier", Type: ParameterTypeString, Default: "DHL"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err = ResolveParameters(id, parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved["carrier"] != "DHL" {
+		t.Errorf("expected default carrier, got %v", resolved["carrier"])
+	}
+	if _, ok := parameters["carrier"]; ok {
+		t.Error("input parameters should not be modified")
+	}
+}
@@ -14,6 +14,9 @@ import (
 )
 
 const table = "fact_definition_v1"
+const tableParameters = "fact_parameter_definition_v1"
+const tableCache = "fact_cache_config_v1"
+const tableSQL = "fact_sql_definition_v1"
 
 // PostgresRepository is a repository containing the Fact definition based on a PSQL database and
 // implementing the repository interface
@@ -250,6 +253,173 @@ func (r *PostgresRepository) GetAllByIDs(ids []int64) (map[int64]engine.Fact, er
 	return facts, nil
 }
 
+// GetParameterDefinitions returns the parameter definitions declared on a fact
+func (r *PostgresRepository) GetParameterDefinitions(id int64) (ParameterDefinitions, error) {
+	rows, err := r.newStatement().
+		Select("definitions").
+		From(tableParameters).
+		Where(sq.Eq{"fact_id": id}).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	defs := make(ParameterDefinitions, 0)
+	if rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &defs); err != nil {
+			return nil, err
+		}
+	}
+	return defs, nil
+}
+
+// SetParameterDefinitions replaces the parameter definitions declared on a fact
+func (r *PostgresRepository) SetParameterDefinitions(id int64, defs ParameterDefinitions) error {
+	if len(defs) == 0 {
+		_, err := r.newStatement().
+			Delete(tableParameters).
+			Where(sq.Eq{"fact_id": id}).
+			Exec()
+		return err
+	}
+
+	data, err := json.Marshal(defs)
+	if err != nil {
+		return errors.New("couldn't marshall the provided data:" + err.Error())
+	}
+
+	_, err = r.newStatement().
+		Insert(tableParameters).
+		Columns("fact_id", "definitions", "last_modified").
+		Values(id, string(data), time.Now().Truncate(1*time.Millisecond).UTC()).
+		Suffix("ON CONFLICT (fact_id) DO UPDATE SET definitions = EXCLUDED.definitions, last_modified = EXCLUDED.last_modified").
+		Exec()
+	return err
+}
+
+// GetCacheTTLs returns the dedicated result cache TTL of every fact
+func (r *PostgresRepository) GetCacheTTLs() (map[int64]time.Duration, error) {
+	rows, err := r.newStatement().
+		Select("fact_id", "ttl_seconds").
+		From(tableCache).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ttls := make(map[int64]time.Duration)
+	for rows.Next() {
+		var factID, seconds int64
+		if err := rows.Scan(&factID, &seconds); err != nil {
+			return nil, err
+		}
+		ttls[factID] = time.Duration(seconds) * time.Second
+	}
+	return ttls, nil
+}
+
+// SetCacheTTL sets the dedicated result cache TTL of a fact (nil to remove it)
+func (r *PostgresRepository) SetCacheTTL(id int64, ttl *time.Duration) error {
+	if ttl == nil {
+		_, err := r.newStatement().
+			Delete(tableCache).
+			Where(sq.Eq{"fact_id": id}).
+			Exec()
+		return err
+	}
+
+	_, err := r.newStatement().
+		Insert(tableCache).
+		Columns("fact_id", "ttl_seconds", "last_modified").
+		Values(id, int64(ttl.Seconds()), time.Now().Truncate(1*time.Millisecond).UTC()).
+		Suffix("ON CONFLICT (fact_id) DO UPDATE SET ttl_seconds = EXCLUDED.ttl_seconds, last_modified = EXCLUDED.last_modified").
+		Exec()
+	return err
+}
+
+// GetSQLDefinition returns the SQL definition of a fact
+func (r *PostgresRepository) GetSQLDefinition(id int64) (SQLDefinition, bool, error) {
+	rows, err := r.newStatement().
+		Select("definition").
+		From(tableSQL).
+		Where(sq.Eq{"fact_id": id}).
+		Query()
+	if err != nil {
+		return SQLDefinition{}, false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return SQLDefinition{}, false, nil
+	}
+	var data []byte
+	if err := rows.Scan(&data); err != nil {
+		return SQLDefinition{}, false, err
+	}
+	var def SQLDefinition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return SQLDefinition{}, false, err
+	}
+	return def, true, nil
+}
+
+// GetSQLDefinitions returns the SQL definition of every SQL fact
+func (r *PostgresRepository) GetSQLDefinitions() (map[int64]SQLDefinition, error) {
+	rows, err := r.newStatement().
+		Select("fact_id", "definition").
+		From(tableSQL).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	defs := make(map[int64]SQLDefinition)
+	for rows.Next() {
+		var factID int64
+		var data []byte
+		if err := rows.Scan(&factID, &data); err != nil {
+			return nil, err
+		}
+		var def SQLDefinition
+		if err := json.Unmarshal(data, &def); err != nil {
+			return nil, err
+		}
+		defs[factID] = def
+	}
+	return defs, rows.Err()
+}
+
+// SetSQLDefinition sets the SQL definition of a fact (nil to remove it)
+func (r *PostgresRepository) SetSQLDefinition(id int64, def *SQLDefinition) error {
+	if def == nil {
+		_, err := r.newStatement().
+			Delete(tableSQL).
+			Where(sq.Eq{"fact_id": id}).
+			Exec()
+		return err
+	}
+
+	data, err := json.Marshal(def)
+	if err != nil {
+		return errors.New("couldn't marshall the provided data:" + err.Error())
+	}
+
+	_, err = r.newStatement().
+		Insert(tableSQL).
+		Columns("fact_id", "definition", "last_modified").
+		Values(id, string(data), time.Now().Truncate(1*time.Millisecond).UTC()).
+		Suffix("ON CONFLICT (fact_id) DO UPDATE SET definition = EXCLUDED.definition, last_modified = EXCLUDED.last_modified").
+		Exec()
+	return err
+}
+
 // newStatement creates a new statement builder with Dollar format
 func (r *PostgresRepository) newStatement() sq.StatementBuilderType {
 	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar).RunWith(r.conn.DB)
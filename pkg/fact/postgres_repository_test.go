@@ -2,6 +2,7 @@ package fact
 
 import (
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/tests"
@@ -12,11 +13,17 @@ func dbInit(dbClient *sqlx.DB, t *testing.T) {
 	dbDestroy(dbClient, t)
 
 	tests.DBExec(dbClient, tests.FactDefinitionTableV1, t, true)
+	tests.DBExec(dbClient, tests.FactParameterDefinitionTableV1, t, true)
+	tests.DBExec(dbClient, tests.FactCacheConfigTableV1, t, true)
+	tests.DBExec(dbClient, tests.FactSQLDefinitionTableV1, t, true)
 	tests.DBExec(dbClient, tests.FactHistoryTableV1, t, true)
 }
 
 func dbDestroy(dbClient *sqlx.DB, t *testing.T) {
 	tests.DBExec(dbClient, tests.FactHistoryDropTableV1, t, false)
+	tests.DBExec(dbClient, tests.FactSQLDefinitionDropTableV1, t, false)
+	tests.DBExec(dbClient, tests.FactCacheConfigDropTableV1, t, false)
+	tests.DBExec(dbClient, tests.FactParameterDefinitionDropTableV1, t, false)
 	tests.DBExec(dbClient, tests.FactDefinitionDropTableV1, t, false)
 }
 
@@ -281,3 +288,129 @@ func TestPostgresDeleteNotExists(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestPostgresParameterDefinitions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping postgresql test in short mode")
+	}
+	db := tests.DBClient(t)
+	defer dbDestroy(db, t)
+	dbInit(db, t)
+	r := NewPostgresRepository(db)
+
+	id, err := r.Create(engine.Fact{Name: "test_name", Comment: "test comment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defs, err := r.GetParameterDefinitions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 0 {
+		t.Fatalf("expected no parameter definitions, got %d", len(defs))
+	}
+
+	err = r.SetParameterDefinitions(id, ParameterDefinitions{
+		{Name: "carrier", Type: ParameterTypeString, Default: "DHL", AllowedValues: []interface{}{"DHL", "UPS"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defs, err = r.GetParameterDefinitions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 1 || defs[0].Name != "carrier" || defs[0].Default != "DHL" {
+		t.Errorf("unexpected parameter definitions %+v", defs)
+	}
+
+	err = r.SetParameterDefinitions(id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs, err = r.GetParameterDefinitions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 0 {
+		t.Errorf("expected parameter definitions to be removed, got %+v", defs)
+	}
+}
+
+func TestPostgresCacheTTL(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping postgresql test in short mode")
+	}
+	db := tests.DBClient(t)
+	defer dbDestroy(db, t)
+	dbInit(db, t)
+	r := NewPostgresRepository(db)
+
+	id, err := r.Create(engine.Fact{Name: "test_name", Comment: "test comment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ttl := 5 * time.Minute
+	if err = r.SetCacheTTL(id, &ttl); err != nil {
+		t.Fatal(err)
+	}
+	ttls, err := r.GetCacheTTLs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttls[id] != ttl {
+		t.Errorf("expected ttl %s, got %s", ttl, ttls[id])
+	}
+
+	if err = r.SetCacheTTL(id, nil); err != nil {
+		t.Fatal(err)
+	}
+	ttls, err = r.GetCacheTTLs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ttls[id]; ok {
+		t.Error("expected ttl to be removed")
+	}
+}
+
+func TestPostgresSQLDefinition(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping postgresql test in short mode")
+	}
+	db := tests.DBClient(t)
+	defer dbDestroy(db, t)
+	dbInit(db, t)
+	r := NewPostgresRepository(db)
+
+	id, err := r.Create(engine.Fact{Name: "test_name", Comment: "test comment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found, err := r.GetSQLDefinition(id); err != nil || found {
+		t.Fatalf("expected no sql definition, got found=%t err=%v", found, err)
+	}
+
+	err = r.SetSQLDefinition(id, &SQLDefinition{Connection: "default", Query: "SELECT 1 AS value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, found, err := r.GetSQLDefinition(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || def.Query != "SELECT 1 AS value" {
+		t.Errorf("unexpected sql definition %+v", def)
+	}
+
+	if err = r.SetSQLDefinition(id, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := r.GetSQLDefinition(id); found {
+		t.Error("expected sql definition to be removed")
+	}
+}
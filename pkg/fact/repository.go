@@ -2,6 +2,7 @@ package fact
 
 import (
 	"sync"
+	"time"
 
 	"github.com/myrteametrics/myrtea-sdk/v5/engine"
 )
@@ -17,6 +18,16 @@ type Repository interface {
 	Delete(id int64) error
 	GetAll() (map[int64]engine.Fact, error)
 	GetAllByIDs(ids []int64) (map[int64]engine.Fact, error)
+
+	GetParameterDefinitions(id int64) (ParameterDefinitions, error)
+	SetParameterDefinitions(id int64, defs ParameterDefinitions) error
+
+	GetCacheTTLs() (map[int64]time.Duration, error)
+	SetCacheTTL(id int64, ttl *time.Duration) error
+
+	GetSQLDefinition(id int64) (SQLDefinition, bool, error)
+	GetSQLDefinitions() (map[int64]SQLDefinition, error)
+	SetSQLDefinition(id int64, def *SQLDefinition) error
 }
 
 var (
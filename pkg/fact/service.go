@@ -20,14 +20,44 @@ import (
 )
 
 // ExecuteFact executes a fact and returns the result
+// The parameters must have been resolved beforehand with ResolveParameters
 func ExecuteFact(
 	ti time.Time,
 	f engine.Fact, situationID int64, situationInstanceID int64, parameters map[string]interface{},
 	nhit int, offset int, update bool,
 ) (*reader.WidgetData, error) {
 
+	if err := CheckHitsLimit(f, nhit); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if sqlDefinition, isSQL := GetSQLDefinition(f.ID); isSQL {
+		widgetData, err := ExecuteSQLFact(context.Background(), ti, f, sqlDefinition, parameters, nhit, offset)
+		if err != nil {
+			return nil, err
+		}
+		GetBaselineValues(widgetData, f.ID, situationID, situationInstanceID, ti)
+		return widgetData, nil
+	}
+
+	indices := FindIndices(f, ti, update)
+
+	// The cache key is built on the raw definition, before its contextualization on ti
+	cacheTTL := Cache().TTL(f.ID)
+	cacheKey := ""
+	if cacheTTL > 0 {
+		cacheKey, err = Cache().Key(f, ti, cacheTTL, parameters, indices, nhit, offset)
+		if err != nil {
+			zap.L().Warn("Cannot build fact cache key", zap.Int64("factID", f.ID), zap.Error(err))
+		} else if widgetData, found := Cache().Get(f.ID, cacheKey); found {
+			GetBaselineValues(widgetData, f.ID, situationID, situationInstanceID, ti)
+			return widgetData, nil
+		}
+	}
+
 	f.ContextualizeDimensions(ti)
-	err := f.ContextualizeCondition(ti, parameters)
+	err = f.ContextualizeCondition(ti, parameters)
 	if err != nil {
 		return nil, err
 	}
@@ -39,7 +69,6 @@ func ExecuteFact(
 	}
 	searchRequest.TrackTotalHits = true
 
-	indices := FindIndices(f, ti, update)
 	zap.L().Debug("search", zap.Strings("indices", indices), zap.Any("request", searchRequest))
 
 	response, err := elasticsearch.C().Search().
@@ -62,6 +91,10 @@ func ExecuteFact(
 		return nil, err
 	}
 
+	if cacheKey != "" {
+		Cache().Set(f.ID, cacheKey, widgetData, cacheTTL)
+	}
+
 	GetBaselineValues(widgetData, f.ID, situationID, situationInstanceID, ti)
 
 	return widgetData, nil
@@ -127,7 +160,9 @@ func FindIndices(f engine.Fact, ti time.Time, update bool) []string {
 	var indices []string
 	var err error
 
-	if update {
+	if coordinator.GetInstance() == nil || coordinator.GetInstance().LogicalIndex(f.Model) == nil {
+		err = fmt.Errorf("no logical index found for model %s", f.Model)
+	} else if update {
 		indices, err = coordinator.GetInstance().LogicalIndex(f.Model).FindIndices(time.Now(),
 			f.CalculationDepth+int64(time.Now().Sub(ti).Hours()/24)+5)
 	} else {
@@ -0,0 +1,296 @@
+package fact
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/reader"
+	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+	"go.uber.org/zap"
+)
+
+// defaultSQLTimeout is the statement timeout applied when the SQL definition has none
+const defaultSQLTimeout = 30 * time.Second
+
+var readOnlyQueryPattern = regexp.MustCompile(`(?is)^\s*(select|with)\b`)
+
+// SQLDefinition makes a fact execute a read-only SQL query instead of an elasticsearch query
+//
+// The query runs on one of the connections explicitly configured for the SQL facts, never on the engine database.
+// The query uses named parameters (ie. ":carrier") bound to the fact parameters, plus the reserved
+// parameters ":ti" (execution time) and ":from" (execution time minus the fact calculation depth).
+// Without KeyColumn, the first row columns are mapped to the fact aggregates. With KeyColumn, each row
+// is mapped to a bucket (named BucketName, or KeyColumn by default) keyed by this column.
+type SQLDefinition struct {
+	Connection string `json:"connection"`
+	Query      string `json:"query"`
+	KeyColumn  string `json:"keyColumn,omitempty"`
+	BucketName string `json:"bucketName,omitempty"`
+	Timeout    string `json:"timeout,omitempty"`
+}
+
+// SQLConnectionConfig is the configuration of an additional SQL connection usable by SQL facts
+type SQLConnectionConfig struct {
+	Name     string `mapstructure:"name"`
+	URL      string `mapstructure:"url"`
+	Port     string `mapstructure:"port"`
+	DbName   string `mapstructure:"dbname"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+}
+
+var (
+	_sqlConnectionsMu sync.RWMutex
+	_sqlConnections   = make(map[string]*sqlx.DB)
+
+	_sqlDefinitionsMu sync.RWMutex
+	_sqlDefinitions   = make(map[int64]SQLDefinition)
+)
+
+// RegisterSQLConnection registers a connection usable by SQL facts
+func RegisterSQLConnection(name string, db *sqlx.DB) {
+	_sqlConnectionsMu.Lock()
+	defer _sqlConnectionsMu.Unlock()
+
+	_sqlConnections[name] = db
+}
+
+// GetSQLConnection returns a registered SQL connection
+func GetSQLConnection(name string) (*sqlx.DB, bool) {
+	_sqlConnectionsMu.RLock()
+	defer _sqlConnectionsMu.RUnlock()
+
+	db, ok := _sqlConnections[name]
+	return db, ok
+}
+
+// GetSQLConnectionNames returns the names of the registered SQL connections
+func GetSQLConnectionNames() []string {
+	_sqlConnectionsMu.RLock()
+	defer _sqlConnectionsMu.RUnlock()
+
+	names := make([]string, 0, len(_sqlConnections))
+	for name := range _sqlConnections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsValid checks if a SQL definition is valid and only contains a single read-only statement
+func (def SQLDefinition) IsValid() (bool, error) {
+	if def.Connection == "" {
+		return false, errors.New("missing Connection")
+	}
+	if strings.TrimSpace(def.Query) == "" {
+		return false, errors.New("missing Query")
+	}
+	if !readOnlyQueryPattern.MatchString(def.Query) {
+		return false, errors.New("query must be a SELECT or a WITH statement")
+	}
+	if strings.Contains(strings.TrimRight(strings.TrimSpace(def.Query), ";"), ";") {
+		return false, errors.New("query must contain a single statement")
+	}
+	if def.Timeout != "" {
+		if _, err := time.ParseDuration(def.Timeout); err != nil {
+			return false, fmt.Errorf("invalid Timeout: %s", err.Error())
+		}
+	}
+	return true, nil
+}
+
+func (def SQLDefinition) timeout() time.Duration {
+	if def.Timeout == "" {
+		return defaultSQLTimeout
+	}
+	timeout, err := time.ParseDuration(def.Timeout)
+	if err != nil || timeout <= 0 {
+		return defaultSQLTimeout
+	}
+	return timeout
+}
+
+// LoadSQLDefinitions replaces the SQL definitions kept in memory, so that the fact executions do not query them
+func LoadSQLDefinitions(defs map[int64]SQLDefinition) {
+	_sqlDefinitionsMu.Lock()
+	defer _sqlDefinitionsMu.Unlock()
+
+	_sqlDefinitions = make(map[int64]SQLDefinition, len(defs))
+	for factID, def := range defs {
+		_sqlDefinitions[factID] = def
+	}
+}
+
+// SetSQLDefinitionInMemory sets the SQL definition kept in memory of a fact (nil to remove it)
+func SetSQLDefinitionInMemory(factID int64, def *SQLDefinition) {
+	_sqlDefinitionsMu.Lock()
+	defer _sqlDefinitionsMu.Unlock()
+
+	if def == nil {
+		delete(_sqlDefinitions, factID)
+		return
+	}
+	_sqlDefinitions[factID] = *def
+}
+
+// GetSQLDefinition returns the SQL definition of a fact, if the fact is a SQL fact
+// The definitions are read from memory (see LoadSQLDefinitions), a fact execution never queries them
+func GetSQLDefinition(factID int64) (SQLDefinition, bool) {
+	_sqlDefinitionsMu.RLock()
+	defer _sqlDefinitionsMu.RUnlock()
+
+	def, found := _sqlDefinitions[factID]
+	return def, found
+}
+
+// ExecuteSQLFact executes the SQL query of a fact in a read-only transaction and maps the result in a WidgetData
+func ExecuteSQLFact(ctx context.Context, ti time.Time, f engine.Fact, def SQLDefinition, parameters map[string]interface{},
+	nhit int, offset int) (*reader.WidgetData, error) {
+
+	if ok, err := def.IsValid(); !ok {
+		return nil, err
+	}
+
+	db, ok := GetSQLConnection(def.Connection)
+	if !ok {
+		return nil, fmt.Errorf("sql connection %s not found", def.Connection)
+	}
+
+	args := make(map[string]interface{}, len(parameters)+2)
+	for key, value := range parameters {
+		args[key] = value
+	}
+	args["ti"] = ti
+	args["from"] = ti.Add(-time.Duration(f.CalculationDepth) * 24 * time.Hour)
+
+	query, values, err := sqlx.Named(def.Query, args)
+	if err != nil {
+		return nil, fmt.Errorf("cannot bind the sql fact parameters: %w", err)
+	}
+	query = db.Rebind(query)
+
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "SET LOCAL statement_timeout = "+strconv.FormatInt(def.timeout().Milliseconds(), 10))
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Debug("sql search", zap.String("connection", def.Connection), zap.String("query", query), zap.Any("args", values))
+
+	rows, err := tx.QueryxContext(ctx, query, values...)
+	if err != nil {
+		zap.L().Error("SQL fact query failed", zap.String("fact", f.Name), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		row := make(map[string]interface{}, len(columns))
+		if err := rows.MapScan(row); err != nil {
+			return nil, err
+		}
+		for key, value := range row {
+			row[key] = normalizeSQLValue(value)
+		}
+		results = append(results, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return buildSQLWidgetData(def, columns, results, nhit, offset), nil
+}
+
+// buildSQLWidgetData maps the rows returned by a SQL fact in the standard fact result format
+func buildSQLWidgetData(def SQLDefinition, columns []string, rows []map[string]interface{}, nhit int, offset int) *reader.WidgetData {
+	widgetData := &reader.WidgetData{
+		Hits:       make([]reader.Hit, 0),
+		Aggregates: &reader.Item{Aggs: make(map[string]*reader.ItemAgg)},
+	}
+
+	if nhit > 0 && offset < len(rows) {
+		end := offset + nhit
+		if end > len(rows) {
+			end = len(rows)
+		}
+		for i := offset; i < end; i++ {
+			id := strconv.Itoa(i)
+			if value, ok := rows[i]["id"]; ok && value != nil {
+				id = fmt.Sprint(value)
+			}
+			widgetData.Hits = append(widgetData.Hits, reader.Hit{ID: id, Fields: rows[i]})
+		}
+	}
+
+	if def.KeyColumn == "" {
+		if len(rows) > 0 {
+			for _, column := range columns {
+				widgetData.Aggregates.Aggs[column] = &reader.ItemAgg{Value: rows[0][column]}
+			}
+		}
+	} else {
+		bucketName := def.BucketName
+		if bucketName == "" {
+			bucketName = def.KeyColumn
+		}
+		buckets := make([]*reader.Item, 0, len(rows))
+		for _, row := range rows {
+			item := &reader.Item{Aggs: make(map[string]*reader.ItemAgg)}
+			switch key := row[def.KeyColumn].(type) {
+			case time.Time:
+				item.Key = key.Format(time.RFC3339)
+			case nil:
+				item.Key = ""
+			default:
+				item.Key = fmt.Sprint(key)
+			}
+			for _, column := range columns {
+				if column != def.KeyColumn {
+					item.Aggs[column] = &reader.ItemAgg{Value: row[column]}
+				}
+			}
+			buckets = append(buckets, item)
+		}
+		widgetData.Aggregates.Buckets = map[string][]*reader.Item{bucketName: buckets}
+	}
+
+	if _, ok := widgetData.Aggregates.Aggs["doc_count"]; !ok {
+		reader.EnrichWithTotalHits(widgetData.Aggregates, int64(len(rows)))
+	}
+
+	return widgetData
+}
+
+// normalizeSQLValue converts the raw values returned by the driver (ie. numeric as []byte) in plain values
+func normalizeSQLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		s := string(v)
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+		return s
+	default:
+		return v
+	}
+}
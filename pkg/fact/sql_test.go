@@ -0,0 +1,148 @@
+package fact
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/tests"
+	"github.com/myrteametrics/myrtea-sdk/v5/engine"
+)
+
+func TestSQLDefinitionIsValid(t *testing.T) {
+	valid := []SQLDefinition{
+		{Connection: "reporting", Query: "SELECT count(*) AS total FROM reporting"},
+		{Connection: "reporting", Query: "  with t as (select 1 as v) select v from t;", Timeout: "5s"},
+	}
+	for _, def := range valid {
+		if ok, err := def.IsValid(); !ok {
+			t.Errorf("definition %+v should be valid: %v", def, err)
+		}
+	}
+
+	invalid := []SQLDefinition{
+		{},
+		{Query: "SELECT 1"},
+		{Connection: "reporting", Query: "DELETE FROM reporting"},
+		{Connection: "reporting", Query: "SELECT 1; DROP TABLE reporting"},
+		{Connection: "reporting", Query: "SELECT 1", Timeout: "abc"},
+	}
+	for _, def := range invalid {
+		if ok, _ := def.IsValid(); ok {
+			t.Errorf("definition %+v should be invalid", def)
+		}
+	}
+}
+
+func TestSQLDefinitionsInMemory(t *testing.T) {
+	LoadSQLDefinitions(map[int64]SQLDefinition{1: {Connection: "reporting", Query: "SELECT 1"}})
+	defer LoadSQLDefinitions(nil)
+
+	if def, found := GetSQLDefinition(1); !found || def.Connection != "reporting" {
+		t.Errorf("expected the loaded sql definition, got %+v %v", def, found)
+	}
+	if _, found := GetSQLDefinition(2); found {
+		t.Error("expected no sql definition for a non-sql fact")
+	}
+
+	SetSQLDefinitionInMemory(2, &SQLDefinition{Connection: "reporting", Query: "SELECT 2"})
+	SetSQLDefinitionInMemory(1, nil)
+	if _, found := GetSQLDefinition(1); found {
+		t.Error("expected the removed sql definition to be forgotten")
+	}
+	if _, found := GetSQLDefinition(2); !found {
+		t.Error("expected the set sql definition")
+	}
+}
+
+func TestBuildSQLWidgetData(t *testing.T) {
+	columns := []string{"carrier", "total"}
+	rows := []map[string]interface{}{
+		{"carrier": "DHL", "total": int64(10)},
+		{"carrier": "UPS", "total": int64(5)},
+	}
+
+	data := buildSQLWidgetData(SQLDefinition{}, columns, rows, 0, 0)
+	if len(data.Hits) != 0 {
+		t.Errorf("no hits expected, got %d", len(data.Hits))
+	}
+	if data.Aggregates.Aggs["total"].Value != int64(10) {
+		t.Errorf("unexpected total %v", data.Aggregates.Aggs["total"].Value)
+	}
+	if data.Aggregates.Aggs["doc_count"].Value != int64(2) {
+		t.Errorf("unexpected doc_count %v", data.Aggregates.Aggs["doc_count"].Value)
+	}
+
+	data = buildSQLWidgetData(SQLDefinition{KeyColumn: "carrier"}, columns, rows, 1, 1)
+	buckets := data.Aggregates.Buckets["carrier"]
+	if len(buckets) != 2 || buckets[1].Key != "UPS" || buckets[1].Aggs["total"].Value != int64(5) {
+		t.Errorf("unexpected buckets %+v", buckets)
+	}
+	if _, ok := buckets[0].Aggs["carrier"]; ok {
+		t.Error("key column should not be mapped as an aggregate")
+	}
+	if len(data.Hits) != 1 || data.Hits[0].Fields["carrier"] != "UPS" {
+		t.Errorf("unexpected hits %+v", data.Hits)
+	}
+}
+
+func TestNormalizeSQLValue(t *testing.T) {
+	if v := normalizeSQLValue([]byte("12.5")); v != 12.5 {
+		t.Errorf("expected 12.5, got %v", v)
+	}
+	if v := normalizeSQLValue([]byte("abc")); v != "abc" {
+		t.Errorf("expected abc, got %v", v)
+	}
+	if v := normalizeSQLValue(int64(3)); v != int64(3) {
+		t.Errorf("expected 3, got %v", v)
+	}
+}
+
+func TestPostgresExecuteSQLFact(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping postgresql test in short mode")
+	}
+	db := tests.DBClient(t)
+	tests.DBExec(db, `DROP TABLE IF EXISTS fact_sql_test_reporting_v1;`, t, false)
+	defer tests.DBExec(db, `DROP TABLE IF EXISTS fact_sql_test_reporting_v1;`, t, false)
+	tests.DBExec(db, `create table fact_sql_test_reporting_v1 (carrier varchar(100), amount numeric, ts timestamptz);`, t, true)
+	tests.DBExec(db, `insert into fact_sql_test_reporting_v1 values
+		('DHL', 10.5, now() - interval '1 hour'), ('DHL', 4.5, now() - interval '2 hour'),
+		('UPS', 3, now() - interval '1 hour'), ('UPS', 100, now() - interval '10 day');`, t, true)
+
+	RegisterSQLConnection("test", db)
+
+	f := engine.Fact{Name: "sql_fact", CalculationDepth: 1}
+	def := SQLDefinition{
+		Connection: "test",
+		Query: `SELECT carrier, sum(amount) AS total FROM fact_sql_test_reporting_v1
+			WHERE ts BETWEEN :from AND :ti AND carrier = ANY(string_to_array(:carriers, ','))
+			GROUP BY carrier ORDER BY carrier`,
+		KeyColumn: "carrier",
+	}
+
+	data, err := ExecuteSQLFact(context.Background(), time.Now(), f, def, map[string]interface{}{"carriers": "DHL,UPS"}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buckets := data.Aggregates.Buckets["carrier"]
+	if len(buckets) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(buckets))
+	}
+	if buckets[0].Key != "DHL" || buckets[0].Aggs["total"].Value != 15.0 {
+		t.Errorf("unexpected bucket %+v", buckets[0])
+	}
+	if buckets[1].Key != "UPS" || buckets[1].Aggs["total"].Value != 3.0 {
+		t.Errorf("unexpected bucket %+v", buckets[1])
+	}
+
+	_, err = ExecuteSQLFact(context.Background(), time.Now(), f, def, map[string]interface{}{}, 0, 0)
+	if err == nil {
+		t.Error("missing parameters should return an error")
+	}
+
+	def = SQLDefinition{Connection: "test", Query: `WITH d AS (DELETE FROM fact_sql_test_reporting_v1 RETURNING 1) SELECT count(*) FROM d`}
+	if _, err = ExecuteSQLFact(context.Background(), time.Now(), f, def, nil, 0, 0); err == nil {
+		t.Error("data-modifying queries should be refused by the read-only transaction")
+	}
+}
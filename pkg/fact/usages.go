@@ -0,0 +1,69 @@
+package fact
+
+import (
+	"regexp"
+)
+
+// Usage is a reference to a resource using a fact
+type Usage struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// TemplateInstanceUsage is a reference to a situation template instance using a fact
+type TemplateInstanceUsage struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	SituationID   int64  `json:"situationId"`
+	SituationName string `json:"situationName"`
+}
+
+// ExpressionFactUsage is a reference to an expression fact referencing a fact by its name
+type ExpressionFactUsage struct {
+	SituationID   int64  `json:"situationId"`
+	SituationName string `json:"situationName"`
+	Name          string `json:"name"`
+	Expression    string `json:"expression"`
+}
+
+// Usages lists every resource depending on a fact
+// Exports are not listed: they are one-off requests (the fact definitions are copied in the export queue), and no
+// export template bound to a fact is stored
+type Usages struct {
+	FactID            int64                   `json:"factId"`
+	FactName          string                  `json:"factName"`
+	Situations        []Usage                 `json:"situations"`
+	TemplateInstances []TemplateInstanceUsage `json:"templateInstances"`
+	ExpressionFacts   []ExpressionFactUsage   `json:"expressionFacts"`
+	Rules             []Usage                 `json:"rules"`
+	Schedules         []Usage                 `json:"schedules"`
+}
+
+// NewUsages returns an empty usage report for a fact
+func NewUsages(factID int64, factName string) Usages {
+	return Usages{
+		FactID:            factID,
+		FactName:          factName,
+		Situations:        make([]Usage, 0),
+		TemplateInstances: make([]TemplateInstanceUsage, 0),
+		ExpressionFacts:   make([]ExpressionFactUsage, 0),
+		Rules:             make([]Usage, 0),
+		Schedules:         make([]Usage, 0),
+	}
+}
+
+// InUse returns true if at least one resource depends on the fact
+func (u Usages) InUse() bool {
+	return len(u.Situations) > 0 || len(u.TemplateInstances) > 0 || len(u.ExpressionFacts) > 0 ||
+		len(u.Rules) > 0 || len(u.Schedules) > 0
+}
+
+// NameMatcher returns a function reporting whether an expression references a fact by its name
+// The name must appear as a whole identifier (ie. "count" does not match "count_total")
+func NameMatcher(name string) func(expression string) bool {
+	if name == "" {
+		return func(string) bool { return false }
+	}
+	re := regexp.MustCompile(`(^|[^A-Za-z0-9_])` + regexp.QuoteMeta(name) + `($|[^A-Za-z0-9_])`)
+	return re.MatchString
+}
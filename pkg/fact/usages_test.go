@@ -0,0 +1,47 @@
+package fact
+
+import "testing"
+
+func TestNameMatcher(t *testing.T) {
+	match := NameMatcher("fact_count")
+
+	matching := []string{
+		"fact_count",
+		"fact_count > 10",
+		"(fact_count.aggs.doc_count.value / 2)",
+		"other + fact_count",
+		`length(fact_count)`,
+	}
+	for _, expression := range matching {
+		if !match(expression) {
+			t.Errorf("expression %q should reference the fact", expression)
+		}
+	}
+
+	notMatching := []string{
+		"",
+		"fact_count_total > 10",
+		"my_fact_count",
+		"fact",
+	}
+	for _, expression := range notMatching {
+		if match(expression) {
+			t.Errorf("expression %q should not reference the fact", expression)
+		}
+	}
+
+	if NameMatcher("")("anything") {
+		t.Error("an empty name should never match")
+	}
+}
+
+func TestUsagesInUse(t *testing.T) {
+	usages := NewUsages(1, "test")
+	if usages.InUse() {
+		t.Error("empty usages should not be in use")
+	}
+	usages.Schedules = append(usages.Schedules, Usage{ID: 1, Name: "schedule"})
+	if !usages.InUse() {
+		t.Error("usages should be in use")
+	}
+}
@@ -11,9 +11,9 @@ import (
 
 // GetBaselineValues fetches the baseline values for a given fact ID and situation instance ID.
 func GetBaselineValues(widgetData *reader.WidgetData, factId int64, situationID int64, situationInstanceID int64, ti time.Time) {
-	pluginBaseline, err := baseline.P()
+	baselineService, err := baseline.S()
 	if err == nil {
-		values, err := pluginBaseline.BaselineService.GetBaselineValues(-1, factId, situationID, situationInstanceID, ti)
+		values, err := baselineService.GetBaselineValues(-1, factId, situationID, situationInstanceID, ti)
 		if err != nil {
 			zap.L().Error("Cannot fetch fact baselines", zap.Int64("id", factId), zap.Error(err))
 			return
@@ -802,6 +802,27 @@ func (r *PostgresRepository) GetSituations(fsID int64) ([]int64, error) {
 	return ids, rows.Err()
 }
 
+// GetIDsBySituation retrieves the IDs of the functional situations containing a situation or one of its template instances (0 if none)
+func (r *PostgresRepository) GetIDsBySituation(situationID int64, instanceID int64) ([]int64, error) {
+	rows, err := r.conn.Query(`SELECT functional_situation_id FROM `+tableInstances+` WHERE template_instance_id = $1
+		UNION SELECT functional_situation_id FROM `+tableSituations+` WHERE situation_id = $2
+		ORDER BY functional_situation_id`, instanceID, situationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // GetSituationsWithParameters retrieves all situation IDs with their parameters for a functional situation in one query
 func (r *PostgresRepository) GetSituationsWithParameters(fsID int64) (map[int64]map[string]interface{}, error) {
 	rows, err := r.newStatement().
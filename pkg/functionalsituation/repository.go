@@ -39,6 +39,9 @@ type Repository interface {
 
 	// Enriched tree with instances and situations
 	GetEnrichedTree() ([]FunctionalSituationTreeNode, error)
+
+	// Memberships of a situation and of its template instance
+	GetIDsBySituation(situationID int64, instanceID int64) ([]int64, error)
 }
 
 var (
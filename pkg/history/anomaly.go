@@ -0,0 +1,313 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	pluginbaseline "github.com/myrteametrics/myrtea-engine-api/v5/pkg/plugins/baseline"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/reader"
+	"go.uber.org/zap"
+)
+
+// Names of the anomaly detection functions available in the rules knowledge base
+const (
+	FunctionZScore              = "zscore"
+	FunctionWeekChange          = "weekChange"
+	FunctionMovingAverage       = "movingAverage"
+	FunctionConsecutiveBreaches = "consecutiveBreaches"
+)
+
+// anomalyLookback bounds the history read by the moving average and consecutive breaches functions
+const anomalyLookback = 31 * 24 * time.Hour
+
+// weekChangeTolerance is the maximum age of the value used as "the same time last week"
+const weekChangeTolerance = 24 * time.Hour
+
+// anomalyContext is the situation instance evaluated by the rules, used to resolve the history fed to the anomaly functions
+type anomalyContext struct {
+	service             HistoryService
+	situationID         int64
+	situationInstanceID int64
+	ts                  time.Time
+	facts               map[string]HistoryFactsV4
+}
+
+// AnomalyFunctions returns the anomaly detection functions to add to the knowledge base of a situation instance
+// Each function takes a fact name as first argument and is computed from the current fact values and the fact history:
+//   - zscore("fact"[, "baseline"]) is the number of standard deviations between the current value and the baseline average
+//   - weekChange("fact") is the percent change between the current value and the value of the same time last week
+//   - movingAverage("fact", n) is the average of the current value and the n-1 previous values
+//   - consecutiveBreaches("fact", threshold, n) is true if the current value and the n-1 previous values are all above threshold
+func (service HistoryService) AnomalyFunctions(situationID int64, situationInstanceID int64, ts time.Time, historyFacts []HistoryFactsV4) map[string]interface{} {
+	ctx := anomalyContext{
+		service:             service,
+		situationID:         situationID,
+		situationInstanceID: situationInstanceID,
+		ts:                  ts,
+		facts:               make(map[string]HistoryFactsV4),
+	}
+	for _, historyFact := range historyFacts {
+		ctx.facts[historyFact.FactName] = historyFact
+	}
+
+	return map[string]interface{}{
+		FunctionZScore:              ctx.zscore,
+		FunctionWeekChange:          ctx.weekChange,
+		FunctionMovingAverage:       ctx.movingAverage,
+		FunctionConsecutiveBreaches: ctx.consecutiveBreaches,
+	}
+}
+
+// RuleKnowledgeBase returns a copy of a situation instance knowledge base with the anomaly detection functions
+// and the facts forecasts (if enabled by the situation parameters) added
+// They are kept out of the original data, which is reused in the tasks context
+func (service HistoryService) RuleKnowledgeBase(data map[string]interface{}, situationID int64, situationInstanceID int64, ts time.Time, historyFacts []HistoryFactsV4) map[string]interface{} {
+	knowledgeBase := make(map[string]interface{}, len(data)+5)
+	for key, value := range service.AnomalyFunctions(situationID, situationInstanceID, ts, historyFacts) {
+		knowledgeBase[key] = value
+	}
+	forecasts, err := service.forecastVariables(data, situationID, situationInstanceID, historyFacts)
+	if err != nil {
+		zap.L().Error("Cannot forecast the situation facts", zap.Int64("situationID", situationID), zap.Int64("situationInstanceID", situationInstanceID), zap.Error(err))
+	} else if forecasts != nil {
+		knowledgeBase[ForecastVariable] = forecasts
+	}
+	for key, value := range data {
+		knowledgeBase[key] = value
+	}
+	return knowledgeBase
+}
+
+func (ctx anomalyContext) zscore(arguments ...interface{}) (interface{}, error) {
+	if len(arguments) < 1 || len(arguments) > 2 {
+		return nil, fmt.Errorf("%s() expects a fact name and an optional baseline name", FunctionZScore)
+	}
+	fact, value, err := ctx.current(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	baselineName := ""
+	if len(arguments) == 2 {
+		if baselineName, err = stringArgument(arguments[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	service, err := pluginbaseline.S()
+	if err != nil {
+		return nil, err
+	}
+	values, err := service.GetBaselineValues(-1, fact.FactID, ctx.situationID, ctx.situationInstanceID, ctx.ts)
+	if err != nil {
+		return nil, err
+	}
+	baselineValue, ok := selectBaselineValue(values, baselineName)
+	if !ok {
+		return nil, fmt.Errorf("no baseline value found for fact %s", fact.FactName)
+	}
+	return zScore(value, baselineValue.Avg, baselineValue.Std)
+}
+
+func (ctx anomalyContext) weekChange(arguments ...interface{}) (interface{}, error) {
+	if len(arguments) != 1 {
+		return nil, fmt.Errorf("%s() expects a fact name", FunctionWeekChange)
+	}
+	fact, value, err := ctx.current(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	lastWeek := ctx.ts.AddDate(0, 0, -7)
+	previous, err := ctx.previousValues(fact, lastWeek.Add(-weekChangeTolerance), lastWeek.Add(time.Second), 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(previous) == 0 {
+		return nil, fmt.Errorf("no history value found for fact %s last week", fact.FactName)
+	}
+	return percentChange(value, previous[0])
+}
+
+func (ctx anomalyContext) movingAverage(arguments ...interface{}) (interface{}, error) {
+	if len(arguments) != 2 {
+		return nil, fmt.Errorf("%s() expects a fact name and a number of points", FunctionMovingAverage)
+	}
+	fact, value, err := ctx.current(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	n, err := countArgument(arguments[1])
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := ctx.previousValues(fact, ctx.ts.Add(-anomalyLookback), fact.Ts, uint64(n-1))
+	if err != nil {
+		return nil, err
+	}
+	return mean(append([]float64{value}, previous...)), nil
+}
+
+func (ctx anomalyContext) consecutiveBreaches(arguments ...interface{}) (interface{}, error) {
+	if len(arguments) != 3 {
+		return nil, fmt.Errorf("%s() expects a fact name, a threshold and a number of points", FunctionConsecutiveBreaches)
+	}
+	fact, value, err := ctx.current(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	threshold, ok := numberArgument(arguments[1])
+	if !ok {
+		return nil, fmt.Errorf("%s() threshold must be a number", FunctionConsecutiveBreaches)
+	}
+	n, err := countArgument(arguments[2])
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := ctx.previousValues(fact, ctx.ts.Add(-anomalyLookback), fact.Ts, uint64(n-1))
+	if err != nil {
+		return nil, err
+	}
+	return breaches(append([]float64{value}, previous...), threshold, n), nil
+}
+
+// current returns the fact named by the argument and its current value
+func (ctx anomalyContext) current(argument interface{}) (HistoryFactsV4, float64, error) {
+	name, err := stringArgument(argument)
+	if err != nil {
+		return HistoryFactsV4{}, 0, err
+	}
+	fact, ok := ctx.facts[name]
+	if !ok {
+		return HistoryFactsV4{}, 0, fmt.Errorf("fact %s is not part of the situation", name)
+	}
+	value, ok := factItemValue(fact.Result)
+	if !ok {
+		return HistoryFactsV4{}, 0, fmt.Errorf("fact %s has no numeric value", name)
+	}
+	return fact, value, nil
+}
+
+// previousValues returns at most limit history values of a fact in [from, before), the most recent first
+func (ctx anomalyContext) previousValues(fact HistoryFactsV4, from time.Time, before time.Time, limit uint64) ([]float64, error) {
+	if limit == 0 {
+		return []float64{}, nil
+	}
+	querier := ctx.service.HistoryFactsQuerier
+	values, err := querier.QueryFactValues(querier.Builder.GetLastFactResults(ctx.situationID, ctx.situationInstanceID, fact.FactID, from, before, limit))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]float64, 0, len(values))
+	for _, v := range values {
+		result = append(result, v.value)
+	}
+	return result, nil
+}
+
+// selectBaselineValue returns the value of the named baseline, or of the first baseline by name if no name is given
+func selectBaselineValue(values map[string]pluginbaseline.BaselineValue, name string) (pluginbaseline.BaselineValue, bool) {
+	if name != "" {
+		v, ok := values[name]
+		return v, ok
+	}
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	if len(names) == 0 {
+		return pluginbaseline.BaselineValue{}, false
+	}
+	sort.Strings(names)
+	return values[names[0]], true
+}
+
+func zScore(value float64, avg float64, std float64) (float64, error) {
+	if std == 0 {
+		return 0, errors.New("baseline standard deviation is zero")
+	}
+	return (value - avg) / std, nil
+}
+
+func percentChange(value float64, previous float64) (float64, error) {
+	if previous == 0 {
+		return 0, errors.New("previous value is zero")
+	}
+	return (value - previous) / math.Abs(previous) * 100, nil
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// breaches returns true if the n first values are all strictly above threshold
+func breaches(values []float64, threshold float64, n int) bool {
+	if len(values) < n {
+		return false
+	}
+	for _, v := range values[:n] {
+		if v <= threshold {
+			return false
+		}
+	}
+	return true
+}
+
+// factItemValue returns the numeric value of a fact result: its aggregation value, or its document count
+func factItemValue(item reader.Item) (float64, bool) {
+	var value, docCount interface{}
+	for k, v := range item.Aggs {
+		if v == nil {
+			continue
+		}
+		if k == "doc_count" {
+			docCount = v.Value
+		} else {
+			value = v.Value
+		}
+	}
+	if value == nil {
+		value = docCount
+	}
+	return numberArgument(value)
+}
+
+func stringArgument(argument interface{}) (string, error) {
+	s, ok := argument.(string)
+	if !ok {
+		return "", fmt.Errorf("expected a fact name, got %v", argument)
+	}
+	return s, nil
+}
+
+func numberArgument(argument interface{}) (float64, bool) {
+	switch v := argument.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func countArgument(argument interface{}) (int, error) {
+	v, ok := numberArgument(argument)
+	if !ok || v < 1 || v != math.Trunc(v) {
+		return 0, fmt.Errorf("expected a positive number of points, got %v", argument)
+	}
+	return int(v), nil
+}
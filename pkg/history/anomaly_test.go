@@ -0,0 +1,169 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	pluginbaseline "github.com/myrteametrics/myrtea-engine-api/v5/pkg/plugins/baseline"
+	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/reader"
+	"github.com/myrteametrics/myrtea-sdk/v5/expression"
+)
+
+func TestZScore(t *testing.T) {
+	z, err := zScore(16, 10, 2)
+	if err != nil || z != 3 {
+		t.Errorf("expected 3, got %v (%v)", z, err)
+	}
+	if _, err := zScore(16, 10, 0); err == nil {
+		t.Error("expected an error with a zero standard deviation")
+	}
+}
+
+func TestPercentChange(t *testing.T) {
+	p, err := percentChange(150, 100)
+	if err != nil || p != 50 {
+		t.Errorf("expected 50, got %v (%v)", p, err)
+	}
+	p, err = percentChange(-50, -100)
+	if err != nil || p != 50 {
+		t.Errorf("expected 50, got %v (%v)", p, err)
+	}
+	if _, err := percentChange(10, 0); err == nil {
+		t.Error("expected an error with a zero previous value")
+	}
+}
+
+func TestBreaches(t *testing.T) {
+	values := []float64{12, 11, 15, 3}
+	if !breaches(values, 10, 3) {
+		t.Error("expected 3 consecutive breaches")
+	}
+	if breaches(values, 10, 4) {
+		t.Error("expected no 4 consecutive breaches")
+	}
+	if breaches(values[:2], 10, 3) {
+		t.Error("expected no breaches without enough history")
+	}
+	if breaches([]float64{10}, 10, 1) {
+		t.Error("expected the threshold itself not to be a breach")
+	}
+}
+
+func TestMean(t *testing.T) {
+	if m := mean([]float64{1, 2, 6}); m != 3 {
+		t.Errorf("expected 3, got %v", m)
+	}
+	if m := mean(nil); m != 0 {
+		t.Errorf("expected 0, got %v", m)
+	}
+}
+
+func TestSelectBaselineValue(t *testing.T) {
+	values := map[string]pluginbaseline.BaselineValue{
+		"weekly": {Avg: 1},
+		"daily":  {Avg: 2},
+	}
+	if v, ok := selectBaselineValue(values, ""); !ok || v.Avg != 2 {
+		t.Errorf("expected the first baseline by name, got %+v", v)
+	}
+	if v, ok := selectBaselineValue(values, "weekly"); !ok || v.Avg != 1 {
+		t.Errorf("expected the weekly baseline, got %+v", v)
+	}
+	if _, ok := selectBaselineValue(values, "monthly"); ok {
+		t.Error("expected no monthly baseline")
+	}
+	if _, ok := selectBaselineValue(nil, ""); ok {
+		t.Error("expected no baseline")
+	}
+}
+
+func TestCountArgument(t *testing.T) {
+	if n, err := countArgument(3.0); err != nil || n != 3 {
+		t.Errorf("expected 3, got %v (%v)", n, err)
+	}
+	for _, invalid := range []interface{}{0.0, -1.0, 2.5, "3"} {
+		if _, err := countArgument(invalid); err == nil {
+			t.Errorf("expected %v to be invalid", invalid)
+		}
+	}
+}
+
+func TestAnomalyFunctionsArguments(t *testing.T) {
+	functions := HistoryService{}.AnomalyFunctions(1, 2, time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC), []HistoryFactsV4{
+		{FactID: 3, FactName: "late_parcels", Result: reader.Item{Aggs: map[string]*reader.ItemAgg{"doc_count": {Value: 12.0}}}},
+	})
+
+	movingAverage := functions[FunctionMovingAverage].(func(...interface{}) (interface{}, error))
+	if v, err := movingAverage("late_parcels", 1.0); err != nil || v != 12.0 {
+		t.Errorf("expected the current value, got %v (%v)", v, err)
+	}
+	if _, err := movingAverage("unknown", 1.0); err == nil {
+		t.Error("expected an error with an unknown fact")
+	}
+	if _, err := movingAverage("late_parcels"); err == nil {
+		t.Error("expected an error without a number of points")
+	}
+
+	consecutiveBreaches := functions[FunctionConsecutiveBreaches].(func(...interface{}) (interface{}, error))
+	if v, err := consecutiveBreaches("late_parcels", 10.0, 1.0); err != nil || v != true {
+		t.Errorf("expected a breach, got %v (%v)", v, err)
+	}
+	if v, err := consecutiveBreaches("late_parcels", 12.0, 1.0); err != nil || v != false {
+		t.Errorf("expected no breach, got %v (%v)", v, err)
+	}
+}
+
+// baselineService returns the same baseline values for every fact
+type baselineService struct {
+	pluginbaseline.BaselineService
+	values map[string]pluginbaseline.BaselineValue
+}
+
+func (s baselineService) GetBaselineValues(id int64, factID int64, situationID int64, situationInstanceID int64, ti time.Time) (map[string]pluginbaseline.BaselineValue, error) {
+	return s.values, nil
+}
+
+func TestAnomalyFunctionsInExpression(t *testing.T) {
+	defer pluginbaseline.RegisterBuiltin(baselineService{values: map[string]pluginbaseline.BaselineValue{
+		"daily": {Avg: 10, Std: 2},
+	}})()
+
+	knowledgeBase := HistoryService{}.RuleKnowledgeBase(map[string]interface{}{"threshold": 2.5}, 1, 2,
+		time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC), []HistoryFactsV4{
+			{FactID: 3, FactName: "late_parcels", Result: reader.Item{Aggs: map[string]*reader.ItemAgg{"doc_count": {Value: 16.0}}}},
+		})
+
+	for expr, expected := range map[string]interface{}{
+		`zscore("late_parcels")`:                                                      3.0,
+		`zscore("late_parcels", "daily") > threshold`:                                 true,
+		`movingAverage("late_parcels", 1) == 16`:                                      true,
+		`consecutiveBreaches("late_parcels", 20, 1)`:                                  false,
+		`zscore("late_parcels") > threshold && movingAverage("late_parcels", 1) > 10`: true,
+	} {
+		result, err := expression.Process(expression.LangEval, expr, knowledgeBase)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", expr, err)
+			continue
+		}
+		if result != expected {
+			t.Errorf("%s: expected %v, got %v", expr, expected, result)
+		}
+	}
+
+	if _, err := expression.Process(expression.LangEval, `zscore("unknown") > 2`, knowledgeBase); err == nil {
+		t.Error("expected an error with an unknown fact")
+	}
+}
+
+func TestFactItemValue(t *testing.T) {
+	item := reader.Item{Aggs: map[string]*reader.ItemAgg{"doc_count": {Value: 10.0}, "avg": {Value: 4.5}}}
+	if v, ok := factItemValue(item); !ok || v != 4.5 {
+		t.Errorf("expected the aggregation value, got %v (%v)", v, ok)
+	}
+	if _, ok := factItemValue(reader.Item{}); ok {
+		t.Error("expected no value")
+	}
+	if v, ok := factItemValue(reader.Item{Aggs: map[string]*reader.ItemAgg{"doc_count": {Value: int64(7)}}}); !ok || v != 7 {
+		t.Errorf("expected 7, got %v (%v)", v, ok)
+	}
+}
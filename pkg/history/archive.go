@@ -0,0 +1,333 @@
+package history
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+	"go.uber.org/zap"
+)
+
+const (
+	archiveDayLayout     = "2006-01-02"
+	archiveFileExtension = ".jsonl.gz"
+)
+
+// HistoryArchive is a day of history archived on disk
+type HistoryArchive struct {
+	Day          string     `json:"day"`
+	Size         int64      `json:"size"`
+	Rehydrated   bool       `json:"rehydrated"`
+	RehydratedAt *time.Time `json:"rehydratedAt,omitempty"`
+}
+
+// HistoryArchiveStore stores the history archives as compressed JSON Lines files, one directory per day (in UTC)
+// and one file per archived table: <Path>/2006-01-02/situation_history.jsonl.gz
+type HistoryArchiveStore struct {
+	Path string
+}
+
+func (store HistoryArchiveStore) file(day time.Time, table HistoryArchiveTable) string {
+	return filepath.Join(store.Path, day.UTC().Format(archiveDayLayout), table.Name+archiveFileExtension)
+}
+
+// List returns the archived days, in chronological order
+func (store HistoryArchiveStore) List() ([]HistoryArchive, error) {
+	archives := make([]HistoryArchive, 0)
+	entries, err := os.ReadDir(store.Path)
+	if os.IsNotExist(err) {
+		return archives, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(archiveDayLayout, entry.Name()); err != nil {
+			continue
+		}
+
+		files, err := os.ReadDir(filepath.Join(store.Path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		archive := HistoryArchive{Day: entry.Name()}
+		found := false
+		for _, f := range files {
+			if f.IsDir() || !strings.HasSuffix(f.Name(), archiveFileExtension) {
+				continue
+			}
+			info, err := f.Info()
+			if err != nil {
+				return nil, err
+			}
+			archive.Size += info.Size()
+			found = true
+		}
+		if found {
+			archives = append(archives, archive)
+		}
+	}
+
+	sort.Slice(archives, func(i, j int) bool { return archives[i].Day < archives[j].Day })
+	return archives, nil
+}
+
+// Open returns a reader of the decompressed archive of a table on a day (empty if the table had no row that day)
+func (store HistoryArchiveStore) Open(day time.Time, table HistoryArchiveTable) (io.ReadCloser, error) {
+	f, err := os.Open(store.file(day, table))
+	if os.IsNotExist(err) {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return archiveReader{Reader: gz, gz: gz, f: f}, nil
+}
+
+type archiveReader struct {
+	io.Reader
+	gz *gzip.Reader
+	f  *os.File
+}
+
+func (r archiveReader) Close() error {
+	err := r.gz.Close()
+	if ferr := r.f.Close(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// archiveWriter writes the rows of a table, received in chronological order, in temporary daily archive files
+// The files replace the existing archives on commit
+type archiveWriter struct {
+	store HistoryArchiveStore
+	table HistoryArchiveTable
+	day   time.Time
+	file  *os.File
+	gz    *gzip.Writer
+	files []string
+}
+
+func newArchiveWriter(store HistoryArchiveStore, table HistoryArchiveTable) *archiveWriter {
+	return &archiveWriter{store: store, table: table, files: make([]string, 0)}
+}
+
+// Write appends a row to the archive file of its day
+func (w *archiveWriter) Write(ts time.Time, row string) error {
+	day := truncateRollup(ts, RollupDay)
+	if w.file == nil || !day.Equal(w.day) {
+		if err := w.close(); err != nil {
+			return err
+		}
+		path := w.store.file(day, w.table)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+		f, err := os.Create(path + ".tmp")
+		if err != nil {
+			return err
+		}
+		w.day, w.file, w.gz = day, f, gzip.NewWriter(f)
+		w.files = append(w.files, path)
+	}
+
+	if _, err := io.WriteString(w.gz, row); err != nil {
+		return err
+	}
+	_, err := w.gz.Write([]byte{'\n'})
+	return err
+}
+
+func (w *archiveWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.gz.Close()
+	if ferr := w.file.Close(); err == nil {
+		err = ferr
+	}
+	w.file, w.gz = nil, nil
+	return err
+}
+
+func (w *archiveWriter) commit() error {
+	if err := w.close(); err != nil {
+		return err
+	}
+	for _, path := range w.files {
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return err
+		}
+	}
+	w.files = w.files[:0]
+	return nil
+}
+
+// abort removes the temporary files which have not been committed
+func (w *archiveWriter) abort() {
+	_ = w.close()
+	for _, path := range w.files {
+		_ = os.Remove(path + ".tmp")
+	}
+	w.files = w.files[:0]
+}
+
+// days returns the days written by the writer (before commit)
+func (w *archiveWriter) days() []string {
+	days := make([]string, 0, len(w.files))
+	for _, path := range w.files {
+		days = append(days, filepath.Base(filepath.Dir(path)))
+	}
+	return days
+}
+
+// ArchiveHistory writes the situation, fact and situation fact history of [from, to) in daily archive files,
+// replacing the existing archives of these days. A zero from archives all the history before to.
+// It returns the number of archived days
+func (service HistoryService) ArchiveHistory(from time.Time, to time.Time) (int, error) {
+	if service.HistoryArchiveStore.Path == "" {
+		return 0, errors.New("the history archive path is not configured")
+	}
+
+	writers := make([]*archiveWriter, 0, len(HistoryArchiveTables))
+	defer func() {
+		for _, w := range writers {
+			w.abort()
+		}
+	}()
+
+	days := make(map[string]bool)
+	for _, table := range HistoryArchiveTables {
+		w := newArchiveWriter(service.HistoryArchiveStore, table)
+		writers = append(writers, w)
+		if err := service.HistoryArchivesQuerier.Export(table, from, to, w.Write); err != nil {
+			return 0, err
+		}
+		if err := w.close(); err != nil {
+			return 0, err
+		}
+		for _, day := range w.days() {
+			days[day] = true
+		}
+	}
+
+	// The archives are only replaced once every table has been exported, so that a failure never leaves a partial day
+	for _, w := range writers {
+		if err := w.commit(); err != nil {
+			return 0, err
+		}
+	}
+
+	zap.L().Info("History archived", zap.Time("from", from), zap.Time("to", to), zap.Int("days", len(days)))
+	return len(days), nil
+}
+
+// ListArchives returns the archived days, and whether they are rehydrated
+func (service HistoryService) ListArchives() ([]HistoryArchive, error) {
+	archives, err := service.HistoryArchiveStore.List()
+	if err != nil {
+		return nil, err
+	}
+	rehydrated, err := service.HistoryArchivesQuerier.RehydratedDays()
+	if err != nil {
+		return nil, err
+	}
+	for i, archive := range archives {
+		if rehydratedAt, ok := rehydrated[archive.Day]; ok {
+			archives[i].Rehydrated = true
+			archives[i].RehydratedAt = &rehydratedAt
+		}
+	}
+	return archives, nil
+}
+
+// RehydrateArchives loads the archived days of [from, to) which are not rehydrated yet in the archive tables,
+// where they can be queried with SearchArchives. It returns the number of rehydrated days
+func (service HistoryService) RehydrateArchives(from time.Time, to time.Time) (int, error) {
+	archives, err := service.ListArchives()
+	if err != nil {
+		return 0, err
+	}
+
+	rehydrated := 0
+	for _, archive := range archivesBetween(archives, from, to) {
+		if archive.Rehydrated {
+			continue
+		}
+		day, _ := time.Parse(archiveDayLayout, archive.Day)
+		err = service.HistoryArchivesQuerier.Rehydrate(day, func(table HistoryArchiveTable) (io.ReadCloser, error) {
+			return service.HistoryArchiveStore.Open(day, table)
+		})
+		if err != nil {
+			return rehydrated, err
+		}
+		rehydrated++
+		zap.L().Info("History archive rehydrated", zap.String("day", archive.Day))
+	}
+	return rehydrated, nil
+}
+
+// EvictArchives removes the rehydrated history of the days of [from, to) from the archive tables (the archive files are kept)
+func (service HistoryService) EvictArchives(from time.Time, to time.Time) (int64, error) {
+	return service.HistoryArchivesQuerier.Evict(truncateRollup(from, RollupDay), ceilRollup(to, RollupDay))
+}
+
+// SearchArchives executes a search query on the rehydrated history archives
+func (service HistoryService) SearchArchives(q search.Query) (search.QueryPage, error) {
+	return service.archives().Search(q)
+}
+
+// archives returns a copy of the service reading the history from the archive tables
+func (service HistoryService) archives() HistoryService {
+	archives := service
+	archives.HistorySituationsQuerier.Builder.Table = "situation_history_archive_v1"
+	archives.HistorySituationFactsQuerier.Builder.Table = "situation_fact_history_archive_v1"
+	archives.HistoryFactsQuerier.Builder.Table = "fact_history_archive_v1"
+	return archives
+}
+
+// archivesBetween returns the archives of the days overlapping [from, to)
+func archivesBetween(archives []HistoryArchive, from time.Time, to time.Time) []HistoryArchive {
+	first := truncateRollup(from, RollupDay).Format(archiveDayLayout)
+	last := ceilRollup(to, RollupDay).Format(archiveDayLayout)
+	selected := make([]HistoryArchive, 0)
+	for _, archive := range archives {
+		if archive.Day >= first && archive.Day < last {
+			selected = append(selected, archive)
+		}
+	}
+	return selected
+}
+
+// HistoryArchiveRange is a range of archived days
+type HistoryArchiveRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// IsValid checks if a range of archived days is valid
+func (r HistoryArchiveRange) IsValid() error {
+	if r.From.IsZero() || r.To.IsZero() {
+		return errors.New("missing from or to")
+	}
+	if !r.From.Before(r.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
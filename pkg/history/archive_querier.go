@@ -0,0 +1,207 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	archiveInsertBatchSize = 1000
+	archiveMaxLineSize     = 64 * 1024 * 1024
+)
+
+// HistoryArchiveTable describes an archived history table
+// Name is the name of the archive files, Source the archived table, and Archive the table the archives are rehydrated in
+type HistoryArchiveTable struct {
+	Name    string
+	Source  string
+	Archive string
+	// export selects the (ts, json row) of [$1, $2), and exportUnbounded the ones before $1
+	export          string
+	exportUnbounded string
+}
+
+// HistoryArchiveTables lists the archived history tables, in their rehydration order
+var HistoryArchiveTables = []HistoryArchiveTable{
+	{
+		Name: "situation_history", Source: "situation_history_v5", Archive: "situation_history_archive_v1",
+		export:          `SELECT t.ts, row_to_json(t)::text FROM situation_history_v5 t WHERE t.ts >= $1 AND t.ts < $2 ORDER BY t.ts`,
+		exportUnbounded: `SELECT t.ts, row_to_json(t)::text FROM situation_history_v5 t WHERE t.ts < $1 ORDER BY t.ts`,
+	},
+	{
+		Name: "fact_history", Source: "fact_history_v5", Archive: "fact_history_archive_v1",
+		export:          `SELECT t.ts, row_to_json(t)::text FROM fact_history_v5 t WHERE t.ts >= $1 AND t.ts < $2 ORDER BY t.ts`,
+		exportUnbounded: `SELECT t.ts, row_to_json(t)::text FROM fact_history_v5 t WHERE t.ts < $1 ORDER BY t.ts`,
+	},
+	{
+		// The links are archived with the timestamp of their situation, as the legacy links have none
+		Name: "situation_fact_history", Source: "situation_fact_history_v5", Archive: "situation_fact_history_archive_v1",
+		export: `SELECT s.ts, json_build_object('situation_history_id', l.situation_history_id, 'fact_history_id', l.fact_history_id, 'fact_id', l.fact_id, 'ts', s.ts)::text
+			FROM situation_fact_history_v5 l INNER JOIN situation_history_v5 s ON s.id = l.situation_history_id
+			WHERE s.ts >= $1 AND s.ts < $2 ORDER BY s.ts`,
+		exportUnbounded: `SELECT s.ts, json_build_object('situation_history_id', l.situation_history_id, 'fact_history_id', l.fact_history_id, 'fact_id', l.fact_id, 'ts', s.ts)::text
+			FROM situation_fact_history_v5 l INNER JOIN situation_history_v5 s ON s.id = l.situation_history_id
+			WHERE s.ts < $1 ORDER BY s.ts`,
+	},
+}
+
+type HistoryArchivesQuerier struct {
+	conn *sqlx.DB
+}
+
+// Export streams the rows of an archived table over [from, to) as JSON, in chronological order
+// A zero from exports every row before to
+func (querier HistoryArchivesQuerier) Export(table HistoryArchiveTable, from time.Time, to time.Time, write func(ts time.Time, row string) error) error {
+	var rows *sqlx.Rows
+	var err error
+	if from.IsZero() {
+		rows, err = querier.conn.Queryx(table.exportUnbounded, to)
+	} else {
+		rows, err = querier.conn.Queryx(table.export, from, to)
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ts time.Time
+		var row string
+		if err = rows.Scan(&ts, &row); err != nil {
+			return err
+		}
+		if err = write(ts, row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Rehydrate loads the archive files of a day in the archive tables, and flags the day as rehydrated, in a single transaction
+// Rows already loaded are ignored
+func (querier HistoryArchivesQuerier) Rehydrate(day time.Time, open func(table HistoryArchiveTable) (io.ReadCloser, error)) error {
+	tx, err := querier.conn.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range HistoryArchiveTables {
+		r, err := open(table)
+		if err != nil {
+			return err
+		}
+		err = rehydrateTable(tx, table, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO history_archive_rehydration_v1 (day, rehydrated_at) VALUES ($1, $2)
+		ON CONFLICT (day) DO UPDATE SET rehydrated_at = EXCLUDED.rehydrated_at`, day.UTC().Format(archiveDayLayout), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rehydrateTable inserts the JSON rows of an archive file in its archive table, by batches
+func rehydrateTable(tx *sqlx.Tx, table HistoryArchiveTable, r io.Reader) error {
+	insert := fmt.Sprintf(`INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, $1::json) ON CONFLICT DO NOTHING`,
+		pq.QuoteIdentifier(table.Archive), pq.QuoteIdentifier(table.Archive))
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), archiveMaxLineSize)
+
+	batch := make([][]byte, 0, archiveInsertBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		rows := append([]byte{'['}, bytes.Join(batch, []byte{','})...)
+		rows = append(rows, ']')
+		batch = batch[:0]
+		_, err := tx.Exec(insert, string(rows))
+		return err
+	}
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		batch = append(batch, append([]byte(nil), line...))
+		if len(batch) >= archiveInsertBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// RehydratedDays returns the rehydration date of every rehydrated day (formatted as 2006-01-02)
+func (querier HistoryArchivesQuerier) RehydratedDays() (map[string]time.Time, error) {
+	rows, err := querier.conn.Query(`SELECT to_char(day, 'YYYY-MM-DD'), rehydrated_at FROM history_archive_rehydration_v1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := make(map[string]time.Time)
+	for rows.Next() {
+		var day string
+		var rehydratedAt time.Time
+		if err = rows.Scan(&day, &rehydratedAt); err != nil {
+			return nil, err
+		}
+		days[day] = rehydratedAt
+	}
+	return days, rows.Err()
+}
+
+// Evict deletes the rehydrated rows of the days [from, to) from the archive tables
+func (querier HistoryArchivesQuerier) Evict(from time.Time, to time.Time) (int64, error) {
+	if !from.Before(to) {
+		return 0, errors.New("invalid eviction range")
+	}
+
+	tx, err := querier.conn.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var deleted int64
+	for i := len(HistoryArchiveTables) - 1; i >= 0; i-- {
+		res, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE ts >= $1 AND ts < $2`, pq.QuoteIdentifier(HistoryArchiveTables[i].Archive)), from, to)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		deleted += affected
+	}
+
+	_, err = tx.Exec(`DELETE FROM history_archive_rehydration_v1 WHERE day >= $1 AND day < $2`,
+		from.UTC().Format(archiveDayLayout), to.UTC().Format(archiveDayLayout))
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, tx.Commit()
+}
@@ -0,0 +1,142 @@
+package history
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchiveWriterRoundTrip(t *testing.T) {
+	store := HistoryArchiveStore{Path: t.TempDir()}
+	table := HistoryArchiveTables[0]
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	w := newArchiveWriter(store, table)
+	rows := []struct {
+		ts  time.Time
+		row string
+	}{
+		{day1.Add(time.Hour), `{"id":1}`},
+		{day1.Add(2 * time.Hour), `{"id":2}`},
+		{day2.Add(time.Hour), `{"id":3}`},
+	}
+	for _, r := range rows {
+		if err := w.Write(r.ts, r.row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+	if days := w.days(); len(days) != 2 || days[0] != "2025-03-01" || days[1] != "2025-03-02" {
+		t.Fatalf("unexpected days %v", days)
+	}
+	if archives, _ := store.List(); len(archives) != 0 {
+		t.Fatalf("uncommitted archives must not be listed, got %+v", archives)
+	}
+	if err := w.commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	archives, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 2 || archives[0].Day != "2025-03-01" || archives[1].Day != "2025-03-02" || archives[0].Size == 0 {
+		t.Fatalf("unexpected archives %+v", archives)
+	}
+
+	r, err := store.Open(day1, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "{\"id\":1}\n{\"id\":2}\n" {
+		t.Errorf("unexpected archive content %q", content)
+	}
+
+	r, err = store.Open(day1, HistoryArchiveTables[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ = io.ReadAll(r)
+	r.Close()
+	if len(content) != 0 {
+		t.Errorf("expected an empty archive, got %q", content)
+	}
+}
+
+func TestArchiveWriterAbort(t *testing.T) {
+	store := HistoryArchiveStore{Path: t.TempDir()}
+	w := newArchiveWriter(store, HistoryArchiveTables[0])
+	if err := w.Write(time.Date(2025, 3, 1, 1, 0, 0, 0, time.UTC), `{"id":1}`); err != nil {
+		t.Fatal(err)
+	}
+	w.abort()
+
+	files, err := os.ReadDir(filepath.Join(store.Path, "2025-03-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected the temporary files to be removed, got %d files", len(files))
+	}
+}
+
+func TestArchiveStoreListIgnoresUnknownEntries(t *testing.T) {
+	store := HistoryArchiveStore{Path: t.TempDir()}
+	if err := os.MkdirAll(filepath.Join(store.Path, "not-a-day"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(store.Path, "2025-03-01"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	archives, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 0 {
+		t.Errorf("expected no archive, got %+v", archives)
+	}
+
+	archives, err = HistoryArchiveStore{Path: filepath.Join(store.Path, "missing")}.List()
+	if err != nil || len(archives) != 0 {
+		t.Errorf("expected no archive in a missing directory, got %+v (%v)", archives, err)
+	}
+}
+
+func TestArchivesBetween(t *testing.T) {
+	archives := []HistoryArchive{{Day: "2025-02-28"}, {Day: "2025-03-01"}, {Day: "2025-03-02"}, {Day: "2025-03-03"}}
+	from := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 2, 6, 0, 0, 0, time.UTC)
+
+	selected := archivesBetween(archives, from, to)
+	days := make([]string, 0)
+	for _, a := range selected {
+		days = append(days, a.Day)
+	}
+	if strings.Join(days, ",") != "2025-03-01,2025-03-02" {
+		t.Errorf("unexpected archives %v", days)
+	}
+}
+
+func TestHistoryArchiveRangeIsValid(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := (HistoryArchiveRange{From: from, To: from.AddDate(0, 0, 1)}).IsValid(); err != nil {
+		t.Error(err)
+	}
+	if err := (HistoryArchiveRange{From: from, To: from}).IsValid(); err == nil {
+		t.Error("expected an empty range to be invalid")
+	}
+	if err := (HistoryArchiveRange{To: from}).IsValid(); err == nil {
+		t.Error("expected a range without start to be invalid")
+	}
+}
@@ -0,0 +1,195 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+)
+
+// HistoryWindow is a time range [Start, End) of the history
+type HistoryWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// HistoryComparison compares the history of a situation instance between two time windows (ie. "this monday vs last monday")
+// Both windows are down-sampled to the latest record of each time bucket, and the buckets are aligned by their offset from the window start
+type HistoryComparison struct {
+	SituationID         int64         `json:"situationId"`
+	SituationInstanceID int64         `json:"situationInstanceId"` // 0 if the situation is not a template
+	Reference           HistoryWindow `json:"reference"`
+	Current             HistoryWindow `json:"current"`
+	Granularity         time.Duration `json:"granularity" swaggertype:"string" example:"1h"`
+}
+
+// HistoryComparisonValue compares a value of the two windows, the delta and percent change are only set if both values exist
+type HistoryComparisonValue struct {
+	Reference     *float64 `json:"reference"`
+	Current       *float64 `json:"current"`
+	Delta         *float64 `json:"delta,omitempty"`
+	PercentChange *float64 `json:"percentChange,omitempty"`
+}
+
+// HistoryComparisonPoint compares the facts and expression facts of a time bucket of the two windows
+type HistoryComparisonPoint struct {
+	Offset          string                            `json:"offset" example:"2h0m0s"`
+	ReferenceTS     time.Time                         `json:"referenceTs"`
+	CurrentTS       time.Time                         `json:"currentTs"`
+	Facts           map[string]HistoryComparisonValue `json:"facts"`
+	ExpressionFacts map[string]HistoryComparisonValue `json:"expressionFacts"`
+}
+
+// UnmarshalJSON unmarshals a history comparison with a granularity duration string
+func (c *HistoryComparison) UnmarshalJSON(data []byte) error {
+	type Alias HistoryComparison
+	aux := &struct {
+		Granularity string `json:"granularity"`
+		*Alias
+	}{
+		Alias: (*Alias)(c),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Granularity != "" {
+		g, err := time.ParseDuration(strings.ToLower(aux.Granularity))
+		if err != nil {
+			return err
+		}
+		c.Granularity = g
+	}
+	return nil
+}
+
+// IsValid checks if a history comparison is valid
+func (c HistoryComparison) IsValid() (bool, error) {
+	if c.SituationID <= 0 {
+		return false, errors.New("missing or invalid situationId")
+	}
+	if c.Granularity <= 0 {
+		return false, errors.New("missing or invalid granularity")
+	}
+	for _, w := range []HistoryWindow{c.Reference, c.Current} {
+		if w.Start.IsZero() || w.End.IsZero() || !w.Start.Before(w.End) {
+			return false, errors.New("the windows must have a start before their end")
+		}
+	}
+	return true, nil
+}
+
+// Compare returns the aligned series of the two windows of a history comparison, with their deltas and percent changes
+func (service HistoryService) Compare(c HistoryComparison) ([]HistoryComparisonPoint, error) {
+	reference, err := service.compareWindow(c, c.Reference)
+	if err != nil {
+		return nil, err
+	}
+	current, err := service.compareWindow(c, c.Current)
+	if err != nil {
+		return nil, err
+	}
+	return compareSeries(c, reference, current), nil
+}
+
+// compareWindow returns the latest record of each time bucket of a window
+func (service HistoryService) compareWindow(c HistoryComparison, w HistoryWindow) ([]search.SituationHistoryRecord, error) {
+	options := GetHistorySituationsOptions{
+		SituationID:          c.SituationID,
+		SituationInstanceIDs: []int64{c.SituationInstanceID},
+		FromTS:               w.Start,
+		ToTS:                 w.End,
+	}
+	historySituations, err := service.GetHistorySituationsIdsByCustomInterval(options, c.Granularity, w.Start)
+	if err != nil {
+		return nil, err
+	}
+	historyFacts, historySituationFacts, err := service.GetHistoryFactsFromSituation(historySituations)
+	if err != nil {
+		return nil, err
+	}
+	return extractSituationHistoryRecords(historySituations, historySituationFacts, historyFacts), nil
+}
+
+// compareSeries aligns the records of the two windows by the offset of their bucket and compares their values
+func compareSeries(c HistoryComparison, reference []search.SituationHistoryRecord, current []search.SituationHistoryRecord) []HistoryComparisonPoint {
+	points := make(map[int64]*HistoryComparisonPoint)
+	point := func(bucket int64) *HistoryComparisonPoint {
+		if p, ok := points[bucket]; ok {
+			return p
+		}
+		offset := time.Duration(bucket) * c.Granularity
+		p := &HistoryComparisonPoint{
+			Offset:          offset.String(),
+			ReferenceTS:     c.Reference.Start.Add(offset),
+			CurrentTS:       c.Current.Start.Add(offset),
+			Facts:           make(map[string]HistoryComparisonValue),
+			ExpressionFacts: make(map[string]HistoryComparisonValue),
+		}
+		points[bucket] = p
+		return p
+	}
+
+	for _, side := range []struct {
+		records   []search.SituationHistoryRecord
+		start     time.Time
+		reference bool
+	}{{reference, c.Reference.Start, true}, {current, c.Current.Start, false}} {
+		for _, record := range side.records {
+			p := point(int64(record.DateTime.Sub(side.start) / c.Granularity))
+			for _, fact := range record.Facts {
+				if v, ok := recordFactValue(fact); ok {
+					p.Facts[fact.FactName] = setComparisonValue(p.Facts[fact.FactName], v, side.reference)
+				}
+			}
+			for name, value := range record.ExpressionFacts {
+				if v, ok := numberArgument(value); ok {
+					p.ExpressionFacts[name] = setComparisonValue(p.ExpressionFacts[name], v, side.reference)
+				}
+			}
+		}
+	}
+
+	buckets := make([]int64, 0, len(points))
+	for bucket := range points {
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+
+	result := make([]HistoryComparisonPoint, 0, len(buckets))
+	for _, bucket := range buckets {
+		p := points[bucket]
+		for name, value := range p.Facts {
+			p.Facts[name] = value.withDelta()
+		}
+		for name, value := range p.ExpressionFacts {
+			p.ExpressionFacts[name] = value.withDelta()
+		}
+		result = append(result, *p)
+	}
+	return result
+}
+
+func setComparisonValue(value HistoryComparisonValue, v float64, reference bool) HistoryComparisonValue {
+	if reference {
+		value.Reference = &v
+	} else {
+		value.Current = &v
+	}
+	return value
+}
+
+// withDelta sets the delta and the percent change (if the reference value is not zero) of the compared values
+func (value HistoryComparisonValue) withDelta() HistoryComparisonValue {
+	if value.Reference == nil || value.Current == nil {
+		return value
+	}
+	delta := *value.Current - *value.Reference
+	value.Delta = &delta
+	if percent, err := percentChange(*value.Current, *value.Reference); err == nil {
+		value.PercentChange = &percent
+	}
+	return value
+}
@@ -0,0 +1,72 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+)
+
+func TestCompareSeries(t *testing.T) {
+	lastMonday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	monday := lastMonday.AddDate(0, 0, 7)
+	c := HistoryComparison{
+		SituationID: 1,
+		Reference:   HistoryWindow{Start: lastMonday, End: lastMonday.AddDate(0, 0, 1)},
+		Current:     HistoryWindow{Start: monday, End: monday.AddDate(0, 0, 1)},
+		Granularity: time.Hour,
+	}
+
+	reference := []search.SituationHistoryRecord{
+		{DateTime: lastMonday.Add(10*time.Hour + 50*time.Minute), Facts: []search.FactHistoryRecord{{FactName: "parcels", Value: 100.0}}, ExpressionFacts: map[string]interface{}{"ratio": 0.5}},
+		{DateTime: lastMonday.Add(11*time.Hour + 55*time.Minute), Facts: []search.FactHistoryRecord{{FactName: "parcels", Value: 0.0}}},
+	}
+	current := []search.SituationHistoryRecord{
+		{DateTime: monday.Add(10*time.Hour + 45*time.Minute), Facts: []search.FactHistoryRecord{{FactName: "parcels", Value: 150.0}}, ExpressionFacts: map[string]interface{}{"ratio": 0.25, "label": "late"}},
+		{DateTime: monday.Add(11*time.Hour + 50*time.Minute), Facts: []search.FactHistoryRecord{{FactName: "parcels", DocCount: int64(20)}}},
+		{DateTime: monday.Add(12*time.Hour + 50*time.Minute), Facts: []search.FactHistoryRecord{{FactName: "parcels", Value: 10.0}}},
+	}
+
+	points := compareSeries(c, reference, current)
+	if len(points) != 3 {
+		t.Fatalf("expected 3 points, got %+v", points)
+	}
+
+	p := points[0]
+	if p.Offset != "10h0m0s" || !p.ReferenceTS.Equal(lastMonday.Add(10*time.Hour)) || !p.CurrentTS.Equal(monday.Add(10*time.Hour)) {
+		t.Errorf("unexpected point %+v", p)
+	}
+	if v := p.Facts["parcels"]; v.Delta == nil || *v.Delta != 50 || v.PercentChange == nil || *v.PercentChange != 50 {
+		t.Errorf("unexpected fact comparison %+v", v)
+	}
+	if v := p.ExpressionFacts["ratio"]; v.Delta == nil || *v.Delta != -0.25 || *v.PercentChange != -50 {
+		t.Errorf("unexpected expression fact comparison %+v", v)
+	}
+	if _, ok := p.ExpressionFacts["label"]; ok {
+		t.Error("expected non numeric expression facts to be skipped")
+	}
+
+	if v := points[1].Facts["parcels"]; v.Delta == nil || *v.Delta != 20 || v.PercentChange != nil {
+		t.Errorf("expected a delta without percent change on a zero reference, got %+v", v)
+	}
+	if v := points[2].Facts["parcels"]; v.Reference != nil || v.Current == nil || v.Delta != nil {
+		t.Errorf("expected a current value only, got %+v", v)
+	}
+}
+
+func TestHistoryComparisonUnmarshal(t *testing.T) {
+	var c HistoryComparison
+	err := json.Unmarshal([]byte(`{"situationId":1,"granularity":"1h","reference":{"start":"2026-03-02T00:00:00Z","end":"2026-03-03T00:00:00Z"},"current":{"start":"2026-03-09T00:00:00Z","end":"2026-03-10T00:00:00Z"}}`), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := c.IsValid(); c.Granularity != time.Hour || !ok {
+		t.Errorf("unexpected comparison %+v", c)
+	}
+
+	c.Current.End = c.Current.Start
+	if ok, _ := c.IsValid(); ok {
+		t.Error("expected an empty window to be invalid")
+	}
+}
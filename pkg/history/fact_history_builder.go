@@ -1,10 +1,22 @@
 package history
 
 import (
+	"time"
+
 	sq "github.com/Masterminds/squirrel"
 )
 
-type HistoryFactsBuilder struct{}
+type HistoryFactsBuilder struct {
+	// Table overrides the fact history table read by the search queries (ie. the rehydrated archives)
+	Table string
+}
+
+func (builder HistoryFactsBuilder) table() string {
+	if builder.Table != "" {
+		return builder.Table
+	}
+	return "fact_history_v5"
+}
 
 func (builder HistoryFactsBuilder) newStatement() sq.StatementBuilderType {
 	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
@@ -25,7 +37,7 @@ func (builder HistoryFactsBuilder) GetHistoryFactLast(situationID int64, instanc
 func (builder HistoryFactsBuilder) GetHistoryFacts(historyFactsIds []int64) sq.SelectBuilder {
 	return builder.newStatement().
 		Select("fh.*, f.name").
-		From("fact_history_v5 fh").
+		From(builder.table() + " fh").
 		InnerJoin("fact_definition_v1 f on fh.fact_id = f.id").
 		Where(sq.Eq{"fh.id": historyFactsIds})
 }
@@ -87,3 +99,36 @@ func (builder HistoryFactsBuilder) GetFactResultByDate(param ParamGetFactHistory
 		Where(sq.Expr("ts >= ?::timestamptz", param.StartDate)).
 		Where(sq.Expr("ts < ?::timestamptz", param.EndDate))
 }
+
+func (builder HistoryFactsBuilder) GetLastFactResults(situationID int64, instanceID int64, factID int64, from time.Time, before time.Time, limit uint64) sq.SelectBuilder {
+	return builder.newStatement().
+		Select("result, ts").
+		From(builder.table()).
+		Where(sq.Eq{"fact_id": factID}).
+		Where(sq.Eq{"situation_id": situationID}).
+		Where(sq.Eq{"situation_instance_id": instanceID}).
+		Where(sq.GtOrEq{"ts": from}).
+		Where(sq.Lt{"ts": before}).
+		OrderBy("ts desc").
+		Limit(limit)
+}
+
+// GetFactResultsInWindows selects the results of facts of situation instances, each one in the range of its window,
+// in chronological order
+func (builder HistoryFactsBuilder) GetFactResultsInWindows(windows []forecastWindow) sq.SelectBuilder {
+	or := sq.Or{}
+	for _, w := range windows {
+		or = append(or, sq.And{
+			sq.Eq{"situation_id": w.situationID},
+			sq.Eq{"situation_instance_id": w.situationInstanceID},
+			sq.Eq{"fact_id": w.factID},
+			sq.GtOrEq{"ts": w.from},
+			sq.Lt{"ts": w.to},
+		})
+	}
+	return builder.newStatement().
+		Select("situation_id, situation_instance_id, fact_id, result, ts").
+		From(builder.table()).
+		Where(or).
+		OrderBy("ts")
+}
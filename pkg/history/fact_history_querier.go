@@ -6,6 +6,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/reader"
 
 	sq "github.com/Masterminds/squirrel"
@@ -44,8 +45,9 @@ type ParamGetFactHistory struct {
 }
 type ParamGetFactHistoryByDate struct {
 	ParamGetFactHistory
-	StartDate string `json:"startDate"` // Expected format: "2006-01-02 15:04:05"
-	EndDate   string `json:"endDate"`   // Expected format: "2006-01-02 15:04:05"
+	StartDate    string               `json:"startDate"`              // Expected format: "2006-01-02 15:04:05"
+	EndDate      string               `json:"endDate"`                // Expected format: "2006-01-02 15:04:05"
+	DownSampling *search.DownSampling `json:"downSampling,omitempty"` // Optional, aggregates the results by time bucket
 }
 
 func (querier HistoryFactsQuerier) Insert(history HistoryFactsV4) (int64, error) {
@@ -227,16 +229,8 @@ func (querier *HistoryFactsQuerier) QueryGetSpecificFields(builder sq.SelectBuil
 		}
 
 		factRes := FactResult{FormattedTime: ts.Format(formatTime)}
-
-		if aggs, ok := parsedResult["aggs"].(map[string]interface{}); ok {
-			for _, v := range aggs {
-				if count, ok := v.(map[string]interface{}); ok {
-					if value, ok := count["value"].(float64); ok {
-						factRes.Value = int64(value)
-						break
-					}
-				}
-			}
+		if value, ok := factResultValue(parsedResult); ok {
+			factRes.Value = int64(value)
 		}
 		results = append(results, factRes)
 	}
@@ -244,6 +238,80 @@ func (querier *HistoryFactsQuerier) QueryGetSpecificFields(builder sq.SelectBuil
 	return GetFactHistory{Results: results}, nil
 }
 
+// QueryFactValues returns the dated values of the fact results selected by the builder (results without value are skipped)
+func (querier HistoryFactsQuerier) QueryFactValues(builder sq.SelectBuilder) ([]timedValue, error) {
+	rows, err := builder.RunWith(querier.conn).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]timedValue, 0)
+	for rows.Next() {
+		var resultBytes []byte
+		var ts time.Time
+		if err = rows.Scan(&resultBytes, &ts); err != nil {
+			return nil, err
+		}
+
+		var parsedResult map[string]interface{}
+		if err = json.Unmarshal(resultBytes, &parsedResult); err != nil {
+			return nil, err
+		}
+
+		if value, ok := factResultValue(parsedResult); ok {
+			values = append(values, timedValue{ts: ts, value: value})
+		}
+	}
+	return values, rows.Err()
+}
+
+// QueryFactWindowValues returns the dated values of the fact results selected by the builder, by situation instance fact
+// The builder must select the situation, the situation instance, the fact, the result and the ts of the fact results
+func (querier HistoryFactsQuerier) QueryFactWindowValues(builder sq.SelectBuilder) (map[forecastKey][]timedValue, error) {
+	rows, err := builder.RunWith(querier.conn).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[forecastKey][]timedValue)
+	for rows.Next() {
+		var key forecastKey
+		var resultBytes []byte
+		var ts time.Time
+		if err = rows.Scan(&key.situationID, &key.situationInstanceID, &key.factID, &resultBytes, &ts); err != nil {
+			return nil, err
+		}
+
+		var parsedResult map[string]interface{}
+		if err = json.Unmarshal(resultBytes, &parsedResult); err != nil {
+			return nil, err
+		}
+
+		if value, ok := factResultValue(parsedResult); ok {
+			values[key] = append(values[key], timedValue{ts: ts, value: value})
+		}
+	}
+	return values, rows.Err()
+}
+
+// factResultValue returns the value of the first aggregation of a raw fact result
+func factResultValue(parsedResult map[string]interface{}) (float64, bool) {
+	aggs, ok := parsedResult["aggs"].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	for _, v := range aggs {
+		if count, ok := v.(map[string]interface{}); ok {
+			if value, ok := count["value"].(float64); ok {
+				return value, true
+			}
+		}
+	}
+	return 0, false
+}
+
 func (querier HistoryFactsQuerier) GetTodaysFactResultByParameters(param ParamGetFactHistory) (GetFactHistory, error) {
 	builder := querier.Builder.GetTodaysFactResultByParameters(param)
 	return querier.QueryGetSpecificFields(builder, FormatHourMinute)
@@ -0,0 +1,323 @@
+package history
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+	pluginbaseline "github.com/myrteametrics/myrtea-engine-api/v5/pkg/plugins/baseline"
+)
+
+// ForecastVariable is the knowledge base variable holding the forecasts of the situation facts, by fact name
+const ForecastVariable = "forecast"
+
+// Situation parameters enabling the forecasts in the rules knowledge base
+const (
+	ParameterForecastEnd      = "forecast_end"
+	ParameterForecastMethod   = "forecast_method"
+	ParameterForecastTimezone = "forecast_timezone"
+	ParameterForecastBaseline = "forecast_baseline"
+)
+
+// forecastMaxPoints bounds the number of history values of the day read by a linear forecast
+const forecastMaxPoints = 1440
+
+// forecastKey identifies the history of a fact of a situation instance
+type forecastKey struct {
+	situationID         int64
+	situationInstanceID int64
+	factID              int64
+}
+
+// forecastRequest is the value of a fact of a situation instance at ts, to project at the end of its period
+type forecastRequest struct {
+	forecastKey
+	ts    time.Time
+	value float64
+}
+
+// forecastWindow is the range of the history of a fact read by the linear forecasts of a period
+type forecastWindow struct {
+	forecastKey
+	from time.Time
+	to   time.Time
+}
+
+// forecastFacts projects the values of facts of situation instances at the end of their periods
+// The forecast of a request is nil if there is not enough data to project its value
+// The history read by the linear forecasts is fetched in a single query, and the baseline values of the seasonal
+// forecasts are read once by fact and time
+func (service HistoryService) forecastFacts(o search.ForecastOptions, requests []forecastRequest) ([]*search.Forecast, error) {
+	forecasts := make([]*search.Forecast, len(requests))
+	starts := make([]time.Time, len(requests))
+	ends := make([]time.Time, len(requests))
+	pending := make([]int, 0)
+	for i, request := range requests {
+		start, end, err := o.Period(request.ts)
+		if err != nil {
+			return nil, err
+		}
+		if !request.ts.Before(end) {
+			forecasts[i] = &search.Forecast{Method: o.ForecastMethod(), End: end, Value: request.value, Lower: request.value, Upper: request.value}
+			continue
+		}
+		starts[i], ends[i] = start, end
+		pending = append(pending, i)
+	}
+	if len(pending) == 0 {
+		return forecasts, nil
+	}
+
+	switch o.ForecastMethod() {
+	case search.ForecastSeasonal:
+		baselineService, err := pluginbaseline.S()
+		if err != nil {
+			return nil, err
+		}
+		type baselineKey struct {
+			forecastKey
+			ts int64
+		}
+		baselines := make(map[baselineKey]map[string]pluginbaseline.BaselineValue)
+		baselineValue := func(key forecastKey, ts time.Time) (pluginbaseline.BaselineValue, bool, error) {
+			values, ok := baselines[baselineKey{key, ts.UnixNano()}]
+			if !ok {
+				var err error
+				values, err = baselineService.GetBaselineValues(-1, key.factID, key.situationID, key.situationInstanceID, ts)
+				if err != nil {
+					return pluginbaseline.BaselineValue{}, false, err
+				}
+				baselines[baselineKey{key, ts.UnixNano()}] = values
+			}
+			value, found := selectBaselineValue(values, o.Baseline)
+			return value, found, nil
+		}
+
+		for _, i := range pending {
+			request := requests[i]
+			current, found, err := baselineValue(request.forecastKey, request.ts)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				continue
+			}
+			// The baseline value of the last hour of the period is the closest to the value at its end
+			final, found, err := baselineValue(request.forecastKey, ends[i].Add(-time.Nanosecond))
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				continue
+			}
+			forecast := search.Forecast{Method: search.ForecastSeasonal, End: ends[i]}
+			forecast.Value, forecast.Lower, forecast.Upper = seasonalProjection(request.value, current, final)
+			forecasts[i] = &forecast
+		}
+
+	default:
+		// The requests of a fact over the same period share a window, ending at the last of them
+		type windowKey struct {
+			forecastKey
+			from int64
+		}
+		windows := make(map[windowKey]int)
+		list := make([]forecastWindow, 0)
+		for _, i := range pending {
+			key := windowKey{requests[i].forecastKey, starts[i].UnixNano()}
+			w, ok := windows[key]
+			if !ok {
+				windows[key] = len(list)
+				list = append(list, forecastWindow{forecastKey: requests[i].forecastKey, from: starts[i], to: requests[i].ts})
+				continue
+			}
+			if requests[i].ts.After(list[w].to) {
+				list[w].to = requests[i].ts
+			}
+		}
+
+		querier := service.HistoryFactsQuerier
+		factValues, err := querier.QueryFactWindowValues(querier.Builder.GetFactResultsInWindows(list))
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range pending {
+			request := requests[i]
+			values := windowValues(factValues[request.forecastKey], starts[i], request.ts, forecastMaxPoints)
+			values = append(values, timedValue{ts: request.ts, value: request.value})
+			v, lower, upper, ok := linearProjection(starts[i], values, ends[i], o.Factor())
+			if !ok {
+				continue
+			}
+			forecasts[i] = &search.Forecast{Method: o.ForecastMethod(), End: ends[i], Value: v, Lower: lower, Upper: upper}
+		}
+	}
+	return forecasts, nil
+}
+
+// windowValues returns the last values (at most limit) of the chronological values in the range [from, to)
+// The returned slice is a copy, so that it can be appended to
+func windowValues(values []timedValue, from time.Time, to time.Time, limit int) []timedValue {
+	first := sort.Search(len(values), func(i int) bool { return !values[i].ts.Before(from) })
+	last := sort.Search(len(values), func(i int) bool { return !values[i].ts.Before(to) })
+	if last-first > limit {
+		first = last - limit
+	}
+	window := make([]timedValue, 0, last-first+1)
+	if first < last {
+		window = append(window, values[first:last]...)
+	}
+	return window
+}
+
+// forecastSearchRecords adds the forecasts of the facts to the search records
+func (service HistoryService) forecastSearchRecords(o search.ForecastOptions, records []search.SituationHistoryRecord) error {
+	requests := make([]forecastRequest, 0)
+	facts := make([]*search.FactHistoryRecord, 0)
+	for _, record := range records {
+		// Facts share their backing array with the record of the slice
+		for i, fact := range record.Facts {
+			if !o.Forecasted(fact.FactName) {
+				continue
+			}
+			value, ok := recordFactValue(fact)
+			if !ok {
+				continue
+			}
+			requests = append(requests, forecastRequest{
+				forecastKey: forecastKey{situationID: record.SituationID, situationInstanceID: record.SituationInstanceID, factID: fact.FactID},
+				ts:          fact.DateTime,
+				value:       value,
+			})
+			facts = append(facts, &record.Facts[i])
+		}
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+
+	forecasts, err := service.forecastFacts(o, requests)
+	if err != nil {
+		return err
+	}
+	for i, forecast := range forecasts {
+		facts[i].Forecast = forecast
+	}
+	return nil
+}
+
+// forecastVariables returns the forecasts of the situation facts by fact name, if the situation parameters enable them
+func (service HistoryService) forecastVariables(data map[string]interface{}, situationID int64, situationInstanceID int64, historyFacts []HistoryFactsV4) (map[string]interface{}, error) {
+	end, ok := data[ParameterForecastEnd]
+	if !ok {
+		return nil, nil
+	}
+	o := search.ForecastOptions{End: fmt.Sprint(end)}
+	if method, ok := data[ParameterForecastMethod]; ok {
+		o.Method = fmt.Sprint(method)
+	}
+	if timezone, ok := data[ParameterForecastTimezone]; ok {
+		o.Timezone = fmt.Sprint(timezone)
+	}
+	if baseline, ok := data[ParameterForecastBaseline]; ok {
+		o.Baseline = fmt.Sprint(baseline)
+	}
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	requests := make([]forecastRequest, 0)
+	names := make([]string, 0)
+	for _, historyFact := range historyFacts {
+		value, ok := factItemValue(historyFact.Result)
+		if !ok {
+			continue
+		}
+		requests = append(requests, forecastRequest{
+			forecastKey: forecastKey{situationID: situationID, situationInstanceID: situationInstanceID, factID: historyFact.FactID},
+			ts:          historyFact.Ts,
+			value:       value,
+		})
+		names = append(names, historyFact.FactName)
+	}
+
+	forecasts := make(map[string]interface{})
+	if len(requests) == 0 {
+		return forecasts, nil
+	}
+	results, err := service.forecastFacts(o, requests)
+	if err != nil {
+		return nil, err
+	}
+	for i, forecast := range results {
+		if forecast != nil {
+			forecasts[names[i]] = map[string]interface{}{
+				"value":  forecast.Value,
+				"lower":  forecast.Lower,
+				"upper":  forecast.Upper,
+				"method": forecast.Method,
+				"end":    forecast.End,
+			}
+		}
+	}
+	return forecasts, nil
+}
+
+// recordFactValue returns the numeric value of a fact search record: its aggregation value, or its document count
+func recordFactValue(fact search.FactHistoryRecord) (float64, bool) {
+	if v, ok := numberArgument(fact.Value); ok {
+		return v, true
+	}
+	return numberArgument(fact.DocCount)
+}
+
+// linearProjection fits the values of the period with a least squares regression and projects it at end
+// The band is the prediction interval of the regression, of factor standard errors
+func linearProjection(start time.Time, values []timedValue, end time.Time, factor float64) (value float64, lower float64, upper float64, ok bool) {
+	n := float64(len(values))
+	if n < 2 {
+		return 0, 0, 0, false
+	}
+
+	var mx, my float64
+	for _, v := range values {
+		mx += v.ts.Sub(start).Seconds()
+		my += v.value
+	}
+	mx, my = mx/n, my/n
+
+	var sxx, sxy float64
+	for _, v := range values {
+		dx := v.ts.Sub(start).Seconds() - mx
+		sxx += dx * dx
+		sxy += dx * (v.value - my)
+	}
+	if sxx == 0 {
+		return 0, 0, 0, false
+	}
+	slope := sxy / sxx
+	intercept := my - slope*mx
+
+	var residuals float64
+	if n > 2 {
+		for _, v := range values {
+			r := v.value - (intercept + slope*v.ts.Sub(start).Seconds())
+			residuals += r * r
+		}
+		residuals /= n - 2
+	}
+
+	xe := end.Sub(start).Seconds()
+	value = intercept + slope*xe
+	band := factor * math.Sqrt(residuals*(1+1/n+(xe-mx)*(xe-mx)/sxx))
+	return value, value - band, value + band, true
+}
+
+// seasonalProjection adds to the current value the increment of the baseline profile until the end of the period
+// The band is the baseline band at the end of the period
+func seasonalProjection(value float64, current pluginbaseline.BaselineValue, final pluginbaseline.BaselineValue) (float64, float64, float64) {
+	projected := value + final.Avg - current.Avg
+	return projected, projected - (final.Avg - final.ValueLower), projected + (final.ValueUpper - final.Avg)
+}
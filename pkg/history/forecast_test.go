@@ -0,0 +1,102 @@
+package history
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	pluginbaseline "github.com/myrteametrics/myrtea-engine-api/v5/pkg/plugins/baseline"
+)
+
+func TestLinearProjection(t *testing.T) {
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	values := []timedValue{
+		{ts: start.Add(6 * time.Hour), value: 600},
+		{ts: start.Add(8 * time.Hour), value: 800},
+		{ts: start.Add(10 * time.Hour), value: 1000},
+	}
+
+	value, lower, upper, ok := linearProjection(start, values, start.Add(18*time.Hour), 2)
+	if !ok || math.Abs(value-1800) > 1e-6 {
+		t.Fatalf("expected 1800, got %v (%v)", value, ok)
+	}
+	if math.Abs(lower-value) > 1e-6 || math.Abs(upper-value) > 1e-6 {
+		t.Errorf("expected no band on a perfect fit, got [%v, %v]", lower, upper)
+	}
+
+	values[1].value = 900
+	value, lower, upper, ok = linearProjection(start, values, start.Add(18*time.Hour), 2)
+	if !ok || !(lower < value && value < upper) {
+		t.Errorf("expected a band around the projection, got %v [%v, %v]", value, lower, upper)
+	}
+	if math.Abs((value-lower)-(upper-value)) > 1e-6 {
+		t.Errorf("expected a symmetric band, got %v [%v, %v]", value, lower, upper)
+	}
+}
+
+func TestLinearProjectionNotEnoughData(t *testing.T) {
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	if _, _, _, ok := linearProjection(start, []timedValue{{ts: start, value: 1}}, start.Add(time.Hour), 2); ok {
+		t.Error("expected no projection with a single value")
+	}
+	same := []timedValue{{ts: start, value: 1}, {ts: start, value: 2}}
+	if _, _, _, ok := linearProjection(start, same, start.Add(time.Hour), 2); ok {
+		t.Error("expected no projection with values at the same time")
+	}
+}
+
+func TestSeasonalProjection(t *testing.T) {
+	current := pluginbaseline.BaselineValue{Avg: 1000}
+	final := pluginbaseline.BaselineValue{Avg: 5000, ValueLower: 4500, ValueUpper: 5600}
+
+	value, lower, upper := seasonalProjection(1200, current, final)
+	if value != 5200 || lower != 4700 || upper != 5800 {
+		t.Errorf("unexpected projection %v [%v, %v]", value, lower, upper)
+	}
+}
+
+func TestWindowValues(t *testing.T) {
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	values := make([]timedValue, 0)
+	for i := 0; i < 6; i++ {
+		values = append(values, timedValue{ts: start.Add(time.Duration(i) * time.Hour), value: float64(i)})
+	}
+
+	window := windowValues(values, start.Add(time.Hour), start.Add(4*time.Hour), 10)
+	if len(window) != 3 || window[0].value != 1 || window[2].value != 3 {
+		t.Errorf("unexpected window %v", window)
+	}
+	window = windowValues(values, start, start.Add(4*time.Hour), 2)
+	if len(window) != 2 || window[0].value != 2 || window[1].value != 3 {
+		t.Errorf("expected the last values of the window, got %v", window)
+	}
+	if window = windowValues(values, start.Add(10*time.Hour), start.Add(12*time.Hour), 10); len(window) != 0 {
+		t.Errorf("expected an empty window, got %v", window)
+	}
+
+	// The window is a copy, appending to it doesn't change the history
+	window = windowValues(values, start, start.Add(2*time.Hour), 10)
+	_ = append(window, timedValue{ts: start.Add(2 * time.Hour), value: 100})
+	if values[2].value != 2 {
+		t.Errorf("expected the history to be left untouched, got %v", values)
+	}
+}
+
+func TestGetFactResultsInWindows(t *testing.T) {
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	windows := []forecastWindow{
+		{forecastKey: forecastKey{situationID: 1, situationInstanceID: 2, factID: 3}, from: start, to: start.Add(10 * time.Hour)},
+		{forecastKey: forecastKey{situationID: 1, situationInstanceID: 4, factID: 3}, from: start, to: start.Add(12 * time.Hour)},
+	}
+	query, args, err := HistoryFactsBuilder{}.GetFactResultsInWindows(windows).ToSql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(query, " OR ") != 1 || !strings.Contains(query, "ORDER BY ts") {
+		t.Errorf("expected a single query over the two windows, got %s", query)
+	}
+	if len(args) != 10 {
+		t.Errorf("unexpected arguments %v", args)
+	}
+}
@@ -0,0 +1,163 @@
+package history
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// HistoryPartitionedTables lists the history tables partitioned by day, in the order their partitions must be dropped
+var HistoryPartitionedTables = []string{"situation_fact_history_v5", "situation_history_v5", "fact_history_v5"}
+
+// HistoryPartition is a partition of a history table, covering [From, To)
+// A zero From (or To) means the partition is unbounded (MINVALUE or MAXVALUE)
+type HistoryPartition struct {
+	Table     string    `json:"table"`
+	Name      string    `json:"name"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	IsDefault bool      `json:"isDefault"`
+}
+
+// Overlaps returns true if the partition contains part of [from, to)
+func (p HistoryPartition) Overlaps(from time.Time, to time.Time) bool {
+	if p.IsDefault {
+		return false
+	}
+	return (p.From.IsZero() || p.From.Before(to)) && (p.To.IsZero() || p.To.After(from))
+}
+
+type HistoryPartitionsQuerier struct {
+	conn *sqlx.DB
+}
+
+var partitionBoundRegexp = regexp.MustCompile(`^FOR VALUES FROM \((.+)\) TO \((.+)\)$`)
+
+// partitionBoundLayouts are the layouts of the timestamps of the partition bounds, which depend on the session time zone
+var partitionBoundLayouts = []string{"2006-01-02 15:04:05.999999-07", "2006-01-02 15:04:05.999999-07:00", "2006-01-02 15:04:05.999999-07:00:00"}
+
+// parsePartitionBound parses a partition bound as returned by pg_get_expr
+func parsePartitionBound(bound string) (from time.Time, to time.Time, isDefault bool, err error) {
+	if bound == "DEFAULT" {
+		return time.Time{}, time.Time{}, true, nil
+	}
+	match := partitionBoundRegexp.FindStringSubmatch(bound)
+	if match == nil {
+		return time.Time{}, time.Time{}, false, fmt.Errorf("unsupported partition bound %s", bound)
+	}
+	if from, err = parsePartitionBoundValue(match[1]); err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	if to, err = parsePartitionBoundValue(match[2]); err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	return from, to, false, nil
+}
+
+func parsePartitionBoundValue(value string) (time.Time, error) {
+	if value == "MINVALUE" || value == "MAXVALUE" {
+		return time.Time{}, nil
+	}
+	value = strings.Trim(value, "'")
+	for _, layout := range partitionBoundLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported partition bound value %s", value)
+}
+
+// partitionName returns the name of the daily partition of a table
+func partitionName(table string, day time.Time) string {
+	return table + "_p" + day.UTC().Format("20060102")
+}
+
+// List returns the partitions of a history table
+func (querier HistoryPartitionsQuerier) List(table string) ([]HistoryPartition, error) {
+	rows, err := querier.conn.Query(`SELECT c.relname, pg_get_expr(c.relpartbound, c.oid)
+		FROM pg_inherits i
+		INNER JOIN pg_class c ON c.oid = i.inhrelid
+		INNER JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = $1
+		ORDER BY c.relname`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partitions := make([]HistoryPartition, 0)
+	for rows.Next() {
+		var name, bound string
+		if err = rows.Scan(&name, &bound); err != nil {
+			return nil, err
+		}
+		from, to, isDefault, err := parsePartitionBound(bound)
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, HistoryPartition{Table: table, Name: name, From: from, To: to, IsDefault: isDefault})
+	}
+	return partitions, rows.Err()
+}
+
+// Create creates the partition [from, to) of a history table
+// The rows of this range stored in the default partition (if any) are moved to the new partition
+func (querier HistoryPartitionsQuerier) Create(table string, name string, from time.Time, to time.Time, defaultPartition string) error {
+	if table == "" || name == "" || !from.Before(to) {
+		return errors.New("invalid partition definition")
+	}
+
+	tx, err := querier.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS)`, pq.QuoteIdentifier(name), pq.QuoteIdentifier(table)))
+	if err != nil {
+		return err
+	}
+
+	if defaultPartition != "" {
+		_, err = tx.Exec(fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s WHERE ts >= $1 AND ts < $2`,
+			pq.QuoteIdentifier(name), pq.QuoteIdentifier(defaultPartition)), from, to)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE ts >= $1 AND ts < $2`, pq.QuoteIdentifier(defaultPartition)), from, to)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (%s) TO (%s)`,
+		pq.QuoteIdentifier(table), pq.QuoteIdentifier(name),
+		pq.QuoteLiteral(from.UTC().Format(time.RFC3339)), pq.QuoteLiteral(to.UTC().Format(time.RFC3339))))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Oldest returns the timestamp of the oldest row of a partition, or a zero time if it is empty
+func (querier HistoryPartitionsQuerier) Oldest(name string) (time.Time, error) {
+	var oldest sql.NullTime
+	err := querier.conn.QueryRow(fmt.Sprintf(`SELECT min(ts) FROM %s`, pq.QuoteIdentifier(name))).Scan(&oldest)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return oldest.Time, nil
+}
+
+// Drop drops a partition and every row it contains
+func (querier HistoryPartitionsQuerier) Drop(name string) error {
+	_, err := querier.conn.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s`, pq.QuoteIdentifier(name)))
+	return err
+}
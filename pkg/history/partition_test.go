@@ -0,0 +1,93 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePartitionBound(t *testing.T) {
+	from, to, isDefault, err := parsePartitionBound("FOR VALUES FROM ('2026-05-18 00:00:00+00') TO ('2026-05-19 02:00:00+02')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isDefault || !from.Equal(time.Date(2026, 5, 18, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2026, 5, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected bound %v %v %v", from, to, isDefault)
+	}
+
+	from, to, _, err = parsePartitionBound("FOR VALUES FROM (MINVALUE) TO ('2026-05-19 05:30:00.5+05:30')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !from.IsZero() || !to.Equal(time.Date(2026, 5, 19, 0, 0, 0, 500000000, time.UTC)) {
+		t.Errorf("unexpected bound %v %v", from, to)
+	}
+
+	if _, _, isDefault, err = parsePartitionBound("DEFAULT"); err != nil || !isDefault {
+		t.Errorf("expected a default partition")
+	}
+	if _, _, _, err = parsePartitionBound("FOR VALUES IN (1, 2)"); err == nil {
+		t.Error("expected an error on a list partition")
+	}
+}
+
+func TestHistoryPartitionRetention(t *testing.T) {
+	day := time.Date(2026, 5, 18, 0, 0, 0, 0, time.UTC)
+	partitions := []HistoryPartition{
+		{Name: "situation_history_v5_legacy", To: day},
+		{Name: "situation_history_v5_p20260518", From: day, To: day.AddDate(0, 0, 1)},
+		{Name: "situation_history_v5_p20260519", From: day.AddDate(0, 0, 1), To: day.AddDate(0, 0, 2)},
+		{Name: "situation_history_v5_default", IsDefault: true},
+	}
+
+	expired := expiredPartitions(partitions, day.AddDate(0, 0, 1).Add(12*time.Hour))
+	if len(expired) != 2 || expired[0].Name != "situation_history_v5_legacy" || expired[1].Name != "situation_history_v5_p20260518" {
+		t.Errorf("unexpected expired partitions %+v", expired)
+	}
+
+	if _, found := overdueLegacyPartition(partitions, day.AddDate(0, 0, 1)); found {
+		t.Error("expected the expired legacy partition not to be purged row by row")
+	}
+	if legacy, found := overdueLegacyPartition(partitions, day.Add(-12*time.Hour)); !found || legacy.Name != "situation_history_v5_legacy" {
+		t.Errorf("expected the legacy partition to be purged row by row, got %+v", legacy)
+	}
+	if _, found := overdueLegacyPartition(partitions[1:], day.Add(-12*time.Hour)); found {
+		t.Error("expected no legacy partition")
+	}
+
+	if !overlapsPartitions(partitions, day.AddDate(0, 0, -3), day.AddDate(0, 0, -2)) {
+		t.Error("expected the legacy partition to overlap")
+	}
+	if overlapsPartitions(partitions, day.AddDate(0, 0, 2), day.AddDate(0, 0, 3)) {
+		t.Error("expected no partition to overlap")
+	}
+	if name := partitionName("fact_history_v5", day.AddDate(0, 0, 2)); name != "fact_history_v5_p20260520" {
+		t.Errorf("unexpected partition name %s", name)
+	}
+}
+
+func TestHistoryPartitionDefaultRetention(t *testing.T) {
+	day := time.Date(2026, 5, 18, 0, 0, 0, 0, time.UTC)
+	// No partition job has run since the legacy partition expired, every row is stored in the default partition
+	partitions := []HistoryPartition{
+		{Name: "situation_history_v5_default", IsDefault: true},
+	}
+
+	if expired := expiredPartitions(partitions, day); len(expired) != 0 {
+		t.Errorf("expected the default partition never to be dropped, got %+v", expired)
+	}
+	if _, found := overdueLegacyPartition(partitions, day); found {
+		t.Error("expected no legacy partition")
+	}
+	if p, found := overdueDefaultPartition(partitions, day.AddDate(0, 0, -3), day.Add(12*time.Hour)); !found || p.Name != "situation_history_v5_default" {
+		t.Errorf("expected the expired rows of the default partition to be purged, got %+v", p)
+	}
+	if _, found := overdueDefaultPartition(partitions, day.Add(6*time.Hour), day.Add(12*time.Hour)); found {
+		t.Error("expected the rows of the expired day to be kept")
+	}
+	if _, found := overdueDefaultPartition(partitions, time.Time{}, day); found {
+		t.Error("expected an empty default partition not to be purged")
+	}
+	if _, found := overdueDefaultPartition(nil, day.AddDate(0, 0, -3), day); found {
+		t.Error("expected no default partition")
+	}
+}
@@ -0,0 +1,346 @@
+package history
+
+import (
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+	"go.uber.org/zap"
+)
+
+// RollupGranularities lists the rollup granularities, from the finest to the coarsest
+var RollupGranularities = []string{RollupHour, RollupDay, RollupMonth}
+
+// rollupChunk is the time range of raw history loaded at once to compute the hourly rollups
+const rollupChunk = 24 * time.Hour
+
+// ChooseRollup returns the coarsest rollup granularity whose buckets can be merged in the buckets of the down-sampling
+func ChooseRollup(d search.DownSampling) (string, bool) {
+	switch d.GranularitySpecial {
+	case "year", "quarter", "month":
+		return RollupMonth, true
+	case "week", "day":
+		return RollupDay, true
+	case "hour":
+		return RollupHour, true
+	case "":
+		switch {
+		case d.Granularity <= 0:
+			return "", false
+		case d.Granularity%(24*time.Hour) == 0:
+			return RollupDay, true
+		case d.Granularity%time.Hour == 0:
+			return RollupHour, true
+		}
+	}
+	return "", false
+}
+
+// supportsRollup returns true if the down-sampling operation can be computed from the rollup aggregates
+func supportsRollup(operation string) bool {
+	_, ok := RollupStats{Count: 1}.Value(operation)
+	return ok
+}
+
+// truncateRollup returns the start of the rollup bucket containing t (rollup buckets are aligned in UTC)
+func truncateRollup(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	switch granularity {
+	case RollupMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case RollupDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	default:
+		return t.Truncate(time.Hour)
+	}
+}
+
+// ceilRollup returns the start of the first rollup bucket starting at or after t
+func ceilRollup(t time.Time, granularity string) time.Time {
+	b := truncateRollup(t, granularity)
+	if b.Equal(t) {
+		return b
+	}
+	return nextRollup(b, granularity)
+}
+
+func nextRollup(b time.Time, granularity string) time.Time {
+	switch granularity {
+	case RollupMonth:
+		return b.AddDate(0, 1, 0)
+	case RollupDay:
+		return b.AddDate(0, 0, 1)
+	default:
+		return b.Add(time.Hour)
+	}
+}
+
+func isRollupAligned(t time.Time, granularity string) bool {
+	return truncateRollup(t, granularity).Equal(t)
+}
+
+// RollupHistory materializes the hourly rollups of the complete hours of [from, to), then updates the daily and monthly rollups
+// The rolled up ranges must be contiguous: each run should start where the previous one stopped
+func (service HistoryService) RollupHistory(from time.Time, to time.Time) error {
+	from = truncateRollup(from, RollupHour)
+	to = truncateRollup(to, RollupHour)
+	if !from.Before(to) {
+		return nil
+	}
+
+	for chunkStart := from; chunkStart.Before(to); {
+		chunkEnd := chunkStart.Add(rollupChunk)
+		if chunkEnd.After(to) {
+			chunkEnd = to
+		}
+
+		options := GetHistorySituationsOptions{SituationID: -1, FromTS: chunkStart, ToTS: chunkEnd}
+		historySituations, err := service.queryHistorySituations(
+			service.HistorySituationsQuerier.Builder.GetHistorySituationsIdsBase(options), false,
+		)
+		if err != nil {
+			return err
+		}
+		historyFacts, historySituationFacts, err := service.GetHistoryFactsFromSituation(historySituations)
+		if err != nil {
+			return err
+		}
+
+		factRollups, expressionFactRollups := computeHourlyRollups(extractSituationHistoryRecords(historySituations, historySituationFacts, historyFacts))
+		err = service.HistoryRollupsQuerier.ReplaceRollups(RollupHour, chunkStart, chunkEnd, factRollups, expressionFactRollups)
+		if err != nil {
+			return err
+		}
+		err = service.HistoryRollupsQuerier.SetCoverage(RollupHour, chunkStart, chunkEnd)
+		if err != nil {
+			return err
+		}
+
+		zap.L().Debug("History hourly rollups materialized", zap.Time("from", chunkStart), zap.Time("to", chunkEnd),
+			zap.Int("facts", len(factRollups)), zap.Int("expressionFacts", len(expressionFactRollups)))
+		chunkStart = chunkEnd
+	}
+
+	for i := 1; i < len(RollupGranularities); i++ {
+		source, target := RollupGranularities[i-1], RollupGranularities[i]
+		if err := service.rollupGranularity(source, target, from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollupGranularity re-aggregates the target buckets overlapping [from, to) from the source rollups
+// Only the target buckets completely covered by the source rollups are marked as materialized
+func (service HistoryService) rollupGranularity(source string, target string, from time.Time, to time.Time) error {
+	err := service.HistoryRollupsQuerier.Rollup(source, target, truncateRollup(from, target), ceilRollup(to, target))
+	if err != nil {
+		return err
+	}
+
+	sourceCoverage, err := service.HistoryRollupsQuerier.GetCoverage(source)
+	if err != nil {
+		return err
+	}
+	coverageFrom := ceilRollup(sourceCoverage.From, target)
+	coverageTo := truncateRollup(sourceCoverage.To, target)
+	if !coverageFrom.Before(coverageTo) {
+		return nil
+	}
+	return service.HistoryRollupsQuerier.SetCoverage(target, coverageFrom, coverageTo)
+}
+
+// computeHourlyRollups aggregates the raw history records by hour
+// Records must be sorted by date, non numeric values are ignored
+func computeHourlyRollups(records []search.SituationHistoryRecord) ([]FactRollup, []ExpressionFactRollup) {
+	factRollups := make([]FactRollup, 0)
+	factIndex := make(map[FactRollup]int)
+	expressionFactRollups := make([]ExpressionFactRollup, 0)
+	expressionFactIndex := make(map[ExpressionFactRollup]int)
+
+	for _, record := range records {
+		bucket := truncateRollup(record.DateTime, RollupHour)
+
+		for _, fact := range record.Facts {
+			values := map[string]interface{}{"value": fact.Value, "doc_count": fact.DocCount}
+			for valueKey, value := range values {
+				v, ok := value.(float64)
+				if !ok {
+					continue
+				}
+				key := FactRollup{Granularity: RollupHour, Bucket: bucket, FactID: fact.FactID,
+					SituationID: record.SituationID, SituationInstanceID: record.SituationInstanceID, ValueKey: valueKey}
+				i, ok := factIndex[key]
+				if !ok {
+					i = len(factRollups)
+					factIndex[key] = i
+					factRollups = append(factRollups, key)
+				}
+				factRollups[i].Stats.Add(v)
+			}
+		}
+
+		for name, value := range record.ExpressionFacts {
+			v, ok := value.(float64)
+			if !ok {
+				continue
+			}
+			key := ExpressionFactRollup{Granularity: RollupHour, Bucket: bucket,
+				SituationID: record.SituationID, SituationInstanceID: record.SituationInstanceID, Name: name}
+			i, ok := expressionFactIndex[key]
+			if !ok {
+				i = len(expressionFactRollups)
+				expressionFactIndex[key] = i
+				expressionFactRollups = append(expressionFactRollups, key)
+			}
+			expressionFactRollups[i].Stats.Add(v)
+		}
+	}
+
+	return factRollups, expressionFactRollups
+}
+
+// searchRollups builds the down-sampled records of the leading buckets which are fully materialized in the rollups
+// It returns the end of the last bucket built from the rollups (zero if no rollup can be used), the following buckets
+// must be computed from the raw history
+func (service HistoryService) searchRollups(q search.Query, options GetHistorySituationsOptions, buckets []time.Time) ([]search.SituationHistoryRecord, time.Time, error) {
+	// Rollups don't keep the situation calendars, required by the calendar status enrichment
+	granularity, ok := ChooseRollup(q.DownSampling)
+	if !ok || !supportsRollup(q.DownSampling.Operation) || options.IncludeCalendarStatus || len(buckets) == 0 {
+		return nil, time.Time{}, nil
+	}
+	for _, b := range buckets {
+		if !isRollupAligned(b, granularity) {
+			return nil, time.Time{}, nil
+		}
+	}
+
+	coverage, err := service.HistoryRollupsQuerier.GetCoverage(granularity)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	end := coverage.To
+	if !options.ToTS.IsZero() && options.ToTS.Before(end) {
+		end = options.ToTS
+	}
+
+	n := 0
+	for n < len(buckets) && !buckets[n].Before(options.FromTS) && !buckets[n].Before(coverage.From) && !q.DownSampling.NextBucket(buckets[n]).After(end) {
+		n++
+	}
+	if n == 0 {
+		return nil, time.Time{}, nil
+	}
+	cutoff := q.DownSampling.NextBucket(buckets[n-1])
+
+	filter := RollupFilter{Granularity: granularity, Situations: options.Situations, FromTS: buckets[0], ToTS: cutoff}
+	factRollups, err := service.HistoryRollupsQuerier.QueryFactRollups(service.HistoryRollupsQuerier.Builder.GetFactRollups(filter))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	expressionFactRollups, err := service.HistoryRollupsQuerier.QueryExpressionFactRollups(service.HistoryRollupsQuerier.Builder.GetExpressionFactRollups(filter))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	records := rollupRecords(factRollups, expressionFactRollups, buckets[:n], q.DownSampling.Operation)
+	for i, record := range records {
+		records[i] = q.Project(record)
+	}
+	return records, cutoff, nil
+}
+
+// rollupRecords merges the rollups by down-sampling bucket and situation instance into history records
+// Rollups must be sorted by bucket
+func rollupRecords(factRollups []FactRollup, expressionFactRollups []ExpressionFactRollup, buckets []time.Time, operation string) []search.SituationHistoryRecord {
+	type recordKey struct {
+		bucket              time.Time
+		situationID         int64
+		situationInstanceID int64
+	}
+	type factKey struct {
+		factID   int64
+		valueKey string
+	}
+
+	keys := make([]recordKey, 0)
+	records := make(map[recordKey]*search.SituationHistoryRecord)
+	factIDs := make(map[recordKey][]int64)
+	factNames := make(map[int64]string)
+	factStats := make(map[recordKey]map[factKey]*RollupStats)
+	expressionFactStats := make(map[recordKey]map[string]*RollupStats)
+
+	recordOf := func(bucket time.Time, situationID int64, situationName string, situationInstanceID int64, situationInstanceName string) recordKey {
+		key := recordKey{bucket: search.BucketOf(buckets, bucket), situationID: situationID, situationInstanceID: situationInstanceID}
+		if _, ok := records[key]; !ok {
+			keys = append(keys, key)
+			records[key] = &search.SituationHistoryRecord{
+				SituationID:           situationID,
+				SituationName:         situationName,
+				SituationInstanceID:   situationInstanceID,
+				SituationInstanceName: situationInstanceName,
+				DateTime:              key.bucket,
+			}
+			factStats[key] = make(map[factKey]*RollupStats)
+			expressionFactStats[key] = make(map[string]*RollupStats)
+		}
+		return key
+	}
+
+	for _, r := range factRollups {
+		key := recordOf(r.Bucket, r.SituationID, r.SituationName, r.SituationInstanceID, r.SituationInstanceName)
+		fk := factKey{factID: r.FactID, valueKey: r.ValueKey}
+		if !containsID(factIDs[key], r.FactID) {
+			factIDs[key] = append(factIDs[key], r.FactID)
+		}
+		if _, ok := factStats[key][fk]; !ok {
+			factStats[key][fk] = &RollupStats{}
+		}
+		factStats[key][fk].Merge(r.Stats)
+		factNames[r.FactID] = r.FactName
+	}
+	for _, r := range expressionFactRollups {
+		key := recordOf(r.Bucket, r.SituationID, r.SituationName, r.SituationInstanceID, r.SituationInstanceName)
+		if _, ok := expressionFactStats[key][r.Name]; !ok {
+			expressionFactStats[key][r.Name] = &RollupStats{}
+		}
+		expressionFactStats[key][r.Name].Merge(r.Stats)
+	}
+
+	result := make([]search.SituationHistoryRecord, 0, len(keys))
+	for _, key := range keys {
+		record := *records[key]
+		for _, factID := range factIDs[key] {
+			fact := search.FactHistoryRecord{DateTime: key.bucket, FactID: factID, FactName: factNames[factID]}
+			if stats, ok := factStats[key][factKey{factID: factID, valueKey: "value"}]; ok {
+				if v, ok := stats.Value(operation); ok {
+					fact.Value = v
+				}
+			}
+			if stats, ok := factStats[key][factKey{factID: factID, valueKey: "doc_count"}]; ok {
+				if v, ok := stats.Value(operation); ok {
+					fact.DocCount = v
+				}
+			}
+			record.Facts = append(record.Facts, fact)
+		}
+		if len(expressionFactStats[key]) > 0 {
+			record.ExpressionFacts = make(map[string]interface{})
+			for name, stats := range expressionFactStats[key] {
+				if v, ok := stats.Value(operation); ok {
+					record.ExpressionFacts[name] = v
+				}
+			}
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package history
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+)
+
+type HistoryRollupsBuilder struct{}
+
+// RollupFilter selects the rollups of some situations (and optionally facts or expression facts) over a time range
+type RollupFilter struct {
+	Granularity string
+	Situations  []search.SituationSelector
+	FactIDs     []int64
+	ValueKeys   []string
+	Names       []string
+	FromTS      time.Time
+	ToTS        time.Time
+}
+
+func (builder HistoryRollupsBuilder) newStatement() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
+
+func (builder HistoryRollupsBuilder) GetCoverage(granularity string) sq.SelectBuilder {
+	return builder.newStatement().
+		Select("rolled_up_from", "rolled_up_to").
+		From("history_rollup_state_v1").
+		Where(sq.Eq{"granularity": granularity})
+}
+
+// SetCoverage extends the range materialized for a rollup granularity
+func (builder HistoryRollupsBuilder) SetCoverage(granularity string, from time.Time, to time.Time) sq.InsertBuilder {
+	return builder.newStatement().
+		Insert("history_rollup_state_v1").
+		Columns("granularity", "rolled_up_from", "rolled_up_to", "last_modified").
+		Values(granularity, from, to, sq.Expr("NOW()")).
+		Suffix(`ON CONFLICT (granularity) DO UPDATE SET
+			rolled_up_from = LEAST(history_rollup_state_v1.rolled_up_from, EXCLUDED.rolled_up_from),
+			rolled_up_to = GREATEST(history_rollup_state_v1.rolled_up_to, EXCLUDED.rolled_up_to),
+			last_modified = EXCLUDED.last_modified`)
+}
+
+func (builder HistoryRollupsBuilder) DeleteFactRollups(granularity string, from time.Time, to time.Time) sq.DeleteBuilder {
+	return builder.newStatement().
+		Delete("fact_history_rollup_v1").
+		Where(sq.Eq{"granularity": granularity}).
+		Where(sq.GtOrEq{"bucket": from}).
+		Where(sq.Lt{"bucket": to})
+}
+
+func (builder HistoryRollupsBuilder) DeleteExpressionFactRollups(granularity string, from time.Time, to time.Time) sq.DeleteBuilder {
+	return builder.newStatement().
+		Delete("expression_fact_history_rollup_v1").
+		Where(sq.Eq{"granularity": granularity}).
+		Where(sq.GtOrEq{"bucket": from}).
+		Where(sq.Lt{"bucket": to})
+}
+
+func (builder HistoryRollupsBuilder) InsertFactRollups(rollups []FactRollup) sq.InsertBuilder {
+	q := builder.newStatement().
+		Insert("fact_history_rollup_v1").
+		Columns("granularity", "bucket", "fact_id", "situation_id", "situation_instance_id", "value_key",
+			"count", "min", "max", "sum", "first", "last")
+	for _, r := range rollups {
+		q = q.Values(r.Granularity, r.Bucket, r.FactID, r.SituationID, r.SituationInstanceID, r.ValueKey,
+			r.Stats.Count, r.Stats.Min, r.Stats.Max, r.Stats.Sum, r.Stats.First, r.Stats.Last)
+	}
+	return q
+}
+
+func (builder HistoryRollupsBuilder) InsertExpressionFactRollups(rollups []ExpressionFactRollup) sq.InsertBuilder {
+	q := builder.newStatement().
+		Insert("expression_fact_history_rollup_v1").
+		Columns("granularity", "bucket", "situation_id", "situation_instance_id", "name",
+			"count", "min", "max", "sum", "first", "last")
+	for _, r := range rollups {
+		q = q.Values(r.Granularity, r.Bucket, r.SituationID, r.SituationInstanceID, r.Name,
+			r.Stats.Count, r.Stats.Min, r.Stats.Max, r.Stats.Sum, r.Stats.First, r.Stats.Last)
+	}
+	return q
+}
+
+// RollupFacts re-aggregates the fact rollups of a finer granularity in a coarser granularity
+func (builder HistoryRollupsBuilder) RollupFacts(source string, target string, from time.Time, to time.Time) sq.InsertBuilder {
+	return builder.newStatement().
+		Insert("fact_history_rollup_v1").
+		Columns("granularity", "bucket", "fact_id", "situation_id", "situation_instance_id", "value_key",
+			"count", "min", "max", "sum", "first", "last").
+		Select(builder.rollupSelect(source, target, from, to, "fact_id", "situation_id", "situation_instance_id", "value_key").
+			From("fact_history_rollup_v1")).
+		Suffix(`ON CONFLICT (granularity, bucket, situation_id, situation_instance_id, fact_id, value_key) DO UPDATE SET
+			count = EXCLUDED.count, min = EXCLUDED.min, max = EXCLUDED.max, sum = EXCLUDED.sum, first = EXCLUDED.first, last = EXCLUDED.last`)
+}
+
+// RollupExpressionFacts re-aggregates the expression fact rollups of a finer granularity in a coarser granularity
+func (builder HistoryRollupsBuilder) RollupExpressionFacts(source string, target string, from time.Time, to time.Time) sq.InsertBuilder {
+	return builder.newStatement().
+		Insert("expression_fact_history_rollup_v1").
+		Columns("granularity", "bucket", "situation_id", "situation_instance_id", "name",
+			"count", "min", "max", "sum", "first", "last").
+		Select(builder.rollupSelect(source, target, from, to, "situation_id", "situation_instance_id", "name").
+			From("expression_fact_history_rollup_v1")).
+		Suffix(`ON CONFLICT (granularity, bucket, situation_id, situation_instance_id, name) DO UPDATE SET
+			count = EXCLUDED.count, min = EXCLUDED.min, max = EXCLUDED.max, sum = EXCLUDED.sum, first = EXCLUDED.first, last = EXCLUDED.last`)
+}
+
+func (builder HistoryRollupsBuilder) rollupSelect(source string, target string, from time.Time, to time.Time, keys ...string) sq.SelectBuilder {
+	columns := []string{"'" + target + "'", "date_trunc('" + target + "', bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS target_bucket"}
+	columns = append(columns, keys...)
+	columns = append(columns,
+		"SUM(count)", "MIN(min)", "MAX(max)", "SUM(sum)",
+		"(ARRAY_AGG(first ORDER BY bucket ASC))[1]", "(ARRAY_AGG(last ORDER BY bucket DESC))[1]",
+	)
+	return builder.newStatement().
+		Select(columns...).
+		Where(sq.Eq{"granularity": source}).
+		Where(sq.GtOrEq{"bucket": from}).
+		Where(sq.Lt{"bucket": to}).
+		GroupBy(append([]string{"target_bucket"}, keys...)...)
+}
+
+func (builder HistoryRollupsBuilder) GetFactRollups(filter RollupFilter) sq.SelectBuilder {
+	q := builder.newStatement().
+		Select("r.granularity", "r.bucket", "r.fact_id", "f.name", "r.situation_id", "s.name", "r.situation_instance_id", "coalesce(si.name, '')",
+			"r.value_key", "r.count", "r.min", "r.max", "r.sum", "r.first", "r.last").
+		From("fact_history_rollup_v1 r").
+		InnerJoin("fact_definition_v1 f on f.id = r.fact_id").
+		InnerJoin("situation_definition_v1 s on s.id = r.situation_id").
+		LeftJoin("situation_template_instances_v1 si on si.id = r.situation_instance_id").
+		OrderBy("r.bucket")
+	q = builder.where(q, filter)
+	if len(filter.FactIDs) > 0 {
+		q = q.Where(sq.Eq{"r.fact_id": filter.FactIDs})
+	}
+	if len(filter.ValueKeys) > 0 {
+		q = q.Where(sq.Eq{"r.value_key": filter.ValueKeys})
+	}
+	return q
+}
+
+func (builder HistoryRollupsBuilder) GetExpressionFactRollups(filter RollupFilter) sq.SelectBuilder {
+	q := builder.newStatement().
+		Select("r.granularity", "r.bucket", "r.situation_id", "s.name", "r.situation_instance_id", "coalesce(si.name, '')",
+			"r.name", "r.count", "r.min", "r.max", "r.sum", "r.first", "r.last").
+		From("expression_fact_history_rollup_v1 r").
+		InnerJoin("situation_definition_v1 s on s.id = r.situation_id").
+		LeftJoin("situation_template_instances_v1 si on si.id = r.situation_instance_id").
+		OrderBy("r.bucket")
+	q = builder.where(q, filter)
+	if len(filter.Names) > 0 {
+		q = q.Where(sq.Eq{"r.name": filter.Names})
+	}
+	return q
+}
+
+func (builder HistoryRollupsBuilder) where(q sq.SelectBuilder, filter RollupFilter) sq.SelectBuilder {
+	q = q.Where(sq.Eq{"r.granularity": filter.Granularity})
+	if len(filter.Situations) > 0 {
+		q = q.Where(situationSelectors("r.", filter.Situations))
+	}
+	if !filter.FromTS.IsZero() {
+		q = q.Where(sq.GtOrEq{"r.bucket": filter.FromTS})
+	}
+	if !filter.ToTS.IsZero() {
+		q = q.Where(sq.Lt{"r.bucket": filter.ToTS})
+	}
+	return q
+}
@@ -0,0 +1,232 @@
+package history
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// Rollup granularities
+const (
+	RollupHour  = "hour"
+	RollupDay   = "day"
+	RollupMonth = "month"
+)
+
+// rollupInsertBatchSize bounds the number of rows inserted per statement (postgresql is limited to 65535 parameters)
+const rollupInsertBatchSize = 1000
+
+// RollupStats are the aggregates of a numeric value over a time bucket
+type RollupStats struct {
+	Count int64   `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Sum   float64 `json:"sum"`
+	First float64 `json:"first"`
+	Last  float64 `json:"last"`
+}
+
+// FactRollup are the aggregates of a fact value ("value" or "doc_count") of a situation instance over a time bucket
+type FactRollup struct {
+	Granularity           string
+	Bucket                time.Time
+	FactID                int64
+	FactName              string
+	SituationID           int64
+	SituationName         string
+	SituationInstanceID   int64
+	SituationInstanceName string
+	ValueKey              string
+	Stats                 RollupStats
+}
+
+// ExpressionFactRollup are the aggregates of an expression fact of a situation instance over a time bucket
+type ExpressionFactRollup struct {
+	Granularity           string
+	Bucket                time.Time
+	SituationID           int64
+	SituationName         string
+	SituationInstanceID   int64
+	SituationInstanceName string
+	Name                  string
+	Stats                 RollupStats
+}
+
+// RollupCoverage is the time range [From, To) materialized for a rollup granularity
+type RollupCoverage struct {
+	Granularity string    `json:"granularity"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+}
+
+// Covers returns true if the range [from, to) is materialized
+func (c RollupCoverage) Covers(from time.Time, to time.Time) bool {
+	return !c.To.IsZero() && !from.Before(c.From) && !to.After(c.To)
+}
+
+// Add adds a value to the aggregates, values must be added in chronological order
+func (s *RollupStats) Add(value float64) {
+	if s.Count == 0 {
+		*s = RollupStats{Count: 1, Min: value, Max: value, Sum: value, First: value, Last: value}
+		return
+	}
+	s.Count++
+	s.Sum += value
+	s.Last = value
+	if value < s.Min {
+		s.Min = value
+	}
+	if value > s.Max {
+		s.Max = value
+	}
+}
+
+// Merge merges the aggregates of the following time bucket
+func (s *RollupStats) Merge(next RollupStats) {
+	if next.Count == 0 {
+		return
+	}
+	if s.Count == 0 {
+		*s = next
+		return
+	}
+	s.Count += next.Count
+	s.Sum += next.Sum
+	s.Last = next.Last
+	if next.Min < s.Min {
+		s.Min = next.Min
+	}
+	if next.Max > s.Max {
+		s.Max = next.Max
+	}
+}
+
+// Value returns the value of a down-sampling operation (first, latest, sum, max, min, avg)
+func (s RollupStats) Value(operation string) (float64, bool) {
+	if s.Count == 0 {
+		return 0, false
+	}
+	switch operation {
+	case "first":
+		return s.First, true
+	case "latest":
+		return s.Last, true
+	case "sum":
+		return s.Sum, true
+	case "max":
+		return s.Max, true
+	case "min":
+		return s.Min, true
+	case "avg":
+		return s.Sum / float64(s.Count), true
+	default:
+		return 0, false
+	}
+}
+
+type HistoryRollupsQuerier struct {
+	Builder HistoryRollupsBuilder
+	conn    *sqlx.DB
+}
+
+func (querier HistoryRollupsQuerier) GetCoverage(granularity string) (RollupCoverage, error) {
+	coverage := RollupCoverage{Granularity: granularity}
+	err := querier.Builder.GetCoverage(granularity).RunWith(querier.conn.DB).QueryRow().Scan(&coverage.From, &coverage.To)
+	if errors.Is(err, sql.ErrNoRows) {
+		return coverage, nil
+	}
+	if err != nil {
+		return RollupCoverage{}, err
+	}
+	return coverage, nil
+}
+
+func (querier HistoryRollupsQuerier) SetCoverage(granularity string, from time.Time, to time.Time) error {
+	_, err := querier.Builder.SetCoverage(granularity, from, to).RunWith(querier.conn.DB).Exec()
+	return err
+}
+
+// ReplaceRollups replaces the rollups of a granularity over a time range in a single transaction
+func (querier HistoryRollupsQuerier) ReplaceRollups(granularity string, from time.Time, to time.Time, factRollups []FactRollup, expressionFactRollups []ExpressionFactRollup) error {
+	tx, err := querier.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = querier.Builder.DeleteFactRollups(granularity, from, to).RunWith(tx).Exec(); err != nil {
+		return err
+	}
+	if _, err = querier.Builder.DeleteExpressionFactRollups(granularity, from, to).RunWith(tx).Exec(); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(factRollups); start += rollupInsertBatchSize {
+		end := min(start+rollupInsertBatchSize, len(factRollups))
+		if _, err = querier.Builder.InsertFactRollups(factRollups[start:end]).RunWith(tx).Exec(); err != nil {
+			return err
+		}
+	}
+	for start := 0; start < len(expressionFactRollups); start += rollupInsertBatchSize {
+		end := min(start+rollupInsertBatchSize, len(expressionFactRollups))
+		if _, err = querier.Builder.InsertExpressionFactRollups(expressionFactRollups[start:end]).RunWith(tx).Exec(); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Rollup re-aggregates the rollups of a finer granularity in a coarser granularity
+func (querier HistoryRollupsQuerier) Rollup(source string, target string, from time.Time, to time.Time) error {
+	if _, err := querier.Builder.RollupFacts(source, target, from, to).RunWith(querier.conn.DB).Exec(); err != nil {
+		return err
+	}
+	_, err := querier.Builder.RollupExpressionFacts(source, target, from, to).RunWith(querier.conn.DB).Exec()
+	return err
+}
+
+func (querier HistoryRollupsQuerier) QueryFactRollups(builder sq.SelectBuilder) ([]FactRollup, error) {
+	rows, err := builder.RunWith(querier.conn.DB).Query()
+	if err != nil {
+		return make([]FactRollup, 0), err
+	}
+	defer rows.Close()
+
+	rollups := make([]FactRollup, 0)
+	for rows.Next() {
+		var r FactRollup
+		err := rows.Scan(&r.Granularity, &r.Bucket, &r.FactID, &r.FactName, &r.SituationID, &r.SituationName,
+			&r.SituationInstanceID, &r.SituationInstanceName, &r.ValueKey,
+			&r.Stats.Count, &r.Stats.Min, &r.Stats.Max, &r.Stats.Sum, &r.Stats.First, &r.Stats.Last)
+		if err != nil {
+			return []FactRollup{}, err
+		}
+		rollups = append(rollups, r)
+	}
+	return rollups, rows.Err()
+}
+
+func (querier HistoryRollupsQuerier) QueryExpressionFactRollups(builder sq.SelectBuilder) ([]ExpressionFactRollup, error) {
+	rows, err := builder.RunWith(querier.conn.DB).Query()
+	if err != nil {
+		return make([]ExpressionFactRollup, 0), err
+	}
+	defer rows.Close()
+
+	rollups := make([]ExpressionFactRollup, 0)
+	for rows.Next() {
+		var r ExpressionFactRollup
+		err := rows.Scan(&r.Granularity, &r.Bucket, &r.SituationID, &r.SituationName,
+			&r.SituationInstanceID, &r.SituationInstanceName, &r.Name,
+			&r.Stats.Count, &r.Stats.Min, &r.Stats.Max, &r.Stats.Sum, &r.Stats.First, &r.Stats.Last)
+		if err != nil {
+			return []ExpressionFactRollup{}, err
+		}
+		rollups = append(rollups, r)
+	}
+	return rollups, rows.Err()
+}
@@ -0,0 +1,167 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+)
+
+func TestRollupStats(t *testing.T) {
+	var s RollupStats
+	for _, v := range []float64{4, 1, 7} {
+		s.Add(v)
+	}
+	expected := RollupStats{Count: 3, Min: 1, Max: 7, Sum: 12, First: 4, Last: 7}
+	if s != expected {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+
+	s.Merge(RollupStats{Count: 1, Min: 10, Max: 10, Sum: 10, First: 10, Last: 10})
+	expected = RollupStats{Count: 4, Min: 1, Max: 10, Sum: 22, First: 4, Last: 10}
+	if s != expected {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+
+	values := map[string]float64{"first": 4, "latest": 10, "sum": 22, "min": 1, "max": 10, "avg": 5.5}
+	for operation, value := range values {
+		if v, ok := s.Value(operation); !ok || v != value {
+			t.Errorf("%s: expected %v, got %v", operation, value, v)
+		}
+	}
+	if _, ok := s.Value("p50"); ok {
+		t.Error("percentiles can't be computed from rollups")
+	}
+	if _, ok := (RollupStats{}).Value("sum"); ok {
+		t.Error("expected no value on empty stats")
+	}
+}
+
+func TestChooseRollup(t *testing.T) {
+	cases := []struct {
+		d        search.DownSampling
+		expected string
+		ok       bool
+	}{
+		{search.DownSampling{GranularitySpecial: "year"}, RollupMonth, true},
+		{search.DownSampling{GranularitySpecial: "week"}, RollupDay, true},
+		{search.DownSampling{GranularitySpecial: "hour"}, RollupHour, true},
+		{search.DownSampling{GranularitySpecial: "minute"}, "", false},
+		{search.DownSampling{Granularity: 48 * time.Hour}, RollupDay, true},
+		{search.DownSampling{Granularity: 6 * time.Hour}, RollupHour, true},
+		{search.DownSampling{Granularity: 90 * time.Minute}, "", false},
+	}
+	for _, c := range cases {
+		if g, ok := ChooseRollup(c.d); g != c.expected || ok != c.ok {
+			t.Errorf("%+v: expected %s (%v), got %s (%v)", c.d, c.expected, c.ok, g, ok)
+		}
+	}
+}
+
+func TestTruncateRollup(t *testing.T) {
+	ts := time.Date(2026, 5, 14, 10, 42, 0, 0, time.UTC)
+	if b := truncateRollup(ts, RollupHour); !b.Equal(time.Date(2026, 5, 14, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected hour bucket %v", b)
+	}
+	if b := truncateRollup(ts, RollupMonth); !b.Equal(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected month bucket %v", b)
+	}
+	if b := ceilRollup(ts, RollupDay); !b.Equal(time.Date(2026, 5, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected day ceil %v", b)
+	}
+	day := time.Date(2026, 5, 14, 0, 0, 0, 0, time.UTC)
+	if b := ceilRollup(day, RollupDay); !b.Equal(day) {
+		t.Errorf("unexpected day ceil %v", b)
+	}
+}
+
+func TestComputeHourlyRollups(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	records := []search.SituationHistoryRecord{
+		{SituationID: 1, SituationInstanceID: 2, DateTime: t0.Add(10 * time.Minute),
+			ExpressionFacts: map[string]interface{}{"exp": 1.0, "label": "a"},
+			Facts:           []search.FactHistoryRecord{{FactID: 3, Value: 10.0, DocCount: 5.0}}},
+		{SituationID: 1, SituationInstanceID: 2, DateTime: t0.Add(20 * time.Minute),
+			ExpressionFacts: map[string]interface{}{"exp": 3.0},
+			Facts:           []search.FactHistoryRecord{{FactID: 3, Value: 30.0}}},
+		{SituationID: 1, SituationInstanceID: 2, DateTime: t0.Add(70 * time.Minute),
+			ExpressionFacts: map[string]interface{}{"exp": 5.0}},
+	}
+
+	factRollups, expressionFactRollups := computeHourlyRollups(records)
+	if len(factRollups) != 2 {
+		t.Fatalf("expected 2 fact rollups, got %+v", factRollups)
+	}
+	for _, r := range factRollups {
+		switch r.ValueKey {
+		case "value":
+			if r.Stats != (RollupStats{Count: 2, Min: 10, Max: 30, Sum: 40, First: 10, Last: 30}) {
+				t.Errorf("unexpected value rollup %+v", r)
+			}
+		case "doc_count":
+			if r.Stats.Count != 1 || r.Stats.Sum != 5 {
+				t.Errorf("unexpected doc_count rollup %+v", r)
+			}
+		}
+	}
+
+	if len(expressionFactRollups) != 2 {
+		t.Fatalf("expected 2 expression fact rollups, got %+v", expressionFactRollups)
+	}
+	if r := expressionFactRollups[0]; !r.Bucket.Equal(t0) || r.Name != "exp" || r.Stats.Sum != 4 {
+		t.Errorf("unexpected first expression fact rollup %+v", r)
+	}
+	if r := expressionFactRollups[1]; !r.Bucket.Equal(t0.Add(time.Hour)) || r.Stats.Sum != 5 {
+		t.Errorf("unexpected second expression fact rollup %+v", r)
+	}
+}
+
+func TestRollupRecords(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	buckets := []time.Time{t0, t0.AddDate(0, 0, 1)}
+	factRollups := []FactRollup{
+		{Bucket: t0, FactID: 3, FactName: "f3", SituationID: 1, SituationInstanceID: 2, ValueKey: "value",
+			Stats: RollupStats{Count: 2, Min: 1, Max: 3, Sum: 4, First: 1, Last: 3}},
+		{Bucket: t0.Add(time.Hour), FactID: 3, FactName: "f3", SituationID: 1, SituationInstanceID: 2, ValueKey: "value",
+			Stats: RollupStats{Count: 1, Min: 6, Max: 6, Sum: 6, First: 6, Last: 6}},
+		{Bucket: t0.Add(time.Hour), FactID: 3, FactName: "f3", SituationID: 1, SituationInstanceID: 2, ValueKey: "doc_count",
+			Stats: RollupStats{Count: 1, Min: 2, Max: 2, Sum: 2, First: 2, Last: 2}},
+	}
+	expressionFactRollups := []ExpressionFactRollup{
+		{Bucket: t0.AddDate(0, 0, 1), SituationID: 1, SituationInstanceID: 2, Name: "exp",
+			Stats: RollupStats{Count: 4, Min: 1, Max: 4, Sum: 10, First: 1, Last: 4}},
+	}
+
+	records := rollupRecords(factRollups, expressionFactRollups, buckets, "avg")
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %+v", records)
+	}
+	if !records[0].DateTime.Equal(t0) || len(records[0].Facts) != 1 {
+		t.Fatalf("unexpected first record %+v", records[0])
+	}
+	if f := records[0].Facts[0]; f.FactName != "f3" || f.Value != 10.0/3 || f.DocCount != 2.0 {
+		t.Errorf("unexpected fact %+v", f)
+	}
+	if !records[1].DateTime.Equal(buckets[1]) || records[1].ExpressionFacts["exp"] != 2.5 {
+		t.Errorf("unexpected second record %+v", records[1])
+	}
+}
+
+func TestDownSampleValues(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	values := []timedValue{
+		{ts: t0.Add(90 * time.Minute), value: 5},
+		{ts: t0.Add(10 * time.Minute), value: 1},
+		{ts: t0.Add(20 * time.Minute), value: 3},
+	}
+
+	result := downSampleValues(search.DownSampling{GranularitySpecial: "hour", Operation: "latest"}, t0, values)
+	if len(result) != 2 || result[0].value != 3 || !result[1].ts.Equal(t0.Add(time.Hour)) || result[1].value != 5 {
+		t.Errorf("unexpected latest values %+v", result)
+	}
+
+	result = downSampleValues(search.DownSampling{Granularity: 2 * time.Hour, Operation: "p50"}, t0, values)
+	if len(result) != 1 || result[0].value != 3 {
+		t.Errorf("unexpected p50 values %+v", result)
+	}
+}
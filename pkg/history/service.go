@@ -8,6 +8,8 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/draft"
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/explainer/issues"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
 var (
@@ -19,6 +21,10 @@ type HistoryService struct {
 	HistorySituationsQuerier     HistorySituationsQuerier
 	HistorySituationFactsQuerier HistorySituationFactsQuerier
 	HistoryFactsQuerier          HistoryFactsQuerier
+	HistoryRollupsQuerier        HistoryRollupsQuerier
+	HistoryPartitionsQuerier     HistoryPartitionsQuerier
+	HistoryArchivesQuerier       HistoryArchivesQuerier
+	HistoryArchiveStore          HistoryArchiveStore
 }
 
 func New(db *sqlx.DB) HistoryService {
@@ -26,6 +32,10 @@ func New(db *sqlx.DB) HistoryService {
 		HistorySituationsQuerier:     HistorySituationsQuerier{conn: db, Builder: HistorySituationsBuilder{}},
 		HistorySituationFactsQuerier: HistorySituationFactsQuerier{conn: db, Builder: HistorySituationFactsBuilder{}},
 		HistoryFactsQuerier:          HistoryFactsQuerier{conn: db, Builder: HistoryFactsBuilder{}},
+		HistoryRollupsQuerier:        HistoryRollupsQuerier{conn: db, Builder: HistoryRollupsBuilder{}},
+		HistoryPartitionsQuerier:     HistoryPartitionsQuerier{conn: db},
+		HistoryArchivesQuerier:       HistoryArchivesQuerier{conn: db},
+		HistoryArchiveStore:          HistoryArchiveStore{Path: viper.GetString("HISTORY_ARCHIVE_PATH")},
 	}
 }
 
@@ -89,6 +99,11 @@ func (service HistoryService) GetHistorySituationsIdsByCustomInterval(options Ge
 	)
 }
 
+// GetHistorySituationsRange returns every situation history record of the options range
+func (service HistoryService) GetHistorySituationsRange(options GetHistorySituationsOptions) ([]HistorySituationsV4, error) {
+	return service.queryHistorySituations(service.HistorySituationsQuerier.Builder.GetHistorySituationsIdsBase(options), options.IncludeCalendarStatus)
+}
+
 func (service HistoryService) GetHistoryFactsFromSituation(historySituations []HistorySituationsV4) ([]HistoryFactsV4, []HistorySituationFactsV4, error) {
 	historySituationsIds := make([]int64, 0)
 	for _, item := range historySituations {
@@ -121,7 +136,16 @@ func (service HistoryService) GetHistoryFactsFromSituationIds(historySituationsI
 	return historyFacts, historySituationFacts, nil
 }
 
+// PurgeHistory deletes the history older than options.DeleteBeforeTs, and the issues detected on it
+// A purge of the whole history drops the expired partitions, so the rows of the most recent expired day are kept
+// until their whole partition expires (the expired rows of the legacy and default partitions are deleted one by one).
+// The missing daily partitions are created first, so the history is purged even if no partition job is scheduled.
+// A purge restricted to some situations deletes the rows one by one.
+// With options.Archive, the dropped partitions are archived to files first (archiving isn't supported by restricted purges).
 func (service HistoryService) PurgeHistory(options GetHistorySituationsOptions) error {
+	if options.SituationID == -1 && len(options.SituationInstanceIDs) == 0 && len(options.Situations) == 0 && len(options.ParameterFilters) == 0 {
+		return service.dropHistoryPurge(options)
+	}
 	return service.deleteHistoryPurge(
 		service.HistorySituationsQuerier.Builder.GetHistorySituationsIdsBase(options), options,
 	)
@@ -157,30 +181,109 @@ func (service HistoryService) deleteHistory(selector sq.SelectBuilder) error {
 
 	return nil
 }
-func (service HistoryService) deleteHistoryPurge(selector sq.SelectBuilder, options GetHistorySituationsOptions) error {
-	err := service.HistorySituationFactsQuerier.ExecDelete(
-		service.HistorySituationFactsQuerier.Builder.DeleteHistoryFrom(selector),
-	)
+func (service HistoryService) dropHistoryPurge(options GetHistorySituationsOptions) error {
+	// The rows of the default partition are moved to their daily partition, only its expired rows are left in it
+	_, err := service.EnsureHistoryPartitions(options.DeleteBeforeTs, time.Now().Add(historyPartitionInterval))
 	if err != nil {
 		return err
 	}
 
-	err = issues.R().DeleteOldIssueDetections(options.DeleteBeforeTs)
+	partitions, err := service.HistoryPartitionsQuerier.List("situation_history_v5")
+	if err != nil {
+		return err
+	}
+
+	if err = service.purgeOverdueRows(partitions, options); err != nil {
+		return err
+	}
+
+	// The issues are only deleted up to the end of the last expired partition, like the history itself
+	expired := expiredPartitions(partitions, options.DeleteBeforeTs)
+	cutoff := time.Time{}
+	for _, p := range expired {
+		if p.To.After(cutoff) {
+			cutoff = p.To
+		}
+	}
+	if cutoff.IsZero() {
+		return nil
+	}
+
+	if options.Archive {
+		// A zero start (the unbounded legacy partition) archives everything before the cutoff
+		from := cutoff
+		for _, p := range expired {
+			if p.From.Before(from) {
+				from = p.From
+			}
+		}
+		if _, err = service.ArchiveHistory(from, cutoff); err != nil {
+			return err
+		}
+	}
+
+	if err = service.deleteOldIssues(cutoff); err != nil {
+		return err
+	}
+
+	dropped, err := service.DropHistoryPartitions(cutoff)
 	if err != nil {
 		return err
 	}
+	zap.L().Info("Auto purge of the history partitions", zap.Int("Number of partitions dropped", dropped))
+
+	return nil
+}
+
+// purgeOverdueRows deletes the expired rows of the unbounded legacy partition and of the default partition one by one,
+// up to the start of the expired day, as the legacy partition is only dropped once its most recent day has expired
+// and the default partition is never dropped
+func (service HistoryService) purgeOverdueRows(partitions []HistoryPartition, options GetHistorySituationsOptions) error {
+	partition, found := overdueLegacyPartition(partitions, options.DeleteBeforeTs)
+	if !found {
+		for _, p := range partitions {
+			if !p.IsDefault {
+				continue
+			}
+			oldest, err := service.HistoryPartitionsQuerier.Oldest(p.Name)
+			if err != nil {
+				return err
+			}
+			partition, found = overdueDefaultPartition(partitions, oldest, options.DeleteBeforeTs)
+		}
+	}
+	if !found {
+		return nil
+	}
+	cutoff := truncateRollup(options.DeleteBeforeTs, RollupDay)
+
+	if options.Archive {
+		if _, err := service.ArchiveHistory(time.Time{}, cutoff); err != nil {
+			return err
+		}
+	}
 
-	err = issues.R().DeleteOldIssueResolutions(options.DeleteBeforeTs)
+	err := service.deleteHistoryPurge(
+		service.HistorySituationsQuerier.Builder.GetHistorySituationsIdsBase(GetHistorySituationsOptions{SituationID: -1, ToTS: cutoff}),
+		GetHistorySituationsOptions{DeleteBeforeTs: cutoff},
+	)
 	if err != nil {
 		return err
 	}
+	zap.L().Info("Auto purge of the history partition rows", zap.String("partition", partition.Name), zap.Time("before", cutoff))
 
-	err = draft.R().DeleteOldIssueResolutionsDrafts(options.DeleteBeforeTs)
+	return nil
+}
+
+func (service HistoryService) deleteHistoryPurge(selector sq.SelectBuilder, options GetHistorySituationsOptions) error {
+	err := service.HistorySituationFactsQuerier.ExecDelete(
+		service.HistorySituationFactsQuerier.Builder.DeleteHistoryFrom(selector),
+	)
 	if err != nil {
 		return err
 	}
 
-	err = issues.R().DeleteOldIssues(options.DeleteBeforeTs)
+	err = service.deleteOldIssues(options.DeleteBeforeTs)
 	if err != nil {
 		return err
 	}
@@ -201,3 +304,24 @@ func (service HistoryService) deleteHistoryPurge(selector sq.SelectBuilder, opti
 
 	return nil
 }
+
+// deleteOldIssues deletes the issues (and their feedbacks) detected on the history older than a date
+// It must run before the history deletion, as issues are selected by their situation history
+func (service HistoryService) deleteOldIssues(before time.Time) error {
+	err := issues.R().DeleteOldIssueDetections(before)
+	if err != nil {
+		return err
+	}
+
+	err = issues.R().DeleteOldIssueResolutions(before)
+	if err != nil {
+		return err
+	}
+
+	err = draft.R().DeleteOldIssueResolutionsDrafts(before)
+	if err != nil {
+		return err
+	}
+
+	return issues.R().DeleteOldIssues(before)
+}
@@ -0,0 +1,188 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+)
+
+// timedValue is a dated numeric value of the history
+type timedValue struct {
+	ts    time.Time
+	value float64
+}
+
+// timedStats are dated aggregates of the history
+type timedStats struct {
+	ts    time.Time
+	stats RollupStats
+}
+
+// GetFactResultByDate returns the results of a fact between two dates
+// With a down-sampling, the results are aggregated by time bucket, from the rollups if they cover the requested range
+func (service HistoryService) GetFactResultByDate(param ParamGetFactHistoryByDate) (GetFactHistory, error) {
+	if param.DownSampling == nil {
+		return service.HistoryFactsQuerier.GetFactResultByDate(param)
+	}
+	start, end := parseDateRange(param.StartDate, param.EndDate)
+	d := *param.DownSampling
+
+	var values []timedValue
+	granularity, ok, err := service.rollupFor(d, start, end)
+	if err != nil {
+		return GetFactHistory{}, err
+	}
+	if ok {
+		rollups, err := service.HistoryRollupsQuerier.QueryFactRollups(service.HistoryRollupsQuerier.Builder.GetFactRollups(RollupFilter{
+			Granularity: granularity,
+			Situations:  []search.SituationSelector{{SituationID: param.SituationID, SituationInstanceIDs: []int64{param.SituationInstanceID}}},
+			FactIDs:     []int64{param.FactID},
+			ValueKeys:   []string{"value"},
+			FromTS:      start,
+			ToTS:        end,
+		}))
+		if err != nil {
+			return GetFactHistory{}, err
+		}
+		stats := make([]timedStats, 0, len(rollups))
+		for _, r := range rollups {
+			stats = append(stats, timedStats{ts: r.Bucket, stats: r.Stats})
+		}
+		values = downSampleStats(d, start, stats)
+	} else {
+		raw, err := service.HistoryFactsQuerier.QueryFactValues(service.HistoryFactsQuerier.Builder.GetFactResultByDate(param))
+		if err != nil {
+			return GetFactHistory{}, err
+		}
+		values = downSampleValues(d, start, raw)
+	}
+
+	results := make([]FactResult, 0, len(values))
+	for _, v := range values {
+		results = append(results, FactResult{Value: int64(v.value), FormattedTime: v.ts.Format(FormatDateHourMinute)})
+	}
+	return GetFactHistory{Results: results}, nil
+}
+
+// GetFactExprResultByDate returns the values of an expression fact between two dates
+// With a down-sampling, the values are aggregated by time bucket, from the rollups if they cover the requested range
+func (service HistoryService) GetFactExprResultByDate(param ParamGetFactExprHistoryByDate) (GetFactExprHistory, error) {
+	if param.DownSampling == nil {
+		return service.HistorySituationsQuerier.GetFactExprResultByDate(param)
+	}
+	start, end := parseDateRange(param.StartDate, param.EndDate)
+	d := *param.DownSampling
+
+	var values []timedValue
+	granularity, ok, err := service.rollupFor(d, start, end)
+	if err != nil {
+		return GetFactExprHistory{}, err
+	}
+	if ok {
+		rollups, err := service.HistoryRollupsQuerier.QueryExpressionFactRollups(service.HistoryRollupsQuerier.Builder.GetExpressionFactRollups(RollupFilter{
+			Granularity: granularity,
+			Situations:  []search.SituationSelector{{SituationID: param.SituationID, SituationInstanceIDs: []int64{param.SituationInstanceID}}},
+			Names:       []string{param.FactExpr},
+			FromTS:      start,
+			ToTS:        end,
+		}))
+		if err != nil {
+			return GetFactExprHistory{}, err
+		}
+		stats := make([]timedStats, 0, len(rollups))
+		for _, r := range rollups {
+			stats = append(stats, timedStats{ts: r.Bucket, stats: r.Stats})
+		}
+		values = downSampleStats(d, start, stats)
+	} else {
+		raw, err := service.HistorySituationsQuerier.QueryFactExprValues(service.HistorySituationsQuerier.Builder.GetFactExprResultByDate(param), param.FactExpr)
+		if err != nil {
+			return GetFactExprHistory{}, err
+		}
+		values = downSampleValues(d, start, raw)
+	}
+
+	results := make([]FactExprResult, 0, len(values))
+	for _, v := range values {
+		results = append(results, FactExprResult{Value: v.value, FormattedTime: v.ts.Format(FormatDateHourMinute)})
+	}
+	return GetFactExprHistory{Results: results}, nil
+}
+
+// rollupFor returns the rollup granularity to use for a down-sampling over [start, end), if the range is fully materialized
+func (service HistoryService) rollupFor(d search.DownSampling, start time.Time, end time.Time) (string, bool, error) {
+	granularity, ok := ChooseRollup(d)
+	if !ok || !supportsRollup(d.Operation) || !isRollupAligned(start, granularity) || !isRollupAligned(end, granularity) {
+		return "", false, nil
+	}
+	coverage, err := service.HistoryRollupsQuerier.GetCoverage(granularity)
+	if err != nil {
+		return "", false, err
+	}
+	return granularity, coverage.Covers(start, end), nil
+}
+
+// parseDateRange parses the dates of the history date endpoints (already validated), rollups are compared in UTC
+func parseDateRange(startDate string, endDate string) (time.Time, time.Time) {
+	start, _ := time.Parse("2006-01-02 15:04:05", startDate)
+	end, _ := time.Parse("2006-01-02 15:04:05", endDate)
+	return start, end
+}
+
+// downSampleValues aggregates the values by down-sampling bucket, buckets of custom granularities are counted from origin
+func downSampleValues(d search.DownSampling, origin time.Time, values []timedValue) []timedValue {
+	sort.SliceStable(values, func(i, j int) bool { return values[i].ts.Before(values[j].ts) })
+
+	if p, ok := d.Percentile(); ok {
+		buckets := make([]time.Time, 0)
+		bucketValues := make(map[time.Time][]float64)
+		for _, v := range values {
+			b := d.Truncate(v.ts, origin)
+			if _, ok := bucketValues[b]; !ok {
+				buckets = append(buckets, b)
+			}
+			bucketValues[b] = append(bucketValues[b], v.value)
+		}
+		result := make([]timedValue, 0, len(buckets))
+		for _, b := range buckets {
+			result = append(result, timedValue{ts: b, value: search.Percentile(bucketValues[b], p)})
+		}
+		return result
+	}
+
+	stats := make([]timedStats, 0, len(values))
+	for _, v := range values {
+		s := timedStats{ts: v.ts}
+		s.stats.Add(v.value)
+		stats = append(stats, s)
+	}
+	return downSampleStats(d, origin, stats)
+}
+
+// downSampleStats merges the aggregates by down-sampling bucket and computes the down-sampling operation
+func downSampleStats(d search.DownSampling, origin time.Time, stats []timedStats) []timedValue {
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].ts.Before(stats[j].ts) })
+
+	operation := d.Operation
+	if operation == "" {
+		operation = "latest"
+	}
+
+	merged := make([]timedStats, 0)
+	for _, s := range stats {
+		b := d.Truncate(s.ts, origin)
+		if len(merged) == 0 || !merged[len(merged)-1].ts.Equal(b) {
+			merged = append(merged, timedStats{ts: b})
+		}
+		merged[len(merged)-1].stats.Merge(s.stats)
+	}
+
+	result := make([]timedValue, 0, len(merged))
+	for _, s := range merged {
+		if v, ok := s.stats.Value(operation); ok {
+			result = append(result, timedValue{ts: s.ts, value: v})
+		}
+	}
+	return result
+}
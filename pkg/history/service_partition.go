@@ -0,0 +1,109 @@
+package history
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const historyPartitionInterval = 24 * time.Hour
+
+// EnsureHistoryPartitions creates the missing daily partitions of the history tables over [from, to)
+func (service HistoryService) EnsureHistoryPartitions(from time.Time, to time.Time) (int, error) {
+	created := 0
+	for _, table := range HistoryPartitionedTables {
+		partitions, err := service.HistoryPartitionsQuerier.List(table)
+		if err != nil {
+			return created, err
+		}
+
+		defaultPartition := ""
+		for _, p := range partitions {
+			if p.IsDefault {
+				defaultPartition = p.Name
+			}
+		}
+
+		for day := truncateRollup(from, RollupDay); day.Before(to); day = day.Add(historyPartitionInterval) {
+			next := day.Add(historyPartitionInterval)
+			if overlapsPartitions(partitions, day, next) {
+				continue
+			}
+
+			name := partitionName(table, day)
+			if err = service.HistoryPartitionsQuerier.Create(table, name, day, next, defaultPartition); err != nil {
+				return created, err
+			}
+			partitions = append(partitions, HistoryPartition{Table: table, Name: name, From: day, To: next})
+			created++
+			zap.L().Info("History partition created", zap.String("partition", name))
+		}
+	}
+	return created, nil
+}
+
+// DropHistoryPartitions drops the partitions of the history tables whose rows are all older than before
+func (service HistoryService) DropHistoryPartitions(before time.Time) (int, error) {
+	dropped := 0
+	for _, table := range HistoryPartitionedTables {
+		partitions, err := service.HistoryPartitionsQuerier.List(table)
+		if err != nil {
+			return dropped, err
+		}
+
+		for _, p := range expiredPartitions(partitions, before) {
+			if err = service.HistoryPartitionsQuerier.Drop(p.Name); err != nil {
+				return dropped, err
+			}
+			dropped++
+			zap.L().Info("History partition dropped", zap.String("partition", p.Name), zap.Time("to", p.To))
+		}
+	}
+	return dropped, nil
+}
+
+func overlapsPartitions(partitions []HistoryPartition, from time.Time, to time.Time) bool {
+	for _, p := range partitions {
+		if p.Overlaps(from, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// expiredPartitions returns the bounded partitions ending before a date (the default partition is never expired)
+func expiredPartitions(partitions []HistoryPartition, before time.Time) []HistoryPartition {
+	expired := make([]HistoryPartition, 0)
+	for _, p := range partitions {
+		if !p.IsDefault && !p.To.IsZero() && !p.To.After(before) {
+			expired = append(expired, p)
+		}
+	}
+	return expired
+}
+
+// overdueLegacyPartition returns the unbounded legacy partition if it still has rows older than the start of the day of a date
+// (it is dropped like the daily partitions once it has fully expired)
+func overdueLegacyPartition(partitions []HistoryPartition, before time.Time) (HistoryPartition, bool) {
+	cutoff := truncateRollup(before, RollupDay)
+	for _, p := range partitions {
+		if !p.IsDefault && p.From.IsZero() && !p.To.IsZero() && p.To.After(cutoff) {
+			return p, true
+		}
+	}
+	return HistoryPartition{}, false
+}
+
+// overdueDefaultPartition returns the default partition if its oldest row is older than the start of the day of a date
+// (rows land in it when no daily partition has been created for them yet, so it is never dropped and is purged row by row)
+func overdueDefaultPartition(partitions []HistoryPartition, oldest time.Time, before time.Time) (HistoryPartition, bool) {
+	if oldest.IsZero() || !oldest.Before(truncateRollup(before, RollupDay)) {
+		return HistoryPartition{}, false
+	}
+	for _, p := range partitions {
+		if p.IsDefault {
+			return p, true
+		}
+	}
+	return HistoryPartition{}, false
+}
@@ -0,0 +1,216 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+)
+
+// Search executes a search query on the history of several situations and instances at once
+//
+// With a Time, the latest record of each situation instance before this time is returned.
+// Without down-sampling, every record of the range is returned, paginated by records.
+// With down-sampling, the records are grouped by time bucket and paginated by buckets: the first or latest
+// record of each bucket is selected in database, other operations are read from the coarsest materialized rollup
+// when possible, or aggregated from every record of the bucket.
+func (service HistoryService) Search(q search.Query) (search.QueryPage, error) {
+	options := GetHistorySituationsOptions{
+		SituationID:           -1,
+		Situations:            q.Situations,
+		FromTS:                q.Start,
+		ToTS:                  q.End,
+		IncludeCalendarStatus: q.IncludeCalendarStatus,
+	}
+
+	cursor, err := search.DecodeCursor(q.Cursor)
+	if err != nil {
+		return search.QueryPage{}, err
+	}
+
+	switch {
+	case !q.Time.IsZero():
+		return service.searchAt(q, options)
+	case q.DownSampling.IsSet():
+		return service.searchDownSampled(q, options, cursor)
+	default:
+		return service.searchRange(q, options, cursor)
+	}
+}
+
+func (service HistoryService) searchAt(q search.Query, options GetHistorySituationsOptions) (search.QueryPage, error) {
+	options.FromTS = time.Time{}
+	options.ToTS = q.Time.Add(time.Microsecond)
+
+	historySituations, err := service.GetHistorySituationsIdsLast(options)
+	if err != nil {
+		return search.QueryPage{}, err
+	}
+
+	records, err := service.searchRecords(q, historySituations)
+	if err != nil {
+		return search.QueryPage{}, err
+	}
+
+	result := search.GroupByDateTime(records, func(search.SituationHistoryRecord) time.Time { return q.Time })
+	return search.QueryPage{Results: service.enrichSearchResult(q, result)}, nil
+}
+
+func (service HistoryService) searchRange(q search.Query, options GetHistorySituationsOptions, cursor search.Cursor) (search.QueryPage, error) {
+	limit := q.PageLimit()
+
+	historySituations, err := service.queryHistorySituations(
+		service.HistorySituationsQuerier.Builder.GetHistorySituationsIdsPage(options, cursor, uint64(limit+1)), options.IncludeCalendarStatus,
+	)
+	if err != nil {
+		return search.QueryPage{}, err
+	}
+
+	sort.Slice(historySituations, func(i, j int) bool {
+		if historySituations[i].Ts.Equal(historySituations[j].Ts) {
+			return historySituations[i].ID < historySituations[j].ID
+		}
+		return historySituations[i].Ts.Before(historySituations[j].Ts)
+	})
+
+	page := search.QueryPage{}
+	if len(historySituations) > limit {
+		historySituations = historySituations[:limit]
+		last := historySituations[limit-1]
+		page.NextCursor = search.EncodeCursor(search.Cursor{TS: last.Ts, ID: last.ID})
+	}
+
+	records, err := service.searchRecords(q, historySituations)
+	if err != nil {
+		return search.QueryPage{}, err
+	}
+
+	result := search.GroupByDateTime(records, func(record search.SituationHistoryRecord) time.Time { return record.DateTime })
+	page.Results = service.enrichSearchResult(q, result)
+	return page, nil
+}
+
+func (service HistoryService) searchDownSampled(q search.Query, options GetHistorySituationsOptions, cursor search.Cursor) (search.QueryPage, error) {
+	limit := q.PageLimit()
+
+	bucket := StandardIntervalBucket(q.DownSampling.GranularitySpecial)
+	if q.DownSampling.GranularitySpecial == "" {
+		bucket = CustomIntervalBucket(q.DownSampling.Granularity, q.Start)
+	}
+
+	if !cursor.TS.IsZero() {
+		options.FromTS = cursor.TS
+	}
+
+	buckets, err := service.HistorySituationsQuerier.QueryTimes(
+		service.HistorySituationsQuerier.Builder.GetHistorySituationsBuckets(options, bucket, uint64(limit+1)),
+	)
+	if err != nil {
+		return search.QueryPage{}, err
+	}
+
+	page := search.QueryPage{Results: make(search.QueryResult, 0)}
+	if len(buckets) == 0 {
+		return page, nil
+	}
+	if len(buckets) > limit {
+		// Buckets are contiguous time ranges, so the next page starts exactly at the next bucket
+		options.ToTS = buckets[limit]
+		page.NextCursor = search.EncodeCursor(search.Cursor{TS: buckets[limit]})
+		buckets = buckets[:limit]
+	}
+
+	var records []search.SituationHistoryRecord
+	if q.DownSampling.IsAggregation() {
+		records, err = service.searchAggregated(q, options, buckets)
+	} else {
+		records, err = service.searchFirstOrLatest(q, options, bucket)
+	}
+	if err != nil {
+		return search.QueryPage{}, err
+	}
+
+	result := search.GroupByDateTime(records, func(record search.SituationHistoryRecord) time.Time {
+		return search.BucketOf(buckets, record.DateTime)
+	})
+	page.Results = service.enrichSearchResult(q, result)
+	return page, nil
+}
+
+// searchAggregated aggregates the records of each bucket, the leading buckets materialized in the rollups are read from the rollups
+func (service HistoryService) searchAggregated(q search.Query, options GetHistorySituationsOptions, buckets []time.Time) ([]search.SituationHistoryRecord, error) {
+	records, cutoff, err := service.searchRollups(q, options, buckets)
+	if err != nil {
+		return nil, err
+	}
+	if !cutoff.IsZero() {
+		if !options.ToTS.IsZero() && !cutoff.Before(options.ToTS) {
+			return records, nil
+		}
+		options.FromTS = cutoff
+	}
+
+	historySituations, err := service.queryHistorySituations(
+		service.HistorySituationsQuerier.Builder.GetHistorySituationsIdsBase(options), options.IncludeCalendarStatus,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rawRecords, err := service.searchRecords(q, historySituations)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(records, search.DownSample(rawRecords, buckets, q.DownSampling.Operation)...), nil
+}
+
+func (service HistoryService) searchFirstOrLatest(q search.Query, options GetHistorySituationsOptions, bucket string) ([]search.SituationHistoryRecord, error) {
+	historySituations, err := service.queryHistorySituations(
+		service.HistorySituationsQuerier.Builder.GetHistorySituationsIdsByBucket(options, bucket, q.DownSampling.Operation == "first"), options.IncludeCalendarStatus,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.searchRecords(q, historySituations)
+}
+
+func (service HistoryService) queryHistorySituations(selector sq.SelectBuilder, withRuleCalendars bool) ([]HistorySituationsV4, error) {
+	subQuery, subQueryArgs, err := selector.ToSql()
+	if err != nil {
+		return make([]HistorySituationsV4, 0), err
+	}
+
+	return service.HistorySituationsQuerier.Query(
+		service.HistorySituationsQuerier.Builder.GetHistorySituationsDetails(subQuery, subQueryArgs, withRuleCalendars),
+	)
+}
+
+// searchRecords loads the facts of the history records, applies the query projections and forecasts the facts
+func (service HistoryService) searchRecords(q search.Query, historySituations []HistorySituationsV4) ([]search.SituationHistoryRecord, error) {
+	historyFacts, historySituationFacts, err := service.GetHistoryFactsFromSituation(historySituations)
+	if err != nil {
+		return nil, err
+	}
+
+	records := extractSituationHistoryRecords(historySituations, historySituationFacts, historyFacts)
+	for i, record := range records {
+		records[i] = q.Project(record)
+	}
+	if q.Forecast != nil {
+		if err = service.forecastSearchRecords(*q.Forecast, records); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+func (service HistoryService) enrichSearchResult(q search.Query, result search.QueryResult) search.QueryResult {
+	if q.IncludeCalendarStatus {
+		result = EnrichCalendarStatus(result)
+		result = EnrichRuleCalendarStatus(result)
+	}
+	return result
+}
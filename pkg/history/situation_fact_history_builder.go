@@ -4,7 +4,17 @@ import (
 	sq "github.com/Masterminds/squirrel"
 )
 
-type HistorySituationFactsBuilder struct{}
+type HistorySituationFactsBuilder struct {
+	// Table overrides the situation fact history table read by the search queries (ie. the rehydrated archives)
+	Table string
+}
+
+func (builder HistorySituationFactsBuilder) table() string {
+	if builder.Table != "" {
+		return builder.Table
+	}
+	return "situation_fact_history_v5"
+}
 
 func (builder HistorySituationFactsBuilder) newStatement() sq.StatementBuilderType {
 	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
@@ -12,17 +22,17 @@ func (builder HistorySituationFactsBuilder) newStatement() sq.StatementBuilderTy
 
 func (builder HistorySituationFactsBuilder) GetHistorySituationFacts(historySituationsIds []int64) sq.SelectBuilder {
 	return builder.newStatement().
-		Select("*").
-		From("situation_fact_history_v5").
+		Select("situation_history_id", "fact_history_id", "fact_id", "ts").
+		From(builder.table()).
 		Where(sq.Eq{"situation_history_id": historySituationsIds})
 }
 
 func (builder HistorySituationFactsBuilder) InsertBulk(historySituationFacts []HistorySituationFactsV4) sq.InsertBuilder {
 	b := builder.newStatement().
 		Insert("situation_fact_history_v5").
-		Columns("situation_history_id", "fact_history_id", "fact_id")
+		Columns("situation_history_id", "fact_history_id", "fact_id", "ts")
 	for _, hishistorySituationFact := range historySituationFacts {
-		b = b.Values(hishistorySituationFact.HistorySituationID, hishistorySituationFact.HistoryFactID, hishistorySituationFact.FactID)
+		b = b.Values(hishistorySituationFact.HistorySituationID, hishistorySituationFact.HistoryFactID, hishistorySituationFact.FactID, hishistorySituationFact.Ts)
 	}
 
 	return b
@@ -3,6 +3,7 @@ package history
 import (
 	"database/sql"
 	"errors"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
@@ -13,6 +14,7 @@ type HistorySituationFactsV4 struct {
 	HistorySituationID int64
 	HistoryFactID      int64
 	FactID             int64
+	Ts                 time.Time // timestamp of the situation history, used as partition key
 }
 
 type HistorySituationFactsQuerier struct {
@@ -64,7 +66,7 @@ func (querier HistorySituationFactsQuerier) Query(builder sq.SelectBuilder) ([]H
 func (querier HistorySituationFactsQuerier) scan(rows *sql.Rows) (HistorySituationFactsV4, error) {
 	item := HistorySituationFactsV4{}
 
-	err := rows.Scan(&item.HistorySituationID, &item.HistoryFactID, &item.FactID)
+	err := rows.Scan(&item.HistorySituationID, &item.HistoryFactID, &item.FactID, &item.Ts)
 	if err != nil {
 		return HistorySituationFactsV4{}, errors.New("couldn't scan the retrieved data: " + err.Error())
 	}
@@ -5,13 +5,18 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
 )
 
-type HistorySituationsBuilder struct{}
+type HistorySituationsBuilder struct {
+	// Table overrides the situation history table read by the search queries (ie. the rehydrated archives)
+	Table string
+}
 
 type GetHistorySituationsOptions struct {
 	SituationID          int64
 	SituationInstanceIDs []int64
+	Situations           []search.SituationSelector // selects several situations (and optionally some of their instances) at once
 	ParameterFilters     map[string]interface{}
 	DeleteBeforeTs       time.Time
 	FromTS               time.Time
@@ -20,17 +25,26 @@ type GetHistorySituationsOptions struct {
 	//   - IsNowOutsideCalendar: real-time check at retrieval time (time.Now())
 	//   - WereRulesOutsideCalendar: historical check at the record's own timestamp
 	IncludeCalendarStatus bool
+	// Archive, if true, archives the history to files before purging it
+	Archive bool
 }
 
 func (builder HistorySituationsBuilder) newStatement() sq.StatementBuilderType {
 	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 }
 
+func (builder HistorySituationsBuilder) table() string {
+	if builder.Table != "" {
+		return builder.Table
+	}
+	return "situation_history_v5"
+}
+
 func (builder HistorySituationsBuilder) GetHistorySituationsIdsBase(options GetHistorySituationsOptions) sq.SelectBuilder {
-	q := builder.newStatement().
-		Select("id").
-		From("situation_history_v5")
+	return builder.where(builder.newStatement().Select("id").From(builder.table()), options)
+}
 
+func (builder HistorySituationsBuilder) where(q sq.SelectBuilder, options GetHistorySituationsOptions) sq.SelectBuilder {
 	if options.SituationID != -1 {
 		q = q.Where(sq.Eq{"situation_id": options.SituationID})
 	}
@@ -39,6 +53,10 @@ func (builder HistorySituationsBuilder) GetHistorySituationsIdsBase(options GetH
 		q = q.Where(sq.Eq{"situation_instance_id": options.SituationInstanceIDs})
 	}
 
+	if len(options.Situations) > 0 {
+		q = q.Where(situationSelectors("", options.Situations))
+	}
+
 	if !options.FromTS.IsZero() {
 		q = q.Where(sq.GtOrEq{"ts": options.FromTS})
 	}
@@ -65,18 +83,82 @@ func (builder HistorySituationsBuilder) GetHistorySituationsIdsLast(options GetH
 }
 
 func (builder HistorySituationsBuilder) GetHistorySituationsIdsByStandardInterval(options GetHistorySituationsOptions, interval string) sq.SelectBuilder {
-	return builder.GetHistorySituationsIdsBase(options).
-		Options("distinct on (situation_id, situation_instance_id, date_trunc('"+interval+"', ts))").
-		OrderBy("situation_id", "situation_instance_id", "date_trunc('"+interval+"', ts) desc, ts desc")
+	return builder.GetHistorySituationsIdsByBucket(options, StandardIntervalBucket(interval), false)
 }
 
 func (builder HistorySituationsBuilder) GetHistorySituationsIdsByCustomInterval(options GetHistorySituationsOptions, interval time.Duration, referenceDate time.Time) sq.SelectBuilder {
-	intervalSeconds := fmt.Sprintf("%d", int64(interval.Seconds()))
-	referenceDateStr := referenceDate.Format("2006-01-02T15:04:05Z07:00")
+	return builder.GetHistorySituationsIdsByBucket(options, CustomIntervalBucket(interval, referenceDate), false)
+}
 
+// GetHistorySituationsIdsByBucket selects the latest (or the first) history record of each situation instance in each time bucket
+func (builder HistorySituationsBuilder) GetHistorySituationsIdsByBucket(options GetHistorySituationsOptions, bucket string, first bool) sq.SelectBuilder {
+	order := "desc"
+	if first {
+		order = "asc"
+	}
 	return builder.GetHistorySituationsIdsBase(options).
-		Options("distinct on (situation_id, situation_instance_id, CAST('"+referenceDateStr+"' AS TIMESTAMPTZ) + INTERVAL '1 second' * "+intervalSeconds+" * FLOOR(DATE_PART('epoch', ts- '"+referenceDateStr+"')/"+intervalSeconds+"))").
-		OrderBy("situation_id", "situation_instance_id", "CAST('"+referenceDateStr+"' AS TIMESTAMPTZ) + INTERVAL '1 second' * "+intervalSeconds+" * FLOOR(DATE_PART('epoch', ts- '"+referenceDateStr+"')/"+intervalSeconds+") desc, ts desc")
+		Options("distinct on (situation_id, situation_instance_id, "+bucket+")").
+		OrderBy("situation_id", "situation_instance_id", bucket+" "+order+", ts "+order)
+}
+
+// GetHistorySituationsBuckets selects the distinct time buckets containing history records, in chronological order
+func (builder HistorySituationsBuilder) GetHistorySituationsBuckets(options GetHistorySituationsOptions, bucket string, limit uint64) sq.SelectBuilder {
+	q := builder.where(builder.newStatement().Select(bucket+" AS bucket").Distinct().From(builder.table()), options).
+		OrderBy("bucket")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	return q
+}
+
+// GetHistorySituationsIdsPage selects the history records following a cursor, in chronological order
+func (builder HistorySituationsBuilder) GetHistorySituationsIdsPage(options GetHistorySituationsOptions, after search.Cursor, limit uint64) sq.SelectBuilder {
+	q := builder.GetHistorySituationsIdsBase(options).
+		OrderBy("ts", "id")
+	if !after.TS.IsZero() {
+		q = q.Where(sq.Expr("(ts, id) > (?, ?)", after.TS, after.ID))
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	return q
+}
+
+// GetHistorySituationsIdsByBucketOrdered selects the latest history record of each situation instance in each time bucket,
+// in chronological order
+func (builder HistorySituationsBuilder) GetHistorySituationsIdsByBucketOrdered(options GetHistorySituationsOptions, bucket string) sq.SelectBuilder {
+	return builder.newStatement().Select("id", "ts").
+		FromSelect(builder.GetHistorySituationsIdsByBucket(options, bucket, false).Columns("ts"), "b").
+		OrderBy("ts", "id")
+}
+
+// situationSelectors matches the rows of several situations (and optionally some of their instances)
+// prefix is the table alias of the situation_id and situation_instance_id columns (ie. "r.")
+func situationSelectors(prefix string, selectors []search.SituationSelector) sq.Or {
+	or := sq.Or{}
+	for _, selector := range selectors {
+		if len(selector.SituationInstanceIDs) > 0 {
+			or = append(or, sq.And{
+				sq.Eq{prefix + "situation_id": selector.SituationID},
+				sq.Eq{prefix + "situation_instance_id": selector.SituationInstanceIDs},
+			})
+		} else {
+			or = append(or, sq.Eq{prefix + "situation_id": selector.SituationID})
+		}
+	}
+	return or
+}
+
+// StandardIntervalBucket returns the expression of a time bucket truncated to a standard interval (year, month, day, ...)
+func StandardIntervalBucket(interval string) string {
+	return "date_trunc('" + interval + "', ts)"
+}
+
+// CustomIntervalBucket returns the expression of a time bucket of a custom duration starting at a reference date
+func CustomIntervalBucket(interval time.Duration, referenceDate time.Time) string {
+	intervalSeconds := fmt.Sprintf("%d", int64(interval.Seconds()))
+	referenceDateStr := referenceDate.Format("2006-01-02T15:04:05Z07:00")
+	return "CAST('" + referenceDateStr + "' AS TIMESTAMPTZ) + INTERVAL '1 second' * " + intervalSeconds + " * FLOOR(DATE_PART('epoch', ts- '" + referenceDateStr + "')/" + intervalSeconds + ")"
 }
 
 // GetHistorySituationsDetails builds the detail query for situation history records.
@@ -92,7 +174,7 @@ func (builder HistorySituationsBuilder) GetHistorySituationsDetails(subQueryIds
 		From("situation_definition_v1 s").
 		LeftJoin("situation_template_instances_v1 si on s.id = si.situation_id").
 		LeftJoin("calendar_v1 c on c.id = COALESCE(si.calendar_id, s.calendar_id)").
-		InnerJoin("situation_history_v5 sh on (s.id = sh.situation_id and (sh.situation_instance_id = si.id OR sh.situation_instance_id = 0))").
+		InnerJoin(builder.table()+" sh on (s.id = sh.situation_id and (sh.situation_instance_id = si.id OR sh.situation_instance_id = 0))").
 		Where("sh.id = any ("+subQueryIds+")", subQueryIdsArgs...)
 
 	if withRuleCalendars {
@@ -103,3 +103,21 @@ func TestGetLatestHistorySituation(t *testing.T) {
 		t.Errorf("Expected args to be %v, but got %v", expectedArgs, args)
 	}
 }
+
+func TestGetHistorySituationsIdsByBucketOrdered(t *testing.T) {
+	options := GetHistorySituationsOptions{SituationID: 1}
+	bucket := CustomIntervalBucket(time.Hour, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	query, args, err := HistorySituationsBuilder{}.GetHistorySituationsIdsByBucketOrdered(options, bucket).ToSql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "SELECT id, ts FROM (SELECT distinct on (situation_id, situation_instance_id, " + bucket + ") id, ts FROM situation_history_v5 WHERE situation_id = $1 " +
+		"ORDER BY situation_id, situation_instance_id, " + bucket + " desc, ts desc) AS b ORDER BY ts, id"
+	if query != expected {
+		t.Errorf("unexpected query\n%s\nexpected\n%s", query, expected)
+	}
+	if !reflect.DeepEqual(args, []interface{}{int64(1)}) {
+		t.Errorf("unexpected args %v", args)
+	}
+}
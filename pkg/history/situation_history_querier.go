@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/myrteametrics/myrtea-engine-api/v5/internal/model"
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/calendar"
 	"github.com/myrteametrics/myrtea-engine-api/v5/pkg/metadata"
 
@@ -52,8 +53,9 @@ type ParamGetFactExprHistory struct {
 }
 type ParamGetFactExprHistoryByDate struct {
 	ParamGetFactExprHistory
-	StartDate string `json:"startDate"` // Expected format: "2006-01-02 15:04:05"
-	EndDate   string `json:"endDate"`   // Expected format: "2006-01-02 15:04:05"
+	StartDate    string               `json:"startDate"`              // Expected format: "2006-01-02 15:04:05"
+	EndDate      string               `json:"endDate"`                // Expected format: "2006-01-02 15:04:05"
+	DownSampling *search.DownSampling `json:"downSampling,omitempty"` // Optional, aggregates the results by time bucket
 }
 
 type GetFactExprHistory struct {
@@ -186,6 +188,88 @@ func (querier HistorySituationsQuerier) QueryIDs(builder sq.SelectBuilder) ([]in
 	return querier.scanAllIDs(rows)
 }
 
+func (querier HistorySituationsQuerier) QueryTimes(builder sq.SelectBuilder) ([]time.Time, error) {
+	rows, err := builder.RunWith(querier.conn.DB).Query()
+	if err != nil {
+		return make([]time.Time, 0), err
+	}
+	defer rows.Close()
+
+	times := make([]time.Time, 0)
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return []time.Time{}, err
+		}
+		times = append(times, t)
+	}
+
+	return times, rows.Err()
+}
+
+// QueryContext is a cancellable Query
+func (querier HistorySituationsQuerier) QueryContext(ctx context.Context, builder sq.SelectBuilder) ([]HistorySituationsV4, error) {
+	rows, err := builder.RunWith(querier.conn.DB).QueryContext(ctx)
+	if err != nil {
+		return make([]HistorySituationsV4, 0), err
+	}
+	defer rows.Close()
+
+	return querier.scanAll(rows)
+}
+
+// QueryCursors returns the (id, ts) rows of a query as cursors
+func (querier HistorySituationsQuerier) QueryCursors(ctx context.Context, builder sq.SelectBuilder) ([]search.Cursor, error) {
+	rows, err := builder.RunWith(querier.conn.DB).QueryContext(ctx)
+	if err != nil {
+		return make([]search.Cursor, 0), err
+	}
+	defer rows.Close()
+
+	cursors := make([]search.Cursor, 0)
+	for rows.Next() {
+		var c search.Cursor
+		if err := rows.Scan(&c.ID, &c.TS); err != nil {
+			return []search.Cursor{}, err
+		}
+		cursors = append(cursors, c)
+	}
+
+	return cursors, rows.Err()
+}
+
+// StreamCursors reads the (id, ts) rows of a query in a single pass, and calls fn with each batch of batchSize cursors
+// The query keeps running while fn is called, so the whole result is never loaded in memory
+func (querier HistorySituationsQuerier) StreamCursors(ctx context.Context, builder sq.SelectBuilder, batchSize int, fn func(cursors []search.Cursor) error) error {
+	rows, err := builder.RunWith(querier.conn.DB).QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]search.Cursor, 0, batchSize)
+	for rows.Next() {
+		var c search.Cursor
+		if err = rows.Scan(&c.ID, &c.TS); err != nil {
+			return err
+		}
+		batch = append(batch, c)
+		if len(batch) == batchSize {
+			if err = fn(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
 func (querier HistorySituationsQuerier) scanAllIDs(rows *sql.Rows) ([]int64, error) {
 	ids := make([]int64, 0)
 
@@ -349,11 +433,8 @@ func (querier *HistorySituationsQuerier) QueryGetSpecificFactExpr(builder sq.Sel
 		}
 
 		factRes := FactExprResult{FormattedTime: ts.Format(formatTime)}
-
-		if factExprValue, ok := parsedResult[factExpr]; ok {
-			if v, ok := factExprValue.(float64); ok {
-				factRes.Value = v
-			}
+		if v, ok := parsedResult[factExpr].(float64); ok {
+			factRes.Value = v
 		}
 		results = append(results, factRes)
 	}
@@ -361,6 +442,34 @@ func (querier *HistorySituationsQuerier) QueryGetSpecificFactExpr(builder sq.Sel
 	return GetFactExprHistory{Results: results}, nil
 }
 
+// QueryFactExprValues returns the dated values of an expression fact selected by the builder (non numeric values are skipped)
+func (querier HistorySituationsQuerier) QueryFactExprValues(builder sq.SelectBuilder, factExpr string) ([]timedValue, error) {
+	rows, err := builder.RunWith(querier.conn).Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]timedValue, 0)
+	for rows.Next() {
+		var resultBytes []byte
+		var ts time.Time
+		if err = rows.Scan(&resultBytes, &ts); err != nil {
+			return nil, err
+		}
+
+		var parsedResult map[string]interface{}
+		if err = json.Unmarshal(resultBytes, &parsedResult); err != nil {
+			return nil, err
+		}
+
+		if v, ok := parsedResult[factExpr].(float64); ok {
+			values = append(values, timedValue{ts: ts, value: v})
+		}
+	}
+	return values, rows.Err()
+}
+
 func (querier HistorySituationsQuerier) GetTodaysFactExprResultByParameters(param ParamGetFactExprHistory) (GetFactExprHistory, error) {
 	builder := querier.Builder.GetTodaysFactExprResultByParameters(param)
 	return querier.QueryGetSpecificFactExpr(builder, FormatHourMinute, param.FactExpr)
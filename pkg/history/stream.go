@@ -0,0 +1,52 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/myrteametrics/myrtea-engine-api/v5/internal/search"
+
+	"github.com/lib/pq"
+)
+
+// StreamBatchSize is the number of situation history records read at once (with their facts) by a stream
+const StreamBatchSize = 500
+
+// StreamHistorySituationsByCustomInterval streams the records of GetHistorySituationsIdsByCustomInterval in chronological order,
+// by batches of StreamBatchSize records, instead of loading the whole range in memory
+// The bucketed query runs once and its rows are read as the batches are written, fn is called with each batch as soon as
+// it is read, the stream stops on the first error of fn or when ctx is done
+func (service HistoryService) StreamHistorySituationsByCustomInterval(ctx context.Context, options GetHistorySituationsOptions, interval time.Duration,
+	referenceDate time.Time, fn func(records []search.SituationHistoryRecord) error) error {
+
+	builder := service.HistorySituationsQuerier.Builder
+	bucket := CustomIntervalBucket(interval, referenceDate)
+
+	return service.HistorySituationsQuerier.StreamCursors(ctx, builder.GetHistorySituationsIdsByBucketOrdered(options, bucket), StreamBatchSize,
+		func(cursors []search.Cursor) error {
+			ids := make([]int64, 0, len(cursors))
+			for _, c := range cursors {
+				ids = append(ids, c.ID)
+			}
+			historySituations, err := service.HistorySituationsQuerier.QueryContext(ctx,
+				builder.GetHistorySituationsDetails("select unnest(?::bigint[])", []interface{}{pq.Array(ids)}, options.IncludeCalendarStatus),
+			)
+			if err != nil {
+				return err
+			}
+
+			historyFacts, historySituationFacts, err := service.GetHistoryFactsFromSituation(historySituations)
+			if err != nil {
+				return err
+			}
+
+			records := extractSituationHistoryRecords(historySituations, historySituationFacts, historyFacts)
+			if options.IncludeCalendarStatus {
+				result := search.QueryResult{{Situations: records}}
+				result = EnrichCalendarStatus(result)
+				result = EnrichRuleCalendarStatus(result)
+				records = result[0].Situations
+			}
+			return fn(records)
+		})
+}
@@ -19,6 +19,22 @@ const (
 )
 
 func ExtractHistoryDataSearch(historySituations []HistorySituationsV4, historySituationFacts []HistorySituationFactsV4, historyFacts []HistoryFactsV4) search.QueryResult {
+	situationRecords := extractSituationHistoryRecords(historySituations, historySituationFacts, historyFacts)
+
+	// TODO: REMOVE THIS ?
+	// returns situationRecords []search.SituationHistoryRecord directly ?
+	situationHistoryRecord := search.SituationHistoryRecords{
+		DateTime:   time.Time{},
+		Situations: situationRecords,
+	}
+	searchResult := []search.SituationHistoryRecords{
+		situationHistoryRecord,
+	}
+
+	return searchResult
+}
+
+func extractSituationHistoryRecords(historySituations []HistorySituationsV4, historySituationFacts []HistorySituationFactsV4, historyFacts []HistoryFactsV4) []search.SituationHistoryRecord {
 	mapFacts := make(map[int64]HistoryFactsV4)
 	for _, historyFact := range historyFacts {
 		mapFacts[historyFact.ID] = historyFact
@@ -45,17 +61,7 @@ func ExtractHistoryDataSearch(historySituations []HistorySituationsV4, historySi
 		return situationRecords[i].DateTime.Before(situationRecords[j].DateTime)
 	})
 
-	// TODO: REMOVE THIS ?
-	// returns situationRecords []search.SituationHistoryRecord directly ?
-	situationHistoryRecord := search.SituationHistoryRecords{
-		DateTime:   time.Time{},
-		Situations: situationRecords,
-	}
-	searchResult := []search.SituationHistoryRecords{
-		situationHistoryRecord,
-	}
-
-	return searchResult
+	return situationRecords
 }
 
 func buildSituationHistoryRecord(historySituation HistorySituationsV4, mapFacts map[int64]HistoryFactsV4, mapSituationFact map[int64][]int64) search.SituationHistoryRecord {
@@ -0,0 +1,36 @@
+package baseline
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	_globalBuiltinMu sync.RWMutex
+	_globalBuiltin   BaselineService
+)
+
+// RegisterBuiltin registers the built-in baseline service, used when no baseline plugin is running
+func RegisterBuiltin(service BaselineService) func() {
+	_globalBuiltinMu.Lock()
+	defer _globalBuiltinMu.Unlock()
+
+	prev := _globalBuiltin
+	_globalBuiltin = service
+	return func() { RegisterBuiltin(prev) }
+}
+
+// S returns the baseline service of the baseline plugin if it is available, or the built-in baseline service
+func S() (BaselineService, error) {
+	if plugin, err := P(); err == nil && plugin.BaselineService != nil {
+		return plugin.BaselineService, nil
+	}
+
+	_globalBuiltinMu.RLock()
+	defer _globalBuiltinMu.RUnlock()
+
+	if _globalBuiltin == nil {
+		return nil, errors.New("no Baseline service found, feature is not available")
+	}
+	return _globalBuiltin, nil
+}
@@ -31,6 +31,60 @@ type Item struct {
 	Baselines   map[string]baseline.BaselineValue `json:"baselines,omitempty"`
 }
 
+// Clone returns a deep copy of the widget data (hits fields are shallow copied)
+func (wd *WidgetData) Clone() *WidgetData {
+	if wd == nil {
+		return nil
+	}
+	clone := &WidgetData{Aggregates: wd.Aggregates.Clone()}
+	if wd.Hits != nil {
+		clone.Hits = make([]Hit, 0, len(wd.Hits))
+		for _, hit := range wd.Hits {
+			fields := make(map[string]interface{}, len(hit.Fields))
+			for key, value := range hit.Fields {
+				fields[key] = value
+			}
+			clone.Hits = append(clone.Hits, Hit{ID: hit.ID, Fields: fields})
+		}
+	}
+	return clone
+}
+
+// Clone returns a deep copy of the item and its sub-items
+func (item *Item) Clone() *Item {
+	if item == nil {
+		return nil
+	}
+	clone := &Item{Key: item.Key, KeyAsString: item.KeyAsString}
+	if item.Aggs != nil {
+		clone.Aggs = make(map[string]*ItemAgg, len(item.Aggs))
+		for key, agg := range item.Aggs {
+			if agg == nil {
+				clone.Aggs[key] = nil
+				continue
+			}
+			clone.Aggs[key] = &ItemAgg{Value: agg.Value}
+		}
+	}
+	if item.Buckets != nil {
+		clone.Buckets = make(map[string][]*Item, len(item.Buckets))
+		for key, buckets := range item.Buckets {
+			cloned := make([]*Item, 0, len(buckets))
+			for _, bucket := range buckets {
+				cloned = append(cloned, bucket.Clone())
+			}
+			clone.Buckets[key] = cloned
+		}
+	}
+	if item.Baselines != nil {
+		clone.Baselines = make(map[string]baseline.BaselineValue, len(item.Baselines))
+		for key, value := range item.Baselines {
+			clone.Baselines[key] = value
+		}
+	}
+	return clone
+}
+
 // ToAbstractMap convert an Item in an abstract map[string]interface{}
 func (item *Item) ToAbstractMap() (map[string]interface{}, error) {
 	b, err := json.Marshal(item)
@@ -34,6 +34,11 @@ const (
 	TypeConfig                      = "config"
 	TypeAPIKey                      = "api_key"
 	TypeTemplate                    = "template"
+	TypeBaseline                    = "baseline"
+	TypeIssueSLA                    = "issue_sla"
+	TypeIncident                    = "incident"
+	TypeIssueWebhook                = "issue_webhook"
+	TypeMaintenance                 = "maintenance"
 	TypeFunctionalSituation         = "functional_situation"
 	TypeFunctionalSituationInstance = "functional_situation_instance"
 	TypeFunctionalSituationContent  = "functional_situation_content"
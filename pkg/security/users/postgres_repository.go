@@ -45,6 +45,20 @@ func (r *PostgresRepository) Get(userUUID uuid.UUID) (User, bool, error) {
 	return dbutils.ScanFirstStruct[User](rows)
 }
 
+// GetByLogin search and returns an User from the repository by its login
+func (r *PostgresRepository) GetByLogin(login string) (User, bool, error) {
+	rows, err := r.newStatement().
+		Select(fields...).
+		From(table).
+		Where("login = ?", login).
+		Query()
+	if err != nil {
+		return User{}, false, err
+	}
+	defer rows.Close()
+	return dbutils.ScanFirstStruct[User](rows)
+}
+
 // Create creates a new User in the repository
 func (r *PostgresRepository) Create(user UserWithPassword) (uuid.UUID, error) {
 	newUUID := uuid.New()
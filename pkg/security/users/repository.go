@@ -11,6 +11,7 @@ import (
 // It allows standard CRUD operation on facts
 type Repository interface {
 	Get(uuid uuid.UUID) (User, bool, error)
+	GetByLogin(login string) (User, bool, error)
 	Create(user UserWithPassword) (uuid.UUID, error)
 	Update(user User) error
 	UpdateWithPassword(user UserWithPassword) error
@@ -60,6 +60,12 @@ var (
 	// ErrAPITooManyRequests must be used in case the client has sent too many requests in a given amount of time
 	ErrAPITooManyRequests = APIError{Status: http.StatusTooManyRequests, ErrType: "ResourceError", Code: 2004, Message: `Too many requests, please try again later`}
 
+	// ErrAPIResourceInUse must be used when a resource cannot be deleted because other resources depend on it
+	ErrAPIResourceInUse = APIError{Status: http.StatusConflict, ErrType: "ResourceError", Code: 2005, Message: `Resource is still in use and cannot be deleted`}
+
+	// ErrAPIRequestTooLarge must be used when the request body exceeds its maximum size
+	ErrAPIRequestTooLarge = APIError{Status: http.StatusRequestEntityTooLarge, ErrType: "ResourceError", Code: 2006, Message: `Request body is too large`}
+
 	// ErrAPIDBResourceNotFound must be used in case a resource is not found in the backend storage system
 	ErrAPIDBResourceNotFound = APIError{Status: http.StatusNotFound, ErrType: "ResourceError", Code: 3000, Message: `Ressource not found`}
 	// ErrAPIDBSelectFailed must be used when a select query returns an error from the backend storage system